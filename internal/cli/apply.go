@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/reporter"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// exportTaskPatch writes the changes made in a patch-only worktree to
+// <outputDir>/changes.patch with a per-file summary in changes.json.
+// Returns nil when the task changed nothing.
+func exportTaskPatch(ctx context.Context, dir, base, outputDir string) (*task.PatchInfo, error) {
+	if base == "" {
+		return nil, fmt.Errorf("unknown base commit")
+	}
+	patchPath := filepath.Join(outputDir, "changes.patch")
+	files, err := runner.ExportPatch(ctx, dir, base, patchPath)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	info := &task.PatchInfo{Path: patchPath, Base: base, Files: files}
+	for _, f := range files {
+		info.Added += f.Added
+		info.Deleted += f.Deleted
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "changes.json"), data, 0o644); err != nil {
+		return nil, fmt.Errorf("write summary: %w", err)
+	}
+	return info, nil
+}
+
+// applyOptions are the user-facing knobs of the apply command.
+type applyOptions struct {
+	tasks     []string // task IDs to apply; empty = every exported patch
+	checkOnly bool
+	commit    bool
+}
+
+// applyResult tracks per-task patch application outcome.
+type applyResult struct {
+	TaskID  string
+	Repo    string
+	Files   int
+	Skipped string // reason if skipped
+	Error   string // reason if failed
+}
+
+func newApplyCmd() *cobra.Command {
+	var (
+		runDir   string
+		reposDir string
+		opts     applyOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply patches exported by a --patch-only run",
+		Long: `Apply the changes.patch files written by 'run --patch-only' to the repos.
+
+Each patch is checked before it is applied, so a patch either applies
+completely or not at all. Changes are left uncommitted unless --commit is
+given, in which case each task's patch is committed with the same message
+auto-commit would use.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runApply(cmd.Context(), cmd.OutOrStdout(), runDir, reposDir, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&runDir, "run-dir", "", "run directory (auto-detects latest if omitted)")
+	cmd.Flags().StringVar(&reposDir, "repos-dir", ".", "base directory containing repos")
+	cmd.Flags().StringSliceVar(&opts.tasks, "task", nil, "task IDs to apply (repeatable; default: all)")
+	cmd.Flags().BoolVar(&opts.checkOnly, "check", false, "only check that patches apply cleanly")
+	cmd.Flags().BoolVar(&opts.commit, "commit", false, "commit each applied patch")
+	return cmd
+}
+
+func runApply(ctx context.Context, w io.Writer, runDir, reposDir string, opts applyOptions) error {
+	if runDir == "" {
+		dir, err := findLatestRunDir(reposDir)
+		if err != nil {
+			return err
+		}
+		runDir = dir
+	}
+
+	report, err := reporter.ReadJSONReport(filepath.Join(runDir, "report.json"))
+	if err != nil {
+		return fmt.Errorf("load report: %w", err)
+	}
+	if report.ReposDir != "" {
+		reposDir = report.ReposDir
+	}
+
+	ids := opts.tasks
+	if len(ids) == 0 {
+		ids = sortedTaskIDs(report.Results)
+	}
+
+	var results []applyResult
+	for _, id := range ids {
+		result, ok := report.Results[id]
+		if !ok {
+			return fmt.Errorf("task %q not found in %s", id, runDir)
+		}
+		if result.Patch == nil {
+			if len(opts.tasks) > 0 {
+				results = append(results, applyResult{TaskID: id, Skipped: "no patch exported"})
+			}
+			continue
+		}
+		results = append(results, applyTaskPatch(ctx, w, result, reposDir, opts))
+	}
+
+	return printApplySummary(w, results, opts.checkOnly)
+}
+
+func applyTaskPatch(ctx context.Context, w io.Writer, result *task.TaskResult, reposDir string, opts applyOptions) applyResult {
+	ar := applyResult{TaskID: result.TaskID, Files: len(result.Patch.Files)}
+	meta, err := loadTaskMeta(result.OutputDir)
+	if err != nil {
+		ar.Error = fmt.Sprintf("load task metadata: %v", err)
+		return ar
+	}
+	ar.Repo = meta.Repo
+	repoDir := config.RepoPath(meta.Repo, reposDir)
+
+	patchPath := result.Patch.Path
+	if patchPath == "" {
+		patchPath = filepath.Join(result.OutputDir, "changes.patch")
+	}
+	if err := runner.ApplyPatch(ctx, repoDir, patchPath, opts.checkOnly); err != nil {
+		ar.Error = err.Error()
+		return ar
+	}
+	if opts.checkOnly {
+		fmt.Fprintf(w, "%s: applies cleanly to %s (%d files)\n", ar.TaskID, ar.Repo, ar.Files)
+		return ar
+	}
+	fmt.Fprintf(w, "%s: applied to %s (%d files, +%d -%d)\n",
+		ar.TaskID, ar.Repo, ar.Files, result.Patch.Added, result.Patch.Deleted)
+
+	if opts.commit {
+		// only the patch's files: unrelated local edits stay uncommitted
+		paths := make([]string, len(result.Patch.Files))
+		for i, f := range result.Patch.Files {
+			paths[i] = f.Path
+		}
+		if err := runner.CommitPaths(ctx, repoDir, meta, paths); err != nil {
+			ar.Error = fmt.Sprintf("commit: %v", err)
+		}
+	}
+	return ar
+}
+
+func printApplySummary(w io.Writer, results []applyResult, checkOnly bool) error {
+	applied, skipped, failed := 0, 0, 0
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			failed++
+			fmt.Fprintf(w, "%s: FAILED: %s\n", r.TaskID, r.Error)
+		case r.Skipped != "":
+			skipped++
+			fmt.Fprintf(w, "%s: skipped: %s\n", r.TaskID, r.Skipped)
+		default:
+			applied++
+		}
+	}
+	verb := "applied"
+	if checkOnly {
+		verb = "apply cleanly"
+	}
+	fmt.Fprintf(w, "\nSummary: %d patches %s, %d skipped, %d failed\n", applied, verb, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d patches failed", failed)
+	}
+	return nil
+}
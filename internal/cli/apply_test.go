@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func gitT(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test",
+		"GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=test",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %s", args, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupApplyTest creates a run whose task "edit" exported a patch for repo
+// "app", and returns the run dir, repos dir and app repo path.
+func setupApplyTest(t *testing.T) (runDir, reposDir, repoDir string) {
+	t.Helper()
+	results := map[string]*task.TaskResult{
+		"edit": {TaskID: "edit", State: task.StateCompleted},
+		"noop": {TaskID: "noop", State: task.StateCompleted},
+	}
+	tasks := map[string]*task.Task{
+		"edit": {ID: "edit", Repo: "app", Title: "Edit readme"},
+		"noop": {ID: "noop", Repo: "app", Title: "Nothing"},
+	}
+	runDir, reposDir = setupPRTest(t, results, tasks)
+
+	repoDir = filepath.Join(reposDir, "app")
+	_ = os.MkdirAll(repoDir, 0o755)
+	gitT(t, repoDir, "init", "-q")
+	_ = os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("old\n"), 0o644)
+	gitT(t, repoDir, "add", ".")
+	gitT(t, repoDir, "commit", "-qm", "initial")
+
+	// the task ran in a separate checkout; only its patch comes back
+	work := t.TempDir()
+	gitT(t, t.TempDir(), "clone", "-q", repoDir, work)
+	base := gitT(t, work, "rev-parse", "HEAD")
+	_ = os.WriteFile(filepath.Join(work, "README.md"), []byte("new\n"), 0o644)
+	patch, err := exportTaskPatch(context.Background(), work, base, results["edit"].OutputDir)
+	if err != nil || patch == nil {
+		t.Fatalf("exportTaskPatch = %v, %v", patch, err)
+	}
+	results["edit"].Patch = patch
+
+	data, _ := json.Marshal(&task.RunReport{RunID: "test-run", ReposDir: reposDir, Results: results})
+	_ = os.WriteFile(filepath.Join(runDir, "report.json"), data, 0o644)
+	return runDir, reposDir, repoDir
+}
+
+func TestExportTaskPatch_Summary(t *testing.T) {
+	runDir, _, _ := setupApplyTest(t)
+	data, err := os.ReadFile(filepath.Join(runDir, "edit", "changes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info task.PatchInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Files) != 1 || info.Files[0].Path != "README.md" || info.Added != 1 || info.Deleted != 1 {
+		t.Errorf("summary = %+v", info)
+	}
+}
+
+func TestApply_AllPatches(t *testing.T) {
+	runDir, reposDir, repoDir := setupApplyTest(t)
+	var buf bytes.Buffer
+	if err := runApply(context.Background(), &buf, runDir, reposDir, applyOptions{}); err != nil {
+		t.Fatalf("runApply: %v\n%s", err, buf.String())
+	}
+	got, _ := os.ReadFile(filepath.Join(repoDir, "README.md"))
+	if string(got) != "new\n" {
+		t.Errorf("README.md = %q", got)
+	}
+	if !strings.Contains(buf.String(), "1 patches applied, 0 skipped, 0 failed") {
+		t.Errorf("output:\n%s", buf.String())
+	}
+	if status := gitT(t, repoDir, "status", "--porcelain"); status == "" {
+		t.Error("patch should be left uncommitted without --commit")
+	}
+}
+
+func TestApply_CheckOnly(t *testing.T) {
+	runDir, reposDir, repoDir := setupApplyTest(t)
+	var buf bytes.Buffer
+	opts := applyOptions{tasks: []string{"edit"}, checkOnly: true}
+	if err := runApply(context.Background(), &buf, runDir, reposDir, opts); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(filepath.Join(repoDir, "README.md"))
+	if string(got) != "old\n" {
+		t.Errorf("--check changed README.md: %q", got)
+	}
+	if !strings.Contains(buf.String(), "edit: applies cleanly to app") {
+		t.Errorf("output:\n%s", buf.String())
+	}
+}
+
+func TestApply_Commit(t *testing.T) {
+	runDir, reposDir, repoDir := setupApplyTest(t)
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	// the user's own work in progress must not end up in the task's commit
+	_ = os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("wip\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0o644)
+	gitT(t, repoDir, "add", "main.go")
+
+	var buf bytes.Buffer
+	if err := runApply(context.Background(), &buf, runDir, reposDir, applyOptions{tasks: []string{"edit"}, commit: true}); err != nil {
+		t.Fatalf("runApply: %v\n%s", err, buf.String())
+	}
+	if status := gitT(t, repoDir, "status", "--porcelain"); status != "A  main.go\n?? notes.txt" {
+		t.Errorf("expected only the user's changes left after --commit, got %q", status)
+	}
+	if files := gitT(t, repoDir, "show", "--name-only", "--format=", "HEAD"); files != "README.md" {
+		t.Errorf("committed files = %q, want README.md", files)
+	}
+	if n := gitT(t, repoDir, "rev-list", "--count", "HEAD"); n != "2" {
+		t.Errorf("commits = %s, want 2", n)
+	}
+}
+
+func TestApply_SelectedWithoutPatch(t *testing.T) {
+	runDir, reposDir, _ := setupApplyTest(t)
+	var buf bytes.Buffer
+	if err := runApply(context.Background(), &buf, runDir, reposDir, applyOptions{tasks: []string{"noop"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "noop: skipped: no patch exported") {
+		t.Errorf("output:\n%s", buf.String())
+	}
+
+	err := runApply(context.Background(), &buf, runDir, reposDir, applyOptions{tasks: []string{"missing"}})
+	if err == nil || !strings.Contains(err.Error(), `task "missing" not found`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestApply_ConflictFails(t *testing.T) {
+	runDir, reposDir, repoDir := setupApplyTest(t)
+	_ = os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("diverged\n"), 0o644)
+	var buf bytes.Buffer
+	err := runApply(context.Background(), &buf, runDir, reposDir, applyOptions{})
+	if err == nil || !strings.Contains(buf.String(), "edit: FAILED: patch does not apply") {
+		t.Errorf("err = %v, output:\n%s", err, buf.String())
+	}
+}
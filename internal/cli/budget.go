@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+// budgetsFromSettings flattens per-key budget config into one telemetry.Budget
+// per configured window. Output is sorted by key, then window, for stable display.
+func budgetsFromSettings(cfg *config.Settings) []telemetry.Budget {
+	if cfg == nil || len(cfg.Budgets) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(cfg.Budgets))
+	for k := range cfg.Budgets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var budgets []telemetry.Budget
+	for _, key := range keys {
+		bc := cfg.Budgets[key]
+		if bc == nil {
+			continue
+		}
+		windows := []struct {
+			window string
+			usd    float64
+			tokens int
+		}{
+			{telemetry.WindowDaily, bc.DailyUSD, bc.DailyTokens},
+			{telemetry.WindowWeekly, bc.WeeklyUSD, bc.WeeklyTokens},
+			{telemetry.WindowMonthly, bc.MonthlyUSD, bc.MonthlyTokens},
+		}
+		for _, w := range windows {
+			if w.usd <= 0 && w.tokens <= 0 {
+				continue
+			}
+			budgets = append(budgets, telemetry.Budget{
+				Key:       key,
+				Window:    w.window,
+				MaxUSD:    w.usd,
+				MaxTokens: w.tokens,
+			})
+		}
+	}
+	return budgets
+}
+
+// queryBudgetStatuses reads current-window consumption from the telemetry DB.
+// Returns nil when no budgets are configured.
+func queryBudgetStatuses(cfg *config.Settings, dbPath string) ([]telemetry.BudgetStatus, error) {
+	budgets := budgetsFromSettings(cfg)
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+	db, err := telemetry.OpenDB(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open telemetry: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	return telemetry.QueryBudgets(db, budgets, time.Now())
+}
+
+// loadBudgetLedger seeds an in-run budget ledger from telemetry. Budget
+// tracking is best-effort: a telemetry failure disables it with a warning
+// rather than aborting the run.
+func loadBudgetLedger(cfg *config.Settings, dbPath string) *telemetry.BudgetLedger {
+	statuses, err := queryBudgetStatuses(cfg, dbPath)
+	if err != nil {
+		slog.Warn("budget tracking disabled", "error", err)
+		return nil
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	return telemetry.NewBudgetLedger(statuses)
+}
+
+// applyBudgetBlocks temp-blocks every runner whose name or provider matches an
+// exhausted budget until the budget window resets, so the cascade routes around it.
+func applyBudgetBlocks(
+	exhausted []telemetry.BudgetStatus,
+	runnerNames []string,
+	profiles map[string]*task.RunnerProfileConfig,
+	blacklist *runner.RunnerBlacklist,
+) {
+	for _, st := range exhausted {
+		for _, name := range runnerNames {
+			if !st.Matches(name, runner.ProviderForRunner(name, profiles)) {
+				continue
+			}
+			blacklist.TempBlock(name, st.ResetsAt)
+			slog.Warn("budget exhausted, blocking runner until window resets",
+				"budget", st.Key, "window", st.Window, "runner", name,
+				"resets_at", st.ResetsAt.Format(time.RFC3339))
+		}
+	}
+}
+
+// budgetCharger records the spend of each cascade attempt against the run's
+// budget ledger, so failed fallback attempts count too and a result that is
+// reported again is not charged twice.
+type budgetCharger struct {
+	ledger      *telemetry.BudgetLedger
+	runnerNames []string
+	profiles    map[string]*task.RunnerProfileConfig
+	blacklist   *runner.RunnerBlacklist
+}
+
+// charge records one attempt's token usage by runnerName and blocks runners
+// whose budget it exhausted. A nil charger or usage is a no-op.
+func (b *budgetCharger) charge(runnerName string, usage *task.TokenUsage) {
+	if b == nil || b.ledger == nil || usage == nil || runnerName == "" {
+		return
+	}
+	model := ""
+	if p, ok := b.profiles[runnerName]; ok {
+		model = p.Model
+	}
+	cost := telemetry.EstimateCost(model, usage.InputTokens, usage.OutputTokens)
+	provider := runner.ProviderForRunner(runnerName, b.profiles)
+	exhausted := b.ledger.Charge(runnerName, provider, cost, totalTokens(usage))
+	applyBudgetBlocks(exhausted, b.runnerNames, b.profiles, b.blacklist)
+}
+
+// printBudgetTable writes budget consumption and headroom as a table.
+func printBudgetTable(w io.Writer, statuses []telemetry.BudgetStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "BUDGET\tWINDOW\tSPENT\tLIMIT\tREMAINING\tRESETS\n")
+	for _, st := range statuses {
+		if st.MaxUSD > 0 {
+			fmt.Fprintf(tw, "%s\t%s\t$%.2f\t$%.2f\t$%.2f\t%s\n",
+				st.Key, st.Window, st.SpentUSD, st.MaxUSD, st.RemainingUSD(), formatBudgetReset(st))
+		}
+		if st.MaxTokens > 0 {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				st.Key, st.Window, formatTokenCount(st.SpentTokens), formatTokenCount(st.MaxTokens),
+				formatTokenCount(st.RemainingTokens()), formatBudgetReset(st))
+		}
+	}
+	return tw.Flush()
+}
+
+func formatBudgetReset(st telemetry.BudgetStatus) string {
+	label := st.ResetsAt.Local().Format("Jan 2 15:04")
+	if st.Exhausted() {
+		label += " (exhausted)"
+	}
+	return label
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+func TestBudgetsFromSettings(t *testing.T) {
+	cfg := &config.Settings{
+		Budgets: map[string]*config.BudgetConfig{
+			"codex":     {WeeklyTokens: 5_000_000},
+			"anthropic": {DailyUSD: 20, MonthlyUSD: 300, MonthlyTokens: 90_000_000},
+			"empty":     {},
+		},
+	}
+
+	got := budgetsFromSettings(cfg)
+	want := []telemetry.Budget{
+		{Key: "anthropic", Window: telemetry.WindowDaily, MaxUSD: 20},
+		{Key: "anthropic", Window: telemetry.WindowMonthly, MaxUSD: 300, MaxTokens: 90_000_000},
+		{Key: "codex", Window: telemetry.WindowWeekly, MaxTokens: 5_000_000},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d budgets, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("budget %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if budgetsFromSettings(nil) != nil {
+		t.Error("nil settings should yield no budgets")
+	}
+}
+
+func TestApplyBudgetBlocks_ByProviderAndName(t *testing.T) {
+	profiles := map[string]*task.RunnerProfileConfig{
+		"sonnet": {Type: "claude", Model: "claude-sonnet-4-6"},
+		"zai":    {Type: "codex"},
+		"gemini": {Type: "gemini"},
+	}
+	names := []string{"claude", "codex", "gemini", "sonnet", "zai"}
+	resetsAt := time.Now().Add(2 * time.Hour)
+	exhausted := []telemetry.BudgetStatus{
+		{Budget: telemetry.Budget{Key: "anthropic", Window: telemetry.WindowDaily}, ResetsAt: resetsAt},
+		{Budget: telemetry.Budget{Key: "codex", Window: telemetry.WindowWeekly}, ResetsAt: resetsAt},
+	}
+
+	bl := runner.NewRunnerBlacklist()
+	applyBudgetBlocks(exhausted, names, profiles, bl)
+
+	for _, name := range []string{"claude", "sonnet", "codex"} {
+		if !bl.IsBlocked(name) {
+			t.Errorf("%s should be blocked", name)
+		}
+	}
+	// zai is an openai-type profile but the budget is keyed on the codex runner name
+	for _, name := range []string{"zai", "gemini"} {
+		if bl.IsBlocked(name) {
+			t.Errorf("%s should not be blocked", name)
+		}
+	}
+}
+
+func TestBudgetCharger_BlocksOnExhaustion(t *testing.T) {
+	ledger := telemetry.NewBudgetLedger([]telemetry.BudgetStatus{
+		{Budget: telemetry.Budget{Key: "openai", Window: telemetry.WindowDaily, MaxTokens: 1_000_000}, ResetsAt: time.Now().Add(time.Hour)},
+	})
+	bl := runner.NewRunnerBlacklist()
+	b := &budgetCharger{ledger: ledger, runnerNames: []string{"codex", "claude"}, blacklist: bl}
+
+	b.charge("codex", &task.TokenUsage{InputTokens: 400_000, OutputTokens: 100_000})
+	if bl.IsBlocked("codex") {
+		t.Fatal("codex should not be blocked below budget")
+	}
+
+	b.charge("codex", &task.TokenUsage{TotalTokens: 600_000})
+	if !bl.IsBlocked("codex") {
+		t.Error("codex should be blocked once budget is exhausted")
+	}
+	if bl.IsBlocked("claude") {
+		t.Error("claude should not be blocked by an openai budget")
+	}
+
+	// attempts without usage data and a nil charger are ignored
+	b.charge("claude", nil)
+	var none *budgetCharger
+	none.charge("claude", &task.TokenUsage{TotalTokens: 1})
+}
+
+func TestBudgetCharger_ChargesEveryCascadeAttempt(t *testing.T) {
+	ledger := telemetry.NewBudgetLedger([]telemetry.BudgetStatus{
+		{Budget: telemetry.Budget{Key: "codex", Window: telemetry.WindowDaily, MaxTokens: 300}, ResetsAt: time.Now().Add(time.Hour)},
+		{Budget: telemetry.Budget{Key: "zai", Window: telemetry.WindowDaily, MaxTokens: 1000}, ResetsAt: time.Now().Add(time.Hour)},
+	})
+	bl := runner.NewRunnerBlacklist()
+	b := &budgetCharger{ledger: ledger, runnerNames: []string{"codex", "zai"}, blacklist: bl}
+	runners := map[string]runner.Runner{
+		"codex": &mockRunner{name: "codex", result: func(tk *task.Task) *task.TaskResult {
+			r := failedMockResult(tk.ID, "tests fail")
+			r.TokensUsed = &task.TokenUsage{TotalTokens: 300}
+			return r
+		}},
+		"zai": &mockRunner{name: "zai", result: func(tk *task.Task) *task.TaskResult {
+			r := completedResult(tk.ID)
+			r.TokensUsed = &task.TokenUsage{TotalTokens: 200}
+			return r
+		}},
+	}
+
+	result := RunWithCascade(context.Background(), &task.Task{ID: "t1"}, "/tmp", t.TempDir(), runners,
+		[]string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, b, nil, nil)
+	if result.State != task.StateCompleted {
+		t.Fatalf("state = %s", result.State)
+	}
+	// the failed codex attempt is charged, not only the final zai attempt
+	if !bl.IsBlocked("codex") {
+		t.Error("codex should be blocked by the tokens of its failed attempt")
+	}
+	if bl.IsBlocked("zai") {
+		t.Error("zai is under budget")
+	}
+}
+
+func TestPrintBudgetTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := printBudgetTable(&buf, []telemetry.BudgetStatus{
+		{Budget: telemetry.Budget{Key: "anthropic", Window: telemetry.WindowDaily, MaxUSD: 20}, SpentUSD: 21, ResetsAt: time.Now().Add(time.Hour)},
+		{Budget: telemetry.Budget{Key: "codex", Window: telemetry.WindowWeekly, MaxTokens: 5_000_000}, SpentTokens: 1_000_000, ResetsAt: time.Now().Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"BUDGET", "anthropic", "$21.00", "$0.00", "(exhausted)", "codex", "weekly"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
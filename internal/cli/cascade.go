@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ppiankov/tokencontrol/internal/config"
 	"github.com/ppiankov/tokencontrol/internal/runner"
 	"github.com/ppiankov/tokencontrol/internal/task"
 )
@@ -23,7 +24,9 @@ var retryBackoffs = []time.Duration{30 * time.Second, 60 * time.Second}
 // On rate-limit or failure, it falls to the next runner in the list.
 // Transient failures (connectivity, idle timeout) are retried up to maxRetries
 // times with backoff before falling to the next runner.
-// It records all attempts and populates RunnerUsed on the final result.
+// With rollback set, each attempt is bracketed by a snapshot and the repo is
+// restored after an attempt that doesn't complete, so the next runner starts
+// clean. It records all attempts and populates RunnerUsed on the final result.
 func RunWithCascade(
 	ctx context.Context,
 	t *task.Task,
@@ -35,7 +38,9 @@ func RunWithCascade(
 	blacklist *runner.RunnerBlacklist,
 	graylist *runner.RunnerGraylist,
 	limiter *runner.ProviderLimiter,
+	budget *budgetCharger,
 	onAttemptStart func(runner string),
+	rollback *attemptRollback,
 ) *task.TaskResult {
 	if len(runnerNames) == 0 {
 		return &task.TaskResult{
@@ -48,6 +53,7 @@ func RunWithCascade(
 
 	var attempts []task.AttemptInfo
 	var lastResult *task.TaskResult
+	var handoff string // summary of the last failed attempt, appended to the next prompt
 
 	for i, name := range runnerNames {
 		if blacklist.IsBlocked(name) {
@@ -118,6 +124,25 @@ func RunWithCascade(
 			// capture HEAD before run so we can detect new commits
 			headBefore := gitHead(repoDir)
 
+			// the pre-attempt state: what rollback restores, and what path
+			// rules and handoffs diff against so pre-existing local changes
+			// are never blamed on the agent
+			var snap *runner.Snapshot
+			var snapErr error
+			if rollback != nil || len(t.AllowPaths) > 0 || len(t.DenyPaths) > 0 || (t.Handoff != nil && t.Handoff.Enabled) {
+				if snap, snapErr = runner.TakeSnapshot(ctx, repoDir); snapErr != nil {
+					slog.Warn("attempt snapshot failed, rollback disabled and path rules fail the attempt",
+						"task", t.ID, "runner", name, "error", snapErr)
+				}
+			}
+
+			// pace dispatch against known request/token rates before the
+			// provider has a chance to answer with a 429
+			if limiter != nil {
+				if err := limiter.Wait(ctx, name); err != nil {
+					slog.Debug("pacing wait interrupted", "task", t.ID, "runner", name, "error", err)
+				}
+			}
 			if onAttemptStart != nil {
 				onAttemptStart(name)
 			}
@@ -126,11 +151,15 @@ func RunWithCascade(
 			}
 			taskCtx, taskCancel := context.WithTimeout(ctx, maxRuntime)
 			start := time.Now()
-			result = r.Run(taskCtx, t, repoDir, attemptDir)
+			result = r.Run(taskCtx, withHandoff(t, r, handoff, attemptDir), repoDir, attemptDir)
 			taskCancel()
 			if limiter != nil {
 				limiter.Release(name)
+				if result.TokensUsed != nil {
+					limiter.Settle(name, totalTokens(result.TokensUsed))
+				}
 			}
+			budget.charge(name, result.TokensUsed)
 			elapsed := time.Since(start)
 
 			// Scan output files for leaked secrets and redact in place.
@@ -138,6 +167,12 @@ func RunWithCascade(
 				slog.Warn("output scan found secrets", "task", t.ID, "runner", name, "dir", attemptDir, "leaks", leaks)
 			}
 
+			// check allow_paths/deny_paths before the attempt is accepted
+			var violations []string
+			if result.State == task.StateCompleted {
+				violations = enforcePathRules(ctx, t, repoDir, snap, snapErr, name, result)
+			}
+
 			attempts = append(attempts, task.AttemptInfo{
 				Runner:            name,
 				Retry:             retry,
@@ -146,7 +181,25 @@ func RunWithCascade(
 				Error:             result.Error,
 				OutputDir:         attemptDir,
 				ConnectivityError: result.ConnectivityError,
+				PathViolations:    violations,
 			})
+			if result.State != task.StateCompleted {
+				// read the handoff before rollback discards the changed files
+				var h *runner.Handoff
+				if t.Handoff != nil && t.Handoff.Enabled {
+					h = runner.ReadHandoff(name, attemptDir, result)
+					if snap != nil {
+						h.Files, _ = snap.ChangedPaths(ctx, repoDir)
+					}
+				}
+				if snap != nil && rollback != nil {
+					rollbackAttempt(ctx, t, repoDir, attemptDir, name, snap, rollback, &attempts[len(attempts)-1])
+				}
+				if h != nil {
+					h.RolledBack = attempts[len(attempts)-1].RolledBack
+					handoff = h.Render(t.Handoff.Limit())
+				}
+			}
 
 			// on success, check for false positive and return
 			if result.State == task.StateCompleted {
@@ -207,6 +260,116 @@ func RunWithCascade(
 	return lastResult
 }
 
+// withHandoff returns t with the previous attempt's handoff appended to its
+// prompt, saving the handoff as handoff.md in the attempt dir. Script runner
+// prompts are shell commands and are left unchanged.
+func withHandoff(t *task.Task, r runner.Runner, handoff, attemptDir string) *task.Task {
+	if handoff == "" {
+		return t
+	}
+	if _, isScript := r.(*runner.ScriptRunner); isScript {
+		return t
+	}
+	_ = os.WriteFile(filepath.Join(attemptDir, "handoff.md"), []byte(strings.TrimSpace(handoff)+"\n"), 0o644)
+	ht := *t
+	ht.Prompt += handoff
+	return &ht
+}
+
+// attemptRollback configures restoring the repo after failed attempts.
+type attemptRollback struct {
+	keepDiff bool // save the discarded changes as failed.patch in the attempt's output dir
+}
+
+// resolveRollback determines rollback of failed attempts from settings
+// (default on, failed diffs kept); --no-rollback disables it.
+func resolveRollback(cfg *config.Settings, noRollback bool) *attemptRollback {
+	if noRollback || (cfg != nil && cfg.RollbackFailed != nil && !*cfg.RollbackFailed) {
+		return nil
+	}
+	keep := cfg == nil || cfg.KeepFailedDiff == nil || *cfg.KeepFailedDiff
+	return &attemptRollback{keepDiff: keep}
+}
+
+// rollbackAttempt restores repoDir to the snapshot taken before a failed
+// attempt so the next attempt starts clean. The discarded changes are saved
+// as failed.patch in the attempt's output dir when rb.keepDiff is set.
+func rollbackAttempt(ctx context.Context, t *task.Task, repoDir, attemptDir, runnerName string, snap *runner.Snapshot, rb *attemptRollback, info *task.AttemptInfo) {
+	// restore even when the run was interrupted
+	ctx = context.WithoutCancel(ctx)
+
+	changed, err := snap.Changed(ctx, repoDir)
+	if err != nil {
+		slog.Warn("rollback check failed", "task", t.ID, "runner", runnerName, "error", err)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	if rb.keepDiff {
+		patchPath := filepath.Join(attemptDir, "failed.patch")
+		files, err := snap.Diff(ctx, repoDir, patchPath)
+		if err != nil {
+			slog.Warn("saving failed attempt diff failed", "task", t.ID, "runner", runnerName, "error", err)
+		} else if len(files) > 0 {
+			info.FailedPatch = patchPath
+		}
+	}
+
+	if err := snap.Restore(ctx, repoDir); err != nil {
+		slog.Warn("rollback of failed attempt failed", "task", t.ID, "runner", runnerName, "error", err)
+		return
+	}
+	info.RolledBack = true
+	slog.Info("rolled back failed attempt", "task", t.ID, "runner", runnerName, "head", snap.Head, "patch", info.FailedPatch)
+}
+
+// enforcePathRules checks what a completed attempt changed since its
+// pre-attempt snapshot against the task's allow_paths/deny_paths. Offending
+// files are always restored to their snapshot state so forbidden changes
+// never reach the next runner. With
+// path_policy "revert" the attempt then stands; otherwise it is marked
+// failed so the cascade moves on. Rules that cannot be checked, because
+// the snapshot (snapErr) or the diff failed, fail the attempt as well.
+// Returns the offending paths.
+func enforcePathRules(ctx context.Context, t *task.Task, repoDir string, snap *runner.Snapshot, snapErr error, runnerName string, result *task.TaskResult) []string {
+	if len(t.AllowPaths) == 0 && len(t.DenyPaths) == 0 {
+		return nil
+	}
+	err := snapErr
+	if err == nil && snap == nil {
+		err = fmt.Errorf("no pre-attempt snapshot")
+	}
+	var violations []string
+	if err == nil {
+		violations, err = runner.PathViolations(ctx, repoDir, snap, t.AllowPaths, t.DenyPaths)
+	}
+	if err != nil {
+		slog.Warn("path rule check failed", "task", t.ID, "runner", runnerName, "error", err)
+		result.State = task.StateFailed
+		result.Error = "path rules could not be checked: " + err.Error()
+		return nil
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	reverted := true
+	if err := runner.RevertPaths(ctx, repoDir, snap, violations); err != nil {
+		slog.Warn("revert of forbidden paths failed", "task", t.ID, "runner", runnerName, "error", err)
+		reverted = false
+	}
+	if reverted && t.PathPolicy == task.PathPolicyRevert {
+		slog.Warn("reverted changes to forbidden paths", "task", t.ID, "runner", runnerName, "paths", violations)
+		return violations
+	}
+
+	result.State = task.StateFailed
+	result.Error = "changed forbidden paths: " + strings.Join(violations, ", ")
+	return violations
+}
+
 // isTransientFailure returns true for failures that may resolve on retry:
 // connectivity errors (except permanent TLS cert issues) and idle timeouts.
 // Rate limits are NOT transient here — they have their own blacklist+cascade logic.
@@ -327,13 +490,29 @@ func validateAndResolveModels(
 	return resolutions
 }
 
-// buildProviderLimiter creates a ProviderLimiter from concurrency limits.
-// Only entries with limit > 0 are enforced.
-func buildProviderLimiter(limits map[string]int) *runner.ProviderLimiter {
-	if len(limits) == 0 {
+// totalTokens returns the reported total, falling back to input+output
+// for runners that do not report a total.
+func totalTokens(u *task.TokenUsage) int {
+	if u == nil {
+		return 0
+	}
+	if u.TotalTokens > 0 {
+		return u.TotalTokens
+	}
+	return u.InputTokens + u.OutputTokens
+}
+
+// buildProviderLimiter creates a ProviderLimiter from concurrency limits
+// and dispatch rates. Only entries with limit > 0 are enforced.
+func buildProviderLimiter(limits map[string]int, rates map[string]runner.RateLimit) *runner.ProviderLimiter {
+	if len(limits) == 0 && len(rates) == 0 {
 		return nil
 	}
-	return runner.NewProviderLimiter(limits)
+	pl := runner.NewProviderLimiter(limits)
+	for name, rl := range rates {
+		pl.SetRate(name, rl)
+	}
+	return pl
 }
 
 // filterEntry records one filter's removal of runners from a cascade.
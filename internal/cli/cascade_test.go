@@ -51,7 +51,7 @@ func TestCascade_FirstSucceeds(t *testing.T) {
 
 	tk := &task.Task{ID: "test-1", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -77,7 +77,7 @@ func TestCascade_FirstRateLimited(t *testing.T) {
 
 	tk := &task.Task{ID: "test-2", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -102,7 +102,7 @@ func TestCascade_FirstFailed(t *testing.T) {
 
 	tk := &task.Task{ID: "test-3", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -124,7 +124,7 @@ func TestCascade_AllFail(t *testing.T) {
 
 	tk := &task.Task{ID: "test-4", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateFailed {
 		t.Fatalf("expected failed, got %s", result.State)
@@ -151,7 +151,7 @@ func TestCascade_BlacklistSkips(t *testing.T) {
 	bl.Block("codex", time.Now().Add(4*time.Hour))
 
 	tk := &task.Task{ID: "test-5", Repo: "test/repo", Prompt: "do stuff"}
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -177,7 +177,7 @@ func TestCascade_NoFallbacks(t *testing.T) {
 
 	tk := &task.Task{ID: "test-6", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -206,7 +206,7 @@ func TestCascade_AttemptsRecorded(t *testing.T) {
 
 	tk := &task.Task{ID: "test-7", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai", "claude-api"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai", "claude-api"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -247,7 +247,7 @@ func TestCascade_RateLimitBlocksForSubsequentTasks(t *testing.T) {
 
 	// first task triggers rate limit
 	tk1 := &task.Task{ID: "task-1", Repo: "test/repo", Prompt: "first"}
-	r1 := RunWithCascade(context.Background(), tk1, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	r1 := RunWithCascade(context.Background(), tk1, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 	if r1.State != task.StateCompleted {
 		t.Fatalf("task-1: expected completed, got %s", r1.State)
 	}
@@ -257,7 +257,7 @@ func TestCascade_RateLimitBlocksForSubsequentTasks(t *testing.T) {
 
 	// second task should skip codex entirely
 	tk2 := &task.Task{ID: "task-2", Repo: "test/repo", Prompt: "second"}
-	r2 := RunWithCascade(context.Background(), tk2, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	r2 := RunWithCascade(context.Background(), tk2, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 	if r2.State != task.StateCompleted {
 		t.Fatalf("task-2: expected completed, got %s", r2.State)
 	}
@@ -1177,7 +1177,7 @@ func TestCascade_RetryOnConnectivity(t *testing.T) {
 
 	tk := &task.Task{ID: "retry-1", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 2, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 2, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed after retry, got %s: %s", result.State, result.Error)
@@ -1218,7 +1218,7 @@ func TestCascade_RetryOnIdleTimeout(t *testing.T) {
 
 	tk := &task.Task{ID: "retry-2", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 2, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 2, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed after retry, got %s", result.State)
@@ -1246,7 +1246,7 @@ func TestCascade_NoRetryOnRealFailure(t *testing.T) {
 
 	tk := &task.Task{ID: "retry-3", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 2, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 2, bl, nil, nil, nil, nil, nil)
 
 	// should NOT retry codex — real failure, falls to zai
 	if calls != 1 {
@@ -1283,7 +1283,7 @@ func TestCascade_MaxRetriesExhausted(t *testing.T) {
 
 	tk := &task.Task{ID: "retry-4", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 2, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 2, bl, nil, nil, nil, nil, nil)
 
 	// codex called 3 times (initial + 2 retries), then falls to zai
 	if calls != 3 {
@@ -1318,7 +1318,7 @@ func TestCascade_NoRetryWhenDisabled(t *testing.T) {
 	tk := &task.Task{ID: "retry-5", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
 	// maxRetries=0 → no retries
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
 
 	if calls != 1 {
 		t.Fatalf("with maxRetries=0, codex should be called once, got %d", calls)
@@ -1351,7 +1351,7 @@ func TestCascade_RetryAttemptsTracked(t *testing.T) {
 
 	tk := &task.Task{ID: "retry-6", Repo: "test/repo", Prompt: "do stuff"}
 	bl := runner.NewRunnerBlacklist()
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 2, bl, nil, nil, nil)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 2, bl, nil, nil, nil, nil, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %s", result.State)
@@ -1473,7 +1473,7 @@ func TestCascade_OnAttemptStartCallback(t *testing.T) {
 		}},
 	}
 
-	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, callback)
+	result := RunWithCascade(context.Background(), tk, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, callback, nil)
 
 	if result.State != task.StateCompleted {
 		t.Fatalf("expected completed, got %v", result.State)
@@ -1568,3 +1568,282 @@ func TestMirrorRunDocs_CopiesArtifacts(t *testing.T) {
 		t.Error("output.log should not exist")
 	}
 }
+
+// setupPathRulesRepo creates a committed repo and a runner that writes the
+// given files into it and completes.
+func setupPathRulesRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+	dir := t.TempDir()
+	gitT(t, dir, "init", "-q")
+	_ = os.WriteFile(filepath.Join(dir, "go.sum"), []byte("orig\n"), 0o644)
+	gitT(t, dir, "add", ".")
+	gitT(t, dir, "commit", "-qm", "initial")
+	return dir
+}
+
+func writingRunner(name, dir string, files ...string) *mockRunner {
+	return &mockRunner{name: name, result: func(tk *task.Task) *task.TaskResult {
+		for _, f := range files {
+			_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0o755)
+			_ = os.WriteFile(filepath.Join(dir, f), []byte(name+"\n"), 0o644)
+		}
+		return completedResult(tk.ID)
+	}}
+}
+
+func TestCascade_PathViolationFailsAttempt(t *testing.T) {
+	dir := setupPathRulesRepo(t)
+	runners := map[string]runner.Runner{
+		"codex": writingRunner("codex", dir, "src/app.go", "go.sum"),
+		"zai":   writingRunner("zai", dir, "src/app.go"),
+	}
+	tk := &task.Task{ID: "t1", AllowPaths: []string{"src/"}, DenyPaths: []string{"go.sum"}}
+	bl := runner.NewRunnerBlacklist()
+	result := RunWithCascade(context.Background(), tk, dir, t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
+
+	if result.State != task.StateCompleted || result.RunnerUsed != "zai" {
+		t.Fatalf("state = %s, runner = %s, error = %s", result.State, result.RunnerUsed, result.Error)
+	}
+	first := result.Attempts[0]
+	if first.State != task.StateFailed || len(first.PathViolations) != 1 || first.PathViolations[0] != "go.sum" {
+		t.Errorf("first attempt = %+v", first)
+	}
+	if !strings.Contains(first.Error, "changed forbidden paths: go.sum") {
+		t.Errorf("error = %q", first.Error)
+	}
+}
+
+func TestCascade_PathRulesFailClosed(t *testing.T) {
+	dir := t.TempDir() // not a git repo: the snapshot fails
+	runners := map[string]runner.Runner{"codex": writingRunner("codex", dir, "go.sum")}
+	tk := &task.Task{ID: "t1", DenyPaths: []string{"go.sum"}}
+	bl := runner.NewRunnerBlacklist()
+	result := RunWithCascade(context.Background(), tk, dir, t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
+
+	if result.State != task.StateFailed {
+		t.Fatalf("state = %s, want failed when path rules cannot be checked", result.State)
+	}
+	if !strings.Contains(result.Error, "path rules could not be checked") {
+		t.Errorf("error = %q", result.Error)
+	}
+}
+
+func TestCascade_PathViolationRevert(t *testing.T) {
+	dir := setupPathRulesRepo(t)
+	runners := map[string]runner.Runner{"codex": writingRunner("codex", dir, "src/app.go", "go.sum", "notes.txt")}
+	tk := &task.Task{ID: "t1", AllowPaths: []string{"src/**"}, DenyPaths: []string{"go.sum"}, PathPolicy: task.PathPolicyRevert}
+	bl := runner.NewRunnerBlacklist()
+	result := RunWithCascade(context.Background(), tk, dir, t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
+
+	if result.State != task.StateCompleted {
+		t.Fatalf("state = %s, error = %s", result.State, result.Error)
+	}
+	if got := strings.Join(result.Attempts[0].PathViolations, ","); got != "go.sum,notes.txt" {
+		t.Errorf("violations = %s", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.sum")); string(data) != "orig\n" {
+		t.Errorf("go.sum not reverted: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); !os.IsNotExist(err) {
+		t.Error("notes.txt should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "app.go")); err != nil {
+		t.Error("allowed change should be kept")
+	}
+}
+
+func TestCascade_PathRevertKeepsPreexistingFiles(t *testing.T) {
+	dir := setupPathRulesRepo(t)
+	// the user's untracked notes and uncommitted go.sum edit predate the run
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "go.sum"), []byte("user\n"), 0o644)
+
+	runners := map[string]runner.Runner{"codex": writingRunner("codex", dir, "src/app.go", "stray.txt")}
+	tk := &task.Task{ID: "t1", AllowPaths: []string{"src/**"}, PathPolicy: task.PathPolicyRevert}
+	bl := runner.NewRunnerBlacklist()
+	result := RunWithCascade(context.Background(), tk, dir, t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
+
+	if result.State != task.StateCompleted {
+		t.Fatalf("state = %s, error = %s", result.State, result.Error)
+	}
+	if got := strings.Join(result.Attempts[0].PathViolations, ","); got != "stray.txt" {
+		t.Errorf("violations = %s, want only the agent's stray.txt", got)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "notes.txt")); err != nil || string(data) != "mine\n" {
+		t.Errorf("pre-existing untracked file lost: %q, %v", data, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.sum")); string(data) != "user\n" {
+		t.Errorf("pre-existing edit lost: go.sum = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stray.txt")); !os.IsNotExist(err) {
+		t.Error("stray.txt should be removed")
+	}
+}
+
+func TestApplyRepoPathRules(t *testing.T) {
+	settings := &config.Settings{Repos: map[string]*config.RepoConfig{
+		"org/api": {AllowPaths: []string{"src/"}, DenyPaths: []string{"LICENSE", "go.sum"}, PathPolicy: task.PathPolicyRevert},
+	}}
+	tasks := []task.Task{
+		{ID: "a", Repo: "org/api"},
+		{ID: "b", Repo: "org/api", AllowPaths: []string{"docs/"}, DenyPaths: []string{"go.sum"}, PathPolicy: task.PathPolicyFail},
+		{ID: "c", Repo: "org/web"},
+	}
+	applyRepoPathRules(tasks, settings)
+
+	a, b, c := tasks[0], tasks[1], tasks[2]
+	if strings.Join(a.AllowPaths, ",") != "src/" || strings.Join(a.DenyPaths, ",") != "LICENSE,go.sum" || a.PathPolicy != task.PathPolicyRevert {
+		t.Errorf("a = %+v", a)
+	}
+	if strings.Join(b.AllowPaths, ",") != "docs/" || strings.Join(b.DenyPaths, ",") != "go.sum,LICENSE" || b.PathPolicy != task.PathPolicyFail {
+		t.Errorf("b = %+v", b)
+	}
+	if len(c.AllowPaths) != 0 || len(c.DenyPaths) != 0 {
+		t.Errorf("c = %+v", c)
+	}
+}
+
+func TestInjectPathConstraints(t *testing.T) {
+	tasks := []task.Task{
+		{ID: "a", Prompt: "fix", Runner: "codex", AllowPaths: []string{"src/"}, DenyPaths: []string{"go.sum"}},
+		{ID: "b", Prompt: "fix", Runner: "codex"},
+		{ID: "c", Prompt: "echo", Runner: "script", DenyPaths: []string{"go.sum"}},
+	}
+	runners := map[string]runner.Runner{"codex": &mockRunner{name: "codex"}, "script": &runner.ScriptRunner{}}
+	injectPathConstraints(tasks, runners)
+
+	if !strings.Contains(tasks[0].Prompt, "Only change files matching: src/.") ||
+		!strings.Contains(tasks[0].Prompt, "Do NOT change files matching: go.sum.") {
+		t.Errorf("prompt = %q", tasks[0].Prompt)
+	}
+	if tasks[1].Prompt != "fix" || tasks[2].Prompt != "echo" {
+		t.Error("tasks without rules or on script runners should be unchanged")
+	}
+}
+
+func TestCascade_RollbackFailedAttempt(t *testing.T) {
+	dir := setupPathRulesRepo(t)
+	var sawLeftovers bool
+	runners := map[string]runner.Runner{
+		"codex": &mockRunner{name: "codex", result: func(tk *task.Task) *task.TaskResult {
+			_ = os.WriteFile(filepath.Join(dir, "half.go"), []byte("package x\n"), 0o644)
+			_ = os.WriteFile(filepath.Join(dir, "go.sum"), []byte("broken\n"), 0o644)
+			return failedMockResult(tk.ID, "exit status 1")
+		}},
+		"zai": &mockRunner{name: "zai", result: func(tk *task.Task) *task.TaskResult {
+			_, err := os.Stat(filepath.Join(dir, "half.go"))
+			data, _ := os.ReadFile(filepath.Join(dir, "go.sum"))
+			sawLeftovers = err == nil || string(data) != "orig\n"
+			return completedResult(tk.ID)
+		}},
+	}
+	tk := &task.Task{ID: "t1"}
+	bl := runner.NewRunnerBlacklist()
+	result := RunWithCascade(context.Background(), tk, dir, t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, &attemptRollback{keepDiff: true})
+
+	if result.State != task.StateCompleted || sawLeftovers {
+		t.Fatalf("state = %s, fallback saw leftovers = %v", result.State, sawLeftovers)
+	}
+	first := result.Attempts[0]
+	if !first.RolledBack || first.FailedPatch == "" {
+		t.Fatalf("first attempt = %+v", first)
+	}
+	patch, _ := os.ReadFile(first.FailedPatch)
+	if !strings.Contains(string(patch), "+broken") || !strings.Contains(string(patch), "half.go") {
+		t.Errorf("failed.patch:\n%s", patch)
+	}
+	if result.Attempts[1].RolledBack {
+		t.Error("completed attempt must not be rolled back")
+	}
+}
+
+func TestCascade_NoRollbackKeepsState(t *testing.T) {
+	dir := setupPathRulesRepo(t)
+	runners := map[string]runner.Runner{
+		"codex": &mockRunner{name: "codex", result: func(tk *task.Task) *task.TaskResult {
+			_ = os.WriteFile(filepath.Join(dir, "half.go"), []byte("package x\n"), 0o644)
+			return failedMockResult(tk.ID, "exit status 1")
+		}},
+	}
+	bl := runner.NewRunnerBlacklist()
+	result := RunWithCascade(context.Background(), &task.Task{ID: "t1"}, dir, t.TempDir(), runners, []string{"codex"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
+
+	if result.Attempts[0].RolledBack {
+		t.Error("rollback disabled but attempt rolled back")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "half.go")); err != nil {
+		t.Error("changes should be left in place without rollback")
+	}
+}
+
+func TestResolveRollback(t *testing.T) {
+	off, on := false, true
+	if rb := resolveRollback(nil, false); rb == nil || !rb.keepDiff {
+		t.Errorf("default = %+v", rb)
+	}
+	if resolveRollback(&config.Settings{}, true) != nil {
+		t.Error("--no-rollback should disable rollback")
+	}
+	if resolveRollback(&config.Settings{RollbackFailed: &off}, false) != nil {
+		t.Error("rollback_failed: false should disable rollback")
+	}
+	if rb := resolveRollback(&config.Settings{RollbackFailed: &on, KeepFailedDiff: &off}, false); rb == nil || rb.keepDiff {
+		t.Errorf("keep_failed_diff: false = %+v", rb)
+	}
+}
+
+func TestCascade_HandoffToNextRunner(t *testing.T) {
+	dir := setupPathRulesRepo(t)
+	var zaiPrompt string
+	runners := map[string]runner.Runner{
+		"codex": &mockRunner{name: "codex", result: func(tk *task.Task) *task.TaskResult {
+			_ = os.WriteFile(filepath.Join(dir, "half.go"), []byte("package x\n"), 0o644)
+			return &task.TaskResult{TaskID: tk.ID, State: task.StateFailed, Error: "exit status 1", LastMsg: "could not get tests passing"}
+		}},
+		"zai": &mockRunner{name: "zai", result: func(tk *task.Task) *task.TaskResult {
+			zaiPrompt = tk.Prompt
+			return completedResult(tk.ID)
+		}},
+	}
+	tk := &task.Task{ID: "t1", Prompt: "fix it", Handoff: &task.HandoffConfig{Enabled: true}}
+	bl := runner.NewRunnerBlacklist()
+	outDir := t.TempDir()
+	result := RunWithCascade(context.Background(), tk, dir, outDir, runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, &attemptRollback{})
+
+	if result.State != task.StateCompleted {
+		t.Fatalf("state = %s", result.State)
+	}
+	for _, want := range []string{"fix it\n\n## Previous attempt", `runner "codex"`, "exit status 1", "Files it changed: half.go", "could not get tests passing", "were discarded"} {
+		if !strings.Contains(zaiPrompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, zaiPrompt)
+		}
+	}
+	if tk.Prompt != "fix it" {
+		t.Error("original task prompt must not be modified")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "attempt-2-zai", "handoff.md")); err != nil {
+		t.Error("handoff.md should be saved in the receiving attempt's dir")
+	}
+}
+
+func TestCascade_HandoffDisabled(t *testing.T) {
+	var zaiPrompt string
+	runners := map[string]runner.Runner{
+		"codex": &mockRunner{name: "codex", result: func(tk *task.Task) *task.TaskResult {
+			return failedMockResult(tk.ID, "exit status 1")
+		}},
+		"zai": &mockRunner{name: "zai", result: func(tk *task.Task) *task.TaskResult {
+			zaiPrompt = tk.Prompt
+			return completedResult(tk.ID)
+		}},
+	}
+	bl := runner.NewRunnerBlacklist()
+	RunWithCascade(context.Background(), &task.Task{ID: "t1", Prompt: "fix it"}, "/tmp", t.TempDir(), runners, []string{"codex", "zai"}, 5*time.Minute, 0, bl, nil, nil, nil, nil, nil)
+	if zaiPrompt != "fix it" {
+		t.Errorf("prompt = %q", zaiPrompt)
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// analyzeTaskChanges summarizes the commits a task added on top of base in
+// dir, or the exported patch in --patch-only mode, and evaluates the risk
+// rules from settings. Returns nil when the diff can't be computed.
+func analyzeTaskChanges(ctx context.Context, dir, base string, patch *task.PatchInfo, settings *config.Settings) *task.ChangeSummary {
+	var denyPaths []string
+	var rules []task.RiskRule
+	if settings != nil && settings.Risk != nil {
+		denyPaths = settings.Risk.DenyPaths
+		rules = settings.Risk.Rules
+	}
+
+	var files []task.FileChange
+	head := ""
+	switch {
+	case patch != nil:
+		files = patch.Files
+	case base != "":
+		var err error
+		files, err = runner.DiffFiles(ctx, dir, base, "HEAD")
+		if err != nil {
+			slog.Warn("change analysis failed", "dir", dir, "error", err)
+			return nil
+		}
+		head = gitHead(dir)
+	default:
+		return nil
+	}
+
+	s := task.AnalyzeChanges(files, denyPaths, rules)
+	s.Base = base
+	s.Head = head
+	return s
+}
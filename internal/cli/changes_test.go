@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func TestAnalyzeTaskChanges_CommitRange(t *testing.T) {
+	dir := t.TempDir()
+	gitT(t, dir, "init", "-q")
+	_ = os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+	gitT(t, dir, "add", ".")
+	gitT(t, dir, "commit", "-qm", "initial")
+	base := gitT(t, dir, "rev-parse", "HEAD")
+
+	_ = os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, ".github", "workflows", "ci.yml"), []byte("on: push\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+	gitT(t, dir, "add", ".")
+	gitT(t, dir, "commit", "-qm", "agent")
+
+	settings := &config.Settings{Risk: &config.RiskConfig{
+		Rules: []task.RiskRule{{Name: "workflows", When: task.RiskWhenCI, Action: task.RiskBlock}},
+	}}
+	s := analyzeTaskChanges(context.Background(), dir, base, nil, settings)
+	if s == nil {
+		t.Fatal("expected a summary")
+	}
+	if len(s.Files) != 2 || s.Added != 3 || s.Base != base || s.Head != gitT(t, dir, "rev-parse", "HEAD") {
+		t.Errorf("summary = %+v", s)
+	}
+	if !s.Blocked() || s.Risks[0].Rule != "workflows" {
+		t.Errorf("risks = %+v", s.Risks)
+	}
+}
+
+func TestAnalyzeTaskChanges_Patch(t *testing.T) {
+	patch := &task.PatchInfo{Files: []task.FileChange{{Path: "go.sum", Added: 2}}}
+	s := analyzeTaskChanges(context.Background(), t.TempDir(), "abc", patch, nil)
+	if s == nil || len(s.Lockfiles) != 1 || !s.NeedsReview() || s.Blocked() {
+		t.Errorf("summary = %+v", s)
+	}
+	if analyzeTaskChanges(context.Background(), t.TempDir(), "", nil, nil) != nil {
+		t.Error("no base and no patch should yield nil")
+	}
+}
+
+func TestBuildPRBody_Risk(t *testing.T) {
+	meta := &task.Task{ID: "t", Title: "Bump deps"}
+	result := &task.TaskResult{
+		RunnerUsed: "codex",
+		Duration:   time.Minute,
+		Changes: task.AnalyzeChanges([]task.FileChange{
+			{Path: "go.sum", Added: 3, Deleted: 1},
+		}, nil, nil),
+	}
+	body := buildPRBody(meta, result)
+	for _, want := range []string{
+		"- **Changes:** 1 files, +3 -1",
+		"### Risk",
+		"- **lockfile-change** (review): lockfile files changed: go.sum",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
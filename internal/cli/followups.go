@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// followupInstruction tells agents how to propose follow-up work.
+const followupInstruction = "\n\nIf you discover follow-up work outside the scope of this task " +
+	"(for example tests that need updating in another package), do NOT do it. " +
+	"Instead, end your final message with a fenced code block tagged `followups` " +
+	"containing a JSON array of tasks, each with \"title\" and \"prompt\" " +
+	"(optional: \"id\", \"priority\", \"runner\", \"depends_on\"). " +
+	"Omit the block when there is no follow-up work."
+
+// injectFollowupInstructions tells agents how to propose follow-up tasks.
+// Script runner tasks are skipped since their prompts are shell commands.
+func injectFollowupInstructions(tasks []task.Task, runners map[string]runner.Runner) {
+	for i := range tasks {
+		if r, ok := runners[tasks[i].Runner]; ok {
+			if _, isScript := r.(*runner.ScriptRunner); isScript {
+				continue
+			}
+		}
+		if strings.Contains(tasks[i].Prompt, "tagged `followups`") {
+			continue
+		}
+		tasks[i].Prompt += followupInstruction
+	}
+}
+
+// followupSpawner validates follow-up tasks proposed by agents and, per the
+// configured mode, inserts them into the run as dependents of the task that
+// proposed them, asks in the TUI first, or only records them.
+type followupSpawner struct {
+	mu        sync.Mutex
+	ctx       context.Context // run context; cancelling it declines pending approvals
+	mode      string
+	maxDepth  int
+	maxPerRun int
+	sched     *task.Scheduler
+	graph     *task.Graph
+	editor    *taskEditor // inserts tasks and records them in the overlay
+	runners   []string
+
+	accepted int                            // follow-ups counted against maxPerRun
+	reserved map[string]bool                // IDs of follow-ups waiting for approval
+	records  map[string][]task.FollowupInfo // by proposing task
+
+	// ask hands a follow-up to the TUI for approval; decide must be called
+	// exactly once. nil when there is no TUI to ask.
+	ask func(parentID string, t task.Task, decide func(approved bool))
+}
+
+// newFollowupSpawner returns nil when follow-ups are not configured.
+func newFollowupSpawner(ctx context.Context, cfg *config.FollowupsConfig, sched *task.Scheduler, editor *taskEditor) *followupSpawner {
+	if cfg == nil {
+		return nil
+	}
+	maxDepth, maxPerRun := cfg.Limits()
+	return &followupSpawner{
+		ctx:       ctx,
+		mode:      cfg.Mode,
+		maxDepth:  maxDepth,
+		maxPerRun: maxPerRun,
+		sched:     sched,
+		graph:     editor.graph,
+		editor:    editor,
+		runners:   editor.runners,
+		reserved:  make(map[string]bool),
+		records:   make(map[string][]task.FollowupInfo),
+	}
+}
+
+// spawn reads the follow-ups proposed by a completed task's final attempt
+// and handles each one according to the mode and limits.
+func (f *followupSpawner) spawn(parent *task.Task, result *task.TaskResult) {
+	dir := result.OutputDir
+	if n := len(result.Attempts); n > 0 && result.Attempts[n-1].OutputDir != "" {
+		dir = result.Attempts[n-1].OutputDir
+	}
+	proposals, err := runner.ReadFollowups(dir, result.LastMsg)
+	if err != nil {
+		slog.Warn("ignoring invalid follow-ups", "task", parent.ID, "error", err)
+	}
+
+	for _, p := range proposals {
+		f.mu.Lock()
+		t, reason := f.validate(parent, p)
+		status := ""
+		switch {
+		case reason != "":
+			status = task.FollowupDropped
+		case f.mode == config.FollowupLog:
+			status = task.FollowupLogged
+		case f.depth(parent.ID)+1 > f.maxDepth:
+			status, reason = task.FollowupDropped, fmt.Sprintf("depth limit %d reached", f.maxDepth)
+		case f.accepted >= f.maxPerRun:
+			status, reason = task.FollowupDropped, fmt.Sprintf("per-run cap %d reached", f.maxPerRun)
+		case f.mode == config.FollowupAsk && f.ask == nil:
+			status, reason = task.FollowupLogged, "no TUI to ask for approval"
+		case f.mode == config.FollowupAsk:
+			status = task.FollowupPending
+			f.accepted++
+			f.reserved[t.ID] = true
+		default:
+			status = task.FollowupAdded
+			f.accepted++
+			f.reserved[t.ID] = true
+		}
+		f.records[parent.ID] = append(f.records[parent.ID], task.FollowupInfo{
+			ID: t.ID, Title: t.Title, Status: status, Reason: reason,
+		})
+		f.mu.Unlock()
+
+		slog.Info("follow-up proposed", "task", parent.ID, "followup", t.ID, "status", status, "reason", reason)
+		switch status {
+		case task.FollowupAdded:
+			f.insert(parent.ID, t)
+		case task.FollowupPending:
+			f.request(parent.ID, t)
+		}
+	}
+}
+
+// request asks the TUI to approve t, keeping the run alive until answered.
+func (f *followupSpawner) request(parentID string, t task.Task) {
+	release, ok := f.sched.Hold()
+	if !ok {
+		f.finish(parentID, t.ID, task.FollowupDropped, "run has finished")
+		return
+	}
+	var once sync.Once
+	decide := func(approved bool, reason string) {
+		once.Do(func() {
+			defer release()
+			if !approved {
+				f.finish(parentID, t.ID, task.FollowupRejected, reason)
+				return
+			}
+			f.insert(parentID, t)
+		})
+	}
+	// a run cancelled while waiting, e.g. by quitting the TUI, declines it
+	stop := context.AfterFunc(f.ctx, func() { decide(false, "run cancelled") })
+	f.ask(parentID, t, func(approved bool) {
+		stop()
+		decide(approved, "")
+	})
+}
+
+// insert adds an accepted follow-up to the run.
+func (f *followupSpawner) insert(parentID string, t task.Task) {
+	if err := f.editor.addTask(t); err != nil {
+		slog.Warn("could not add follow-up", "task", parentID, "followup", t.ID, "error", err)
+		f.finish(parentID, t.ID, task.FollowupDropped, err.Error())
+		return
+	}
+	f.finish(parentID, t.ID, task.FollowupAdded, "")
+}
+
+// finish records the final status of a follow-up and releases its slot
+// when it was not added.
+func (f *followupSpawner) finish(parentID, id, status, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.reserved, id)
+	if status != task.FollowupAdded {
+		f.accepted--
+	}
+	recs := f.records[parentID]
+	for i := range recs {
+		if recs[i].ID == id && (recs[i].Status == task.FollowupPending || recs[i].Status == task.FollowupAdded) {
+			recs[i].Status = status
+			recs[i].Reason = reason
+		}
+	}
+}
+
+// validate completes a proposed follow-up from its parent and checks it
+// against the run. It returns a reason when the follow-up must be dropped.
+// Path rules and handoff always come from the parent so a follow-up cannot
+// widen what its parent was allowed to change. Caller must hold f.mu.
+func (f *followupSpawner) validate(parent *task.Task, p task.Task) (task.Task, string) {
+	t := task.Task{
+		ID:         strings.TrimSpace(p.ID),
+		Repo:       p.Repo,
+		Priority:   p.Priority,
+		Title:      strings.TrimSpace(p.Title),
+		Prompt:     strings.TrimSpace(p.Prompt),
+		Runner:     p.Runner,
+		Fallbacks:  p.Fallbacks,
+		DependsOn:  []string{parent.ID},
+		AllowPaths: parent.AllowPaths,
+		DenyPaths:  parent.DenyPaths,
+		PathPolicy: parent.PathPolicy,
+		Handoff:    parent.Handoff,
+		SpawnedBy:  parent.ID,
+	}
+	if t.Repo == "" {
+		t.Repo = parent.Repo
+	}
+	if t.Priority == 0 {
+		t.Priority = parent.Priority
+	}
+	if t.Runner == "" {
+		t.Runner = parent.Runner
+		if len(t.Fallbacks) == 0 {
+			t.Fallbacks = parent.Fallbacks
+		}
+	}
+	if t.Title == "" {
+		t.Title = followupTitle(t.Prompt)
+	}
+	if t.ID == "" {
+		for n := 1; ; n++ {
+			t.ID = fmt.Sprintf("%s-followup-%d", parent.ID, n)
+			if f.graph.Task(t.ID) == nil && !f.reserved[t.ID] && !f.recorded(parent.ID, t.ID) {
+				break
+			}
+		}
+	}
+
+	if t.Prompt == "" {
+		return t, "missing prompt"
+	}
+	if f.graph.Task(t.ID) != nil || f.reserved[t.ID] {
+		return t, fmt.Sprintf("task %q already exists", t.ID)
+	}
+	if t.Repo != parent.Repo && !f.knownRepo(t.Repo) {
+		return t, fmt.Sprintf("repo %q is not part of this run", t.Repo)
+	}
+	for _, r := range append([]string{t.Runner}, t.Fallbacks...) {
+		if r != "" && !slices.Contains(f.runners, r) {
+			return t, fmt.Sprintf("unknown runner %q", r)
+		}
+	}
+	for _, dep := range p.DependsOn {
+		if dep == parent.ID || slices.Contains(t.DependsOn, dep) {
+			continue
+		}
+		if f.graph.Task(dep) == nil {
+			return t, fmt.Sprintf("unknown dependency %q", dep)
+		}
+		t.DependsOn = append(t.DependsOn, dep)
+	}
+	return t, ""
+}
+
+// depth returns how many follow-up generations separate id from a task of
+// the original task files.
+func (f *followupSpawner) depth(id string) int {
+	d := 0
+	for t := f.graph.Task(id); t != nil && t.SpawnedBy != ""; t = f.graph.Task(t.SpawnedBy) {
+		d++
+	}
+	return d
+}
+
+// recorded reports whether parentID already proposed a follow-up named id,
+// so generated IDs stay distinct even for dropped proposals.
+func (f *followupSpawner) recorded(parentID, id string) bool {
+	return slices.ContainsFunc(f.records[parentID], func(r task.FollowupInfo) bool { return r.ID == id })
+}
+
+func (f *followupSpawner) knownRepo(repo string) bool {
+	for _, t := range f.graph.Tasks() {
+		if t.Repo == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// attach copies the follow-up records onto the proposing tasks' results.
+// Follow-ups still waiting for approval when the run ended are dropped.
+func (f *followupSpawner) attach(results map[string]*task.TaskResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, recs := range f.records {
+		for i := range recs {
+			if recs[i].Status == task.FollowupPending {
+				recs[i].Status = task.FollowupDropped
+				recs[i].Reason = "run ended before approval"
+			}
+		}
+		if r := results[id]; r != nil {
+			r.Followups = recs
+		}
+	}
+}
+
+// followupTitle derives a title from the first line of a prompt.
+func followupTitle(prompt string) string {
+	title, _, _ := strings.Cut(prompt, "\n")
+	title = strings.TrimSpace(title)
+	if r := []rune(title); len(r) > 72 {
+		title = string(r[:71]) + "…"
+	}
+	return title
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func followupResult(t *testing.T, file, msg string) *task.TaskResult {
+	t.Helper()
+	dir := t.TempDir()
+	if file != "" {
+		if err := os.WriteFile(filepath.Join(dir, runner.FollowupsFile), []byte(file), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &task.TaskResult{State: task.StateCompleted, OutputDir: dir, LastMsg: msg}
+}
+
+func TestFollowups_AutoInsertsDependents(t *testing.T) {
+	ed, finish := startEditorRun(t, []task.Task{
+		{ID: "busy", Repo: "org/r", Priority: 2, Title: "Busy", Prompt: "busy", Runner: "codex",
+			DenyPaths: []string{"go.sum"}},
+	})
+	f := newFollowupSpawner(context.Background(), &config.FollowupsConfig{Mode: config.FollowupAuto, MaxPerRun: 2}, ed.sched, ed)
+
+	file := `[{"prompt": "update tests in pkg/x\nmore detail"}, {"prompt": "bad", "runner": "nope"}]`
+	msg := "done\n```followups\n[{\"id\": \"docs\", \"title\": \"Docs\", \"prompt\": \"document it\", \"deny_paths\": []}, {\"prompt\": \"one too many\"}]\n```"
+	f.spawn(ed.graph.Task("busy"), followupResult(t, file, msg))
+
+	added := ed.graph.Task("busy-followup-1")
+	if added == nil {
+		t.Fatal("follow-up without id should get a generated one")
+	}
+	if added.Title != "update tests in pkg/x" || added.SpawnedBy != "busy" || added.Priority != 2 || added.Runner != "codex" {
+		t.Errorf("follow-up = %+v", added)
+	}
+	if len(added.DependsOn) != 1 || added.DependsOn[0] != "busy" {
+		t.Errorf("depends_on = %v", added.DependsOn)
+	}
+	if docs := ed.graph.Task("docs"); docs == nil || len(docs.DenyPaths) != 1 {
+		t.Errorf("docs follow-up should keep the parent's deny paths, got %+v", docs)
+	}
+
+	results := finish()
+	f.attach(results)
+	for _, id := range []string{"busy-followup-1", "docs"} {
+		if r := results[id]; r == nil || r.State != task.StateCompleted {
+			t.Errorf("%s = %+v", id, r)
+		}
+	}
+	got := map[string]string{}
+	for _, r := range results["busy"].Followups {
+		got[r.ID] = r.Status + " " + r.Reason
+	}
+	if got["busy-followup-1"] != "added " || got["docs"] != "added " {
+		t.Errorf("records = %v", got)
+	}
+	if !strings.Contains(got["busy-followup-2"], `unknown runner "nope"`) {
+		t.Errorf("invalid runner record = %q", got["busy-followup-2"])
+	}
+	if !strings.Contains(got["busy-followup-3"], "per-run cap 2") {
+		t.Errorf("cap record = %q", got["busy-followup-3"])
+	}
+}
+
+func TestFollowups_DepthLimitAndLogMode(t *testing.T) {
+	ed, finish := startEditorRun(t, []task.Task{
+		{ID: "busy", Repo: "org/r", Priority: 1, Title: "Busy", Prompt: "busy", Runner: "codex"},
+		{ID: "child", Repo: "org/r", Priority: 1, Title: "Child", Prompt: "child", Runner: "codex", SpawnedBy: "busy"},
+	})
+	defer finish()
+	file := `[{"id": "deeper", "prompt": "go deeper"}]`
+
+	f := newFollowupSpawner(context.Background(), &config.FollowupsConfig{Mode: config.FollowupAuto}, ed.sched, ed)
+	f.spawn(ed.graph.Task("child"), followupResult(t, file, ""))
+	if ed.graph.Task("deeper") != nil {
+		t.Error("follow-up beyond max_depth should not be inserted")
+	}
+	if recs := f.records["child"]; len(recs) != 1 || !strings.Contains(recs[0].Reason, "depth limit 1") {
+		t.Errorf("records = %+v", recs)
+	}
+
+	logOnly := newFollowupSpawner(context.Background(), &config.FollowupsConfig{Mode: config.FollowupLog}, ed.sched, ed)
+	logOnly.spawn(ed.graph.Task("busy"), followupResult(t, file, ""))
+	if ed.graph.Task("deeper") != nil {
+		t.Error("log mode should not insert tasks")
+	}
+	if recs := logOnly.records["busy"]; len(recs) != 1 || recs[0].Status != task.FollowupLogged {
+		t.Errorf("log records = %+v", recs)
+	}
+}
+
+func TestFollowups_AskMode(t *testing.T) {
+	ed, finish := startEditorRun(t, []task.Task{
+		{ID: "busy", Repo: "org/r", Priority: 1, Title: "Busy", Prompt: "busy", Runner: "codex"},
+	})
+	f := newFollowupSpawner(context.Background(), &config.FollowupsConfig{Mode: config.FollowupAsk}, ed.sched, ed)
+
+	// without a TUI the follow-ups are only logged
+	f.spawn(ed.graph.Task("busy"), followupResult(t, `[{"id": "a", "prompt": "a"}]`, ""))
+	if recs := f.records["busy"]; len(recs) != 1 || recs[0].Status != task.FollowupLogged {
+		t.Fatalf("records without TUI = %+v", recs)
+	}
+
+	var asked []string
+	f.ask = func(parentID string, tk task.Task, decide func(bool)) {
+		asked = append(asked, tk.ID)
+		decide(tk.ID == "yes")
+	}
+	f.spawn(ed.graph.Task("busy"), followupResult(t, `[{"id": "yes", "prompt": "y"}, {"id": "no", "prompt": "n"}]`, ""))
+	if len(asked) != 2 {
+		t.Errorf("asked = %v", asked)
+	}
+	if ed.graph.Task("yes") == nil || ed.graph.Task("no") != nil {
+		t.Error("only the approved follow-up should be inserted")
+	}
+
+	results := finish()
+	f.attach(results)
+	status := map[string]string{}
+	for _, r := range results["busy"].Followups {
+		status[r.ID] = r.Status
+	}
+	if status["yes"] != task.FollowupAdded || status["no"] != task.FollowupRejected {
+		t.Errorf("statuses = %v", status)
+	}
+	if r := results["yes"]; r == nil || r.State != task.StateCompleted {
+		t.Errorf("yes = %+v", r)
+	}
+}
+
+// Follow-ups are spawned from the parent's worker before it completes, so
+// with one worker they must be queued without blocking it.
+func TestFollowups_SingleWorkerMoreThanInitialTasks(t *testing.T) {
+	graph, err := task.BuildGraph([]task.Task{
+		{ID: "parent", Repo: "org/r", Priority: 1, Title: "Parent", Prompt: "parent", Runner: "codex"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f *followupSpawner
+	file := `[{"id": "f1", "prompt": "one"}, {"id": "f2", "prompt": "two"}, {"id": "f3", "prompt": "three"}]`
+	sched := task.NewScheduler(graph, task.SchedulerConfig{
+		Workers:  1,
+		ReposDir: t.TempDir(),
+		RunDir:   t.TempDir(),
+		ExecFn: func(_ context.Context, tk *task.Task, _, _ string) *task.TaskResult {
+			result := followupResult(t, "", "")
+			if tk.ID == "parent" {
+				result = followupResult(t, file, "")
+				f.spawn(tk, result)
+			}
+			result.TaskID = tk.ID
+			return result
+		},
+	})
+	ed := &taskEditor{
+		sched:         sched,
+		graph:         graph,
+		runners:       []string{"codex"},
+		runDir:        t.TempDir(),
+		defaultRunner: "codex",
+		basePrompts:   map[string]string{"parent": "parent"},
+	}
+	f = newFollowupSpawner(context.Background(), &config.FollowupsConfig{Mode: config.FollowupAuto}, sched, ed)
+
+	done := make(chan map[string]*task.TaskResult)
+	go func() { done <- sched.Run(context.Background()) }()
+	select {
+	case results := <-done:
+		for _, id := range []string{"parent", "f1", "f2", "f3"} {
+			if r := results[id]; r == nil || r.State != task.StateCompleted {
+				t.Errorf("%s = %+v, want completed", id, r)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run hung queueing follow-ups with a single worker")
+	}
+}
+
+func TestInjectFollowupInstructions(t *testing.T) {
+	tasks := []task.Task{{ID: "a", Runner: "codex", Prompt: "do it"}}
+	injectFollowupInstructions(tasks, nil)
+	injectFollowupInstructions(tasks, nil)
+	if strings.Count(tasks[0].Prompt, "tagged `followups`") != 1 {
+		t.Errorf("prompt = %q", tasks[0].Prompt)
+	}
+}
@@ -0,0 +1,490 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// maxResolveSteps bounds the rebase steps handled for one branch.
+const maxResolveSteps = 50
+
+// maxHunkLines caps each side of a hunk quoted in the agent prompt.
+const maxHunkLines = 40
+
+// conflictInfo holds metadata about a merge-conflicted task for resolution.
+type conflictInfo struct {
+	taskID string
+	branch string
+	title  string
+	prompt string
+	repo   string
+}
+
+// collectConflicts finds tasks with unmerged worktree branches, in DAG order
+// so that dependencies are rebased and merged before their dependents.
+func collectConflicts(results map[string]*task.TaskResult, tasks []task.Task, graph *task.Graph) []conflictInfo {
+	// index tasks by ID for prompt/title lookup
+	taskMap := make(map[string]*task.Task, len(tasks))
+	for i := range tasks {
+		taskMap[tasks[i].ID] = &tasks[i]
+	}
+
+	var conflicts []conflictInfo
+	for _, r := range results {
+		if !r.MergeConflict || r.WorktreeBranch == "" {
+			continue
+		}
+		ci := conflictInfo{
+			taskID: r.TaskID,
+			branch: r.WorktreeBranch,
+		}
+		if t, ok := taskMap[r.TaskID]; ok {
+			ci.title = t.Title
+			ci.repo = t.Repo
+			ci.prompt = t.Prompt
+			if len(ci.prompt) > 200 {
+				ci.prompt = ci.prompt[:200] + "..."
+			}
+		}
+		conflicts = append(conflicts, ci)
+	}
+
+	rank := make(map[string]int)
+	if graph != nil {
+		for i, id := range graph.Order() {
+			rank[id] = i
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		ri, iok := rank[conflicts[i].taskID]
+		rj, jok := rank[conflicts[j].taskID]
+		if iok != jok {
+			return iok
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return conflicts[i].taskID < conflicts[j].taskID
+	})
+	return conflicts
+}
+
+// fileConflict is a conflicted file left for the agent after automatic
+// resolution.
+type fileConflict struct {
+	path  string
+	note  string // set when the file has no markers, e.g. modify/delete or binary
+	hunks []runner.ConflictHunk
+}
+
+// resolveAgent resolves the remaining hunks of one rebase step by editing
+// files in dir. A nil agent leaves non-trivial conflicts unresolved.
+type resolveAgent func(ctx context.Context, c conflictInfo, dir, prompt string) *task.TaskResult
+
+// branchResolver rebases conflicted branches of one repo onto its main
+// branch and merges them once the resolution passes verification.
+type branchResolver struct {
+	repoDir  string
+	reposDir string
+	logDir   string
+	verify   bool // also run make test/lint before merging
+	agent    resolveAgent
+}
+
+// resolveStats counts how conflicts in one branch were resolved.
+type resolveStats struct {
+	rerere  int
+	trivial int
+	agent   int
+}
+
+// resolve rebases c.branch onto main, resolving conflicts step by step:
+// rerere replays and trivial hunks are applied directly, only the remaining
+// hunks and conflicts without markers (modify/delete, binary, file mode)
+// go to the agent. The rebased branch is merged only after the build
+// (and, when enabled, make test/lint) passes; otherwise it is restored.
+func (b *branchResolver) resolve(ctx context.Context, c conflictInfo) (resolveStats, []*task.TaskResult, error) {
+	var stats resolveStats
+	var agentResults []*task.TaskResult
+
+	reb, err := runner.StartConflictRebase(ctx, b.repoDir, b.reposDir, c.branch)
+	if err != nil {
+		return stats, nil, fmt.Errorf("rebase: %w", err)
+	}
+
+	for step := 0; !reb.Done; step++ {
+		if step >= maxResolveSteps {
+			reb.Abort(ctx)
+			return stats, agentResults, fmt.Errorf("rebase did not finish after %d steps", maxResolveSteps)
+		}
+		stats.rerere += reb.Replayed // staged by rerere before the step stopped
+		files, err := reb.Unmerged(ctx)
+		if err != nil {
+			reb.Abort(ctx)
+			return stats, agentResults, err
+		}
+
+		var staged []string
+		var pending []fileConflict
+		for _, f := range files {
+			path := filepath.Join(reb.Dir, f)
+			data, err := os.ReadFile(path)
+			if err != nil || !runner.HasConflictMarkers(string(data)) {
+				note, err := reb.ConflictNote(ctx, f)
+				if err != nil {
+					reb.Abort(ctx)
+					return stats, agentResults, err
+				}
+				pending = append(pending, fileConflict{path: f, note: note})
+				continue
+			}
+			content, hunks := runner.ResolveTrivialHunks(string(data))
+			if content != string(data) {
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					reb.Abort(ctx)
+					return stats, agentResults, fmt.Errorf("write %s: %w", f, err)
+				}
+			}
+			if len(hunks) == 0 {
+				stats.trivial++
+				staged = append(staged, f)
+				continue
+			}
+			pending = append(pending, fileConflict{path: f, hunks: hunks})
+		}
+
+		if len(pending) > 0 {
+			if b.agent == nil {
+				reb.Abort(ctx)
+				return stats, agentResults, fmt.Errorf("%d files need manual resolution and no runner is available", len(pending))
+			}
+			result := b.agent(ctx, c, reb.Dir, buildConflictPrompt(c, pending))
+			if result != nil {
+				agentResults = append(agentResults, result)
+			}
+			if result == nil || result.State != task.StateCompleted {
+				reb.Abort(ctx)
+				return stats, agentResults, fmt.Errorf("agent could not resolve %s", conflictPaths(pending))
+			}
+			for _, p := range pending {
+				data, err := os.ReadFile(filepath.Join(reb.Dir, p.path))
+				if err == nil && runner.HasConflictMarkers(string(data)) {
+					reb.Abort(ctx)
+					return stats, agentResults, fmt.Errorf("conflict markers left in %s", p.path)
+				}
+				staged = append(staged, p.path)
+			}
+			stats.agent += len(pending)
+		}
+
+		if err := reb.Stage(ctx, staged...); err != nil {
+			reb.Abort(ctx)
+			return stats, agentResults, err
+		}
+		if err := reb.Continue(ctx); err != nil {
+			reb.Abort(ctx)
+			return stats, agentResults, err
+		}
+	}
+
+	if err := b.check(ctx, c, reb.Dir); err != nil {
+		reb.Abort(ctx)
+		return stats, agentResults, fmt.Errorf("verification failed: %w", err)
+	}
+	reb.Finish(ctx)
+
+	if err := runner.MergeBack(ctx, b.repoDir, c.branch); err != nil {
+		return stats, agentResults, fmt.Errorf("merge: %w", err)
+	}
+	runner.DeleteBranch(ctx, b.repoDir, c.branch)
+	return stats, agentResults, nil
+}
+
+// check runs the build check and, when enabled, the repo's make test/lint
+// acceptance checks against the rebased branch.
+func (b *branchResolver) check(ctx context.Context, c conflictInfo, dir string) error {
+	if err := runner.QuickVerify(ctx, dir); err != nil {
+		return err
+	}
+	if !b.verify {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Makefile")); err != nil {
+		return nil
+	}
+	vr := runner.Verify(ctx, c.repo, dir, filepath.Join(b.logDir, c.taskID))
+	if !vr.Passed {
+		return fmt.Errorf("%s", vr.Error)
+	}
+	return nil
+}
+
+// buildConflictPrompt constructs agent instructions for the hunks that could
+// not be resolved automatically in one rebase step.
+func buildConflictPrompt(c conflictInfo, pending []fileConflict) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are resolving merge conflicts while rebasing branch %s onto the updated main branch.\n", c.branch)
+	if c.title != "" {
+		fmt.Fprintf(&b, "The branch implements task %q.\n", c.title)
+	}
+	if c.prompt != "" {
+		fmt.Fprintf(&b, "Task description: %s\n", c.prompt)
+	}
+	b.WriteString("\nOnly the conflicts below need a decision; all others were resolved automatically.\n")
+	b.WriteString("Files use diff3 markers: <<<<<<< main, ||||||| common base, =======, this branch, >>>>>>>.\n\n")
+
+	for _, f := range pending {
+		fmt.Fprintf(&b, "File: %s\n", f.path)
+		if f.note != "" {
+			fmt.Fprintf(&b, "  %s — keep the version, or delete the file, as the task intends.\n\n", f.note)
+			continue
+		}
+		for _, h := range f.hunks {
+			fmt.Fprintf(&b, "  Conflict at line %d\n", h.Line)
+			writeHunkSide(&b, "main", h.Ours)
+			if h.HasBase {
+				writeHunkSide(&b, "base", h.Base)
+			}
+			writeHunkSide(&b, "branch", h.Theirs)
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("Edit each file so it keeps the intent of both main and the branch, and remove all conflict markers.\n")
+	b.WriteString("Do NOT run git commit, git rebase, git merge or git checkout — the rebase is continued for you.\n")
+	return b.String()
+}
+
+func writeHunkSide(b *strings.Builder, label string, lines []string) {
+	fmt.Fprintf(b, "  %s:\n", label)
+	if len(lines) == 0 {
+		b.WriteString("    (empty)\n")
+		return
+	}
+	shown := lines
+	if len(shown) > maxHunkLines {
+		shown = shown[:maxHunkLines]
+	}
+	for _, l := range shown {
+		fmt.Fprintf(b, "    %s\n", l)
+	}
+	if len(lines) > len(shown) {
+		fmt.Fprintf(b, "    ... (%d more lines)\n", len(lines)-len(shown))
+	}
+}
+
+func conflictPaths(pending []fileConflict) string {
+	paths := make([]string, len(pending))
+	for i, p := range pending {
+		paths[i] = p.path
+	}
+	return strings.Join(paths, ", ")
+}
+
+// runMergeResolve rebases conflicted branches onto their repo's main branch
+// in DAG order and merges each one whose resolution passes verification.
+// Merged tasks are cleared of MergeConflict in results. It returns a
+// synthetic merge-resolve result when an agent was dispatched, nil otherwise.
+func runMergeResolve(
+	ctx context.Context,
+	conflicts []conflictInfo,
+	results map[string]*task.TaskResult,
+	cfg execRunConfig,
+	runners map[string]runner.Runner,
+	defaultRunner string,
+	tf *task.TaskFile,
+	blacklist *runner.RunnerBlacklist,
+	graylist *runner.RunnerGraylist,
+	limiter *runner.ProviderLimiter,
+	runDir string,
+) *task.TaskResult {
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	resolveID := "merge-resolve"
+	outputDir := filepath.Join(runDir, resolveID)
+	_ = os.MkdirAll(outputDir, 0o755)
+
+	cascade := resolveRunnerCascade(&task.Task{ID: resolveID, Difficulty: "medium"}, defaultRunner, tf.DefaultFallbacks)
+	var agent resolveAgent
+	if len(cascade) > 0 {
+		agent = func(ctx context.Context, c conflictInfo, dir, prompt string) *task.TaskResult {
+			resolveTask := &task.Task{
+				ID:         resolveID + "-" + c.taskID,
+				Repo:       c.repo,
+				Title:      "Merge resolve: " + c.branch,
+				Prompt:     prompt,
+				Difficulty: "medium",
+			}
+			taskOut := filepath.Join(outputDir, c.taskID)
+			_ = os.MkdirAll(taskOut, 0o755)
+			return runResolveAgent(ctx, resolveTask, dir, taskOut, runners, cascade, cfg, blacklist, graylist, limiter)
+		}
+	} else {
+		slog.Warn("merge-resolve: no runners available, only automatic resolutions will be applied")
+	}
+
+	// group by repo, keeping DAG order within each repo
+	var repos []string
+	byRepo := make(map[string][]conflictInfo)
+	for _, c := range conflicts {
+		if _, ok := byRepo[c.repo]; !ok {
+			repos = append(repos, c.repo)
+		}
+		byRepo[c.repo] = append(byRepo[c.repo], c)
+	}
+
+	fmt.Fprintf(os.Stdout, "\nMerge resolution: rebasing %d conflicted branches onto main...\n", len(conflicts))
+
+	start := time.Now()
+	var agentResults []*task.TaskResult
+	var unresolved []string
+	merged := 0
+	for _, repo := range repos {
+		repoDir := config.RepoPath(repo, cfg.reposDir)
+		if err := runner.WaitAndAcquire(ctx, repoDir, resolveID); err != nil {
+			slog.Warn("merge-resolve: failed to acquire repo lock", "repo", repo, "error", err)
+			for _, c := range byRepo[repo] {
+				unresolved = append(unresolved, c.branch)
+			}
+			continue
+		}
+		br := &branchResolver{
+			repoDir:  repoDir,
+			reposDir: cfg.reposDir,
+			logDir:   outputDir,
+			verify:   cfg.verify,
+			agent:    agent,
+		}
+		for _, c := range byRepo[repo] {
+			stats, used, err := br.resolve(ctx, c)
+			agentResults = append(agentResults, used...)
+			if err != nil {
+				fmt.Fprintf(os.Stdout, "  %s: left for manual resolution: %v\n", c.branch, err)
+				unresolved = append(unresolved, c.branch)
+				continue
+			}
+			merged++
+			fmt.Fprintf(os.Stdout, "  %s: merged (rerere %d, trivial %d, agent %d files)\n",
+				c.branch, stats.rerere, stats.trivial, stats.agent)
+			if r := results[c.taskID]; r != nil {
+				r.MergeConflict = false
+				r.WorktreeBranch = ""
+			}
+		}
+		runner.Release(repoDir)
+	}
+	fmt.Fprintf(os.Stdout, "Merge resolution: %d/%d branches merged successfully\n", merged, len(conflicts))
+
+	if len(agentResults) == 0 {
+		return nil
+	}
+	summary := &task.TaskResult{
+		TaskID:    resolveID,
+		State:     task.StateCompleted,
+		StartedAt: start,
+		EndedAt:   time.Now(),
+		Duration:  time.Since(start),
+		OutputDir: outputDir,
+	}
+	byStep := make(map[string]*task.TaskResult, len(agentResults))
+	for i, r := range agentResults {
+		summary.RunnerUsed = r.RunnerUsed
+		summary.Attempts = append(summary.Attempts, r.Attempts...)
+		byStep[fmt.Sprint(i)] = r
+	}
+	summary.TokensUsed = aggregateTokens(byStep)
+	if len(unresolved) > 0 {
+		summary.State = task.StateFailed
+		summary.Error = "unresolved branches: " + strings.Join(unresolved, ", ")
+	}
+	return summary
+}
+
+// runResolveAgent runs the cascade for one resolution step, streaming the
+// runner's stderr so the user sees agent progress.
+func runResolveAgent(
+	ctx context.Context,
+	t *task.Task,
+	dir, outputDir string,
+	runners map[string]runner.Runner,
+	cascade []string,
+	cfg execRunConfig,
+	blacklist *runner.RunnerBlacklist,
+	graylist *runner.RunnerGraylist,
+	limiter *runner.ProviderLimiter,
+) *task.TaskResult {
+	start := time.Now()
+	tickDone := make(chan struct{})
+	stderrPath := filepath.Join(outputDir, "stderr.log")
+	go func() {
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		var offset int64
+		for {
+			select {
+			case <-tickDone:
+				return
+			case <-ticker.C:
+				offset = tailMergeLog(stderrPath, offset, start)
+			}
+		}
+	}()
+
+	result := RunWithCascade(ctx, t, dir, outputDir, runners, cascade,
+		cfg.maxRuntime, cfg.maxRetries, blacklist, graylist, limiter, nil,
+		func(runnerName string) {
+			fmt.Fprintf(os.Stdout, "  %s: using runner %q\n", t.ID, runnerName)
+		}, nil)
+	close(tickDone)
+	// flush remaining output
+	tailMergeLog(stderrPath, 0, start)
+	return result
+}
+
+// tailMergeLog reads new lines from the merge-resolve stderr log and prints
+// them to stdout with a prefix. Returns the new file offset.
+func tailMergeLog(path string, offset int64, start time.Time) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return offset
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() <= offset {
+		return offset
+	}
+
+	if _, err := f.Seek(offset, 0); err != nil {
+		return offset
+	}
+
+	buf := make([]byte, info.Size()-offset)
+	n, err := f.Read(buf)
+	if err != nil || n == 0 {
+		return offset
+	}
+
+	lines := strings.Split(strings.TrimRight(string(buf[:n]), "\n"), "\n")
+	elapsed := time.Since(start).Truncate(time.Second)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "  [%s] %s\n", elapsed, line)
+	}
+	return offset + int64(n)
+}
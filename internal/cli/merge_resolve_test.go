@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// resolveTestRepo creates repos/app with a main branch and a tokencontrol/a
+// branch that both edited notes.txt starting from the same base.
+func resolveTestRepo(t *testing.T, base, mainContent, branchContent string) (reposDir, repoDir string) {
+	t.Helper()
+	reposDir = t.TempDir()
+	repoDir = filepath.Join(reposDir, "app")
+	_ = os.MkdirAll(repoDir, 0o755)
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=test",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	write := func(content string) {
+		_ = os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte(content), 0o644)
+	}
+	git("init", "-b", "main")
+	write(base)
+	git("add", ".")
+	git("commit", "-m", "initial")
+	git("checkout", "-b", "tokencontrol/a")
+	write(branchContent)
+	git("commit", "-am", "branch")
+	git("checkout", "main")
+	write(mainContent)
+	git("commit", "-am", "main")
+	return reposDir, repoDir
+}
+
+func branchExists(repoDir, branch string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", branch)
+	cmd.Dir = repoDir
+	return cmd.Run() == nil
+}
+
+func TestCollectConflicts_DAGOrder(t *testing.T) {
+	tasks := []task.Task{
+		{ID: "c", Repo: "org/app", Title: "C", DependsOn: []string{"b"}},
+		{ID: "b", Repo: "org/app", Title: "B", DependsOn: []string{"a"}},
+		{ID: "a", Repo: "org/app", Title: "A", Prompt: strings.Repeat("x", 300)},
+	}
+	graph, err := task.BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := map[string]*task.TaskResult{
+		"c": {TaskID: "c", MergeConflict: true, WorktreeBranch: "tokencontrol/c"},
+		"a": {TaskID: "a", MergeConflict: true, WorktreeBranch: "tokencontrol/a"},
+		"b": {TaskID: "b", State: task.StateCompleted},
+	}
+
+	got := collectConflicts(results, tasks, graph)
+	if len(got) != 2 || got[0].taskID != "a" || got[1].taskID != "c" {
+		t.Fatalf("conflicts = %+v, want a then c", got)
+	}
+	if len(got[0].prompt) != 203 || got[0].repo != "org/app" {
+		t.Errorf("conflict a = %+v", got[0])
+	}
+}
+
+func TestBuildConflictPrompt(t *testing.T) {
+	c := conflictInfo{taskID: "a", branch: "tokencontrol/a", title: "Add retries"}
+	prompt := buildConflictPrompt(c, []fileConflict{
+		{path: "main.go", hunks: []runner.ConflictHunk{{
+			Line: 12, Ours: []string{"x := 1"}, Base: []string{"x := 0"}, Theirs: []string{"x := 2"}, HasBase: true,
+		}}},
+		{path: "old.go", note: "deleted on main and modified on this branch"},
+	})
+	for _, want := range []string{
+		"rebasing branch tokencontrol/a",
+		`task "Add retries"`,
+		"File: main.go",
+		"Conflict at line 12",
+		"main:\n    x := 1",
+		"base:\n    x := 0",
+		"branch:\n    x := 2",
+		"File: old.go\n  deleted on main",
+		"Do NOT run git commit",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestBuildConflictPrompt_TruncatesLongHunks(t *testing.T) {
+	long := make([]string, maxHunkLines+5)
+	for i := range long {
+		long[i] = "line"
+	}
+	prompt := buildConflictPrompt(conflictInfo{branch: "b"}, []fileConflict{
+		{path: "f", hunks: []runner.ConflictHunk{{Ours: long, Theirs: []string{"y"}}}},
+	})
+	if !strings.Contains(prompt, "... (5 more lines)") {
+		t.Errorf("expected truncation marker:\n%s", prompt)
+	}
+}
+
+func TestBranchResolver_TrivialWithoutAgent(t *testing.T) {
+	// sides differ only in whitespace: resolved without an agent; the
+	// branch also edits another line so rebase doesn't drop it as applied
+	reposDir, repoDir := resolveTestRepo(t, "foo()\n1\n2\n3\n4\nend\n", "foo(a, b)\n1\n2\n3\n4\nend\n", "foo(a,  b)\n1\n2\n3\n4\nEND\n")
+	br := &branchResolver{repoDir: repoDir, reposDir: reposDir, logDir: t.TempDir()}
+
+	stats, used, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if stats.trivial != 1 || stats.agent != 0 || len(used) != 0 {
+		t.Errorf("stats = %+v, agent results = %d", stats, len(used))
+	}
+	got, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt"))
+	if string(got) != "foo(a, b)\n1\n2\n3\n4\nEND\n" {
+		t.Errorf("merged content = %q", got)
+	}
+	if branchExists(repoDir, "tokencontrol/a") {
+		t.Error("merged branch should be deleted")
+	}
+}
+
+func TestBranchResolver_BothAddedNeedsAgent(t *testing.T) {
+	// both sides appended different lines: not trivial, and no agent is set
+	reposDir, repoDir := resolveTestRepo(t, "head\n", "head\nmain line\n", "head\nbranch line\n")
+	br := &branchResolver{repoDir: repoDir, reposDir: reposDir, logDir: t.TempDir()}
+
+	_, _, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err == nil || !strings.Contains(err.Error(), "need manual resolution") {
+		t.Fatalf("err = %v", err)
+	}
+	if !branchExists(repoDir, "tokencontrol/a") {
+		t.Error("branch should be kept")
+	}
+}
+
+func TestBranchResolver_AgentResolvesHunk(t *testing.T) {
+	reposDir, repoDir := resolveTestRepo(t, "x = 0\n", "x = 1\n", "x = 2\n")
+	var prompts []string
+	br := &branchResolver{repoDir: repoDir, reposDir: reposDir, logDir: t.TempDir(),
+		agent: func(_ context.Context, _ conflictInfo, dir, prompt string) *task.TaskResult {
+			prompts = append(prompts, prompt)
+			_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x = 3\n"), 0o644)
+			return &task.TaskResult{TaskID: "merge-resolve-a", State: task.StateCompleted}
+		}}
+
+	stats, used, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if stats.agent != 1 || len(used) != 1 || len(prompts) != 1 {
+		t.Fatalf("stats = %+v, results = %d, prompts = %d", stats, len(used), len(prompts))
+	}
+	if !strings.Contains(prompts[0], "main:\n    x = 1") || !strings.Contains(prompts[0], "branch:\n    x = 2") {
+		t.Errorf("prompt lacks hunk context:\n%s", prompts[0])
+	}
+	got, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt"))
+	if string(got) != "x = 3\n" {
+		t.Errorf("merged content = %q", got)
+	}
+}
+
+func TestBranchResolver_UnresolvedRestoresBranch(t *testing.T) {
+	reposDir, repoDir := resolveTestRepo(t, "x = 0\n", "x = 1\n", "x = 2\n")
+	br := &branchResolver{repoDir: repoDir, reposDir: reposDir, logDir: t.TempDir(),
+		agent: func(context.Context, conflictInfo, string, string) *task.TaskResult {
+			return &task.TaskResult{State: task.StateCompleted} // leaves markers in place
+		}}
+
+	_, _, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err == nil || !strings.Contains(err.Error(), "conflict markers left in notes.txt") {
+		t.Fatalf("err = %v", err)
+	}
+	if !branchExists(repoDir, "tokencontrol/a") {
+		t.Error("unresolved branch should be kept for manual resolution")
+	}
+	got, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt"))
+	if string(got) != "x = 1\n" {
+		t.Errorf("main should be unchanged, got %q", got)
+	}
+}
+
+func TestBranchResolver_MarkerlessConflictNotStaged(t *testing.T) {
+	// binary content: the conflict has no markers and is not resolved
+	reposDir, repoDir := resolveTestRepo(t, "\x00base\n", "\x00main\n", "\x00branch\n")
+	br := &branchResolver{repoDir: repoDir, reposDir: reposDir, logDir: t.TempDir()}
+
+	_, _, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err == nil || !strings.Contains(err.Error(), "need manual resolution") {
+		t.Fatalf("err = %v", err)
+	}
+	if !branchExists(repoDir, "tokencontrol/a") {
+		t.Error("branch should be kept")
+	}
+	if got, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt")); string(got) != "\x00main\n" {
+		t.Errorf("main should be unchanged, got %q", got)
+	}
+
+	var prompt string
+	br.agent = func(_ context.Context, _ conflictInfo, dir, p string) *task.TaskResult {
+		prompt = p
+		_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("\x00both\n"), 0o644)
+		return &task.TaskResult{State: task.StateCompleted}
+	}
+	stats, _, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err != nil {
+		t.Fatalf("resolve with agent: %v", err)
+	}
+	if stats.agent != 1 || stats.rerere != 0 {
+		t.Errorf("stats = %+v, want the file resolved by the agent", stats)
+	}
+	if !strings.Contains(prompt, "File: notes.txt\n  changed on both sides") {
+		t.Errorf("prompt should describe the conflict:\n%s", prompt)
+	}
+	if got, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt")); string(got) != "\x00both\n" {
+		t.Errorf("merged content = %q", got)
+	}
+}
+
+func TestBranchResolver_VerificationBlocksMerge(t *testing.T) {
+	reposDir, repoDir := resolveTestRepo(t, "foo()\n1\n2\n3\n4\nend\n", "foo(a, b)\n1\n2\n3\n4\nend\n", "foo(a,  b)\n1\n2\n3\n4\nEND\n")
+	// a Makefile whose test target fails rejects the resolution
+	cmd := exec.Command("sh", "-c", `printf 'test:\n\tfalse\nlint:\n\ttrue\n' > Makefile && git add Makefile && git -c user.name=t -c user.email=t@t commit -qm make`)
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("add Makefile: %s", out)
+	}
+	br := &branchResolver{repoDir: repoDir, reposDir: reposDir, logDir: t.TempDir(), verify: true}
+
+	_, _, err := br.resolve(context.Background(), conflictInfo{taskID: "a", branch: "tokencontrol/a", repo: "app"})
+	if err == nil || !strings.Contains(err.Error(), "verification failed: make test failed") {
+		t.Fatalf("err = %v", err)
+	}
+	if !branchExists(repoDir, "tokencontrol/a") {
+		t.Error("branch should be kept when verification fails")
+	}
+}
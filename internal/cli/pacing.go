@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+const (
+	// defaultPacingSafetyFactor keeps learned pacing below the rate at
+	// which a runner was previously throttled.
+	defaultPacingSafetyFactor = 0.8
+	// pacingLookback bounds the telemetry history used to learn rates.
+	pacingLookback = 30 * 24 * time.Hour
+)
+
+// learnRunnerRates reads per-runner throughput history from telemetry.
+// Learning is best-effort: failures are logged and yield no learned rates.
+func learnRunnerRates(cfg *config.Settings, dbPath string) []telemetry.RunnerRate {
+	if cfg != nil && cfg.Pacing != nil && cfg.Pacing.Learn != nil && !*cfg.Pacing.Learn {
+		return nil
+	}
+	db, err := telemetry.OpenDB(dbPath)
+	if err != nil {
+		slog.Debug("rate learning disabled", "error", err)
+		return nil
+	}
+	defer func() { _ = db.Close() }()
+
+	since := time.Now().Add(-pacingLookback).UTC().Format(time.RFC3339)
+	rates, err := telemetry.QueryRunnerRates(db, since)
+	if err != nil {
+		slog.Warn("rate learning disabled", "error", err)
+		return nil
+	}
+	return rates
+}
+
+// buildRateLimits combines provider-published limits from settings with
+// limits learned from past rate-limit events. Configured values always win;
+// learned ceilings are scaled by the safety factor and only fill dimensions
+// the config leaves unset.
+func buildRateLimits(cfg *config.Settings, learned []telemetry.RunnerRate) map[string]runner.RateLimit {
+	safety := defaultPacingSafetyFactor
+	if cfg != nil && cfg.Pacing != nil && cfg.Pacing.SafetyFactor > 0 && cfg.Pacing.SafetyFactor <= 1 {
+		safety = cfg.Pacing.SafetyFactor
+	}
+
+	limits := make(map[string]runner.RateLimit)
+	if cfg != nil {
+		for name, rp := range cfg.Runners {
+			if rp == nil || (rp.RequestsPerMinute <= 0 && rp.TokensPerMinute <= 0) {
+				continue
+			}
+			limits[name] = runner.RateLimit{
+				RequestsPerMinute: float64(rp.RequestsPerMinute),
+				TokensPerMinute:   float64(rp.TokensPerMinute),
+			}
+		}
+	}
+
+	for _, lr := range learned {
+		rl := limits[lr.Runner]
+		if rl.RequestsPerMinute <= 0 && lr.LimitedRequestsPerMin > 0 {
+			rl.RequestsPerMinute = lr.LimitedRequestsPerMin * safety
+		}
+		if rl.TokensPerMinute <= 0 && lr.LimitedTokensPerMin > 0 {
+			rl.TokensPerMinute = lr.LimitedTokensPerMin * safety
+		}
+		if rl.RequestsPerMinute <= 0 && rl.TokensPerMinute <= 0 {
+			continue
+		}
+		rl.EstTokensPerTask = lr.AvgTokensPerTask
+		limits[lr.Runner] = rl
+	}
+	return limits
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+func TestBuildRateLimits_ConfiguredWinsOverLearned(t *testing.T) {
+	cfg := &config.Settings{
+		Runners: map[string]*config.RunnerProfile{
+			"claude": {Type: "claude", RequestsPerMinute: 50},
+			"codex":  {Type: "codex"},
+		},
+	}
+	learned := []telemetry.RunnerRate{
+		{Runner: "claude", AvgTokensPerTask: 30_000, LimitedRequestsPerMin: 10, LimitedTokensPerMin: 100_000},
+		{Runner: "codex", AvgTokensPerTask: 20_000},
+		{Runner: "gemini", LimitedRequestsPerMin: 5},
+	}
+
+	limits := buildRateLimits(cfg, learned)
+
+	claude := limits["claude"]
+	if claude.RequestsPerMinute != 50 {
+		t.Errorf("configured rpm should win: got %.1f", claude.RequestsPerMinute)
+	}
+	if claude.TokensPerMinute != 80_000 {
+		t.Errorf("learned tpm should be scaled by safety factor: got %.0f", claude.TokensPerMinute)
+	}
+	if claude.EstTokensPerTask != 30_000 {
+		t.Errorf("estimate: got %d, want 30000", claude.EstTokensPerTask)
+	}
+	if _, ok := limits["codex"]; ok {
+		t.Error("codex has no limits and was never throttled — should not be paced")
+	}
+	if limits["gemini"].RequestsPerMinute != 4 {
+		t.Errorf("gemini learned rpm: got %.1f, want 4", limits["gemini"].RequestsPerMinute)
+	}
+}
+
+func TestBuildRateLimits_CustomSafetyFactor(t *testing.T) {
+	cfg := &config.Settings{Pacing: &config.PacingConfig{SafetyFactor: 0.5}}
+	limits := buildRateLimits(cfg, []telemetry.RunnerRate{{Runner: "claude", LimitedRequestsPerMin: 10}})
+	if limits["claude"].RequestsPerMinute != 5 {
+		t.Errorf("got %.1f rpm, want 5", limits["claude"].RequestsPerMinute)
+	}
+}
+
+func TestLearnRunnerRates_Disabled(t *testing.T) {
+	learn := false
+	cfg := &config.Settings{Pacing: &config.PacingConfig{Learn: &learn}}
+	dbPath := filepath.Join(t.TempDir(), "telemetry.db")
+	if rates := learnRunnerRates(cfg, dbPath); rates != nil {
+		t.Errorf("expected no learned rates, got %+v", rates)
+	}
+}
+
+func TestBuildProviderLimiter_Rates(t *testing.T) {
+	if buildProviderLimiter(nil, nil) != nil {
+		t.Error("expected nil limiter without limits")
+	}
+	pl := buildProviderLimiter(nil, map[string]runner.RateLimit{"claude": {RequestsPerMinute: 5}})
+	if pl == nil {
+		t.Fatal("expected limiter when rates are configured")
+	}
+	if rl, ok := pl.Rate("claude"); !ok || rl.RequestsPerMinute != 5 {
+		t.Errorf("unexpected rate: %+v ok=%v", rl, ok)
+	}
+}
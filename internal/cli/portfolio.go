@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/state"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+// portfolioSorts orders the portfolio so the repos needing attention come
+// first: lowest score, biggest drop, most open tasks, stalest run, most spent.
+var portfolioSorts = map[string]func(a, b *telemetry.RepoHealth) bool{
+	"score": func(a, b *telemetry.RepoHealth) bool { return a.Score < b.Score },
+	"trend": func(a, b *telemetry.RepoHealth) bool {
+		if a.Trend == nil || b.Trend == nil {
+			return a.Trend != nil && b.Trend == nil
+		}
+		return *a.Trend < *b.Trend
+	},
+	"tasks":    func(a, b *telemetry.RepoHealth) bool { return a.OpenTasks > b.OpenTasks },
+	"last-run": func(a, b *telemetry.RepoHealth) bool { return a.LastRun.Before(b.LastRun) },
+	"cost":     func(a, b *telemetry.RepoHealth) bool { return a.CostUSD > b.CostUSD },
+	"repo":     func(a, b *telemetry.RepoHealth) bool { return a.Repo < b.Repo },
+}
+
+func newPortfolioCmd() *cobra.Command {
+	var (
+		sortBy string
+		format string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "portfolio",
+		Short: "Rank repos by health score",
+		Long: `Score every scanned repo 0-100 from its findings at the latest recorded scan,
+weighted by severity and category (scan.score in the config). Each row shows
+the score change since the repo's previous scan, open tasks from the state
+tracker, the last successful run and the cost spent on the repo.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			less, ok := portfolioSorts[sortBy]
+			if !ok {
+				return fmt.Errorf("invalid --sort %q (use score, trend, tasks, last-run, cost, or repo)", sortBy)
+			}
+			if format != "text" && format != "json" && format != "html" {
+				return fmt.Errorf("invalid --format %q (use text, json, or html)", format)
+			}
+
+			cfg, err := config.LoadSettings(configFile)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+
+			db, err := telemetry.OpenDB(telemetry.DefaultPath())
+			if err != nil {
+				return fmt.Errorf("open telemetry: %w", err)
+			}
+			defer func() { _ = db.Close() }()
+
+			repos, err := telemetry.QueryPortfolio(db, cfg.Scan.ScoreWeights())
+			if err != nil {
+				return err
+			}
+			countOpenTasks(repos, state.Load(state.DefaultPath()).Entries())
+			sort.SliceStable(repos, func(i, j int) bool { return less(&repos[i], &repos[j]) })
+
+			w := io.Writer(os.Stdout)
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("create output file: %w", err)
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+
+			switch format {
+			case "json":
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(repos)
+			case "html":
+				return writePortfolioHTML(w, repos, time.Now())
+			}
+			if len(repos) == 0 {
+				fmt.Fprintln(w, "No scan history yet. Run tokencontrol scan first.")
+				return nil
+			}
+			return printPortfolio(w, repos)
+		},
+	}
+
+	cmd.Flags().StringVar(&sortBy, "sort", "score", "sort by: score, trend, tasks, last-run, cost, repo")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text, json, html")
+	cmd.Flags().StringVar(&output, "output", "", "write output to file instead of stdout")
+
+	return cmd
+}
+
+// countOpenTasks counts tracked tasks that haven't completed per repo. Task
+// IDs start with the repo name (<repo>-scan-<check> for scan tasks); the
+// longest matching repo wins so "app-web-x" belongs to "app-web", not "app".
+func countOpenTasks(repos []telemetry.RepoHealth, entries map[string]*state.TaskEntry) {
+	for id, e := range entries {
+		if e.Status == state.StatusCompleted {
+			continue
+		}
+		best := -1
+		for i, r := range repos {
+			if strings.HasPrefix(id, r.Repo+"-") && (best < 0 || len(r.Repo) > len(repos[best].Repo)) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			repos[best].OpenTasks++
+		}
+	}
+}
+
+func printPortfolio(w io.Writer, repos []telemetry.RepoHealth) error {
+	total := 0
+	for _, r := range repos {
+		total += r.Score
+	}
+	fmt.Fprintf(w, "Portfolio: %d repos, average score %d\n\n", len(repos), total/len(repos))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "REPO\tSCORE\tTREND\tFINDINGS\tOPEN TASKS\tLAST RUN\tCOST\tSCANNED\n")
+	for _, r := range repos {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%s\t$%.2f\t%s\n", r.Repo, r.Score, formatTrend(r.Trend),
+			r.Findings, r.OpenTasks, formatDate(r.LastRun), r.CostUSD, formatDate(r.ScannedAt))
+	}
+	return tw.Flush()
+}
+
+// formatTrend renders a score change with its sign, or "-" without one.
+func formatTrend(trend *int) string {
+	if trend == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+d", *trend)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// scoreClass buckets a score for the HTML report's colors.
+func scoreClass(score int) string {
+	switch {
+	case score >= 80:
+		return "good"
+	case score >= 50:
+		return "fair"
+	default:
+		return "poor"
+	}
+}
+
+var portfolioHTML = template.Must(template.New("portfolio").Funcs(template.FuncMap{
+	"trend":      formatTrend,
+	"date":       formatDate,
+	"scoreClass": scoreClass,
+	"unix": func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	},
+	"trendKey": func(trend *int) int {
+		if trend == nil {
+			return 0
+		}
+		return *trend
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>tokencontrol portfolio</title>
+<style>
+body { font: 14px system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 12px; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { cursor: pointer; user-select: none; background: #f4f4f4; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+.good { color: #1a7f37; } .fair { color: #9a6700; } .poor { color: #cf222e; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>Portfolio health</h1>
+<p class="meta">{{len .Repos}} repos, generated {{.Generated.Format "2006-01-02 15:04"}}. Click a column to sort.</p>
+<table id="portfolio">
+<thead><tr>
+<th data-type="text">Repo</th><th>Score</th><th>Trend</th><th>Findings</th><th>Critical</th><th>Warning</th><th>Info</th><th>Open tasks</th><th>Last run</th><th>Cost</th><th>Scanned</th>
+</tr></thead>
+<tbody>
+{{- range .Repos}}
+<tr>
+<td data-key="{{.Repo}}">{{.Repo}}</td>
+<td data-key="{{.Score}}" class="{{scoreClass .Score}}">{{.Score}}</td>
+<td data-key="{{trendKey .Trend}}">{{trend .Trend}}</td>
+<td data-key="{{.Findings}}">{{.Findings}}</td>
+<td data-key="{{index .BySeverity "critical"}}">{{index .BySeverity "critical"}}</td>
+<td data-key="{{index .BySeverity "warning"}}">{{index .BySeverity "warning"}}</td>
+<td data-key="{{index .BySeverity "info"}}">{{index .BySeverity "info"}}</td>
+<td data-key="{{.OpenTasks}}">{{.OpenTasks}}</td>
+<td data-key="{{unix .LastRun}}">{{date .LastRun}}</td>
+<td data-key="{{.CostUSD}}">{{printf "$%.2f" .CostUSD}}</td>
+<td data-key="{{unix .ScannedAt}}">{{date .ScannedAt}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+document.querySelectorAll("#portfolio th").forEach(function (th, col) {
+  th.addEventListener("click", function () {
+    var asc = !th.classList.contains("asc");
+    document.querySelectorAll("#portfolio th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(asc ? "asc" : "desc");
+    var text = th.dataset.type === "text";
+    var body = document.querySelector("#portfolio tbody");
+    Array.from(body.rows).sort(function (a, b) {
+      var x = a.cells[col].dataset.key, y = b.cells[col].dataset.key;
+      var c = text ? x.localeCompare(y) : Number(x) - Number(y);
+      return asc ? c : -c;
+    }).forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// writePortfolioHTML renders a self-contained report with a table sortable
+// by clicking its headers.
+func writePortfolioHTML(w io.Writer, repos []telemetry.RepoHealth, generated time.Time) error {
+	return portfolioHTML.Execute(w, struct {
+		Repos     []telemetry.RepoHealth
+		Generated time.Time
+	}{repos, generated})
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/state"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+func portfolioFixture() []telemetry.RepoHealth {
+	up, down := 5, -12
+	day := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	return []telemetry.RepoHealth{
+		{Repo: "app", Score: 72, Trend: &up, Findings: 6, BySeverity: map[string]int{"warning": 6}, ScannedAt: day, LastRun: day, CostUSD: 1.5},
+		{Repo: "app-web", Score: 40, Trend: &down, Findings: 9, BySeverity: map[string]int{"critical": 3}, ScannedAt: day, CostUSD: 0.2},
+		{Repo: "lib", Score: 100, ScannedAt: day},
+	}
+}
+
+func TestCountOpenTasks(t *testing.T) {
+	repos := portfolioFixture()
+	countOpenTasks(repos, map[string]*state.TaskEntry{
+		"app-scan-missing-ci":       {Status: state.StatusFailed},
+		"app-scan-missing-readme":   {Status: state.StatusCompleted},
+		"app-web-scan-node-no-ci":   {Status: state.StatusInterrupted},
+		"app-web-scan-node-no-lock": {Status: state.StatusInProgress},
+		"unknown-task":              {Status: state.StatusFailed},
+	})
+	got := []int{repos[0].OpenTasks, repos[1].OpenTasks, repos[2].OpenTasks}
+	if got[0] != 1 || got[1] != 2 || got[2] != 0 {
+		t.Errorf("open tasks = %v, want [1 2 0]", got)
+	}
+}
+
+func TestPortfolioSorts(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"score", "app-web,app,lib"},
+		{"trend", "app-web,app,lib"},
+		{"last-run", "app-web,lib,app"},
+		{"cost", "app,app-web,lib"},
+		{"repo", "app,app-web,lib"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			repos := portfolioFixture()
+			less := portfolioSorts[tt.sort]
+			sort.SliceStable(repos, func(i, j int) bool { return less(&repos[i], &repos[j]) })
+			var got []string
+			for _, r := range repos {
+				got = append(got, r.Repo)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("order = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintPortfolio(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printPortfolio(&buf, portfolioFixture()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"3 repos, average score 70",
+		"REPO",
+		"+5",
+		"-12",
+		"$1.50",
+		"2026-10-01",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if line := strings.Split(out, "\n")[5]; !strings.HasPrefix(line, "lib") || !strings.Contains(line, " - ") {
+		t.Errorf("lib row should show no trend or run: %q", line)
+	}
+}
+
+func TestWritePortfolioHTML(t *testing.T) {
+	repos := portfolioFixture()
+	repos[0].Repo = "<app>"
+	var buf bytes.Buffer
+	if err := writePortfolioHTML(&buf, repos, time.Date(2026, 10, 2, 8, 0, 0, 0, time.Local)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"3 repos, generated 2026-10-02 08:00",
+		`<td data-key="&lt;app&gt;">&lt;app&gt;</td>`,
+		`<td data-key="40" class="poor">40</td>`,
+		`<td data-key="-12">-12</td>`,
+		`<td data-key="3">3</td>`,
+		"$0.20",
+		"addEventListener",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+}
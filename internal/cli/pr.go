@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/githost"
 	"github.com/ppiankov/tokencontrol/internal/reporter"
 	"github.com/ppiankov/tokencontrol/internal/task"
 	"github.com/spf13/cobra"
@@ -22,6 +24,45 @@ import (
 type prEnv struct {
 	runGit func(ctx context.Context, dir string, args ...string) (string, error)
 	runGH  func(ctx context.Context, dir string, args ...string) (string, error)
+	hosts  githost.Config // provider selection; RunGH is filled from runGH
+
+	providers map[string]githost.Provider // per repo dir
+}
+
+// provider returns the git hosting provider for a repo, chosen from the
+// origin remote URL and config. Falls back to the gh CLI in auto mode.
+func (e *prEnv) provider(ctx context.Context, repoDir string) (githost.Provider, error) {
+	if p, ok := e.providers[repoDir]; ok {
+		return p, nil
+	}
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	remote, err := e.runGit(timeout, repoDir, "remote", "get-url", "origin")
+	cancel()
+	if err != nil {
+		remote = ""
+	}
+	cfg := e.hosts
+	cfg.RunGH = e.runGH
+	p, err := githost.New(cfg, remote, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	if e.providers == nil {
+		e.providers = make(map[string]githost.Provider)
+	}
+	e.providers[repoDir] = p
+	return p, nil
+}
+
+// prOptions are the user-facing knobs of the pr command.
+type prOptions struct {
+	base      string
+	dryRun    bool
+	draft     bool
+	stack     bool
+	retarget  bool
+	labels    []string
+	reviewers []string
 }
 
 // prResult tracks per-task PR creation outcome.
@@ -29,6 +70,7 @@ type prResult struct {
 	TaskID  string
 	Repo    string
 	Branch  string
+	Base    string // branch the PR targets
 	PRURL   string
 	Skipped string // reason if skipped
 	Error   string // reason if failed
@@ -38,32 +80,82 @@ func newPRCmd() *cobra.Command {
 	var (
 		runDir   string
 		reposDir string
-		base     string
-		dryRun   bool
-		draft    bool
+		provider string
+		opts     prOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "pr",
-		Short: "Create GitHub PRs from completed tasks",
-		Long:  "Push worktree branches and create pull requests for completed tasks in a run.",
+		Short: "Create PRs (GitHub, GitLab, Gitea) from completed tasks",
+		Long: `Push worktree branches and create pull requests for completed tasks in a run.
+
+The hosting provider is chosen per repo from the origin remote URL: GitHub,
+GitLab (merge requests) and Gitea are used through their REST APIs when a
+token is set (GITHUB_TOKEN/GH_TOKEN, GITLAB_TOKEN, GITEA_TOKEN); otherwise
+the gh CLI is used. Self-hosted instances are configured under pr.hosts.
+
+With --stack, a task that depends on another task in the same repo gets a PR
+against its parent's branch instead of --base, and the PR bodies link the
+chain. Once a parent PR is merged, re-running with --stack reports dependents
+that still target the merged branch; add --retarget to move them to --base.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadSettings(configFile)
+			if err != nil {
+				return err
+			}
+			hosts := prHostsConfig(cfg.PR)
+			if cmd.Flags().Changed("provider") {
+				hosts.Mode = provider
+			}
+			if cfg.PR != nil {
+				if !cmd.Flags().Changed("label") {
+					opts.labels = cfg.PR.Labels
+				}
+				if !cmd.Flags().Changed("reviewer") {
+					opts.reviewers = cfg.PR.Reviewers
+				}
+			}
 			env := &prEnv{
 				runGit: shellCmd("git"),
 				runGH:  shellCmd("gh"),
+				hosts:  hosts,
 			}
-			return runPR(cmd.Context(), env, cmd.OutOrStdout(), runDir, reposDir, base, dryRun, draft)
+			return runPR(cmd.Context(), env, cmd.OutOrStdout(), runDir, reposDir, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&runDir, "run-dir", "", "run directory (auto-detects latest if omitted)")
 	cmd.Flags().StringVar(&reposDir, "repos-dir", ".", "base directory containing repos")
-	cmd.Flags().StringVar(&base, "base", "main", "base branch for PRs")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be created without executing")
-	cmd.Flags().BoolVar(&draft, "draft", false, "create draft PRs")
+	cmd.Flags().StringVar(&opts.base, "base", "main", "base branch for PRs")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show what would be created without executing")
+	cmd.Flags().BoolVar(&opts.draft, "draft", false, "create draft PRs")
+	cmd.Flags().BoolVar(&opts.stack, "stack", false, "open dependent tasks' PRs against their parent task's branch")
+	cmd.Flags().BoolVar(&opts.retarget, "retarget", false, "with --stack, re-target PRs whose parent PR was merged onto --base")
+	cmd.Flags().StringSliceVar(&opts.labels, "label", nil, "labels to add to created PRs")
+	cmd.Flags().StringSliceVar(&opts.reviewers, "reviewer", nil, "usernames to request review from")
+	cmd.Flags().StringVar(&provider, "provider", "auto", "hosting access: auto (API if token, else gh CLI), api, cli")
 	return cmd
 }
 
+// prHostsConfig converts pr settings into provider selection config.
+func prHostsConfig(pc *config.PRConfig) githost.Config {
+	var hc githost.Config
+	if pc == nil {
+		return hc
+	}
+	hc.Mode = pc.Provider
+	if len(pc.Hosts) > 0 {
+		hc.Hosts = make(map[string]githost.HostConfig, len(pc.Hosts))
+		for host, h := range pc.Hosts {
+			if h == nil {
+				continue
+			}
+			hc.Hosts[host] = githost.HostConfig{Type: h.Type, APIURL: h.APIURL, TokenEnv: h.TokenEnv}
+		}
+	}
+	return hc
+}
+
 // shellCmd returns a function that runs a CLI tool and captures stdout.
 func shellCmd(bin string) func(ctx context.Context, dir string, args ...string) (string, error) {
 	return func(ctx context.Context, dir string, args ...string) (string, error) {
@@ -77,7 +169,7 @@ func shellCmd(bin string) func(ctx context.Context, dir string, args ...string)
 	}
 }
 
-func runPR(ctx context.Context, env *prEnv, w io.Writer, runDir, reposDir, base string, dryRun, draft bool) error {
+func runPR(ctx context.Context, env *prEnv, w io.Writer, runDir, reposDir string, opts prOptions) error {
 	// Auto-detect latest run dir if not specified.
 	if runDir == "" {
 		dir, err := findLatestRunDir(reposDir)
@@ -101,6 +193,8 @@ func runPR(ctx context.Context, env *prEnv, w io.Writer, runDir, reposDir, base
 
 	// Collect completed tasks with worktree branches.
 	var results []prResult
+	candidates := make(map[string]*prCandidate)
+	var order []string
 	taskIDs := sortedTaskIDs(report.Results)
 
 	for _, taskID := range taskIDs {
@@ -127,45 +221,227 @@ func runPR(ctx context.Context, env *prEnv, w io.Writer, runDir, reposDir, base
 			continue
 		}
 
-		repoDir := config.RepoPath(meta.Repo, reposDir)
-		pr := createPR(ctx, env, w, meta, result, repoDir, base, dryRun, draft)
-		results = append(results, pr)
+		candidates[taskID] = &prCandidate{
+			meta:    meta,
+			result:  result,
+			repoDir: config.RepoPath(meta.Repo, reposDir),
+		}
+		order = append(order, taskID)
+	}
+
+	if opts.stack {
+		for _, c := range candidates {
+			c.parent = stackParent(c, candidates)
+		}
+		order = stackOrder(order, candidates)
+	}
+
+	for _, taskID := range order {
+		c := candidates[taskID]
+		if !opts.dryRun {
+			p, err := env.provider(ctx, c.repoDir)
+			if err != nil {
+				c.pr = prResult{TaskID: taskID, Repo: c.meta.Repo, Branch: c.result.WorktreeBranch, Error: fmt.Sprintf("git host: %v", err)}
+				continue
+			}
+			c.host = p
+		}
+
+		prBase := opts.base
+		parentMerged := false
+		parentRef := ""
+		if c.parent != nil {
+			parentMerged = !opts.dryRun && c.parent.host != nil && prMerged(ctx, c.parent.host, c.parent.result.WorktreeBranch)
+			parentRef = c.parent.ref()
+			if !parentMerged {
+				prBase = c.parent.result.WorktreeBranch
+				fmt.Fprintf(w, "Stacking %s on %s\n", c.result.WorktreeBranch, prBase)
+			}
+		}
+
+		c.body = buildStackedPRBody(c.meta, c.result, parentRef, nil)
+		c.pr, c.created = createPR(ctx, env, c.host, w, c.meta, c.result, c.repoDir, prBase, c.body, opts)
+
+		if parentMerged && c.pr.Skipped != "" {
+			retargetPR(ctx, w, c, opts.base, opts.retarget)
+		}
+	}
+
+	if opts.stack && !opts.dryRun {
+		linkStackChildren(ctx, w, order, candidates)
 	}
 
+	for _, taskID := range order {
+		results = append(results, candidates[taskID].pr)
+	}
 	printPRSummary(w, results)
 	return nil
 }
 
+// prCandidate is a completed worktree task eligible for a PR.
+type prCandidate struct {
+	meta    *task.Task
+	result  *task.TaskResult
+	repoDir string
+	parent  *prCandidate // same-repo dependency the PR stacks on (--stack)
+	host    githost.Provider
+	body    string
+	pr      prResult
+	created *githost.PR
+}
+
+// ref returns the PR URL if one was created, otherwise the branch name.
+func (c *prCandidate) ref() string {
+	if c.pr.PRURL != "" {
+		return c.pr.PRURL
+	}
+	return "`" + c.result.WorktreeBranch + "`"
+}
+
+// stackParent picks the candidate a task's PR stacks on: the branch its
+// worktree was created from, or for older reports the first same-repo
+// dependency that still has a branch.
+func stackParent(c *prCandidate, candidates map[string]*prCandidate) *prCandidate {
+	if c.result.BaseBranch != "" {
+		for _, p := range candidates {
+			if p.result.WorktreeBranch == c.result.BaseBranch {
+				return p
+			}
+		}
+	}
+	for _, dep := range c.meta.DependsOn {
+		if p, ok := candidates[dep]; ok && p.meta.Repo == c.meta.Repo {
+			return p
+		}
+	}
+	return nil
+}
+
+// stackOrder reorders task IDs so every parent precedes its dependents,
+// keeping the original order otherwise.
+func stackOrder(ids []string, candidates map[string]*prCandidate) []string {
+	done := make(map[*prCandidate]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for len(out) < len(ids) {
+		progressed := false
+		for _, id := range ids {
+			c := candidates[id]
+			if done[c] || (c.parent != nil && !done[c.parent]) {
+				continue
+			}
+			done[c] = true
+			out = append(out, id)
+			progressed = true
+		}
+		if !progressed { // cycle guard: emit the rest as-is
+			for _, id := range ids {
+				if !done[candidates[id]] {
+					done[candidates[id]] = true
+					out = append(out, id)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// prMerged reports whether the PR for branch has been merged.
+func prMerged(ctx context.Context, host githost.Provider, branch string) bool {
+	timeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	pr, err := host.FindPR(timeout, branch)
+	return err == nil && pr != nil && pr.State == githost.StateMerged
+}
+
+// retargetPR moves an existing stacked PR onto base after its parent merged.
+// Without --retarget it only prints the suggestion.
+func retargetPR(ctx context.Context, w io.Writer, c *prCandidate, base string, retarget bool) {
+	branch := c.result.WorktreeBranch
+	if !retarget {
+		fmt.Fprintf(w, "Parent of %s is merged; re-run with --retarget to move its PR onto %s\n", branch, base)
+		return
+	}
+	fmt.Fprintf(w, "Re-targeting PR for %s → %s ...", branch, base)
+	timeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	existing, err := c.host.FindPR(timeout, branch)
+	if err == nil && existing == nil {
+		err = fmt.Errorf("no PR found for %s", branch)
+	}
+	if err == nil {
+		err = c.host.UpdatePR(timeout, existing, githost.PRUpdate{Base: base})
+	}
+	if err != nil {
+		fmt.Fprintln(w, " failed")
+		c.pr.Error = fmt.Sprintf("retarget: %v", err)
+		return
+	}
+	fmt.Fprintln(w, " ok")
+	c.pr.Base = base
+	c.pr.Skipped = "PR re-targeted"
+}
+
+// linkStackChildren adds "Followed by" links to parent PRs created in this
+// run, so reviewers can walk the stack in both directions.
+func linkStackChildren(ctx context.Context, w io.Writer, order []string, candidates map[string]*prCandidate) {
+	children := make(map[*prCandidate][]string)
+	for _, id := range order {
+		c := candidates[id]
+		if c.parent != nil && c.pr.PRURL != "" {
+			children[c.parent] = append(children[c.parent], c.pr.PRURL)
+		}
+	}
+	for _, id := range order {
+		p := candidates[id]
+		if p.created == nil || len(children[p]) == 0 {
+			continue
+		}
+		parentRef := ""
+		if p.parent != nil {
+			parentRef = p.parent.ref()
+		}
+		body := buildStackedPRBody(p.meta, p.result, parentRef, children[p])
+		timeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := p.host.UpdatePR(timeout, p.created, githost.PRUpdate{Body: body})
+		cancel()
+		if err != nil {
+			fmt.Fprintf(w, "Linking stack for %s failed: %v\n", p.pr.PRURL, err)
+		}
+	}
+}
+
 func createPR(
 	ctx context.Context,
 	env *prEnv,
+	host githost.Provider,
 	w io.Writer,
 	meta *task.Task,
 	result *task.TaskResult,
-	repoDir, base string,
-	dryRun, draft bool,
-) prResult {
+	repoDir, base, body string,
+	opts prOptions,
+) (prResult, *githost.PR) {
 	pr := prResult{
 		TaskID: meta.ID,
 		Repo:   meta.Repo,
 		Branch: result.WorktreeBranch,
+		Base:   base,
 	}
 
 	prefix := ""
-	if dryRun {
+	if opts.dryRun {
 		prefix = "[dry-run] "
 	}
 
 	// Push branch.
 	fmt.Fprintf(w, "%sPushing %s → origin ...", prefix, result.WorktreeBranch)
-	if !dryRun {
+	if !opts.dryRun {
 		timeout, cancel := context.WithTimeout(ctx, 60*time.Second)
 		_, err := env.runGit(timeout, repoDir, "push", "-u", "origin", result.WorktreeBranch)
 		cancel()
 		if err != nil {
 			fmt.Fprintln(w, " failed")
 			pr.Error = fmt.Sprintf("push: %v", err)
-			return pr
+			return pr, nil
 		}
 	}
 	fmt.Fprintln(w, " ok")
@@ -175,45 +451,50 @@ func createPR(
 	if title == "" {
 		title = meta.ID
 	}
-	body := buildPRBody(meta, result)
 
 	fmt.Fprintf(w, "%sCreating PR: %q → %s ...", prefix, title, meta.Repo)
-	if !dryRun {
-		args := []string{
-			"pr", "create",
-			"--head", result.WorktreeBranch,
-			"--base", base,
-			"--title", title,
-			"--body", body,
-		}
-		if draft {
-			args = append(args, "--draft")
-		}
-
-		timeout, cancel := context.WithTimeout(ctx, 30*time.Second)
-		out, err := env.runGH(timeout, repoDir, args...)
-		cancel()
-		if err != nil {
-			errStr := err.Error()
-			if strings.Contains(errStr, "already exists") {
-				fmt.Fprintln(w, " already exists")
-				pr.Skipped = "PR already exists"
-				return pr
-			}
-			fmt.Fprintln(w, " failed")
-			pr.Error = fmt.Sprintf("gh pr create: %v", err)
-			return pr
-		}
-		pr.PRURL = out
-		fmt.Fprintf(w, " %s\n", out)
-	} else {
+	if opts.dryRun {
 		fmt.Fprintln(w, " skipped (dry-run)")
+		return pr, nil
 	}
 
-	return pr
+	timeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	created, err := host.CreatePR(timeout, githost.PRRequest{
+		Head:      result.WorktreeBranch,
+		Base:      base,
+		Title:     title,
+		Body:      body,
+		Draft:     opts.draft,
+		Labels:    opts.labels,
+		Reviewers: opts.reviewers,
+	})
+	cancel()
+	if errors.Is(err, githost.ErrPRExists) {
+		fmt.Fprintln(w, " already exists")
+		pr.Skipped = "PR already exists"
+		return pr, nil
+	}
+	if created == nil {
+		fmt.Fprintln(w, " failed")
+		pr.Error = fmt.Sprintf("%s pr create: %v", host.Name(), err)
+		return pr, nil
+	}
+	pr.PRURL = created.URL
+	fmt.Fprintf(w, " %s\n", created.URL)
+	if err != nil {
+		// PR exists but labels or reviewers could not be applied
+		fmt.Fprintf(w, "  warning: %v\n", err)
+	}
+	return pr, created
 }
 
 func buildPRBody(meta *task.Task, result *task.TaskResult) string {
+	return buildStackedPRBody(meta, result, "", nil)
+}
+
+// buildStackedPRBody is buildPRBody with links to the parent PR this one
+// stacks on and to the PRs stacked on top of it.
+func buildStackedPRBody(meta *task.Task, result *task.TaskResult, parent string, children []string) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "## %s\n\n", meta.Title)
 	fmt.Fprintf(&b, "- **Runner:** %s\n", result.RunnerUsed)
@@ -221,6 +502,24 @@ func buildPRBody(meta *task.Task, result *task.TaskResult) string {
 	if result.AutoCommitted {
 		b.WriteString("- **Auto-committed:** yes\n")
 	}
+	if c := result.Changes; c != nil {
+		fmt.Fprintf(&b, "- **Changes:** %d files, +%d -%d\n", len(c.Files), c.Added, c.Deleted)
+		if c.NeedsReview() {
+			b.WriteString("\n### Risk\n\n")
+			for _, r := range c.Risks {
+				fmt.Fprintf(&b, "- **%s** (%s): %s\n", r.Rule, r.Action, r.Reason)
+			}
+		}
+	}
+	if parent != "" || len(children) > 0 {
+		b.WriteString("\n### Stack\n\n")
+		if parent != "" {
+			fmt.Fprintf(&b, "- Depends on %s — merge it first\n", parent)
+		}
+		for _, c := range children {
+			fmt.Fprintf(&b, "- Followed by %s\n", c)
+		}
+	}
 	b.WriteString("\n---\n*Created by [tokencontrol](https://github.com/ppiankov/tokencontrol)*\n")
 	return b.String()
 }
@@ -5,12 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ppiankov/tokencontrol/internal/githost"
 	"github.com/ppiankov/tokencontrol/internal/task"
 )
 
@@ -93,7 +96,7 @@ func TestPR_HappyPath(t *testing.T) {
 
 	env := fakePREnv("", "https://github.com/ppiankov/myrepo/pull/42", nil, nil)
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -139,7 +142,7 @@ func TestPR_DryRun(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", true, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main", dryRun: true})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -169,7 +172,7 @@ func TestPR_SkipNoBranch(t *testing.T) {
 	env := fakePREnv("", "", nil, nil)
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -198,7 +201,7 @@ func TestPR_PushFailure(t *testing.T) {
 	env := fakePREnv("", "", fmt.Errorf("push failed: remote rejected"), nil)
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -237,7 +240,7 @@ func TestPR_AlreadyExists(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -279,7 +282,7 @@ func TestPR_DraftFlag(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, true)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main", draft: true})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -318,7 +321,7 @@ func TestPR_SkipNonCompleted(t *testing.T) {
 	env := fakePREnv("", "", nil, nil)
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -361,7 +364,7 @@ func TestPR_MultipleTasks(t *testing.T) {
 	env := fakePREnv("", "https://github.com/ppiankov/repo/pull/1", nil, nil)
 
 	var buf bytes.Buffer
-	err := runPR(context.Background(), env, &buf, runDir, reposDir, "main", false, false)
+	err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -375,6 +378,208 @@ func TestPR_MultipleTasks(t *testing.T) {
 	}
 }
 
+// stackPRFixture returns a parent/child pair in the same repo where the
+// child's worktree was stacked on the parent's branch.
+func stackPRFixture(t *testing.T) (runDir, reposDir string) {
+	t.Helper()
+	results := map[string]*task.TaskResult{
+		"a": {TaskID: "a", State: task.StateCompleted, WorktreeBranch: "tokencontrol/a"},
+		"b": {TaskID: "b", State: task.StateCompleted, WorktreeBranch: "tokencontrol/b", BaseBranch: "tokencontrol/a"},
+	}
+	tasks := map[string]*task.Task{
+		"a": {ID: "a", Title: "Parent", Repo: "org/r"},
+		"b": {ID: "b", Title: "Child", Repo: "org/r", DependsOn: []string{"a"}},
+	}
+	return setupPRTest(t, results, tasks)
+}
+
+// ghRecorder fakes gh: pr create returns a URL per head branch, pr view
+// returns viewState, and every call is recorded.
+type ghRecorder struct {
+	calls     [][]string
+	viewState string
+	createErr error
+}
+
+func (g *ghRecorder) env() *prEnv {
+	return &prEnv{
+		runGit: func(_ context.Context, _ string, _ ...string) (string, error) { return "", nil },
+		runGH: func(_ context.Context, _ string, args ...string) (string, error) {
+			g.calls = append(g.calls, args)
+			switch {
+			case args[0] == "pr" && args[1] == "view":
+				return fmt.Sprintf(`{"number":7,"url":"https://example.com/pull/%s","state":%q}`, args[2], g.viewState), nil
+			case args[0] == "pr" && args[1] == "create":
+				if g.createErr != nil {
+					return "", g.createErr
+				}
+				return "https://example.com/pull/" + argAfter(args, "--head"), nil
+			}
+			return "", nil
+		},
+	}
+}
+
+func (g *ghRecorder) find(sub string) [][]string {
+	var out [][]string
+	for _, c := range g.calls {
+		if c[1] == sub {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func argAfter(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestPR_StackTargetsParentBranch(t *testing.T) {
+	runDir, reposDir := stackPRFixture(t)
+	gh := &ghRecorder{viewState: "OPEN"}
+
+	var buf bytes.Buffer
+	if err := runPR(context.Background(), gh.env(), &buf, runDir, reposDir, prOptions{base: "main", stack: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	creates := gh.find("create")
+	if len(creates) != 2 {
+		t.Fatalf("expected 2 pr create calls, got %v", gh.calls)
+	}
+	if argAfter(creates[0], "--head") != "tokencontrol/a" || argAfter(creates[0], "--base") != "main" {
+		t.Errorf("parent PR args = %v", creates[0])
+	}
+	if argAfter(creates[1], "--base") != "tokencontrol/a" {
+		t.Errorf("child PR should target parent branch, got %v", creates[1])
+	}
+	if body := argAfter(creates[1], "--body"); !strings.Contains(body, "Depends on https://example.com/pull/tokencontrol/a") {
+		t.Errorf("child body missing parent link:\n%s", body)
+	}
+
+	edits := gh.find("edit")
+	if len(edits) != 1 || edits[0][2] != "https://example.com/pull/tokencontrol/a" {
+		t.Fatalf("expected parent body edit, got %v", edits)
+	}
+	if body := argAfter(edits[0], "--body"); !strings.Contains(body, "Followed by https://example.com/pull/tokencontrol/b") {
+		t.Errorf("parent body missing child link:\n%s", body)
+	}
+}
+
+func TestPR_WithoutStackTargetsBase(t *testing.T) {
+	runDir, reposDir := stackPRFixture(t)
+	gh := &ghRecorder{}
+
+	var buf bytes.Buffer
+	if err := runPR(context.Background(), gh.env(), &buf, runDir, reposDir, prOptions{base: "main"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range gh.find("create") {
+		if argAfter(c, "--base") != "main" {
+			t.Errorf("expected base main without --stack, got %v", c)
+		}
+	}
+	if len(gh.find("view")) != 0 || len(gh.find("edit")) != 0 {
+		t.Errorf("unexpected stack calls without --stack: %v", gh.calls)
+	}
+}
+
+func TestPR_StackParentMergedOffersRetarget(t *testing.T) {
+	runDir, reposDir := stackPRFixture(t)
+	gh := &ghRecorder{viewState: "MERGED", createErr: fmt.Errorf("a pull request already exists")}
+
+	var buf bytes.Buffer
+	if err := runPR(context.Background(), gh.env(), &buf, runDir, reposDir, prOptions{base: "main", stack: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "re-run with --retarget") {
+		t.Errorf("expected retarget hint, got:\n%s", buf.String())
+	}
+	if len(gh.find("edit")) != 0 {
+		t.Errorf("should not edit without --retarget: %v", gh.calls)
+	}
+
+	gh.calls = nil
+	buf.Reset()
+	if err := runPR(context.Background(), gh.env(), &buf, runDir, reposDir, prOptions{base: "main", stack: true, retarget: true}); err != nil {
+		t.Fatal(err)
+	}
+	edits := gh.find("edit")
+	if len(edits) != 1 || edits[0][2] != "https://example.com/pull/tokencontrol/b" || argAfter(edits[0], "--base") != "main" {
+		t.Errorf("expected retarget of tokencontrol/b onto main, got %v", edits)
+	}
+}
+
+func TestPR_GitLabAPI(t *testing.T) {
+	results := map[string]*task.TaskResult{
+		"task-1": {TaskID: "task-1", State: task.StateCompleted, WorktreeBranch: "tokencontrol/task-1"},
+	}
+	tasks := map[string]*task.Task{
+		"task-1": {ID: "task-1", Title: "Fix", Repo: "grp/app"},
+	}
+	runDir, reposDir := setupPRTest(t, results, tasks)
+
+	var gotPath, gotTitle string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotTitle, _ = body["title"].(string)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"iid":1,"web_url":"https://gitlab.example/grp/app/-/merge_requests/1","state":"opened"}`))
+	}))
+	defer srv.Close()
+
+	ghCalled := false
+	env := &prEnv{
+		runGit: func(_ context.Context, _ string, args ...string) (string, error) {
+			if args[0] == "remote" {
+				return "git@gitlab.example:grp/app.git", nil
+			}
+			return "", nil
+		},
+		runGH: func(context.Context, string, ...string) (string, error) {
+			ghCalled = true
+			return "", nil
+		},
+		hosts: githost.Config{
+			Hosts:  map[string]githost.HostConfig{"gitlab.example": {Type: githost.TypeGitLab, APIURL: srv.URL}},
+			Getenv: func(string) string { return "tok" },
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := runPR(context.Background(), env, &buf, runDir, reposDir, prOptions{base: "main", draft: true}); err != nil {
+		t.Fatal(err)
+	}
+	if ghCalled {
+		t.Error("gh CLI should not be used when the API is configured")
+	}
+	if gotPath != "/projects/grp%2Fapp/merge_requests" || gotTitle != "Draft: Fix" {
+		t.Errorf("request path=%s title=%q", gotPath, gotTitle)
+	}
+	if !strings.Contains(buf.String(), "merge_requests/1") {
+		t.Errorf("expected MR URL in output:\n%s", buf.String())
+	}
+}
+
+func TestStackOrder(t *testing.T) {
+	a := &prCandidate{}
+	b := &prCandidate{parent: a}
+	c := &prCandidate{parent: b}
+	candidates := map[string]*prCandidate{"a": a, "b": b, "c": c}
+
+	got := stackOrder([]string{"c", "b", "a"}, candidates)
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("order = %v, want parents first", got)
+	}
+}
+
 func TestBuildPRBody(t *testing.T) {
 	meta := &task.Task{ID: "task-1", Title: "Fix authentication"}
 	result := &task.TaskResult{
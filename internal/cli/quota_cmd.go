@@ -8,7 +8,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/ppiankov/tokencontrol/internal/config"
 	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
 )
 
 func newQuotaCmd() *cobra.Command {
@@ -27,13 +29,25 @@ Checks all providers that have API keys set in environment variables:
 Providers without API keys are skipped. Providers without quota
 endpoints (Gemini, Groq) are not checked.
 
+Budgets configured under "budgets:" in .tokencontrol.yml are shown with
+current-window consumption and remaining headroom from local telemetry.
+
 Note: OpenAI and Anthropic usage endpoints require admin API keys.
 Regular API keys will show "admin API key required" but are non-fatal.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			results := runner.CheckAllQuotas(cmd.Context(), os.Getenv)
 
+			cfg, err := config.LoadSettings(configFile)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			budgets, err := queryBudgetStatuses(cfg, telemetry.DefaultPath())
+			if err != nil {
+				return err
+			}
+
 			if jsonOutput {
-				data, err := json.MarshalIndent(results, "", "  ")
+				data, err := quotaJSON(results, budgets)
 				if err != nil {
 					return err
 				}
@@ -51,6 +65,10 @@ Regular API keys will show "admin API key required" but are non-fatal.`,
 				fmt.Println("")
 				fmt.Println("Note: codex/claude CLI OAuth auth cannot be used for quota checks.")
 				fmt.Println("Usage/quota APIs require separate admin API keys from provider dashboards.")
+				if len(budgets) > 0 {
+					fmt.Println("")
+					return printBudgetTable(os.Stdout, budgets)
+				}
 				return nil
 			}
 
@@ -85,7 +103,15 @@ Regular API keys will show "admin API key required" but are non-fatal.`,
 
 				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Provider, status, used, burn, balance)
 			}
-			return w.Flush()
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if len(budgets) > 0 {
+				fmt.Println("")
+				return printBudgetTable(os.Stdout, budgets)
+			}
+			return nil
 		},
 	}
 
@@ -93,3 +119,16 @@ Regular API keys will show "admin API key required" but are non-fatal.`,
 
 	return cmd
 }
+
+// quotaJSON renders quota --json output: the array of provider quotas, as
+// before budgets existed, or an object with quotas and budgets when any
+// budget is configured.
+func quotaJSON(results []*runner.QuotaInfo, budgets []telemetry.BudgetStatus) ([]byte, error) {
+	if len(budgets) == 0 {
+		return json.MarshalIndent(results, "", "  ")
+	}
+	return json.MarshalIndent(struct {
+		Quotas  []*runner.QuotaInfo      `json:"quotas"`
+		Budgets []telemetry.BudgetStatus `json:"budgets"`
+	}{Quotas: results, Budgets: budgets}, "", "  ")
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/runner"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+func TestQuotaJSON(t *testing.T) {
+	results := []*runner.QuotaInfo{{Provider: "deepseek", Available: true, Balance: "4.20"}}
+
+	// without budgets the output stays a top-level array
+	data, err := quotaJSON(results, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quotas []runner.QuotaInfo
+	if err := json.Unmarshal(data, &quotas); err != nil || len(quotas) != 1 || quotas[0].Provider != "deepseek" {
+		t.Errorf("no-budget output = %s (%v), want the quota array", data, err)
+	}
+
+	data, err = quotaJSON(results, []telemetry.BudgetStatus{{Budget: telemetry.Budget{Key: "anthropic", Window: "daily", MaxUSD: 5}, SpentUSD: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Quotas  []runner.QuotaInfo       `json:"quotas"`
+		Budgets []telemetry.BudgetStatus `json:"budgets"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil || len(out.Quotas) != 1 || len(out.Budgets) != 1 || out.Budgets[0].Key != "anthropic" {
+		t.Errorf("budget output = %s (%v), want quotas and budgets", data, err)
+	}
+}
@@ -129,6 +129,13 @@ func rerunTasks(runDir string, workers int, reposDir string, maxRuntime, idleTim
 		return fmt.Errorf("merge task files for rerun: %w", err)
 	}
 
+	// tasks edited or added from the TUI during the original run
+	if prevReport.Overlay != "" {
+		if err := config.ApplyOverlay(tf, prevReport.Overlay); err != nil {
+			return fmt.Errorf("apply task overlay: %w", err)
+		}
+	}
+
 	// filter to rerunnable tasks and strip completed dependencies
 	var tasks []task.Task
 	var missing []string
@@ -203,6 +210,7 @@ func rerunTasks(runDir string, workers int, reposDir string, maxRuntime, idleTim
 	result, err := executeRun(execRunConfig{
 		tasksFiles:   prevReport.TasksFiles,
 		taskFile:     tf,
+		taskFiles:    taskFiles,
 		tasks:        tasks,
 		graph:        graph,
 		workers:      workers,
@@ -215,6 +223,10 @@ func rerunTasks(runDir string, workers int, reposDir string, maxRuntime, idleTim
 		settings:     cfg,
 		tuiMode:      tuiMode,
 		stateTracker: state.Load(state.DefaultPath()),
+		waitForReset: cfg.WaitForReset,
+		maxResetWait: cfg.MaxResetWait,
+		rollback:     resolveRollback(cfg, false),
+		overlay:      prevReport.Overlay,
 	})
 	if err != nil {
 		return err
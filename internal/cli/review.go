@@ -71,9 +71,10 @@ func (p *ReviewPool) Start(ctx context.Context, workers int) {
 	}
 }
 
-// Submit sends a review job. The pool decides whether the task needs review.
+// Submit sends a review job. The pool decides whether the task needs review;
+// tasks flagged by risk rules are always reviewed.
 func (p *ReviewPool) Submit(job reviewJob) {
-	if p.config.FallbackOnly && len(job.result.Attempts) <= 1 {
+	if p.config.FallbackOnly && len(job.result.Attempts) <= 1 && !job.result.Changes.NeedsReview() {
 		return
 	}
 	select {
@@ -47,6 +47,7 @@ func NewRootCmd() *cobra.Command {
 	root.AddCommand(newVersionCmd())
 	root.AddCommand(newGenerateCmd())
 	root.AddCommand(newValidateTasksCmd())
+	root.AddCommand(newSchemaCmd())
 	root.AddCommand(newWatchCmd())
 	root.AddCommand(newUnlockCmd())
 	root.AddCommand(newScanCmd())
@@ -59,9 +60,11 @@ func NewRootCmd() *cobra.Command {
 	root.AddCommand(newDoctorCmd())
 	root.AddCommand(newInitCmd())
 	root.AddCommand(newPRCmd())
+	root.AddCommand(newApplyCmd())
 	root.AddCommand(newStatsCmd())
 	root.AddCommand(newExportCmd())
 	root.AddCommand(newBenchCmd())
+	root.AddCommand(newPortfolioCmd())
 
 	return root
 }
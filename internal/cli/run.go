@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -46,9 +47,13 @@ func newRunCmd() *cobra.Command {
 		retry          bool
 		noAutoCommit   bool
 		parallelRepo   bool
+		patchOnly      bool
 		noMergeResolve bool
+		noRollback     bool
 		noVerify       bool
 		maxRetries     int
+		waitForReset   bool
+		maxResetWait   time.Duration
 
 		strictReadiness bool
 
@@ -62,7 +67,7 @@ func newRunCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run [files...]",
 		Short: "Execute tasks with dependency-aware parallelism",
-		Long:  "Run tasks from one or more JSON files. Files can be passed as positional args,\nvia --tasks flag (glob or comma-separated), or both.\n\nExamples:\n  tokencontrol run /tmp/phase1.json /tmp/phase2.json\n  tokencontrol run /tmp/pack20-phase*.json\n  tokencontrol run --tasks '/tmp/a.json,/tmp/b.json'",
+		Long:  "Run tasks from one or more JSON or YAML files. Files can be passed as positional args,\nvia --tasks flag (glob or comma-separated), or both.\n\nExamples:\n  tokencontrol run /tmp/phase1.json /tmp/phase2.json\n  tokencontrol run /tmp/pack20-phase*.json\n  tokencontrol run --tasks '/tmp/a.json,/tmp/b.json'",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := config.LoadSettings(configFile)
 			if err != nil {
@@ -86,6 +91,12 @@ func newRunCmd() *cobra.Command {
 			if !cmd.Flags().Changed("verify") && cfg.Verify {
 				verify = cfg.Verify
 			}
+			if !cmd.Flags().Changed("wait-for-reset") && cfg.WaitForReset {
+				waitForReset = cfg.WaitForReset
+			}
+			if !cmd.Flags().Changed("max-reset-wait") && cfg.MaxResetWait > 0 {
+				maxResetWait = cfg.MaxResetWait
+			}
 			if cfg.CodexQuota != nil {
 				if !cmd.Flags().Changed("codex-quota-remaining") && cfg.CodexQuota.RemainingTokens > 0 {
 					codexQuotaRemaining = cfg.CodexQuota.RemainingTokens
@@ -121,7 +132,8 @@ func newRunCmd() *cobra.Command {
 					tasksFile = strings.Join(args, ",")
 				}
 			}
-			return runTasks(tasksFile, workers, verify, reposDir, filter, dryRun, maxRuntime, idleTimeout, failFast, tuiMode, allowFree, retry, noAutoCommit, parallelRepo, noMergeResolve, strictReadiness, maxRetries, quotaCfg, cfg)
+			resetCfg := resetWaitConfig{Enabled: waitForReset, MaxWait: maxResetWait}
+			return runTasks(tasksFile, workers, verify, reposDir, filter, dryRun, maxRuntime, idleTimeout, failFast, tuiMode, allowFree, retry, noAutoCommit, parallelRepo, patchOnly, noMergeResolve, noRollback, strictReadiness, maxRetries, quotaCfg, resetCfg, cfg)
 		},
 	}
 
@@ -140,8 +152,12 @@ func newRunCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&retry, "retry", false, "re-execute failed and interrupted tasks")
 	cmd.Flags().BoolVar(&noAutoCommit, "no-auto-commit", false, "disable auto-commit of uncommitted changes after task completion")
 	cmd.Flags().BoolVar(&parallelRepo, "parallel-repo", false, "use git worktrees for parallel same-repo task execution")
-	cmd.Flags().BoolVar(&noMergeResolve, "no-merge-resolve", false, "disable auto-generated merge resolution task for parallel conflicts")
+	cmd.Flags().BoolVar(&patchOnly, "patch-only", false, "run each task in a throwaway worktree and export its diff to changes.patch instead of committing")
+	cmd.Flags().BoolVar(&noMergeResolve, "no-merge-resolve", false, "disable post-run conflict resolution for parallel-repo branches")
+	cmd.Flags().BoolVar(&noRollback, "no-rollback", false, "keep a failed attempt's changes in the repo instead of restoring the pre-attempt state")
 	cmd.Flags().IntVar(&maxRetries, "max-retries", 2, "max retries per runner on transient failures (connectivity, idle timeout); 0 disables")
+	cmd.Flags().BoolVar(&waitForReset, "wait-for-reset", false, "on rate limit, pause and resume dispatch at the reset time instead of ending the run")
+	cmd.Flags().DurationVar(&maxResetWait, "max-reset-wait", 0, "max time to wait per rate-limit pause; also used when the reset time is unknown (0 = until reset)")
 	cmd.Flags().BoolVar(&strictReadiness, "strict-readiness", false, "fail if agent readiness checks produce warnings")
 	cmd.Flags().IntVar(&codexQuotaRemaining, "codex-quota-remaining", 0, "remaining codex budget in tokens; 0 disables quota preflight")
 	cmd.Flags().IntVar(&codexQuotaReserve, "codex-quota-reserve", 0, "tokens to hold back from codex remaining budget")
@@ -152,7 +168,7 @@ func newRunCmd() *cobra.Command {
 	return cmd
 }
 
-func runTasks(tasksFile string, workers int, verify bool, reposDir, filter string, dryRun bool, maxRuntime, idleTimeout time.Duration, failFast bool, tuiMode string, allowFree, retry, noAutoCommit, parallelRepo, noMergeResolve, strictReadiness bool, maxRetries int, quotaCfg quotaPreflightConfig, cfg *config.Settings) error {
+func runTasks(tasksFile string, workers int, verify bool, reposDir, filter string, dryRun bool, maxRuntime, idleTimeout time.Duration, failFast bool, tuiMode string, allowFree, retry, noAutoCommit, parallelRepo, patchOnly, noMergeResolve, noRollback, strictReadiness bool, maxRetries int, quotaCfg quotaPreflightConfig, resetCfg resetWaitConfig, cfg *config.Settings) error {
 	// resolve glob pattern to concrete file paths
 	paths, err := config.ResolveGlob(tasksFile)
 	if err != nil {
@@ -372,6 +388,7 @@ func runTasks(tasksFile string, workers int, verify bool, reposDir, filter strin
 	report, err := executeRun(execRunConfig{
 		tasksFiles:     paths,
 		taskFile:       tf,
+		taskFiles:      taskFiles,
 		tasks:          tasks,
 		graph:          graph,
 		workers:        workers,
@@ -390,8 +407,13 @@ func runTasks(tasksFile string, workers int, verify bool, reposDir, filter strin
 		noAutoCommit:   noAutoCommit,
 		parallelRepo:   resolveParallelRepo(parallelRepo, cfg, tf, tasks),
 		mergeBack:      resolveMergeBack(tf, cfg),
+		patchOnly:      patchOnly,
 		noMergeResolve: noMergeResolve,
+		verify:         verify,
+		rollback:       resolveRollback(cfg, noRollback),
 		initialQuotas:  initialQuotas,
+		waitForReset:   resetCfg.Enabled,
+		maxResetWait:   resetCfg.MaxWait,
 	})
 	if err != nil {
 		return err
@@ -419,7 +441,8 @@ func runTasks(tasksFile string, workers int, verify bool, reposDir, filter strin
 // execRunConfig holds parameters for executeRun.
 type execRunConfig struct {
 	tasksFiles     []string
-	taskFile       *task.TaskFile // full parsed/merged file with profiles
+	taskFile       *task.TaskFile   // full parsed/merged file with profiles
+	taskFiles      []*task.TaskFile // files as loaded, before merging; nil=taskFile
 	tasks          []task.Task
 	graph          *task.Graph
 	workers        int
@@ -438,10 +461,22 @@ type execRunConfig struct {
 	noAutoCommit   bool                                      // disable auto-commit of uncommitted changes
 	parallelRepo   bool                                      // use git worktrees for parallel same-repo execution
 	mergeBack      bool                                      // auto-merge worktree branches back to main
+	patchOnly      bool                                      // export diffs from throwaway worktrees, never touch the repo
 	noMergeResolve bool                                      // disable auto-generated merge resolution task
+	verify         bool                                      // run make test/lint before merging resolved branches
+	rollback       *attemptRollback                          // restore the repo after failed attempts; nil=off
 	stateTracker   *state.Tracker                            // persistent task state across runs
 	onProgress     func(results map[string]*task.TaskResult) // optional progress callback for sentinel
 	initialQuotas  []*runner.QuotaInfo                       // pre-flight quota results to seed TUI cache
+	waitForReset   bool                                      // pause and resume on rate limit instead of ending the run
+	maxResetWait   time.Duration                             // cap per rate-limit pause
+	overlay        string                                    // task overlay inherited from the run being rerun
+}
+
+// resetWaitConfig holds --wait-for-reset options.
+type resetWaitConfig struct {
+	Enabled bool
+	MaxWait time.Duration
 }
 
 // execRunResult wraps the report and run directory.
@@ -546,10 +581,7 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 		}
 		if len(zeroProviders) > 0 {
 			for name, profile := range tf.Runners {
-				prov := runner.RunnerToProvider(profile.Type)
-				if prov == "" {
-					prov = providerFromModel(profile.Model)
-				}
+				prov := runner.ProviderForRunner(name, tf.Runners)
 				if zeroProviders[prov] {
 					graylist.Add(name, profile.Model, "zero balance on "+prov)
 				}
@@ -557,6 +589,19 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 		}
 	}
 
+	// proactive budgets: block runners whose provider is already over budget
+	runnerNames := make([]string, 0, len(runners))
+	for name := range runners {
+		runnerNames = append(runnerNames, name)
+	}
+	sort.Strings(runnerNames)
+	budgetLedger := loadBudgetLedger(cfg.settings, telemetry.DefaultPath())
+	var budget *budgetCharger
+	if budgetLedger != nil {
+		applyBudgetBlocks(budgetLedger.Exhausted(), runnerNames, tf.Runners, blacklist)
+		budget = &budgetCharger{ledger: budgetLedger, runnerNames: runnerNames, profiles: tf.Runners, blacklist: blacklist}
+	}
+
 	// build per-provider concurrency limiter from settings
 	var concurrencyLimits map[string]int
 	if cfg.settings != nil {
@@ -567,7 +612,12 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 			}
 		}
 	}
-	limiter := buildProviderLimiter(concurrencyLimits)
+	rateLimits := buildRateLimits(cfg.settings, learnRunnerRates(cfg.settings, telemetry.DefaultPath()))
+	for name, rl := range rateLimits {
+		slog.Info("pacing runner dispatch", "runner", name,
+			"rpm", fmt.Sprintf("%.1f", rl.RequestsPerMinute), "tpm", fmt.Sprintf("%.0f", rl.TokensPerMinute))
+	}
+	limiter := buildProviderLimiter(concurrencyLimits, rateLimits)
 
 	// setup review pool if configured
 	var reviewPool *ReviewPool
@@ -593,16 +643,40 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 	if cfg.settings != nil {
 		promptConventions = cfg.settings.PromptConventions
 	}
-	injectPromptConventions(cfg.tasks, promptConventions, runners)
+	prepareTasks := func(tasks []task.Task) {
+		injectPromptConventions(tasks, promptConventions, runners)
 
-	// inject commit instructions into all agent-bound prompts (safety net)
-	injectCommitInstructions(cfg.tasks, runners)
+		// inject commit instructions into all agent-bound prompts (safety net)
+		injectCommitInstructions(tasks, runners)
 
-	// tell agents to write generated docs to the gitignored docs dir
-	injectDocDirective(cfg.tasks, runners, cfg.settings.EffectiveDocsDir())
+		// tell agents to write generated docs to the gitignored docs dir
+		injectDocDirective(tasks, runners, cfg.settings.EffectiveDocsDir())
+
+		// merge per-repo path rules and tell agents which paths are off limits;
+		// the rules are enforced on each attempt's diff in RunWithCascade
+		applyRepoPathRules(tasks, cfg.settings)
+		injectPathConstraints(tasks, runners)
+
+		// explain how to propose follow-up tasks when they are collected
+		if cfg.settings != nil && cfg.settings.Followups != nil {
+			injectFollowupInstructions(tasks, runners)
+		}
+	}
+	// keep the prompts as loaded for the TUI task editor
+	basePrompts := make(map[string]string, len(cfg.tasks))
+	for _, t := range cfg.tasks {
+		basePrompts[t.ID] = t.Prompt
+	}
+	prepareTasks(cfg.tasks)
 
 	// Forward-declare scheduler so execFn closure can call SetRunnerUsed.
 	var sched *task.Scheduler
+	var followups *followupSpawner
+
+	// branches kept by completed worktree tasks (merge_back off or conflict);
+	// same-repo dependents branch from them so their work stacks on the parent
+	var stackMu sync.Mutex
+	stackBranches := make(map[string]string)
 
 	execFn := func(ctx context.Context, t *task.Task, repoDir, outputDir string) *task.TaskResult {
 		// show intended runner immediately so TUI displays it during lock wait
@@ -614,8 +688,28 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 		// acquire execution directory: worktree (parallel) or lock (serial)
 		var execDir string
 		var wtBranch string
-		if cfg.parallelRepo {
+		var baseBranch string
+		var patchBase string
+		if cfg.patchOnly {
+			// never fall back to the repo itself: nothing may be committed there
 			wtDir, branch, wtErr := runner.CreateWorktree(ctx, repoDir, cfg.reposDir, t.ID)
+			if wtErr != nil {
+				return &task.TaskResult{
+					TaskID:  t.ID,
+					State:   task.StateFailed,
+					Error:   fmt.Sprintf("create patch worktree: %v", wtErr),
+					EndedAt: time.Now(),
+				}
+			}
+			defer runner.DeleteBranch(ctx, repoDir, branch)
+			defer runner.RemoveWorktree(ctx, repoDir, wtDir)
+			execDir = wtDir
+			patchBase = gitHead(wtDir)
+		} else if cfg.parallelRepo {
+			stackMu.Lock()
+			baseBranch = stackParentBranch(t, cfg.graph, stackBranches)
+			stackMu.Unlock()
+			wtDir, branch, wtErr := runner.CreateWorktreeFrom(ctx, repoDir, cfg.reposDir, t.ID, baseBranch)
 			if wtErr != nil {
 				slog.Warn("worktree creation failed, falling back to lock",
 					"task", t.ID, "error", wtErr)
@@ -673,8 +767,9 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 				EndedAt: time.Now(),
 			}
 		}
-		result := RunWithCascade(ctx, t, execDir, outputDir, runners, cascade, cfg.maxRuntime, cfg.maxRetries, blacklist, graylist, limiter,
-			func(runnerName string) { sched.SetRunnerUsed(t.ID, runnerName) },
+		headBefore := gitHead(execDir)
+		result := RunWithCascade(ctx, t, execDir, outputDir, runners, cascade, cfg.maxRuntime, cfg.maxRetries, blacklist, graylist, limiter, budget,
+			func(runnerName string) { sched.SetRunnerUsed(t.ID, runnerName) }, cfg.rollback,
 		)
 
 		// sanitize agent commit messages — strip attribution and watermark trailers
@@ -701,7 +796,7 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 
 				// dispatch remediation to strong runners (tier 1)
 				remResult := runRemediation(ctx, t, execDir, outputDir, buildErr,
-					runners, tf, blacklist, graylist, limiter, cfg.maxRuntime, cfg.maxRetries)
+					runners, tf, blacklist, graylist, limiter, budget, cfg.maxRuntime, cfg.maxRetries, cfg.rollback)
 				if remResult != nil && remResult.State == task.StateCompleted {
 					result.Remediated = true
 					result.RemediatedBy = remResult.RunnerUsed
@@ -722,8 +817,28 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 			}
 		}
 
+		// export the diff; the worktree and its branch are discarded on return
+		if cfg.patchOnly && result.State == task.StateCompleted {
+			patch, err := exportTaskPatch(ctx, execDir, patchBase, outputDir)
+			if err != nil {
+				result.State = task.StateFailed
+				result.Error = fmt.Sprintf("export patch: %v", err)
+			} else {
+				result.Patch = patch
+			}
+		}
+
+		// record what changed and apply risk rules
+		if result.State == task.StateCompleted {
+			result.Changes = analyzeTaskChanges(ctx, execDir, headBefore, result.Patch, cfg.settings)
+			if result.Changes.NeedsReview() {
+				slog.Warn("task changes flagged by risk rules",
+					"task", t.ID, "blocked", result.Changes.Blocked(), "rules", strings.Join(result.Changes.RuleNames(), ","))
+			}
+		}
+
 		// merge worktree branch back to main repo
-		if wtBranch != "" && result.State == task.StateCompleted && cfg.mergeBack {
+		if wtBranch != "" && result.State == task.StateCompleted && cfg.mergeBack && !result.Changes.Blocked() {
 			if err := runner.WaitAndAcquire(ctx, repoDir, t.ID+"-merge"); err == nil {
 				if err := runner.MergeBack(ctx, repoDir, wtBranch); err != nil {
 					result.MergeConflict = true
@@ -738,6 +853,17 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 		}
 		if wtBranch != "" {
 			result.WorktreeBranch = wtBranch
+			result.BaseBranch = baseBranch
+			if result.State == task.StateCompleted {
+				stackMu.Lock()
+				stackBranches[t.ID] = wtBranch
+				stackMu.Unlock()
+			}
+		}
+
+		// agents may propose follow-up tasks that depend on this one
+		if followups != nil && result.State == task.StateCompleted {
+			followups.spawn(t, result)
 		}
 
 		// update persistent state with final result
@@ -761,9 +887,22 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 		RunDir:   runDir,
 		ExecFn:   execFn,
 		FailFast: cfg.failFast,
+
+		WaitForReset: cfg.waitForReset,
+		MaxResetWait: cfg.maxResetWait,
+		OnPause: func(p task.RunPause) {
+			if p.EndedAt.IsZero() {
+				slog.Warn("rate limited, pausing dispatch until reset",
+					"task", p.TaskID, "resume_at", p.ResumeAt.Format(time.RFC3339))
+			} else {
+				slog.Warn("rate-limit pause ended", "resumed", p.Resumed,
+					"waited", p.EndedAt.Sub(p.StartedAt).Truncate(time.Second))
+			}
+		},
 		OnUpdate: func(id string, result *task.TaskResult) {
 			slog.Debug("task update", "task", id, "state", result.State)
-			writeStatusFile(len(cfg.tasks), sched.Results())
+			current := sched.Results()
+			writeStatusFile(len(current), current)
 			if cfg.onProgress != nil {
 				cfg.onProgress(sched.Results())
 			}
@@ -781,6 +920,29 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 		},
 	})
 
+	editor := &taskEditor{
+		sched:            sched,
+		graph:            cfg.graph,
+		runners:          runnerNames,
+		runDir:           runDir,
+		defaultRunner:    defaultRunner,
+		defaultFallbacks: tf.DefaultFallbacks,
+		files:            cfg.taskFiles,
+		basePrompts:      basePrompts,
+		prepare:          prepareTasks,
+	}
+	if len(editor.files) == 0 {
+		editor.files = []*task.TaskFile{tf}
+	}
+	if cfg.overlay != "" {
+		if err := editor.seedOverlay(cfg.overlay); err != nil {
+			slog.Warn("could not carry over task overlay", "path", cfg.overlay, "error", err)
+		}
+	}
+	if cfg.settings != nil {
+		followups = newFollowupSpawner(ctx, cfg.settings.Followups, sched, editor)
+	}
+
 	// resolve display mode: full TUI, minimal live reporter, or off
 	displayMode := cfg.tuiMode
 	if displayMode == "" || displayMode == "auto" {
@@ -844,20 +1006,23 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 			IsBlacklisted: blacklist.IsBlocked,
 			Profiles:      profileInfo,
 		}
-		// Collect runner names for interactive picker.
-		var runnerNames []string
-		for name := range runners {
-			runnerNames = append(runnerNames, name)
-		}
-		sort.Strings(runnerNames)
-
 		taskCtrl := &reporter.TaskControl{
 			CancelTask:  sched.CancelTask,
 			RequeueTask: sched.RequeueTask,
 			Runners:     runnerNames,
+			ActivePause: sched.ActivePause,
+			DraftTask:   editor.DraftTask,
+			ApplyDraft:  editor.ApplyDraft,
+			Editor:      editorCommand(),
 		}
 		tuiModel := reporter.NewTUIModel(cfg.graph, sched.Results, cancel, logPath, start, agentPool, taskCtrl, runDir)
 		tuiProgram = tea.NewProgram(tuiModel, tea.WithAltScreen())
+		if followups != nil {
+			prog := tuiProgram
+			followups.ask = func(parentID string, t task.Task, decide func(bool)) {
+				go prog.Send(reporter.FollowupProposalMsg{ParentID: parentID, TaskID: t.ID, Title: t.Title, Decide: decide})
+			}
+		}
 		go func() {
 			if _, err := tuiProgram.Run(); err != nil {
 				slog.Warn("TUI error", "error", err)
@@ -872,6 +1037,13 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 
 	results := sched.Run(ctx)
 	totalDuration := time.Since(start)
+	// tasks edited or added from the TUI exist only in the graph
+	overlayPath := editor.overlayPath()
+	if overlayPath != "" {
+		cfg.tasks = graphTasks(cfg.graph)
+	} else {
+		overlayPath = cfg.overlay
+	}
 	removeStatusFile()
 
 	if tuiProgram != nil {
@@ -899,16 +1071,22 @@ func executeRun(cfg execRunConfig) (*execRunResult, error) {
 
 	// auto-resolve merge conflicts from parallel repo execution
 	if cfg.parallelRepo && cfg.mergeBack && !cfg.noMergeResolve {
-		conflicts := collectConflicts(results, cfg.tasks)
+		conflicts := collectConflicts(results, cfg.tasks, cfg.graph)
 		if len(conflicts) > 0 {
-			resolveResult := runMergeResolve(ctx, conflicts, cfg, runners, defaultRunner, tf, blacklist, graylist, limiter, runDir)
+			resolveResult := runMergeResolve(ctx, conflicts, results, cfg, runners, defaultRunner, tf, blacklist, graylist, limiter, runDir)
 			if resolveResult != nil {
 				results[resolveResult.TaskID] = resolveResult
 			}
 		}
 	}
 
+	if followups != nil {
+		followups.attach(results)
+	}
+
 	report := buildReport(cfg.tasksFiles, cfg.workers, cfg.filter, cfg.reposDir, results, totalDuration, cfg.parentRunID)
+	report.Pauses = sched.Pauses()
+	report.Overlay = overlayPath
 	textRep.PrintStatus(cfg.graph, results)
 	textRep.PrintSummary(report)
 
@@ -1002,6 +1180,12 @@ func buildReport(tasksFiles []string, workers int, filter, reposDir string, resu
 			if r.MergeConflict {
 				report.MergeConflicts++
 			}
+			if r.Changes.NeedsReview() {
+				report.RiskReviews++
+			}
+			if r.Changes.Blocked() {
+				report.RiskBlocked++
+			}
 			if r.Remediated {
 				report.Remediations++
 			}
@@ -1153,45 +1337,6 @@ func resolveMergeBack(tf *task.TaskFile, cfg *config.Settings) bool {
 	return true // default: auto-merge
 }
 
-// conflictInfo holds metadata about a merge-conflicted task for resolution.
-type conflictInfo struct {
-	taskID string
-	branch string
-	title  string
-	prompt string
-	repo   string
-}
-
-// collectConflicts finds tasks with unmerged worktree branches.
-func collectConflicts(results map[string]*task.TaskResult, tasks []task.Task) []conflictInfo {
-	// index tasks by ID for prompt/title lookup
-	taskMap := make(map[string]*task.Task, len(tasks))
-	for i := range tasks {
-		taskMap[tasks[i].ID] = &tasks[i]
-	}
-
-	var conflicts []conflictInfo
-	for _, r := range results {
-		if !r.MergeConflict || r.WorktreeBranch == "" {
-			continue
-		}
-		ci := conflictInfo{
-			taskID: r.TaskID,
-			branch: r.WorktreeBranch,
-		}
-		if t, ok := taskMap[r.TaskID]; ok {
-			ci.title = t.Title
-			ci.repo = t.Repo
-			ci.prompt = t.Prompt
-			if len(ci.prompt) > 200 {
-				ci.prompt = ci.prompt[:200] + "..."
-			}
-		}
-		conflicts = append(conflicts, ci)
-	}
-	return conflicts
-}
-
 // runRemediation dispatches a build-fix task to strong runners (tier 1) when a
 // weaker runner produces code that doesn't compile. The strong runner sees the
 // repo in its current state (with the weak runner's commits) and gets a focused
@@ -1206,8 +1351,10 @@ func runRemediation(
 	blacklist *runner.RunnerBlacklist,
 	graylist *runner.RunnerGraylist,
 	limiter *runner.ProviderLimiter,
+	budget *budgetCharger,
 	maxRuntime time.Duration,
 	maxRetries int,
+	rollback *attemptRollback,
 ) *task.TaskResult {
 	prompt := fmt.Sprintf(`The previous agent completed task "%s" but the build is broken.
 
@@ -1272,183 +1419,7 @@ Commit your fix with: git add <files> && git commit -m "fix: resolve build error
 	fmt.Fprintf(os.Stderr, "  → build broken, dispatching remediation to %v\n", strongRunners)
 
 	return RunWithCascade(ctx, remTask, repoDir, remOutputDir, runners, strongRunners,
-		maxRuntime, maxRetries, blacklist, graylist, limiter, nil)
-}
-
-// runMergeResolve dispatches a synthetic merge resolution task for conflicted branches.
-func runMergeResolve(
-	ctx context.Context,
-	conflicts []conflictInfo,
-	cfg execRunConfig,
-	runners map[string]runner.Runner,
-	defaultRunner string,
-	tf *task.TaskFile,
-	blacklist *runner.RunnerBlacklist,
-	graylist *runner.RunnerGraylist,
-	limiter *runner.ProviderLimiter,
-	runDir string,
-) *task.TaskResult {
-	if len(conflicts) == 0 {
-		return nil
-	}
-
-	// all conflicts must be on the same repo (parallel_repo is per-repo)
-	repoDir := config.RepoPath(cfg.reposDir, conflicts[0].repo)
-
-	// build the resolution prompt
-	prompt := buildMergeResolvePrompt(ctx, repoDir, conflicts)
-
-	resolveID := "merge-resolve"
-	resolveTask := &task.Task{
-		ID:         resolveID,
-		Repo:       conflicts[0].repo,
-		Title:      fmt.Sprintf("Merge resolve: %d branches", len(conflicts)),
-		Prompt:     prompt,
-		Difficulty: "medium",
-	}
-
-	// resolve cascade for the synthetic task
-	cascade := resolveRunnerCascade(resolveTask, defaultRunner, tf.DefaultFallbacks)
-	if len(cascade) == 0 {
-		slog.Warn("merge-resolve: no runners available")
-		return nil
-	}
-
-	outputDir := filepath.Join(runDir, resolveID)
-	_ = os.MkdirAll(outputDir, 0o755)
-
-	fmt.Fprintf(os.Stdout, "\nMerge resolution: dispatching agent to resolve %d conflicted branches...\n", len(conflicts))
-
-	// acquire repo lock for merge resolution (runs on main repo, not worktree)
-	if err := runner.WaitAndAcquire(ctx, repoDir, resolveID); err != nil {
-		slog.Warn("merge-resolve: failed to acquire repo lock", "error", err)
-		return nil
-	}
-	defer runner.Release(repoDir)
-
-	// live tail — stream runner stderr to stdout so the user sees agent progress
-	resolveStart := time.Now()
-	tickDone := make(chan struct{})
-	stderrPath := filepath.Join(outputDir, "stderr.log")
-	go func() {
-		ticker := time.NewTicker(2 * time.Second)
-		defer ticker.Stop()
-		var offset int64
-		for {
-			select {
-			case <-tickDone:
-				return
-			case <-ticker.C:
-				offset = tailMergeLog(stderrPath, offset, resolveStart)
-			}
-		}
-	}()
-
-	result := RunWithCascade(ctx, resolveTask, repoDir, outputDir, runners, cascade,
-		cfg.maxRuntime, cfg.maxRetries, blacklist, graylist, limiter,
-		func(runnerName string) {
-			fmt.Fprintf(os.Stdout, "  merge-resolve: using runner %q\n", runnerName)
-		})
-	close(tickDone)
-	// flush remaining output
-	tailMergeLog(stderrPath, 0, resolveStart)
-
-	if result.State == task.StateCompleted {
-		runner.SanitizeHeadCommit(ctx, repoDir)
-		// verify which branches were merged and clean them up
-		merged := 0
-		for _, c := range conflicts {
-			if isBranchMerged(ctx, repoDir, c.branch) {
-				runner.DeleteBranch(ctx, repoDir, c.branch)
-				merged++
-			}
-		}
-		fmt.Fprintf(os.Stdout, "Merge resolution: %d/%d branches merged successfully\n", merged, len(conflicts))
-	} else {
-		slog.Warn("merge-resolve task failed, branches left for manual resolution",
-			"state", result.State, "error", result.Error)
-	}
-
-	return result
-}
-
-// buildMergeResolvePrompt constructs agent instructions for resolving merge conflicts.
-func buildMergeResolvePrompt(ctx context.Context, repoDir string, conflicts []conflictInfo) string {
-	var b strings.Builder
-	fmt.Fprintf(&b, "You have %d unmerged branches from parallel task execution that need to be merged into the current branch.\n\n", len(conflicts))
-	b.WriteString("For each branch listed below, merge it sequentially using: git merge <branch>\n")
-	b.WriteString("If there are conflicts, resolve them by understanding what each task intended.\n")
-	b.WriteString("Do NOT delete any branch — just merge them.\n\n")
-
-	for _, c := range conflicts {
-		fmt.Fprintf(&b, "Branch: %s\n", c.branch)
-		if c.title != "" {
-			fmt.Fprintf(&b, "  Task: %q\n", c.title)
-		}
-		files := runner.ListConflictFiles(ctx, repoDir, c.branch)
-		if len(files) > 0 {
-			fmt.Fprintf(&b, "  Changed files: %s\n", strings.Join(files, ", "))
-		}
-		b.WriteString("\n")
-	}
-
-	b.WriteString("After merging all branches, run: go build ./... && go test ./... -race -count=1\n")
-	b.WriteString("If a test fails after merging, fix the issue before proceeding to the next branch.\n")
-	b.WriteString("If any merge cannot be resolved, stop and report which branch failed and why.\n")
-
-	return b.String()
-}
-
-// isBranchMerged checks if a branch has been merged into HEAD.
-func isBranchMerged(ctx context.Context, repoDir, branch string) bool {
-	cmd := exec.CommandContext(ctx, "git", "branch", "--merged", "HEAD")
-	cmd.Dir = repoDir
-	out, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	for _, line := range strings.Split(string(out), "\n") {
-		if strings.TrimSpace(line) == branch {
-			return true
-		}
-	}
-	return false
-}
-
-// tailMergeLog reads new lines from the merge-resolve stderr log and prints
-// them to stdout with a prefix. Returns the new file offset.
-func tailMergeLog(path string, offset int64, start time.Time) int64 {
-	f, err := os.Open(path)
-	if err != nil {
-		return offset
-	}
-	defer func() { _ = f.Close() }()
-
-	info, err := f.Stat()
-	if err != nil || info.Size() <= offset {
-		return offset
-	}
-
-	if _, err := f.Seek(offset, 0); err != nil {
-		return offset
-	}
-
-	buf := make([]byte, info.Size()-offset)
-	n, err := f.Read(buf)
-	if err != nil || n == 0 {
-		return offset
-	}
-
-	lines := strings.Split(strings.TrimRight(string(buf[:n]), "\n"), "\n")
-	elapsed := time.Since(start).Truncate(time.Second)
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		fmt.Fprintf(os.Stdout, "  [%s] %s\n", elapsed, line)
-	}
-	return offset + int64(n)
+		maxRuntime, maxRetries, blacklist, graylist, limiter, budget, nil, rollback)
 }
 
 // stripeRunners distributes primary runner assignments across available
@@ -1803,6 +1774,58 @@ func injectDocDirective(tasks []task.Task, runners map[string]runner.Runner, doc
 	}
 }
 
+// applyRepoPathRules merges the per-repo path rules from settings into each
+// task: deny patterns are combined, allow patterns and the policy apply only
+// when the task sets none of its own.
+func applyRepoPathRules(tasks []task.Task, settings *config.Settings) {
+	if settings == nil || len(settings.Repos) == 0 {
+		return
+	}
+	for i := range tasks {
+		rc := settings.Repos[tasks[i].Repo]
+		if rc == nil {
+			continue
+		}
+		for _, p := range rc.DenyPaths {
+			if !slices.Contains(tasks[i].DenyPaths, p) {
+				tasks[i].DenyPaths = append(tasks[i].DenyPaths, p)
+			}
+		}
+		if len(tasks[i].AllowPaths) == 0 {
+			tasks[i].AllowPaths = rc.AllowPaths
+		}
+		if tasks[i].PathPolicy == "" {
+			tasks[i].PathPolicy = rc.PathPolicy
+		}
+	}
+}
+
+// injectPathConstraints tells agents which paths they may and may not change
+// so path rules are respected up front rather than only enforced afterwards.
+// Script runner tasks are skipped since their prompts are shell commands.
+func injectPathConstraints(tasks []task.Task, runners map[string]runner.Runner) {
+	for i := range tasks {
+		if len(tasks[i].AllowPaths) == 0 && len(tasks[i].DenyPaths) == 0 {
+			continue
+		}
+		if r, ok := runners[tasks[i].Runner]; ok {
+			if _, isScript := r.(*runner.ScriptRunner); isScript {
+				continue
+			}
+		}
+		var b strings.Builder
+		b.WriteString("\n\nIMPORTANT: This task is restricted to specific paths. ")
+		if len(tasks[i].AllowPaths) > 0 {
+			fmt.Fprintf(&b, "Only change files matching: %s. ", strings.Join(tasks[i].AllowPaths, ", "))
+		}
+		if len(tasks[i].DenyPaths) > 0 {
+			fmt.Fprintf(&b, "Do NOT change files matching: %s. ", strings.Join(tasks[i].DenyPaths, ", "))
+		}
+		b.WriteString("Changes outside these rules cause the attempt to be rejected.")
+		tasks[i].Prompt += b.String()
+	}
+}
+
 // mirrorRunDocs copies run-level artifacts (report, original task files) and per-task
 // outputs to docs/tokencontrol/<run-id>/ in each repo touched by the run.
 func mirrorRunDocs(report *task.RunReport, tasks []task.Task, reposDir, docsDir, runDir string, tasksFiles []string) {
@@ -1885,25 +1908,6 @@ func isPositiveBalance(balance string) bool {
 	return f > 0.001 // treat anything below $0.001 as effectively zero
 }
 
-// providerFromModel extracts provider from "provider/model" format used by opencode runners.
-func providerFromModel(model string) string {
-	if model == "" {
-		return ""
-	}
-	parts := strings.SplitN(model, "/", 2)
-	if len(parts) < 2 {
-		return ""
-	}
-	switch parts[0] {
-	case "deepseek":
-		return "deepseek"
-	case "zai":
-		return "" // ZAI uses its own API key, not a shared provider quota
-	default:
-		return ""
-	}
-}
-
 func allScriptTasks(tasks []task.Task) bool {
 	for _, t := range tasks {
 		if t.Runner != "script" {
@@ -2101,3 +2105,18 @@ func collectANCCForTUI() []reporter.AgentInfo {
 	}
 	return agents
 }
+
+// stackParentBranch returns the retained branch of the first dependency of t
+// that targets the same repo, or "" when t should branch from HEAD.
+func stackParentBranch(t *task.Task, graph *task.Graph, branches map[string]string) string {
+	for _, dep := range t.DependsOn {
+		parent := graph.Task(dep)
+		if parent == nil || parent.Repo != t.Repo {
+			continue
+		}
+		if branch, ok := branches[dep]; ok {
+			return branch
+		}
+	}
+	return ""
+}
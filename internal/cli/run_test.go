@@ -213,3 +213,28 @@ func ids(tasks []task.Task) []string {
 	}
 	return out
 }
+
+func TestStackParentBranch(t *testing.T) {
+	tasks := []task.Task{
+		{ID: "a", Repo: "org/r", Prompt: "p"},
+		{ID: "other", Repo: "org/x", Prompt: "p"},
+		{ID: "b", Repo: "org/r", Prompt: "p", DependsOn: []string{"other", "a"}},
+		{ID: "c", Repo: "org/r", Prompt: "p", DependsOn: []string{"a"}},
+	}
+	graph, err := task.BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches := map[string]string{"a": "tokencontrol/a", "other": "tokencontrol/other"}
+
+	if got := stackParentBranch(graph.Task("b"), graph, branches); got != "tokencontrol/a" {
+		t.Errorf("b: got %q, want same-repo parent branch", got)
+	}
+	if got := stackParentBranch(graph.Task("a"), graph, branches); got != "" {
+		t.Errorf("a: got %q, want empty for root task", got)
+	}
+	delete(branches, "a") // parent merged back and its branch deleted
+	if got := stackParentBranch(graph.Task("c"), graph, branches); got != "" {
+		t.Errorf("c: got %q, want HEAD when parent branch is gone", got)
+	}
+}
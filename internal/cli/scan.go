@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -20,6 +23,20 @@ func newScanCmd() *cobra.Command {
 		owner      string
 		runner     string
 		output     string
+
+		baselinePath   string
+		updateBaseline bool
+		all            bool
+		noHistory      bool
+
+		fix       bool
+		fixDryRun bool
+		fixCommit bool
+
+		scanWorkers int
+		noCache     bool
+		advisories  string
+		histDepth   int
 	)
 
 	cmd := &cobra.Command{
@@ -42,6 +59,10 @@ func newScanCmd() *cobra.Command {
 			if cfg.Scan != nil {
 				excludeRepos = cfg.Scan.ExcludeRepos
 			}
+			rules, err := cfg.Scan.LoadRules(configFile)
+			if err != nil {
+				return fmt.Errorf("load scan rules: %w", err)
+			}
 
 			var minSev scan.Severity
 			if severity != "" {
@@ -51,17 +72,96 @@ func newScanCmd() *cobra.Command {
 				}
 			}
 
+			if !cmd.Flags().Changed("baseline") {
+				baselinePath = cfg.Scan.BaselinePath(configFile)
+			}
+			if updateBaseline && (len(checks) > 0 || minSev > 0) {
+				return fmt.Errorf("--update-baseline records all findings; drop --check and --severity")
+			}
+			if updateBaseline && (fix || fixDryRun) {
+				return fmt.Errorf("--update-baseline and --fix can't be combined")
+			}
+			if fixCommit && !fix {
+				return fmt.Errorf("--commit requires --fix")
+			}
+			var baseline *scan.Baseline
+			if !updateBaseline {
+				if baseline, err = scan.LoadBaseline(baselinePath); err != nil {
+					return err
+				}
+			}
+
+			if !cmd.Flags().Changed("workers") {
+				scanWorkers = cfg.Scan.ScanWorkers()
+			}
+			cachePath := cfg.Scan.CachePath()
+			if noCache {
+				cachePath = ""
+			}
+			if !cmd.Flags().Changed("history-depth") {
+				histDepth = cfg.Scan.ScanHistoryDepth()
+			}
+			if !cmd.Flags().Changed("advisories") {
+				advisories = cfg.Scan.AdvisoryDir(configFile)
+			}
+
 			result, err := scan.Scan(scan.ScanOptions{
 				ReposDir:     reposDir,
 				FilterRepo:   filterRepo,
 				MinSeverity:  minSev,
 				Categories:   checks,
 				ExcludeRepos: excludeRepos,
+				Rules:        rules,
+				Baseline:     baseline,
+				IncludeKnown: true, // known findings are recorded, then hidden below
+				Workers:      scanWorkers,
+				CachePath:    cachePath,
+				Version:      Version,
+				AdvisoryDir:  advisories,
+				HistoryDepth: histDepth,
 			})
 			if err != nil {
 				return err
 			}
 
+			// filtered scans would look like resolved findings in the history
+			if !noHistory && len(checks) == 0 && minSev == 0 {
+				recordScanHistory(reposDir, time.Now(), result)
+			}
+
+			if updateBaseline {
+				b, err := scan.LoadBaseline(baselinePath)
+				if err != nil {
+					return err
+				}
+				if b == nil {
+					b = &scan.Baseline{}
+				}
+				b.Update(result, time.Now())
+				if err := b.Save(baselinePath); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Baseline %s updated: %d known findings\n", baselinePath, len(b.Findings))
+				return nil
+			}
+			if !all {
+				result = result.WithoutKnown()
+			}
+			if fix || fixDryRun {
+				fixes, err := scan.ApplyFixes(result, scan.FixOptions{
+					ReposDir: reposDir,
+					DryRun:   fixDryRun,
+					Commit:   fixCommit,
+				})
+				if err != nil {
+					return err
+				}
+				printFixes(os.Stderr, fixes, fixDryRun)
+				if !fixDryRun {
+					result = result.WithoutFixed(fixes)
+				}
+			}
+
 			w := os.Stdout
 			if output != "" {
 				f, err := os.Create(output)
@@ -77,6 +177,10 @@ func newScanCmd() *cobra.Command {
 			switch format {
 			case "json":
 				return scan.NewJSONFormatter().Format(w, result)
+			case "sarif":
+				return scan.NewSARIFFormatter().Format(w, result)
+			case "junit":
+				return scan.NewJUnitFormatter().Format(w, result)
 			case "tasks":
 				if err := scan.NewTaskFormatter(owner, runner).Format(w, result); err != nil {
 					return err
@@ -92,13 +196,49 @@ func newScanCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&reposDir, "repos-dir", ".", "base directory containing repos")
-	cmd.Flags().StringVar(&format, "format", "text", "output format: text, json, tasks")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text, json, tasks, sarif, junit")
 	cmd.Flags().StringVar(&filterRepo, "filter-repo", "", "scan only this repo")
 	cmd.Flags().StringVar(&severity, "severity", "", "minimum severity: critical, warning, info")
-	cmd.Flags().StringSliceVar(&checks, "check", nil, "check categories to run (structure,go,python,security,ci,quality)")
+	cmd.Flags().StringSliceVar(&checks, "check", nil, "check categories to run (structure,go,python,node,rust,security,ci,quality, or a custom rule category)")
 	cmd.Flags().StringVar(&owner, "owner", "", "GitHub owner for task format output")
 	cmd.Flags().StringVar(&runner, "runner", "codex", "default runner for task format output")
 	cmd.Flags().StringVar(&output, "output", "", "write output to file instead of stdout")
+	cmd.Flags().StringVar(&baselinePath, "baseline", scan.BaselineFile, "baseline of known findings to hide")
+	cmd.Flags().BoolVar(&updateBaseline, "update-baseline", false, "record the current findings as the baseline and exit")
+	cmd.Flags().BoolVar(&all, "all", false, "include baselined and suppressed findings")
+	cmd.Flags().BoolVar(&noHistory, "no-history", false, "don't record this scan in the telemetry database")
+	cmd.Flags().BoolVar(&fix, "fix", false, "apply deterministic fixes (missing boilerplate files) before output")
+	cmd.Flags().BoolVar(&fixDryRun, "fix-dry-run", false, "list the files --fix would create without writing them")
+	cmd.Flags().BoolVar(&fixCommit, "commit", false, "commit each repo's fixes (with --fix)")
+	cmd.Flags().IntVar(&scanWorkers, "workers", 0, "repos scanned concurrently (0 = one per CPU)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "re-check every repo instead of reusing cached results")
+	cmd.Flags().IntVar(&histDepth, "history-depth", 0, "commits searched for committed secrets (0 = 200, -1 = off)")
+	cmd.Flags().StringVar(&advisories, "advisories", "", "OSV advisory dir for sec-vulnerable-dependency (default ~/.tokencontrol/advisories)")
+
+	cmd.AddCommand(newScanTrendCmd())
 
 	return cmd
 }
+
+// printFixes reports applied (or, in a dry run, planned) fixes per repo.
+func printFixes(w io.Writer, fixes []scan.FixResult, dryRun bool) {
+	verb := "Fixed"
+	if dryRun {
+		verb = "Would fix"
+	}
+	files, repos := 0, 0
+	for _, fr := range fixes {
+		if fr.Err != nil {
+			fmt.Fprintf(w, "%s: fix failed: %v\n", fr.Repo, fr.Err)
+			continue
+		}
+		files += len(fr.Files)
+		repos++
+		committed := ""
+		if fr.Committed {
+			committed = " (committed)"
+		}
+		fmt.Fprintf(w, "%s %s: %s%s\n", verb, fr.Repo, strings.Join(fr.Files, ", "), committed)
+	}
+	fmt.Fprintf(w, "%s %d files in %d repos\n\n", verb, files, repos)
+}
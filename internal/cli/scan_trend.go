@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/tokencontrol/internal/scan"
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+// trendColumns is how many of the latest scans the per-repo and
+// per-category tables show.
+const trendColumns = 6
+
+// recordScanHistory stores a scan in the telemetry database (best-effort).
+func recordScanHistory(reposDir string, at time.Time, result *scan.ScanResult) {
+	abs, err := filepath.Abs(reposDir)
+	if err != nil {
+		abs = reposDir
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s", at.UnixNano(), abs)
+	scanID := hex.EncodeToString(h.Sum(nil)[:6])
+
+	db, err := telemetry.OpenDB(telemetry.DefaultPath())
+	if err != nil {
+		slog.Warn("scan history not recorded", "error", err)
+		return
+	}
+	defer func() { _ = db.Close() }()
+	if err := telemetry.RecordScan(db, scanID, abs, at, result); err != nil {
+		slog.Warn("scan history not recorded", "error", err)
+	}
+}
+
+func newScanTrendCmd() *cobra.Command {
+	var (
+		since  string
+		repo   string
+		asJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "trend",
+		Short: "Show scan findings over time",
+		Long: `Replay recorded scans to show per-repo and per-category finding counts,
+newly introduced and resolved findings, and mean time to fix. Resolved
+findings are linked to the completed <repo>-scan-<check> task that fixed them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sinceTime time.Time
+			if since != "" {
+				t, err := time.ParseInLocation("2006-01-02", since, time.Local)
+				if err != nil {
+					return fmt.Errorf("invalid --since %q (want YYYY-MM-DD)", since)
+				}
+				sinceTime = t
+			}
+
+			db, err := telemetry.OpenDB(telemetry.DefaultPath())
+			if err != nil {
+				return fmt.Errorf("open telemetry: %w", err)
+			}
+			defer func() { _ = db.Close() }()
+
+			trend, err := telemetry.QueryScanTrend(db, sinceTime, repo)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(trend)
+			}
+			if len(trend.Points) == 0 {
+				fmt.Println("No scan history yet. Run tokencontrol scan first.")
+				return nil
+			}
+			printScanTrend(os.Stdout, trend)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "show scans since date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&repo, "repo", "", "show only this repo")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output as JSON")
+
+	return cmd
+}
+
+func printScanTrend(w io.Writer, trend *telemetry.ScanTrend) {
+	points := trend.Points
+	first, last := points[0], points[len(points)-1]
+	fmt.Fprintf(w, "Scan trend: %d scans from %s to %s, %d open findings\n\n",
+		len(points), first.At.Local().Format("2006-01-02"), last.At.Local().Format("2006-01-02"), trend.Open)
+
+	fmt.Fprintln(w, "Scans:")
+	for _, p := range points {
+		fmt.Fprintf(w, "  %s  %3d repos  %4d findings  +%d new  -%d resolved\n",
+			p.At.Local().Format("2006-01-02 15:04"), p.Repos, p.Findings, p.Introduced, p.Resolved)
+	}
+
+	cols := points
+	if len(cols) > trendColumns {
+		cols = cols[len(cols)-trendColumns:]
+	}
+	printTrendTable(w, "By repo", cols, func(p telemetry.ScanPoint) map[string]int { return p.ByRepo })
+	printTrendTable(w, "By category", cols, func(p telemetry.ScanPoint) map[string]int { return p.ByCategory })
+
+	fmt.Fprintf(w, "\nResolved: %d", len(trend.Resolved))
+	if len(trend.Resolved) > 0 {
+		fmt.Fprintf(w, ", mean time to fix %s", formatAge(trend.MeanTimeToFix))
+	}
+	fmt.Fprintln(w)
+	for _, c := range trend.Resolved {
+		fixed := ""
+		if c.TaskID != "" {
+			fixed = fmt.Sprintf(", fixed by %s in run %s", c.TaskID, c.RunID)
+		}
+		fmt.Fprintf(w, "  %-20s %-25s %s (%s%s)\n", c.Repo, c.Check, c.Message,
+			formatAge(c.ResolvedAt.Sub(c.IntroducedAt)), fixed)
+	}
+
+	fmt.Fprintf(w, "\nIntroduced: %d\n", len(trend.Introduced))
+	for _, c := range trend.Introduced {
+		fmt.Fprintf(w, "  %-20s %-25s %s (%s)\n", c.Repo, c.Check, c.Message, c.IntroducedAt.Local().Format("2006-01-02"))
+	}
+}
+
+// printTrendTable prints one row per key with its count in each scan.
+func printTrendTable(w io.Writer, title string, cols []telemetry.ScanPoint, counts func(telemetry.ScanPoint) map[string]int) {
+	keys := make(map[string]bool)
+	for _, p := range cols {
+		for k := range counts(p) {
+			keys[k] = true
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	header := make([]string, len(cols))
+	for i, p := range cols {
+		header[i] = fmt.Sprintf("%6s", p.At.Local().Format("01-02"))
+	}
+	fmt.Fprintf(w, "\n%s:\n  %-20s %s\n", title, "", strings.Join(header, ""))
+	for _, k := range names {
+		row := make([]string, len(cols))
+		for i, p := range cols {
+			row[i] = fmt.Sprintf("%6d", counts(p)[k])
+		}
+		fmt.Fprintf(w, "  %-20s %s\n", k, strings.Join(row, ""))
+	}
+}
+
+// formatAge renders a duration in days and hours, e.g. "3d 4h".
+func formatAge(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
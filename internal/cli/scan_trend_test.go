@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/telemetry"
+)
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{76 * time.Hour, "3d 4h"},
+		{5 * time.Hour, "5h"},
+		{42 * time.Minute, "42m"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestPrintScanTrend(t *testing.T) {
+	day := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	trend := &telemetry.ScanTrend{
+		Points: []telemetry.ScanPoint{
+			{At: day, Repos: 2, Findings: 3, ByRepo: map[string]int{"app": 2, "lib": 1}, ByCategory: map[string]int{"structure": 3}},
+			{At: day.AddDate(0, 0, 2), Repos: 2, Findings: 2, Resolved: 1, ByRepo: map[string]int{"app": 1, "lib": 1}, ByCategory: map[string]int{"structure": 2}},
+		},
+		Resolved: []telemetry.ScanChange{{
+			Repo: "app", Check: "missing-readme", Message: "No README.md found",
+			IntroducedAt: day, ResolvedAt: day.AddDate(0, 0, 2), RunID: "r1", TaskID: "app-scan-missing-readme",
+		}},
+		MeanTimeToFix: 48 * time.Hour,
+		Open:          2,
+	}
+
+	var buf bytes.Buffer
+	printScanTrend(&buf, trend)
+	out := buf.String()
+	for _, want := range []string{
+		"2 scans from 2026-10-01 to 2026-10-03, 2 open findings",
+		"-1 resolved",
+		"By repo:",
+		"By category:",
+		"Resolved: 1, mean time to fix 2d 0h",
+		"fixed by app-scan-missing-readme in run r1",
+		"Introduced: 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
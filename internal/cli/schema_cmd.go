@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+)
+
+func newSchemaCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for task files",
+		Long: "Print the JSON Schema describing tokencontrol task files (JSON or YAML).\n\n" +
+			"Reference it from a task file for editor completion and inline errors:\n" +
+			"  JSON: \"$schema\": \"./taskfile.schema.json\"\n" +
+			"  YAML: # yaml-language-server: $schema=./taskfile.schema.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema := config.TaskFileSchema()
+			if output == "" {
+				_, err := cmd.OutOrStdout().Write(schema)
+				return err
+			}
+			if err := os.WriteFile(output, schema, 0o644); err != nil {
+				return fmt.Errorf("write schema: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Schema written to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write schema to file instead of stdout")
+
+	return cmd
+}
@@ -65,6 +65,11 @@ Leave it running for unattended portfolio maintenance.`,
 				fallbacks = cfg.DefaultFallbacks
 			}
 
+			scanRules, err := cfg.Scan.LoadRules(configFile)
+			if err != nil {
+				return fmt.Errorf("load scan rules: %w", err)
+			}
+
 			absReposDir, err := filepath.Abs(reposDir)
 			if err != nil {
 				return fmt.Errorf("resolve repos dir: %w", err)
@@ -74,14 +79,22 @@ Leave it running for unattended portfolio maintenance.`,
 			runFn := buildSentinelRunFn(absReposDir, workers, runnerName, fallbacks, maxRuntime, idleTimeout, failFast, cfg)
 
 			loop, err := sentinel.NewLoop(sentinel.LoopConfig{
-				ReposDir:     absReposDir,
-				Owner:        owner,
-				StateDir:     sentinel.DefaultTrackerPath(),
-				Cooldown:     cooldown,
-				ScanOnly:     scanOnly,
-				GenerateOnly: generateOnly,
-				Settings:     cfg,
-				RunFn:        runFn,
+				ReposDir:       absReposDir,
+				Owner:          owner,
+				StateDir:       sentinel.DefaultTrackerPath(),
+				Cooldown:       cooldown,
+				ScanOnly:       scanOnly,
+				GenerateOnly:   generateOnly,
+				Settings:       cfg,
+				ScanRules:      scanRules,
+				ScanBaseline:   cfg.Scan.BaselinePath(configFile),
+				ScanFix:        cfg.Scan != nil && cfg.Scan.Fix,
+				ScanWorkers:    cfg.Scan.ScanWorkers(),
+				ScanCache:      cfg.Scan.CachePath(),
+				ScanAdvisories: cfg.Scan.AdvisoryDir(configFile),
+				ScanHistory:    cfg.Scan.ScanHistoryDepth(),
+				Version:        Version,
+				RunFn:          runFn,
 			})
 			if err != nil {
 				return fmt.Errorf("init sentinel loop: %w", err)
@@ -96,14 +109,22 @@ Leave it running for unattended portfolio maintenance.`,
 				runFnWithProgress := buildSentinelRunFnWithProgress(absReposDir, workers, runnerName, fallbacks, maxRuntime, idleTimeout, failFast, cfg, state)
 				// rebuild loop with progress-aware RunFn
 				loop, err = sentinel.NewLoop(sentinel.LoopConfig{
-					ReposDir:     absReposDir,
-					Owner:        owner,
-					StateDir:     sentinel.DefaultTrackerPath(),
-					Cooldown:     cooldown,
-					ScanOnly:     scanOnly,
-					GenerateOnly: generateOnly,
-					Settings:     cfg,
-					RunFn:        runFnWithProgress,
+					ReposDir:       absReposDir,
+					Owner:          owner,
+					StateDir:       sentinel.DefaultTrackerPath(),
+					Cooldown:       cooldown,
+					ScanOnly:       scanOnly,
+					GenerateOnly:   generateOnly,
+					Settings:       cfg,
+					ScanRules:      scanRules,
+					ScanBaseline:   cfg.Scan.BaselinePath(configFile),
+					ScanFix:        cfg.Scan != nil && cfg.Scan.Fix,
+					ScanWorkers:    cfg.Scan.ScanWorkers(),
+					ScanCache:      cfg.Scan.CachePath(),
+					ScanAdvisories: cfg.Scan.AdvisoryDir(configFile),
+					ScanHistory:    cfg.Scan.ScanHistoryDepth(),
+					Version:        Version,
+					RunFn:          runFnWithProgress,
 				})
 				if err != nil {
 					return fmt.Errorf("init sentinel loop: %w", err)
@@ -181,6 +202,7 @@ func buildSentinelRunFnWithProgress(reposDir string, workers int, runnerName str
 			failFast:    failFast,
 			settings:    settings,
 			tuiMode:     "off",
+			rollback:    resolveRollback(settings, false),
 		}
 
 		if state != nil {
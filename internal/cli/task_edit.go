@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/tokencontrol/internal/config"
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// overlayFileName is the task file, in the run directory, that records tasks
+// edited or added from the TUI so rerun and verify see the same definitions.
+const overlayFileName = "tasks-overlay.json"
+
+// taskDraft is the editable part of a task as shown in $EDITOR.
+type taskDraft struct {
+	ID        string   `yaml:"id"`
+	Repo      string   `yaml:"repo"`
+	Title     string   `yaml:"title"`
+	Priority  int      `yaml:"priority"`
+	Runner    string   `yaml:"runner"`
+	Fallbacks []string `yaml:"fallbacks"`
+	DependsOn []string `yaml:"depends_on"`
+	Prompt    string   `yaml:"prompt"`
+}
+
+// taskEditor applies task drafts edited in the TUI to a live run and keeps
+// the run's overlay task file up to date.
+type taskEditor struct {
+	mu      sync.Mutex
+	sched   *task.Scheduler
+	graph   *task.Graph
+	runners []string
+	runDir  string
+
+	defaultRunner    string
+	defaultFallbacks []string
+	files            []*task.TaskFile // loaded task files, for the rules of added tasks
+
+	// basePrompts holds prompts as loaded, before run-time injections, so
+	// drafts and the overlay never contain injected instructions.
+	basePrompts map[string]string
+	// prepare applies the run-time prompt injections to edited tasks.
+	prepare func(tasks []task.Task)
+
+	overlay []task.Task // edited and added tasks, base prompts
+	changed bool        // overlay written during this run
+}
+
+// editorCommand returns the command used to open drafts: $VISUAL, then
+// $EDITOR, then vi.
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return e
+		}
+	}
+	return "vi"
+}
+
+// seedOverlay carries the tasks of an earlier run's overlay into this one,
+// so an overlay rewritten by a rerun still holds the original edits.
+func (e *taskEditor) seedOverlay(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read overlay: %w", err)
+	}
+	var tf task.TaskFile
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return fmt.Errorf("parse overlay: %w", err)
+	}
+	e.overlay = append(e.overlay, tf.Tasks...)
+	return nil
+}
+
+// DraftTask writes a draft of task id, or of a new task when id is empty,
+// and returns its path.
+func (e *taskEditor) DraftTask(id string) (string, error) {
+	d, err := e.draft(id)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(d)
+	if err != nil {
+		return "", fmt.Errorf("encode draft: %w", err)
+	}
+	var b bytes.Buffer
+	if id == "" {
+		b.WriteString("# New task: fill in the prompt, save and quit to add it to the run.\n")
+	} else {
+		b.WriteString("# Edit the task, save and quit to apply. id and repo cannot change.\n")
+	}
+	fmt.Fprintf(&b, "# Runners: %s\n", strings.Join(e.runners, ", "))
+	b.Write(data)
+
+	dir := filepath.Join(e.runDir, "drafts")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create drafts dir: %w", err)
+	}
+	path := filepath.Join(dir, d.ID+".yaml")
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("write draft: %w", err)
+	}
+	return path, nil
+}
+
+// draft returns the draft of task id, or of a new task when id is empty.
+// Follow-ups add tasks from worker goroutines, so it holds e.mu.
+func (e *taskEditor) draft(id string) (taskDraft, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if id == "" {
+		return e.newDraft(), nil
+	}
+	t := e.graph.Task(id)
+	if t == nil {
+		return taskDraft{}, fmt.Errorf("task %q not found", id)
+	}
+	return draftFromTask(t, e.basePrompt(t)), nil
+}
+
+// ApplyDraft applies the edited draft at path. id is the edited task, or
+// empty when the draft describes a new task. Returns the ID of the task that
+// changed, or "" when the draft was left as it was.
+func (e *taskEditor) ApplyDraft(id, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read draft: %w", err)
+	}
+	var d taskDraft
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		return "", fmt.Errorf("parse draft: %w", err)
+	}
+	d.Prompt = strings.TrimSpace(d.Prompt)
+	if err := e.validate(d); err != nil {
+		return "", err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if id == "" {
+		if d.Prompt == "" {
+			return "", nil // template saved untouched
+		}
+		if e.graph.Task(d.ID) != nil {
+			return "", fmt.Errorf("task %q already exists", d.ID)
+		}
+		var t task.Task
+		d.applyTo(&t)
+		e.applyFileRules(&t)
+		return d.ID, e.insert(t)
+	}
+
+	cur := e.graph.Task(id)
+	if cur == nil {
+		return "", fmt.Errorf("task %q not found", id)
+	}
+	if d.ID != id || d.Repo != cur.Repo {
+		return "", fmt.Errorf("id and repo cannot be changed")
+	}
+	if d.Prompt == "" {
+		return "", fmt.Errorf("prompt is empty")
+	}
+	if d.equal(draftFromTask(cur, e.basePrompt(cur))) {
+		return "", nil
+	}
+	t := *cur
+	d.applyTo(&t)
+	if err := e.sched.UpdateTask(e.prepared(t)); err != nil {
+		return "", err
+	}
+	return id, e.record(t)
+}
+
+// addTask inserts a new task into the run and records it in the overlay.
+func (e *taskEditor) addTask(t task.Task) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.insert(t)
+}
+
+// applyFileRules applies the defaults and path rules of the task files that
+// hold tasks in t's repo, or of every file when none does, as loading would
+// have. Deny paths add up; other values come from the first file setting them.
+func (e *taskEditor) applyFileRules(t *task.Task) {
+	var files []*task.TaskFile
+	for _, f := range e.files {
+		if slices.ContainsFunc(f.Tasks, func(o task.Task) bool { return o.Repo == t.Repo }) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		files = e.files
+	}
+	for _, f := range files {
+		config.ApplyFileRules(f, t)
+	}
+}
+
+// insert adds t to the scheduler and the overlay. Caller must hold e.mu.
+func (e *taskEditor) insert(t task.Task) error {
+	t.SourceFile = filepath.Join(e.runDir, overlayFileName)
+	if err := e.sched.AddTask(e.prepared(t)); err != nil {
+		return err
+	}
+	return e.record(t)
+}
+
+// validate checks the parts of a draft that don't depend on the graph state.
+func (e *taskEditor) validate(d taskDraft) error {
+	if d.ID == "" || d.Repo == "" {
+		return fmt.Errorf("id and repo are required")
+	}
+	for _, r := range append([]string{d.Runner}, d.Fallbacks...) {
+		if r != "" && !slices.Contains(e.runners, r) {
+			return fmt.Errorf("unknown runner %q", r)
+		}
+	}
+	return nil
+}
+
+// prepared returns t with the run-time prompt injections applied.
+func (e *taskEditor) prepared(t task.Task) task.Task {
+	e.basePrompts[t.ID] = t.Prompt
+	tasks := []task.Task{t}
+	if e.prepare != nil {
+		e.prepare(tasks)
+	}
+	return tasks[0]
+}
+
+// record stores t in the overlay and rewrites the overlay file.
+func (e *taskEditor) record(t task.Task) error {
+	t.SourceFile = ""
+	if i := slices.IndexFunc(e.overlay, func(o task.Task) bool { return o.ID == t.ID }); i >= 0 {
+		e.overlay[i] = t
+	} else {
+		e.overlay = append(e.overlay, t)
+	}
+	tf := task.TaskFile{
+		Description: "Tasks edited or added during the run",
+		Tasks:       e.overlay,
+	}
+	data, err := json.MarshalIndent(tf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode overlay: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.runDir, overlayFileName), data, 0o644); err != nil {
+		return fmt.Errorf("write overlay: %w", err)
+	}
+	e.changed = true
+	return nil
+}
+
+// overlayPath returns the overlay file written during this run, or "".
+func (e *taskEditor) overlayPath() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.changed {
+		return ""
+	}
+	return filepath.Join(e.runDir, overlayFileName)
+}
+
+// basePrompt returns t's prompt without run-time injections. Caller must
+// hold e.mu.
+func (e *taskEditor) basePrompt(t *task.Task) string {
+	if p, ok := e.basePrompts[t.ID]; ok {
+		return p
+	}
+	return t.Prompt
+}
+
+// newDraft returns a template for an ad-hoc task with an unused ID, aimed
+// at the repo of the first task in the run.
+func (e *taskEditor) newDraft() taskDraft {
+	id := ""
+	for n := 1; ; n++ {
+		id = fmt.Sprintf("adhoc-%d", n)
+		if e.graph.Task(id) == nil {
+			break
+		}
+	}
+	d := taskDraft{
+		ID:        id,
+		Title:     "Ad-hoc task",
+		Priority:  1,
+		Runner:    e.defaultRunner,
+		Fallbacks: e.defaultFallbacks,
+	}
+	if order := e.graph.Order(); len(order) > 0 {
+		d.Repo = e.graph.Task(order[0]).Repo
+	}
+	return d
+}
+
+// graphTasks returns the tasks of g in topological order, including tasks
+// added while the run was in progress.
+func graphTasks(g *task.Graph) []task.Task {
+	order := g.Order()
+	tasks := make([]task.Task, 0, len(order))
+	for _, id := range order {
+		tasks = append(tasks, *g.Task(id))
+	}
+	return tasks
+}
+
+func draftFromTask(t *task.Task, prompt string) taskDraft {
+	return taskDraft{
+		ID:        t.ID,
+		Repo:      t.Repo,
+		Title:     t.Title,
+		Priority:  t.Priority,
+		Runner:    t.Runner,
+		Fallbacks: t.Fallbacks,
+		DependsOn: t.DependsOn,
+		Prompt:    strings.TrimSpace(prompt),
+	}
+}
+
+// equal compares drafts, treating empty and missing lists alike.
+func (d taskDraft) equal(o taskDraft) bool {
+	return d.ID == o.ID && d.Repo == o.Repo && d.Title == o.Title && d.Priority == o.Priority &&
+		d.Runner == o.Runner && d.Prompt == o.Prompt &&
+		slices.Equal(d.Fallbacks, o.Fallbacks) && slices.Equal(d.DependsOn, o.DependsOn)
+}
+
+func (d taskDraft) applyTo(t *task.Task) {
+	t.ID = d.ID
+	t.Repo = d.Repo
+	t.Title = d.Title
+	t.Priority = d.Priority
+	t.Runner = d.Runner
+	t.Fallbacks = d.Fallbacks
+	t.DependsOn = d.DependsOn
+	t.Prompt = d.Prompt
+}
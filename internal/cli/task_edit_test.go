@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// startEditorRun starts a scheduler whose "busy" task blocks until the
+// returned release func is called, so the run stays live for edits.
+func startEditorRun(t *testing.T, tasks []task.Task) (*taskEditor, func() map[string]*task.TaskResult) {
+	t.Helper()
+	graph, err := task.BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sched := task.NewScheduler(graph, task.SchedulerConfig{
+		Workers:  2,
+		ReposDir: t.TempDir(),
+		RunDir:   t.TempDir(),
+		ExecFn: func(_ context.Context, tk *task.Task, _, _ string) *task.TaskResult {
+			if tk.ID == "busy" {
+				close(started)
+				<-release
+			}
+			return &task.TaskResult{TaskID: tk.ID, State: task.StateCompleted}
+		},
+	})
+	base := make(map[string]string)
+	for _, tk := range tasks {
+		base[tk.ID] = tk.Prompt
+	}
+	ed := &taskEditor{
+		sched:         sched,
+		graph:         graph,
+		runners:       []string{"codex", "claude"},
+		runDir:        t.TempDir(),
+		defaultRunner: "codex",
+		basePrompts:   base,
+		prepare: func(ts []task.Task) {
+			for i := range ts {
+				ts[i].Prompt += "\n\nINJECTED"
+			}
+		},
+	}
+	done := make(chan map[string]*task.TaskResult)
+	go func() { done <- sched.Run(context.Background()) }()
+	<-started
+	return ed, func() map[string]*task.TaskResult {
+		close(release)
+		return <-done
+	}
+}
+
+func TestTaskEditor_EditQueuedTask(t *testing.T) {
+	ed, finish := startEditorRun(t, []task.Task{
+		{ID: "busy", Repo: "org/r", Priority: 1, Title: "Busy", Prompt: "busy", Runner: "codex"},
+		{ID: "next", Repo: "org/r", Priority: 1, Title: "Next", Prompt: "old prompt", Runner: "codex", DependsOn: []string{"busy"}},
+	})
+
+	path, err := ed.DraftTask("next")
+	if err != nil {
+		t.Fatalf("DraftTask: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "old prompt") || strings.Contains(string(data), "INJECTED") {
+		t.Errorf("draft should hold the base prompt:\n%s", data)
+	}
+
+	// unchanged draft is a no-op
+	if id, err := ed.ApplyDraft("next", path); err != nil || id != "" {
+		t.Errorf("unchanged ApplyDraft = %q, %v", id, err)
+	}
+
+	edited := strings.Replace(string(data), "old prompt", "new prompt", 1)
+	edited = strings.Replace(edited, "runner: codex", "runner: claude", 1)
+	_ = os.WriteFile(path, []byte(edited), 0o644)
+	if id, err := ed.ApplyDraft("next", path); err != nil || id != "next" {
+		t.Fatalf("ApplyDraft = %q, %v", id, err)
+	}
+	got := ed.graph.Task("next")
+	if got.Runner != "claude" || got.Prompt != "new prompt\n\nINJECTED" {
+		t.Errorf("graph task = runner %q prompt %q", got.Runner, got.Prompt)
+	}
+
+	// running tasks and unknown runners are rejected
+	busyPath, _ := ed.DraftTask("busy")
+	busy, _ := os.ReadFile(busyPath)
+	_ = os.WriteFile(busyPath, []byte(strings.Replace(string(busy), "prompt: busy", "prompt: changed", 1)), 0o644)
+	if _, err := ed.ApplyDraft("busy", busyPath); err == nil {
+		t.Error("expected error editing a running task")
+	}
+	_ = os.WriteFile(path, []byte(strings.Replace(edited, "runner: claude", "runner: nope", 1)), 0o644)
+	if _, err := ed.ApplyDraft("next", path); err == nil || !strings.Contains(err.Error(), "unknown runner") {
+		t.Errorf("expected unknown runner error, got %v", err)
+	}
+
+	results := finish()
+	if results["next"].State != task.StateCompleted {
+		t.Errorf("next = %s", results["next"].State)
+	}
+	if ed.overlayPath() == "" {
+		t.Fatal("overlay should be written")
+	}
+	var overlay task.TaskFile
+	raw, _ := os.ReadFile(ed.overlayPath())
+	if err := json.Unmarshal(raw, &overlay); err != nil {
+		t.Fatal(err)
+	}
+	if len(overlay.Tasks) != 1 || overlay.Tasks[0].Prompt != "new prompt" || overlay.Tasks[0].Runner != "claude" {
+		t.Errorf("overlay tasks = %+v", overlay.Tasks)
+	}
+}
+
+func TestTaskEditor_AddTask(t *testing.T) {
+	ed, finish := startEditorRun(t, []task.Task{
+		{ID: "busy", Repo: "org/r", Priority: 1, Title: "Busy", Prompt: "busy", Runner: "codex"},
+	})
+	ed.files = []*task.TaskFile{
+		{Tasks: []task.Task{{ID: "other", Repo: "org/other"}}, DenyPaths: []string{"secrets/**"}},
+		{
+			Tasks:      []task.Task{{ID: "busy", Repo: "org/r"}},
+			Defaults:   &task.TaskDefaults{Difficulty: "simple"},
+			DenyPaths:  []string{"LICENSE"},
+			PathPolicy: "revert",
+			Handoff:    &task.HandoffConfig{Enabled: true},
+		},
+	}
+
+	path, err := ed.DraftTask("")
+	if err != nil {
+		t.Fatalf("DraftTask: %v", err)
+	}
+	if filepath.Base(path) != "adhoc-1.yaml" {
+		t.Errorf("draft path = %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "repo: org/r") || !strings.Contains(string(data), "runner: codex") {
+		t.Errorf("template:\n%s", data)
+	}
+
+	// template saved without a prompt adds nothing
+	if id, err := ed.ApplyDraft("", path); err != nil || id != "" {
+		t.Errorf("empty template ApplyDraft = %q, %v", id, err)
+	}
+
+	edited := strings.Replace(string(data), `prompt: ""`, "prompt: fix the flaky test", 1)
+	edited = strings.Replace(edited, "depends_on: []", "depends_on: [busy]", 1)
+	_ = os.WriteFile(path, []byte(edited), 0o644)
+	if id, err := ed.ApplyDraft("", path); err != nil || id != "adhoc-1" {
+		t.Fatalf("ApplyDraft = %q, %v\n%s", id, err, edited)
+	}
+	if _, err := ed.ApplyDraft("", path); err == nil {
+		t.Error("expected error adding the same task twice")
+	}
+	// the rules of the file holding org/r's tasks apply, not the other file's
+	added := ed.graph.Task("adhoc-1")
+	if strings.Join(added.DenyPaths, ",") != "LICENSE" || added.PathPolicy != "revert" ||
+		added.Handoff == nil || added.Difficulty != "simple" {
+		t.Errorf("added task rules = deny %v policy %q handoff %v difficulty %q",
+			added.DenyPaths, added.PathPolicy, added.Handoff, added.Difficulty)
+	}
+
+	results := finish()
+	if r := results["adhoc-1"]; r == nil || r.State != task.StateCompleted {
+		t.Fatalf("adhoc-1 = %+v", r)
+	}
+	tasks := graphTasks(ed.graph)
+	if len(tasks) != 2 || tasks[1].ID != "adhoc-1" {
+		t.Errorf("graph tasks = %+v", tasks)
+	}
+}
+
+// Follow-ups add tasks from worker goroutines while the TUI drafts; run with
+// -race to catch unguarded access to the editor's maps.
+func TestTaskEditor_DraftWhileAddingTasks(t *testing.T) {
+	ed, finish := startEditorRun(t, []task.Task{
+		{ID: "busy", Repo: "org/r", Priority: 1, Title: "Busy", Prompt: "busy", Runner: "codex"},
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 20 {
+			id := fmt.Sprintf("followup-%d", i)
+			err := ed.addTask(task.Task{ID: id, Repo: "org/r", Priority: 1, Title: id, Prompt: id, DependsOn: []string{"busy"}})
+			if err != nil {
+				t.Errorf("addTask %s: %v", id, err)
+			}
+		}
+	}()
+	for range 20 {
+		if _, err := ed.DraftTask("busy"); err != nil {
+			t.Errorf("DraftTask: %v", err)
+		}
+		if _, err := ed.DraftTask(""); err != nil {
+			t.Errorf("DraftTask new: %v", err)
+		}
+	}
+	wg.Wait()
+
+	results := finish()
+	if r := results["followup-19"]; r == nil || r.State != task.StateCompleted {
+		t.Errorf("followup-19 = %+v", r)
+	}
+}
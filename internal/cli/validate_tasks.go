@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -17,21 +20,55 @@ func newValidateTasksCmd() *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "validate",
-		Short: "Validate a task file without running anything",
+		Use:   "validate [files...]",
+		Short: "Validate task files without running anything",
+		Long:  "Validate task files (JSON or YAML) against the task file schema, then check\ndependencies and runner references. Schema errors are reported as file:line:column.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return validateTasks(tasksFile, reposDir)
+			if len(args) > 0 {
+				tasksFile = strings.Join(args, ",")
+			}
+			return validateTasks(os.Stdout, tasksFile, reposDir)
 		},
 	}
 
-	cmd.Flags().StringVar(&tasksFile, "tasks", "tokencontrol.json", "path to tasks JSON file")
+	cmd.Flags().StringVar(&tasksFile, "tasks", "tokencontrol.json", "task files: path, glob, or comma-separated (JSON or YAML)")
 	cmd.Flags().StringVar(&reposDir, "repos-dir", "", "verify repos exist on disk (optional)")
 
 	return cmd
 }
 
-func validateTasks(tasksFile, reposDir string) error {
-	tf, err := config.Load(tasksFile)
+func validateTasks(w io.Writer, tasksFile, reposDir string) error {
+	paths, err := config.ResolveGlob(tasksFile)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	// schema pass first: report every violation with its position
+	schemaErrors := 0
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("validate: read %s: %w", p, err)
+		}
+		for _, se := range config.ValidateSchema(p, data) {
+			fmt.Fprintln(w, se.Error())
+			schemaErrors++
+		}
+	}
+	if schemaErrors > 0 {
+		return fmt.Errorf("validate: %d schema error(s)", schemaErrors)
+	}
+
+	var tf *task.TaskFile
+	if len(paths) == 1 {
+		tf, err = config.Load(paths[0])
+	} else {
+		var files []*task.TaskFile
+		files, err = config.LoadMulti(paths)
+		if err == nil {
+			tf, err = config.MergeTaskFiles(files)
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("validate: %w", err)
 	}
@@ -54,7 +91,7 @@ func validateTasks(tasksFile, reposDir string) error {
 	repos := countRepos(tf.Tasks)
 	depth := maxDepth(graph)
 
-	fmt.Printf("valid: %d tasks, %d repos, max depth %d\n", len(tf.Tasks), repos, depth)
+	fmt.Fprintf(w, "valid: %d tasks, %d repos, max depth %d\n", len(tf.Tasks), repos, depth)
 	return nil
 }
 
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateTasks_SchemaErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pack.yaml")
+	data := "tasks:\n  - id: t1\n    repo: org/r\n    prompt: p\n    priority: high\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err := validateTasks(&buf, path, "")
+	if err == nil || !strings.Contains(err.Error(), "1 schema error") {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+	want := path + ":5:15: tasks[0].priority: expected integer, got string"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestValidateTasks_MultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.yml")
+	if err := os.WriteFile(a, []byte(`{"tasks": [{"id": "a1", "repo": "org/r", "prompt": "p"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("tasks:\n  - id: b1\n    repo: org/s\n    prompt: p\n    depends_on: a1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := validateTasks(&buf, a+","+b, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "valid: 2 tasks, 2 repos, max depth 1") {
+		t.Errorf("unexpected output: %s", got)
+	}
+}
@@ -83,6 +83,11 @@ func verifyRun(runDir, reposDir string, markDone bool) error {
 	if err != nil {
 		return fmt.Errorf("merge tasks files: %w", err)
 	}
+	if report.Overlay != "" {
+		if err := config.ApplyOverlay(tf, report.Overlay); err != nil {
+			return fmt.Errorf("apply task overlay: %w", err)
+		}
+	}
 
 	// build task lookup
 	taskMap := make(map[string]*task.Task, len(tf.Tasks))
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// resolveTaskFile reads a task file and resolves its extends and include
+// references. Referenced paths are relative to the file that names them.
+//
+// extends inherits settings (runners, default_runner, default_fallbacks,
+// defaults, review, allowed_repos, parallel_repo, merge_back) from a base
+// file; values set in the extending file win and base tasks are not
+// inherited. include merges the tasks and settings of other files; a runner
+// profile defined differently in two files is an error.
+//
+// Each file's defaults are applied to its own tasks before merging, so an
+// included pack keeps the defaults it was written with.
+func resolveTaskFile(path string, chain []string) (*task.TaskFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", path, err)
+	}
+	for _, seen := range chain {
+		if seen == abs {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(chain, abs), " -> "))
+		}
+	}
+	chain = append(chain, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tasks file: %w", err)
+	}
+	var tf task.TaskFile
+	if err := decodeTaskFile(path, data, &tf); err != nil {
+		return nil, fmt.Errorf("parse tasks file: %w", err)
+	}
+	for i := range tf.Tasks {
+		if tf.Tasks[i].SourceFile == "" {
+			tf.Tasks[i].SourceFile = path
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if tf.Extends != "" {
+		base, err := resolveTaskFile(relativeTo(dir, tf.Extends), chain)
+		if err != nil {
+			return nil, fmt.Errorf("extends %s: %w", tf.Extends, err)
+		}
+		inheritSettings(&tf, base)
+	}
+
+	applyTaskDefaults(&tf)
+	applyPathRules(&tf)
+
+	runnerSources := make(map[string]string, len(tf.Runners))
+	for name := range tf.Runners {
+		runnerSources[name] = path
+	}
+	for _, inc := range tf.Include {
+		incPath := relativeTo(dir, inc)
+		child, err := resolveTaskFile(incPath, chain)
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", inc, err)
+		}
+		if err := mergeIncluded(&tf, child, runnerSources, incPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return &tf, nil
+}
+
+// relativeTo resolves ref against dir unless ref is already absolute.
+func relativeTo(dir, ref string) string {
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(dir, ref)
+}
+
+// inheritSettings fills settings the child leaves unset from base.
+// Runner profiles are inherited by name; a child profile with the same
+// name replaces the base profile entirely.
+func inheritSettings(child, base *task.TaskFile) {
+	if len(base.Runners) > 0 {
+		runners := make(map[string]*task.RunnerProfileConfig, len(base.Runners)+len(child.Runners))
+		for name, rp := range base.Runners {
+			runners[name] = rp
+		}
+		for name, rp := range child.Runners {
+			runners[name] = rp
+		}
+		child.Runners = runners
+	}
+	if child.DefaultRunner == "" {
+		child.DefaultRunner = base.DefaultRunner
+	}
+	if len(child.DefaultFallbacks) == 0 {
+		child.DefaultFallbacks = base.DefaultFallbacks
+	}
+	if child.Review == nil {
+		child.Review = base.Review
+	}
+	if len(child.AllowedRepos) == 0 {
+		child.AllowedRepos = base.AllowedRepos
+	}
+	if !child.ParallelRepo {
+		child.ParallelRepo = base.ParallelRepo
+	}
+	if child.MergeBack == nil {
+		child.MergeBack = base.MergeBack
+	}
+	if len(child.AllowPaths) == 0 {
+		child.AllowPaths = base.AllowPaths
+	}
+	child.DenyPaths = unionStrings(base.DenyPaths, child.DenyPaths)
+	if child.PathPolicy == "" {
+		child.PathPolicy = base.PathPolicy
+	}
+	if child.Handoff == nil {
+		child.Handoff = base.Handoff
+	}
+	if base.Defaults != nil {
+		if child.Defaults == nil {
+			child.Defaults = &task.TaskDefaults{}
+		}
+		if child.Defaults.Runner == "" {
+			child.Defaults.Runner = base.Defaults.Runner
+		}
+		if len(child.Defaults.Fallbacks) == 0 {
+			child.Defaults.Fallbacks = base.Defaults.Fallbacks
+		}
+		if child.Defaults.Difficulty == "" {
+			child.Defaults.Difficulty = base.Defaults.Difficulty
+		}
+	}
+}
+
+// applyTaskDefaults fills runner, fallbacks and difficulty on tasks that
+// leave them unset. Explicit task values always win.
+func applyTaskDefaults(tf *task.TaskFile) {
+	for i := range tf.Tasks {
+		taskDefaults(tf, &tf.Tasks[i])
+	}
+}
+
+func taskDefaults(tf *task.TaskFile, t *task.Task) {
+	d := tf.Defaults
+	if d == nil {
+		return
+	}
+	if t.Runner == "" {
+		t.Runner = d.Runner
+	}
+	if len(t.Fallbacks) == 0 && len(d.Fallbacks) > 0 {
+		t.Fallbacks = append([]string(nil), d.Fallbacks...)
+	}
+	if t.Difficulty == "" {
+		t.Difficulty = d.Difficulty
+	}
+}
+
+// applyPathRules adds file-level deny_paths to every task and fills
+// allow_paths, path_policy and handoff on tasks that leave them unset.
+func applyPathRules(tf *task.TaskFile) {
+	for i := range tf.Tasks {
+		taskPathRules(tf, &tf.Tasks[i])
+	}
+}
+
+func taskPathRules(tf *task.TaskFile, t *task.Task) {
+	t.DenyPaths = unionStrings(tf.DenyPaths, t.DenyPaths)
+	if len(t.AllowPaths) == 0 && len(tf.AllowPaths) > 0 {
+		t.AllowPaths = append([]string(nil), tf.AllowPaths...)
+	}
+	if t.PathPolicy == "" {
+		t.PathPolicy = tf.PathPolicy
+	}
+	if t.Handoff == nil {
+		t.Handoff = tf.Handoff
+	}
+}
+
+// ApplyFileRules applies tf's defaults and path rules to a task added after
+// loading, the same way they were applied to the file's own tasks.
+func ApplyFileRules(tf *task.TaskFile, t *task.Task) {
+	taskDefaults(tf, t)
+	taskPathRules(tf, t)
+}
+
+// unionStrings returns a followed by the elements of b not in a.
+func unionStrings(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	out := append([]string(nil), a...)
+	for _, s := range b {
+		if !containsString(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// mergeIncluded appends an included file's tasks to tf and merges its
+// settings. Runner profiles must agree; other settings fill gaps only.
+func mergeIncluded(tf, inc *task.TaskFile, runnerSources map[string]string, incPath string) error {
+	tf.Tasks = append(tf.Tasks, inc.Tasks...)
+
+	names := make([]string, 0, len(inc.Runners))
+	for name := range inc.Runners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := inc.Runners[name]
+		if prev, exists := tf.Runners[name]; exists {
+			if diff := runnerProfileDiff(prev, profile); len(diff) > 0 {
+				return fmt.Errorf("runner profile %q conflicts between %s and %s: %s",
+					name, runnerSources[name], incPath, strings.Join(diff, ", "))
+			}
+			continue
+		}
+		if tf.Runners == nil {
+			tf.Runners = make(map[string]*task.RunnerProfileConfig)
+		}
+		tf.Runners[name] = profile
+		runnerSources[name] = incPath
+	}
+
+	for _, r := range inc.AllowedRepos {
+		if !containsString(tf.AllowedRepos, r) {
+			tf.AllowedRepos = append(tf.AllowedRepos, r)
+		}
+	}
+	if tf.DefaultRunner == "" {
+		tf.DefaultRunner = inc.DefaultRunner
+	}
+	if len(tf.DefaultFallbacks) == 0 {
+		tf.DefaultFallbacks = inc.DefaultFallbacks
+	}
+	if tf.Review == nil {
+		tf.Review = inc.Review
+	}
+	return nil
+}
+
+// runnerProfileDiff lists the keys on which two runner profiles differ,
+// e.g. `model ("a" vs "b")` or `env.API_KEY`. Only type, model, profile and
+// env are compared; the first file's cascade hints (tier, free, ...) win.
+// Env values are not printed since they may hold credentials. An empty
+// result means the profiles agree.
+func runnerProfileDiff(a, b *task.RunnerProfileConfig) []string {
+	var diff []string
+	str := func(key, x, y string) {
+		if x != y {
+			diff = append(diff, fmt.Sprintf("%s (%q vs %q)", key, x, y))
+		}
+	}
+	str("type", a.Type, b.Type)
+	str("model", a.Model, b.Model)
+	str("profile", a.Profile, b.Profile)
+
+	keys := make(map[string]struct{}, len(a.Env)+len(b.Env))
+	for k := range a.Env {
+		keys[k] = struct{}{}
+	}
+	for k := range b.Env {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		av, aok := a.Env[k]
+		bv, bok := b.Env[k]
+		if aok != bok || av != bv {
+			diff = append(diff, "env."+k)
+		}
+	}
+	return diff
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
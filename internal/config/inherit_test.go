@@ -0,0 +1,245 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func writeTaskFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_Extends(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"base.json": `{
+			"default_runner": "deep",
+			"default_fallbacks": ["claude"],
+			"runners": {
+				"deep": {"type": "codex", "model": "deepseek-chat"},
+				"fast": {"type": "codex", "model": "o4-mini"}
+			},
+			"review": {"enabled": true},
+			"defaults": {"difficulty": "simple", "fallbacks": ["fast"]}
+		}`,
+		"pack.json": `{
+			"extends": "base.json",
+			"runners": {"fast": {"type": "claude"}},
+			"defaults": {"runner": "fast"},
+			"tasks": [
+				{"id": "a", "repo": "org/r", "prompt": "p"},
+				{"id": "b", "repo": "org/r", "prompt": "p", "runner": "deep", "difficulty": "complex"}
+			]
+		}`,
+	})
+
+	tf, err := Load(filepath.Join(dir, "pack.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if tf.DefaultRunner != "deep" || len(tf.DefaultFallbacks) != 1 || tf.Review == nil {
+		t.Errorf("base settings not inherited: %+v", tf)
+	}
+	if tf.Runners["fast"].Type != "claude" {
+		t.Errorf("child profile should override base, got %+v", tf.Runners["fast"])
+	}
+	if tf.Runners["deep"] == nil {
+		t.Error("base profile deep not inherited")
+	}
+
+	a, b := tf.Tasks[0], tf.Tasks[1]
+	if a.Runner != "fast" || a.Difficulty != "simple" || len(a.Fallbacks) != 1 || a.Fallbacks[0] != "fast" {
+		t.Errorf("defaults not applied to a: %+v", a)
+	}
+	if b.Runner != "deep" || b.Difficulty != "complex" {
+		t.Errorf("explicit task values should win: %+v", b)
+	}
+	if len(tf.Tasks) != 2 {
+		t.Errorf("tasks = %d, want 2", len(tf.Tasks))
+	}
+}
+
+func TestLoad_PathRules(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"base.json": `{"deny_paths": ["LICENSE"], "path_policy": "revert", "handoff": {"enabled": true, "max_bytes": 2000}}`,
+		"pack.json": `{
+			"extends": "base.json",
+			"allow_paths": ["src/**"],
+			"deny_paths": ["go.sum"],
+			"tasks": [
+				{"id": "a", "repo": "org/r", "prompt": "p"},
+				{"id": "b", "repo": "org/r", "prompt": "p", "allow_paths": ["docs/"], "deny_paths": ["go.sum", "Makefile"], "path_policy": "fail", "handoff": {"enabled": false}}
+			]
+		}`,
+	})
+
+	tf, err := Load(filepath.Join(dir, "pack.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	a, b := tf.Tasks[0], tf.Tasks[1]
+	if strings.Join(a.DenyPaths, ",") != "LICENSE,go.sum" || strings.Join(a.AllowPaths, ",") != "src/**" || a.PathPolicy != "revert" {
+		t.Errorf("file rules not applied to a: %+v", a)
+	}
+	if strings.Join(b.DenyPaths, ",") != "LICENSE,go.sum,Makefile" || strings.Join(b.AllowPaths, ",") != "docs/" || b.PathPolicy != "fail" {
+		t.Errorf("task rules should extend deny and keep own allow/policy: %+v", b)
+	}
+	if a.Handoff == nil || !a.Handoff.Enabled || a.Handoff.Limit() != 2000 || b.Handoff == nil || b.Handoff.Enabled {
+		t.Errorf("handoff a = %+v, b = %+v", a.Handoff, b.Handoff)
+	}
+
+	// tasks added after loading get the same rules
+	added := task.Task{ID: "c", Repo: "org/r", Prompt: "p"}
+	ApplyFileRules(tf, &added)
+	if strings.Join(added.DenyPaths, ",") != "LICENSE,go.sum" || strings.Join(added.AllowPaths, ",") != "src/**" || added.PathPolicy != "revert" || added.Handoff == nil {
+		t.Errorf("file rules not applied to added task: %+v", added)
+	}
+
+	bad := writeTaskFiles(t, map[string]string{
+		"bad.json": `{"tasks": [{"id": "a", "repo": "org/r", "prompt": "p", "path_policy": "ignore"}]}`,
+	})
+	if _, err := Load(filepath.Join(bad, "bad.json")); err == nil || !strings.Contains(err.Error(), `unknown path_policy "ignore"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLoad_Include(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"main.json": `{
+			"include": ["packs/extra.json"],
+			"runners": {"deep": {"type": "codex", "model": "deepseek-chat"}},
+			"tasks": [{"id": "a", "repo": "org/r", "prompt": "p"}]
+		}`,
+		"packs/extra.json": `{
+			"runners": {"deep": {"type": "codex", "model": "deepseek-chat"}},
+			"defaults": {"runner": "deep"},
+			"tasks": [{"id": "b", "repo": "org/r", "prompt": "p", "depends_on": "a"}]
+		}`,
+	})
+
+	tf, err := Load(filepath.Join(dir, "main.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(tf.Tasks) != 2 {
+		t.Fatalf("tasks = %d, want 2", len(tf.Tasks))
+	}
+	if tf.Tasks[0].Runner != "" {
+		t.Errorf("included defaults leaked to parent task: %q", tf.Tasks[0].Runner)
+	}
+	if tf.Tasks[1].Runner != "deep" {
+		t.Errorf("included task runner = %q, want deep", tf.Tasks[1].Runner)
+	}
+	if !strings.HasSuffix(tf.Tasks[1].SourceFile, filepath.Join("packs", "extra.json")) {
+		t.Errorf("included task SourceFile = %q", tf.Tasks[1].SourceFile)
+	}
+}
+
+func TestLoad_IncludeConflict(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"main.json": `{
+			"include": ["extra.json"],
+			"runners": {"deep": {"type": "codex", "model": "a", "env": {"K": "1", "SAME": "x"}}},
+			"tasks": [{"id": "a", "repo": "org/r", "prompt": "p"}]
+		}`,
+		"extra.json": `{
+			"runners": {"deep": {"type": "codex", "model": "b", "env": {"SAME": "x"}}},
+			"tasks": [{"id": "b", "repo": "org/r", "prompt": "p"}]
+		}`,
+	})
+
+	_, err := Load(filepath.Join(dir, "main.json"))
+	if err == nil {
+		t.Fatal("expected conflict error")
+	}
+	msg := err.Error()
+	for _, want := range []string{`runner profile "deep" conflicts`, `model ("a" vs "b")`, "env.K"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q missing %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "env.SAME") || strings.Contains(msg, "type") {
+		t.Errorf("error lists keys that agree: %q", msg)
+	}
+}
+
+func TestLoad_IncludeCycle(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"a.json": `{"include": ["b.json"], "tasks": [{"id": "a", "repo": "org/r", "prompt": "p"}]}`,
+		"b.json": `{"extends": "a.json", "tasks": [{"id": "b", "repo": "org/r", "prompt": "p"}]}`,
+	})
+
+	_, err := Load(filepath.Join(dir, "a.json"))
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+}
+
+func TestLoad_IncludeDuplicateID(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"a.json": `{"include": ["b.json"], "tasks": [{"id": "x", "repo": "org/r", "prompt": "p"}]}`,
+		"b.json": `{"tasks": [{"id": "x", "repo": "org/r", "prompt": "p"}]}`,
+	})
+
+	_, err := Load(filepath.Join(dir, "a.json"))
+	if err == nil || !strings.Contains(err.Error(), "duplicate task id") {
+		t.Fatalf("expected duplicate id error, got %v", err)
+	}
+}
+
+func TestLoadMulti_SharedBase(t *testing.T) {
+	base := `{
+		"default_runner": "deep",
+		"runners": {"deep": {"type": "codex", "model": "deepseek-chat"}}
+	}`
+	dir := writeTaskFiles(t, map[string]string{
+		"base.json":  base,
+		"pack1.json": `{"extends": "base.json", "tasks": [{"id": "a", "repo": "org/r", "prompt": "p"}]}`,
+		"pack2.json": `{"extends": "base.json", "tasks": [{"id": "b", "repo": "org/r", "prompt": "p"}]}`,
+	})
+
+	paths, err := ResolveGlob(filepath.Join(dir, "pack*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := LoadMulti(paths)
+	if err != nil {
+		t.Fatalf("LoadMulti: %v", err)
+	}
+	merged, err := MergeTaskFiles(files)
+	if err != nil {
+		t.Fatalf("MergeTaskFiles: %v", err)
+	}
+	if merged.DefaultRunner != "deep" || len(merged.Tasks) != 2 {
+		t.Errorf("merged = %+v", merged)
+	}
+}
+
+func TestRunnerProfileDiff(t *testing.T) {
+	a := &task.RunnerProfileConfig{Type: "codex", Tier: 1, Env: map[string]string{"A": "1"}}
+	b := &task.RunnerProfileConfig{Type: "claude", Tier: 2, Free: true, Env: map[string]string{"B": "1"}}
+
+	// cascade hints such as tier and free are not conflicts
+	got := strings.Join(runnerProfileDiff(a, b), ", ")
+	want := `type ("codex" vs "claude"), env.A, env.B`
+	if got != want {
+		t.Errorf("diff = %q, want %q", got, want)
+	}
+	if diff := runnerProfileDiff(a, a); len(diff) != 0 {
+		t.Errorf("identical profiles diff = %v", diff)
+	}
+}
@@ -8,48 +8,58 @@ import (
 	"sort"
 	"strings"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/ppiankov/tokencontrol/internal/task"
 )
 
-// Load reads and validates a tokencontrol task file.
-func Load(path string) (*task.TaskFile, error) {
-	data, err := os.ReadFile(path)
+// decodeTaskFile parses JSON or, for .yaml/.yml paths, YAML task files.
+// YAML is converted to JSON first so both formats share the json tags and
+// custom unmarshalers on task.TaskFile.
+func decodeTaskFile(path string, data []byte, tf *task.TaskFile) error {
+	if !isYAMLPath(path) {
+		return json.Unmarshal(data, tf)
+	}
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	converted, err := json.Marshal(raw)
 	if err != nil {
-		return nil, fmt.Errorf("read tasks file: %w", err)
+		return fmt.Errorf("convert yaml: %w", err)
 	}
+	return json.Unmarshal(converted, tf)
+}
 
-	var tf task.TaskFile
-	if err := json.Unmarshal(data, &tf); err != nil {
-		return nil, fmt.Errorf("parse tasks file: %w", err)
+// Load reads and validates a tokencontrol task file (JSON or YAML),
+// resolving its extends and include references.
+func Load(path string) (*task.TaskFile, error) {
+	tf, err := resolveTaskFile(path, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := validate(&tf); err != nil {
+	if err := validate(tf); err != nil {
 		return nil, err
 	}
 
 	for i := range tf.Tasks {
-		tf.Tasks[i].SourceFile = path
 		tf.Tasks[i].Runner = normalizeRunner(tf.Tasks[i].Runner)
 	}
 
-	return &tf, nil
+	return tf, nil
 }
 
 // loadRaw reads and structurally validates a task file without checking
 // dependency references. Used by LoadMulti where cross-file deps are
 // validated after merging.
 func loadRaw(path string) (*task.TaskFile, error) {
-	data, err := os.ReadFile(path)
+	tf, err := resolveTaskFile(path, nil)
 	if err != nil {
-		return nil, fmt.Errorf("read tasks file: %w", err)
-	}
-
-	var tf task.TaskFile
-	if err := json.Unmarshal(data, &tf); err != nil {
-		return nil, fmt.Errorf("parse tasks file: %w", err)
+		return nil, err
 	}
 
-	if err := validateStructure(&tf); err != nil {
+	if err := validateStructure(tf); err != nil {
 		return nil, err
 	}
 
@@ -57,11 +67,7 @@ func loadRaw(path string) (*task.TaskFile, error) {
 		tf.Tasks[i].Runner = normalizeRunner(tf.Tasks[i].Runner)
 	}
 
-	for i := range tf.Tasks {
-		tf.Tasks[i].SourceFile = path
-	}
-
-	return &tf, nil
+	return tf, nil
 }
 
 // ResolveGlob expands a --tasks argument into concrete file paths.
@@ -114,6 +120,48 @@ func LoadMulti(paths []string) ([]*task.TaskFile, error) {
 	return files, nil
 }
 
+// ApplyOverlay merges the overlay file written by the run-time task editor
+// into tf: tasks with a known ID replace the original, keeping its source
+// file, and the rest are appended. Runner names are not checked here since
+// they may refer to profiles defined in the original files.
+func ApplyOverlay(tf *task.TaskFile, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read overlay: %w", err)
+	}
+	var overlay task.TaskFile
+	if err := decodeTaskFile(path, data, &overlay); err != nil {
+		return fmt.Errorf("parse overlay %s: %w", path, err)
+	}
+
+	index := make(map[string]int, len(tf.Tasks))
+	for i, t := range tf.Tasks {
+		index[t.ID] = i
+	}
+	for _, t := range overlay.Tasks {
+		if t.ID == "" || t.Repo == "" || t.Prompt == "" {
+			return fmt.Errorf("overlay %s: task %q is missing id, repo or prompt", path, t.ID)
+		}
+		if i, ok := index[t.ID]; ok {
+			t.SourceFile = tf.Tasks[i].SourceFile
+			tf.Tasks[i] = t
+			continue
+		}
+		t.SourceFile = path
+		index[t.ID] = len(tf.Tasks)
+		tf.Tasks = append(tf.Tasks, t)
+	}
+
+	for _, t := range tf.Tasks {
+		for _, dep := range t.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("overlay %s: task %q depends on unknown task %q", path, t.ID, dep)
+			}
+		}
+	}
+	return nil
+}
+
 // MergeTaskFiles combines multiple task files into a single TaskFile.
 // It validates that task IDs are unique across files, runner profiles
 // don't conflict, and all dependency references resolve.
@@ -151,8 +199,9 @@ func MergeTaskFiles(files []*task.TaskFile) (*task.TaskFile, error) {
 		// merge runner profiles — conflict = error
 		for name, profile := range tf.Runners {
 			if prevFile, exists := runnerSources[name]; exists {
-				if !runnerProfilesEqual(merged.Runners[name], profile) {
-					return nil, fmt.Errorf("runner profile %q conflicts between %s and %s", name, prevFile, src)
+				if diff := runnerProfileDiff(merged.Runners[name], profile); len(diff) > 0 {
+					return nil, fmt.Errorf("runner profile %q conflicts between %s and %s: %s",
+						name, prevFile, src, strings.Join(diff, ", "))
 				}
 				continue
 			}
@@ -199,21 +248,6 @@ func sourceLabel(tf *task.TaskFile) string {
 	return "<unknown>"
 }
 
-func runnerProfilesEqual(a, b *task.RunnerProfileConfig) bool {
-	if a.Type != b.Type || a.Model != b.Model || a.Profile != b.Profile {
-		return false
-	}
-	if len(a.Env) != len(b.Env) {
-		return false
-	}
-	for k, v := range a.Env {
-		if b.Env[k] != v {
-			return false
-		}
-	}
-	return true
-}
-
 // validate checks structure, duplicate IDs, dependency references, and runner profiles.
 func validate(tf *task.TaskFile) error {
 	if err := validateStructure(tf); err != nil {
@@ -243,6 +277,11 @@ func validateStructure(tf *task.TaskFile) error {
 		if _, dup := ids[t.ID]; dup {
 			return fmt.Errorf("duplicate task id: %q", t.ID)
 		}
+		switch t.PathPolicy {
+		case "", task.PathPolicyFail, task.PathPolicyRevert:
+		default:
+			return fmt.Errorf("task %q has unknown path_policy %q (want fail or revert)", t.ID, t.PathPolicy)
+		}
 		ids[t.ID] = struct{}{}
 	}
 
@@ -460,6 +460,9 @@ func TestMergeTaskFiles_ConflictingRunnerProfile(t *testing.T) {
 	if !strings.Contains(err.Error(), "conflicts") {
 		t.Errorf("expected 'conflicts' error, got: %v", err)
 	}
+	if !strings.Contains(err.Error(), `model ("deepseek-chat" vs "different-model")`) {
+		t.Errorf("expected differing key in error, got: %v", err)
+	}
 }
 
 func TestMergeTaskFiles_IdenticalRunnerProfileOK(t *testing.T) {
@@ -485,6 +488,33 @@ func TestMergeTaskFiles_IdenticalRunnerProfileOK(t *testing.T) {
 	}
 }
 
+func TestMergeTaskFiles_CascadeHintsNotConflicts(t *testing.T) {
+	tf1 := &task.TaskFile{
+		Tasks: []task.Task{
+			{ID: "t1", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a", SourceFile: "a.json"},
+		},
+		Runners: map[string]*task.RunnerProfileConfig{
+			"deepseek": {Type: "codex", Profile: "ds", Tier: 1, Free: true},
+		},
+	}
+	tf2 := &task.TaskFile{
+		Tasks: []task.Task{
+			{ID: "t2", Repo: "org/r", Priority: 1, Title: "B", Prompt: "b", SourceFile: "b.json"},
+		},
+		Runners: map[string]*task.RunnerProfileConfig{
+			"deepseek": {Type: "codex", Profile: "ds", Tier: 2, FallbackOnly: true, DataCollection: true},
+		},
+	}
+
+	merged, err := MergeTaskFiles([]*task.TaskFile{tf1, tf2})
+	if err != nil {
+		t.Fatalf("profiles differing only in cascade hints should merge, got: %v", err)
+	}
+	if p := merged.Runners["deepseek"]; p.Tier != 1 || !p.Free || p.FallbackOnly {
+		t.Errorf("merged profile = %+v, want the first file's", p)
+	}
+}
+
 func TestMergeTaskFiles_CrossFileDependency(t *testing.T) {
 	tf1 := &task.TaskFile{
 		Tasks: []task.Task{
@@ -556,3 +586,37 @@ func loadTestTasks(t *testing.T, data string) *task.TaskFile {
 	}
 	return tf
 }
+
+func TestApplyOverlay(t *testing.T) {
+	tf := &task.TaskFile{Tasks: []task.Task{
+		{ID: "t1", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a", SourceFile: "a.json"},
+		{ID: "t2", Repo: "org/r", Priority: 1, Title: "B", Prompt: "b", SourceFile: "a.json"},
+	}}
+	path := filepath.Join(t.TempDir(), "tasks-overlay.json")
+	overlay := `{"tasks": [
+		{"id": "t2", "repo": "org/r", "priority": 0, "title": "B", "prompt": "edited", "runner": "custom"},
+		{"id": "adhoc-1", "repo": "org/r", "priority": 1, "title": "New", "prompt": "new", "depends_on": ["t1"]}
+	]}`
+	if err := os.WriteFile(path, []byte(overlay), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ApplyOverlay(tf, path); err != nil {
+		t.Fatalf("ApplyOverlay: %v", err)
+	}
+	if len(tf.Tasks) != 3 {
+		t.Fatalf("tasks = %d, want 3", len(tf.Tasks))
+	}
+	if got := tf.Tasks[1]; got.Prompt != "edited" || got.Runner != "custom" || got.SourceFile != "a.json" {
+		t.Errorf("replaced task = %+v", got)
+	}
+	if got := tf.Tasks[2]; got.ID != "adhoc-1" || got.SourceFile != path {
+		t.Errorf("added task = %+v", got)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	_ = os.WriteFile(bad, []byte(`{"tasks": [{"id": "x", "repo": "org/r", "prompt": "p", "depends_on": ["missing"]}]}`), 0o644)
+	if err := ApplyOverlay(tf, bad); err == nil {
+		t.Error("expected error for unknown dependency")
+	}
+}
@@ -0,0 +1,446 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed taskfile.schema.json
+var taskFileSchema []byte
+
+var (
+	schemaOnce sync.Once
+	schemaRoot map[string]any
+	schemaErr  error
+)
+
+// TaskFileSchema returns the JSON Schema (draft 2020-12) describing task files.
+func TaskFileSchema() []byte {
+	out := make([]byte, len(taskFileSchema))
+	copy(out, taskFileSchema)
+	return out
+}
+
+func loadSchema() (map[string]any, error) {
+	schemaOnce.Do(func() {
+		schemaErr = json.Unmarshal(taskFileSchema, &schemaRoot)
+	})
+	return schemaRoot, schemaErr
+}
+
+// SchemaError is a schema or syntax violation at a position in a task file.
+// Line and Column are 1-based; zero means the position is unknown.
+type SchemaError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string // JSON path of the offending value, e.g. "tasks[3].runner"
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ":%d", e.Column)
+		}
+	}
+	b.WriteString(": ")
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// isYAMLPath reports whether a task file should be parsed as YAML.
+func isYAMLPath(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".yaml") || strings.HasSuffix(lower, ".yml")
+}
+
+// ValidateSchema checks a task file against the task file schema and returns
+// every violation with its position. The format is chosen by file extension.
+func ValidateSchema(path string, data []byte) []SchemaError {
+	root, err := loadSchema()
+	if err != nil {
+		return []SchemaError{{File: path, Message: fmt.Sprintf("load schema: %v", err)}}
+	}
+
+	var node *yaml.Node
+	if isYAMLPath(path) {
+		node, err = parseYAMLNode(data)
+	} else {
+		node, err = parseJSONNode(data)
+	}
+	if err != nil {
+		var se SchemaError
+		if errors.As(err, &se) {
+			se.File = path
+			return []SchemaError{se}
+		}
+		return []SchemaError{{File: path, Message: err.Error()}}
+	}
+
+	v := &schemaValidator{root: root, file: path}
+	v.validate(node, root, "")
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].Line != v.errs[j].Line {
+			return v.errs[i].Line < v.errs[j].Line
+		}
+		return v.errs[i].Column < v.errs[j].Column
+	})
+	return v.errs
+}
+
+var yamlLineRe = regexp.MustCompile(`line (\d+)`)
+
+func parseYAMLNode(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		se := SchemaError{Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			se.Line, _ = strconv.Atoi(m[1])
+		}
+		return nil, se
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, SchemaError{Line: 1, Column: 1, Message: "empty document"}
+	}
+	return doc.Content[0], nil
+}
+
+// parseJSONNode builds a yaml.Node tree from JSON so JSON and YAML files
+// share one validator while keeping exact line/column positions.
+func parseJSONNode(data []byte) (*yaml.Node, error) {
+	p := &jsonNodeParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+	tok, start, err := p.next()
+	if err != nil {
+		if err == io.EOF {
+			return nil, SchemaError{Line: 1, Column: 1, Message: "empty document"}
+		}
+		return nil, p.syntaxError(err)
+	}
+	node, err := p.value(tok, start)
+	if err != nil {
+		return nil, p.syntaxError(err)
+	}
+	if _, start, err := p.next(); err != io.EOF {
+		line, col := p.position(start)
+		return nil, SchemaError{Line: line, Column: col, Message: "unexpected data after top-level value"}
+	}
+	return node, nil
+}
+
+type jsonNodeParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// next returns the next token and the byte offset where it starts.
+func (p *jsonNodeParser) next() (json.Token, int, error) {
+	start := int(p.dec.InputOffset())
+	for start < len(p.data) {
+		switch p.data[start] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			start++
+			continue
+		}
+		break
+	}
+	tok, err := p.dec.Token()
+	return tok, start, err
+}
+
+func (p *jsonNodeParser) position(offset int) (line, col int) {
+	if offset > len(p.data) {
+		offset = len(p.data)
+	}
+	line = 1 + bytes.Count(p.data[:offset], []byte("\n"))
+	col = offset - bytes.LastIndexByte(p.data[:offset], '\n')
+	return line, col
+}
+
+func (p *jsonNodeParser) syntaxError(err error) error {
+	var syn *json.SyntaxError
+	if errors.As(err, &syn) {
+		line, col := p.position(int(syn.Offset))
+		return SchemaError{Line: line, Column: col, Message: syn.Error()}
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		line, col := p.position(len(p.data))
+		return SchemaError{Line: line, Column: col, Message: "unexpected end of JSON input"}
+	}
+	return err
+}
+
+func (p *jsonNodeParser) value(tok json.Token, start int) (*yaml.Node, error) {
+	line, col := p.position(start)
+	n := &yaml.Node{Line: line, Column: col}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n.Kind = yaml.MappingNode
+			n.Tag = "!!map"
+			for {
+				keyTok, keyStart, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				if d, ok := keyTok.(json.Delim); ok && d == '}' {
+					return n, nil
+				}
+				key, err := p.value(keyTok, keyStart)
+				if err != nil {
+					return nil, err
+				}
+				valTok, valStart, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				val, err := p.value(valTok, valStart)
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, key, val)
+			}
+		case '[':
+			n.Kind = yaml.SequenceNode
+			n.Tag = "!!seq"
+			for {
+				itemTok, itemStart, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				if d, ok := itemTok.(json.Delim); ok && d == ']' {
+					return n, nil
+				}
+				item, err := p.value(itemTok, itemStart)
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, item)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q", rune(t))
+		}
+	case string:
+		n.Kind, n.Tag, n.Value = yaml.ScalarNode, "!!str", t
+	case json.Number:
+		n.Kind, n.Value = yaml.ScalarNode, t.String()
+		n.Tag = "!!int"
+		if strings.ContainsAny(n.Value, ".eE") {
+			n.Tag = "!!float"
+		}
+	case bool:
+		n.Kind, n.Tag, n.Value = yaml.ScalarNode, "!!bool", strconv.FormatBool(t)
+	case nil:
+		n.Kind, n.Tag, n.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return n, nil
+}
+
+// schemaValidator checks a node tree against the subset of JSON Schema used
+// by taskfile.schema.json: type, properties, required, additionalProperties,
+// items, minItems, enum, minLength, minimum, maximum, oneOf and local $ref.
+type schemaValidator struct {
+	root map[string]any
+	file string
+	errs []SchemaError
+}
+
+func (v *schemaValidator) fail(n *yaml.Node, path, format string, args ...any) {
+	v.errs = append(v.errs, SchemaError{
+		File:    v.file,
+		Line:    n.Line,
+		Column:  n.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) resolve(s map[string]any) map[string]any {
+	ref, ok := s["$ref"].(string)
+	if !ok {
+		return s
+	}
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	defs, _ := v.root["$defs"].(map[string]any)
+	if def, ok := defs[name].(map[string]any); ok {
+		return def
+	}
+	return s
+}
+
+// nodeType maps a YAML node to its JSON Schema type name.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case "!!int":
+			return "integer"
+		case "!!float":
+			return "number"
+		case "!!bool":
+			return "boolean"
+		case "!!null":
+			return "null"
+		default:
+			return "string"
+		}
+	}
+	return "unknown"
+}
+
+func typeMatches(want, got string) bool {
+	return want == got || (want == "number" && got == "integer")
+}
+
+func (v *schemaValidator) validate(n *yaml.Node, s map[string]any, path string) {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	s = v.resolve(s)
+
+	if branches, ok := s["oneOf"].([]any); ok {
+		matched := 0
+		var types []string
+		for _, b := range branches {
+			bs, ok := b.(map[string]any)
+			if !ok {
+				continue
+			}
+			bs = v.resolve(bs)
+			if t, ok := bs["type"].(string); ok {
+				types = append(types, t)
+			}
+			sub := &schemaValidator{root: v.root, file: v.file}
+			sub.validate(n, bs, path)
+			if len(sub.errs) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(n, path, "expected %s, got %s", strings.Join(types, " or "), nodeType(n))
+			return
+		}
+	}
+
+	got := nodeType(n)
+	if want, ok := s["type"].(string); ok && !typeMatches(want, got) {
+		v.fail(n, path, "expected %s, got %s", want, got)
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		var allowed []string
+		for _, e := range enum {
+			es := fmt.Sprint(e)
+			if es != "" {
+				allowed = append(allowed, strconv.Quote(es))
+			}
+			if es == n.Value {
+				found = true
+			}
+		}
+		if !found {
+			v.fail(n, path, "invalid value %q (allowed: %s)", n.Value, strings.Join(allowed, ", "))
+		}
+	}
+
+	switch got {
+	case "string":
+		if min, ok := s["minLength"].(float64); ok && len(n.Value) < int(min) {
+			if min == 1 {
+				v.fail(n, path, "must not be empty")
+			} else {
+				v.fail(n, path, "must be at least %d characters", int(min))
+			}
+		}
+	case "integer", "number":
+		f, err := strconv.ParseFloat(n.Value, 64)
+		if err == nil {
+			if min, ok := s["minimum"].(float64); ok && f < min {
+				v.fail(n, path, "must be >= %v", min)
+			}
+			if max, ok := s["maximum"].(float64); ok && f > max {
+				v.fail(n, path, "must be <= %v", max)
+			}
+		}
+	case "object":
+		v.validateObject(n, s, path)
+	case "array":
+		if min, ok := s["minItems"].(float64); ok && len(n.Content) < int(min) {
+			v.fail(n, path, "must contain at least %d item(s)", int(min))
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range n.Content {
+				v.validate(item, items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(n *yaml.Node, s map[string]any, path string) {
+	props, _ := s["properties"].(map[string]any)
+	seen := make(map[string]bool, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		childPath := joinPath(path, key.Value)
+		if seen[key.Value] {
+			v.fail(key, childPath, "duplicate property %q", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+
+		if ps, ok := props[key.Value].(map[string]any); ok {
+			v.validate(val, ps, childPath)
+			continue
+		}
+		switch ap := s["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				v.fail(key, childPath, "unknown property %q", key.Value)
+			}
+		case map[string]any:
+			v.validate(val, ap, childPath)
+		}
+	}
+
+	if req, ok := s["required"].([]any); ok {
+		for _, r := range req {
+			name, _ := r.(string)
+			if !seen[name] {
+				v.fail(n, path, "missing required property %q", name)
+			}
+		}
+	}
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
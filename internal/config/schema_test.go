@@ -0,0 +1,168 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func TestTaskFileSchema_CoversModelFields(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(TaskFileSchema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	defs := schema["$defs"].(map[string]any)
+
+	check := func(name string, typ reflect.Type, props map[string]any) {
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			if _, ok := props[tag]; !ok {
+				t.Errorf("%s: field %q missing from schema", name, tag)
+			}
+		}
+	}
+	check("TaskFile", reflect.TypeOf(task.TaskFile{}), schema["properties"].(map[string]any))
+	for name, typ := range map[string]reflect.Type{
+		"task":          reflect.TypeOf(task.Task{}),
+		"runnerProfile": reflect.TypeOf(task.RunnerProfileConfig{}),
+		"review":        reflect.TypeOf(task.ReviewConfig{}),
+	} {
+		def := defs[name].(map[string]any)
+		check(name, typ, def["properties"].(map[string]any))
+	}
+}
+
+func TestValidateSchema_ValidJSON(t *testing.T) {
+	data := `{
+	"$schema": "./taskfile.schema.json",
+	"runners": {"zai": {"type": "codex", "env": {"KEY": "env:ZAI_KEY"}, "tier": 2}},
+	"review": {"enabled": true},
+	"tasks": [
+		{"id": "t1", "repo": "org/r", "priority": 1, "title": "T1", "prompt": "p"},
+		{"id": "t2", "repo": "org/r", "priority": 2, "depends_on": "t1", "title": "T2", "prompt": "p", "difficulty": "simple"},
+		{"id": "t3", "repo": "org/r", "priority": 2, "depends_on": ["t1", "t2"], "title": "T3", "prompt": "p"}
+	]
+}`
+	if errs := ValidateSchema("tasks.json", []byte(data)); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateSchema_ReportsPositions(t *testing.T) {
+	data := `{
+  "tasks": [
+    {"id": "t1", "repo": "org/r", "prompt": "p", "priority": "high"},
+    {"id": "t2", "repo": "", "prompt": "p", "depend_on": "t1"},
+    {"id": "t3", "repo": "org/r", "prompt": "p", "depends_on": 7, "difficulty": "hard"}
+  ],
+  "runners": {"x": {"model": "m"}}
+}`
+	errs := ValidateSchema("pack.json", []byte(data))
+	want := []string{
+		`pack.json:3:62: tasks[0].priority: expected integer, got string`,
+		`pack.json:4:26: tasks[1].repo: must not be empty`,
+		`pack.json:4:45: tasks[1].depend_on: unknown property "depend_on"`,
+		`pack.json:5:64: tasks[2].depends_on: expected string or array, got integer`,
+		`pack.json:5:81: tasks[2].difficulty: invalid value "hard" (allowed: "simple", "medium", "complex")`,
+		`pack.json:7:20: runners.x: missing required property "type"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %d:\n%v", len(want), len(errs), errs)
+	}
+	for i := range want {
+		if got := errs[i].Error(); got != want[i] {
+			t.Errorf("error %d:\n got  %s\n want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestValidateSchema_JSONSyntaxError(t *testing.T) {
+	data := "{\n  \"tasks\": [\n    {\"id\": \"t1\" \"repo\": \"org/r\"}\n  ]\n}"
+	errs := ValidateSchema("bad.json", []byte(data))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if errs[0].Line != 3 || errs[0].Column == 0 {
+		t.Errorf("expected error on line 3 with a column, got %d:%d (%s)", errs[0].Line, errs[0].Column, errs[0].Message)
+	}
+}
+
+func TestValidateSchema_YAML(t *testing.T) {
+	data := `default_runner: claude
+tasks:
+  - id: t1
+    repo: org/r
+    prompt: do it
+    priority: 1
+  - id: t2
+    repo: org/r
+    prompt: next
+    fallbacks: codex
+`
+	errs := ValidateSchema("tasks.yaml", []byte(data))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if got, want := errs[0].Error(), "tasks.yaml:10:16: tasks[1].fallbacks: expected array, got string"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestValidateSchema_YAMLSyntaxError(t *testing.T) {
+	errs := ValidateSchema("tasks.yml", []byte("tasks:\n  - id: t1\n   repo: [\n"))
+	if len(errs) != 1 || errs[0].Line == 0 {
+		t.Fatalf("expected one positioned syntax error, got %v", errs)
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.yml")
+	data := `runners:
+  zai:
+    type: codex
+    model: glm-4
+tasks:
+  - id: t1
+    repo: org/r
+    prompt: one
+  - id: t2
+    repo: org/r
+    prompt: two
+    depends_on: t1
+    runner: claude
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.Tasks) != 2 || tf.Tasks[1].DependsOn[0] != "t1" {
+		t.Fatalf("unexpected tasks: %+v", tf.Tasks)
+	}
+	if tf.Tasks[1].Runner != "claude" {
+		t.Errorf("runner not decoded, got %q", tf.Tasks[1].Runner)
+	}
+	if tf.Runners["zai"].Model != "glm-4" {
+		t.Errorf("runner profile not decoded: %+v", tf.Runners["zai"])
+	}
+	if tf.Tasks[0].SourceFile != path {
+		t.Errorf("source file not stamped: %q", tf.Tasks[0].SourceFile)
+	}
+
+	files, err := LoadMulti([]string{path})
+	if err != nil || len(files[0].Tasks) != 2 {
+		t.Fatalf("LoadMulti: %v", err)
+	}
+}
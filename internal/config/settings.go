@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/ppiankov/tokencontrol/internal/scan"
+	"github.com/ppiankov/tokencontrol/internal/task"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,6 +22,10 @@ type Settings struct {
 	Verify      bool          `yaml:"verify"`
 	PostRun     string        `yaml:"post_run"` // shell command to run after report is written; $RUNFORGE_RUN_DIR is set
 
+	// Keep runs alive across rate limits and resume at the reset time
+	WaitForReset bool          `yaml:"wait_for_reset,omitempty"`
+	MaxResetWait time.Duration `yaml:"max_reset_wait,omitempty"` // cap per pause; also used when the reset time is unknown
+
 	// Runner config injected into generated task files
 	DefaultRunner    string                    `yaml:"default_runner"`
 	DefaultFallbacks []string                  `yaml:"default_fallbacks"`
@@ -43,6 +50,28 @@ type Settings struct {
 	// Codex quota preflight guard before dispatch.
 	CodexQuota *CodexQuotaConfig `yaml:"codex_quota,omitempty"`
 
+	// Proactive spend limits keyed by provider ("anthropic") or runner profile ("codex").
+	Budgets map[string]*BudgetConfig `yaml:"budgets,omitempty"`
+
+	// Pre-emptive rate-limit pacing
+	Pacing *PacingConfig `yaml:"pacing,omitempty"`
+
+	// Pull request creation for `tokencontrol pr`
+	PR *PRConfig `yaml:"pr,omitempty"`
+
+	// Change-risk rules evaluated on each completed task's diff
+	Risk *RiskConfig `yaml:"risk,omitempty"`
+
+	// Restore the repo after failed attempts so fallbacks start clean
+	RollbackFailed *bool `yaml:"rollback_failed,omitempty"`  // nil=true
+	KeepFailedDiff *bool `yaml:"keep_failed_diff,omitempty"` // save rolled-back changes as failed.patch; nil=true
+
+	// Per-repo settings keyed by repo ("owner/name") as written in task files
+	Repos map[string]*RepoConfig `yaml:"repos,omitempty"`
+
+	// Follow-up tasks proposed by agents while the run is going
+	Followups *FollowupsConfig `yaml:"followups,omitempty"`
+
 	// Directory for agent-generated docs (gitignored); default "docs/tokencontrol"
 	DocsDir string `yaml:"docs_dir,omitempty"`
 }
@@ -64,9 +93,114 @@ type CodexQuotaConfig struct {
 	LookbackRuns    int     `yaml:"lookback_runs,omitempty"`
 }
 
+// BudgetConfig caps spend for a provider or runner profile per calendar
+// window (UTC). Zero values are unlimited.
+type BudgetConfig struct {
+	DailyUSD      float64 `yaml:"daily_usd,omitempty"`
+	WeeklyUSD     float64 `yaml:"weekly_usd,omitempty"`
+	MonthlyUSD    float64 `yaml:"monthly_usd,omitempty"`
+	DailyTokens   int     `yaml:"daily_tokens,omitempty"`
+	WeeklyTokens  int     `yaml:"weekly_tokens,omitempty"`
+	MonthlyTokens int     `yaml:"monthly_tokens,omitempty"`
+}
+
 // ScanConfig holds settings for the scan command.
 type ScanConfig struct {
-	ExcludeRepos []string `yaml:"exclude_repos,omitempty"`
+	ExcludeRepos []string    `yaml:"exclude_repos,omitempty"`
+	Rules        []scan.Rule `yaml:"rules,omitempty"`         // custom checks, see scan.Rule
+	RulesDir     string      `yaml:"rules_dir,omitempty"`     // rule packs; default scan/rules.d next to the config file
+	Baseline     string      `yaml:"baseline,omitempty"`      // known findings; default .tokencontrol-baseline.json next to the config file
+	Fix          bool        `yaml:"fix,omitempty"`           // sentinel loop applies and commits deterministic fixes
+	Workers      int         `yaml:"workers,omitempty"`       // repos scanned concurrently; default one per CPU
+	NoCache      bool        `yaml:"no_cache,omitempty"`      // re-check every repo instead of reusing cached results
+	Advisories   string      `yaml:"advisories,omitempty"`    // OSV advisory dir; default ~/.tokencontrol/advisories
+	HistoryDepth int         `yaml:"history_depth,omitempty"` // commits searched for committed secrets; default 200, -1 = off
+
+	Score *scan.ScoreWeights `yaml:"score,omitempty"` // health score weights for the portfolio command; unset keys keep defaults
+}
+
+// ScanWorkers returns the configured scan concurrency; 0 means one per CPU.
+func (c *ScanConfig) ScanWorkers() int {
+	if c == nil {
+		return 0
+	}
+	return c.Workers
+}
+
+// ScanHistoryDepth returns the configured history depth for the secret scan;
+// 0 means the default.
+func (c *ScanConfig) ScanHistoryDepth() int {
+	if c == nil {
+		return 0
+	}
+	return c.HistoryDepth
+}
+
+// ScoreWeights returns the health score weights, with defaults for any
+// severity or category not configured.
+func (c *ScanConfig) ScoreWeights() scan.ScoreWeights {
+	if c == nil || c.Score == nil {
+		return scan.DefaultScoreWeights()
+	}
+	return c.Score.WithDefaults()
+}
+
+// CachePath returns the scan result cache file, or "" when caching is off.
+func (c *ScanConfig) CachePath() string {
+	if c != nil && c.NoCache {
+		return ""
+	}
+	return scan.DefaultCachePath()
+}
+
+// AdvisoryDir returns the OSV advisory dir for sec-vulnerable-dependency,
+// resolving a relative path against the config file's directory.
+func (c *ScanConfig) AdvisoryDir(configPath string) string {
+	if c == nil || c.Advisories == "" {
+		return scan.DefaultAdvisoryDir()
+	}
+	if filepath.IsAbs(c.Advisories) {
+		return c.Advisories
+	}
+	return filepath.Join(filepath.Dir(configPath), c.Advisories)
+}
+
+// BaselinePath returns the scan baseline file, resolving a relative path
+// against the config file's directory.
+func (c *ScanConfig) BaselinePath(configPath string) string {
+	path := scan.BaselineFile
+	if c != nil && c.Baseline != "" {
+		path = c.Baseline
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	return path
+}
+
+// DefaultRulesDir is where rule packs are read from when rules_dir is unset,
+// relative to the config file's directory.
+const DefaultRulesDir = "scan/rules.d"
+
+// LoadRules returns the inline rules followed by the rule packs in the rules
+// dir. Relative rules dirs are resolved against the config file's directory.
+func (c *ScanConfig) LoadRules(configPath string) ([]scan.Rule, error) {
+	dir := DefaultRulesDir
+	var rules []scan.Rule
+	if c != nil {
+		rules = append(rules, c.Rules...)
+		if c.RulesDir != "" {
+			dir = c.RulesDir
+		}
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(configPath), dir)
+	}
+	packs, err := scan.LoadRuleDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, packs...), nil
 }
 
 // RunnerProfile mirrors task.RunnerProfileConfig for YAML config.
@@ -80,6 +214,70 @@ type RunnerProfile struct {
 	Free           bool              `yaml:"free,omitempty"`            // true = free-tier model, excluded from cascade by default
 	Tier           int               `yaml:"tier,omitempty"`            // 1=complex-capable, 2=medium, 3=simple-only; 0=use default
 	FallbackOnly   bool              `yaml:"fallback_only,omitempty"`   // true = never assign as primary via striping
+
+	// Provider-published rate limits; dispatch is paced to stay under them
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty"`
+	TokensPerMinute   int `yaml:"tokens_per_minute,omitempty"`
+}
+
+// PacingConfig controls pre-emptive dispatch pacing learned from telemetry.
+type PacingConfig struct {
+	Learn        *bool   `yaml:"learn,omitempty"`         // derive limits from past 429s; nil=true
+	SafetyFactor float64 `yaml:"safety_factor,omitempty"` // fraction of a learned limit to use; default 0.8
+}
+
+// PRConfig controls how `tokencontrol pr` reaches git hosting providers.
+type PRConfig struct {
+	Provider  string                    `yaml:"provider,omitempty"` // auto (default), api, cli
+	Labels    []string                  `yaml:"labels,omitempty"`
+	Reviewers []string                  `yaml:"reviewers,omitempty"`
+	Hosts     map[string]*GitHostConfig `yaml:"hosts,omitempty"` // keyed by remote host, e.g. "git.example.com"
+}
+
+// RiskConfig controls change-risk analysis of completed tasks.
+type RiskConfig struct {
+	DenyPaths []string        `yaml:"deny_paths,omitempty"` // reported as denied; matched by "when: denied" rules
+	Rules     []task.RiskRule `yaml:"rules,omitempty"`      // default rules when empty
+}
+
+// RepoConfig holds settings that apply to every task targeting a repo.
+type RepoConfig struct {
+	AllowPaths []string `yaml:"allow_paths,omitempty"` // tasks may only change matching paths
+	DenyPaths  []string `yaml:"deny_paths,omitempty"`  // tasks must not change matching paths
+	PathPolicy string   `yaml:"path_policy,omitempty"` // fail (default) or revert
+}
+
+// Follow-up approval modes.
+const (
+	FollowupAuto = "auto" // insert follow-ups into the run
+	FollowupAsk  = "ask"  // ask in the TUI before inserting
+	FollowupLog  = "log"  // only record them in the report
+)
+
+// FollowupsConfig controls follow-up tasks that agents propose at runtime.
+type FollowupsConfig struct {
+	Mode      string `yaml:"mode"`                  // auto, ask or log
+	MaxDepth  int    `yaml:"max_depth,omitempty"`   // follow-ups of follow-ups; default 1
+	MaxPerRun int    `yaml:"max_per_run,omitempty"` // default 10
+}
+
+// Limits returns the depth limit and per-run cap with defaults applied.
+func (f *FollowupsConfig) Limits() (maxDepth, maxPerRun int) {
+	maxDepth, maxPerRun = 1, 10
+	if f.MaxDepth > 0 {
+		maxDepth = f.MaxDepth
+	}
+	if f.MaxPerRun > 0 {
+		maxPerRun = f.MaxPerRun
+	}
+	return maxDepth, maxPerRun
+}
+
+// GitHostConfig configures a self-hosted or non-default git host.
+type GitHostConfig struct {
+	Type     string `yaml:"type"`                // github, gitlab, gitea
+	APIURL   string `yaml:"api_url,omitempty"`   // default derived from host
+	TokenEnv string `yaml:"token_env,omitempty"` // env var with the API token
 }
 
 // ProxyConfig controls the built-in Responses API → Chat Completions proxy.
@@ -110,6 +308,51 @@ func LoadSettings(path string) (*Settings, error) {
 	if err := yaml.Unmarshal(data, &s); err != nil {
 		return nil, fmt.Errorf("parse config %s: %w", path, err)
 	}
+	if err := s.Risk.validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	for repo, rc := range s.Repos {
+		if rc == nil {
+			continue
+		}
+		switch rc.PathPolicy {
+		case "", task.PathPolicyFail, task.PathPolicyRevert:
+		default:
+			return nil, fmt.Errorf("config %s: repo %s: unknown path_policy %q (want fail or revert)", path, repo, rc.PathPolicy)
+		}
+	}
+
+	if f := s.Followups; f != nil {
+		switch f.Mode {
+		case FollowupAuto, FollowupAsk, FollowupLog:
+		default:
+			return nil, fmt.Errorf("config %s: followups: unknown mode %q (want auto, ask or log)", path, f.Mode)
+		}
+	}
 
 	return &s, nil
 }
+
+// validate checks rule categories and actions.
+func (r *RiskConfig) validate() error {
+	if r == nil {
+		return nil
+	}
+	for i, rule := range r.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		switch rule.When {
+		case "", task.RiskWhenCI, task.RiskWhenLockfile, task.RiskWhenSecret, task.RiskWhenDenied:
+		default:
+			return fmt.Errorf("risk rule %s: unknown when %q (want ci, lockfile, secret or denied)", name, rule.When)
+		}
+		switch rule.Action {
+		case "", task.RiskReview, task.RiskBlock:
+		default:
+			return fmt.Errorf("risk rule %s: unknown action %q (want review or block)", name, rule.Action)
+		}
+	}
+	return nil
+}
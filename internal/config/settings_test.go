@@ -3,8 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/scan"
 )
 
 func TestLoadSettings_Valid(t *testing.T) {
@@ -137,6 +140,154 @@ codex_quota:
 	}
 }
 
+func TestLoadSettings_Budgets(t *testing.T) {
+	content := `
+budgets:
+  anthropic:
+    daily_usd: 20
+  codex:
+    weekly_tokens: 5000000
+`
+	path := writeTemp(t, content)
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Budgets) != 2 {
+		t.Fatalf("expected 2 budgets, got %d", len(s.Budgets))
+	}
+	if s.Budgets["anthropic"].DailyUSD != 20 {
+		t.Errorf("anthropic daily_usd: got %.2f, want 20", s.Budgets["anthropic"].DailyUSD)
+	}
+	if s.Budgets["codex"].WeeklyTokens != 5000000 {
+		t.Errorf("codex weekly_tokens: got %d, want 5000000", s.Budgets["codex"].WeeklyTokens)
+	}
+}
+
+func TestLoadSettings_Pacing(t *testing.T) {
+	content := `
+runners:
+  claude:
+    type: claude
+    requests_per_minute: 50
+    tokens_per_minute: 400000
+pacing:
+  learn: false
+  safety_factor: 0.7
+`
+	path := writeTemp(t, content)
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp := s.Runners["claude"]
+	if rp.RequestsPerMinute != 50 || rp.TokensPerMinute != 400000 {
+		t.Errorf("claude rates: got %d rpm / %d tpm", rp.RequestsPerMinute, rp.TokensPerMinute)
+	}
+	if s.Pacing == nil || s.Pacing.Learn == nil || *s.Pacing.Learn {
+		t.Error("expected pacing.learn=false")
+	}
+	if s.Pacing.SafetyFactor != 0.7 {
+		t.Errorf("safety_factor: got %.2f, want 0.7", s.Pacing.SafetyFactor)
+	}
+}
+
+func TestLoadSettings_PR(t *testing.T) {
+	content := `
+pr:
+  provider: api
+  labels: [tokencontrol]
+  reviewers: [alice, bob]
+  hosts:
+    git.example.com:
+      type: gitea
+      token_env: CORP_TOKEN
+`
+	path := writeTemp(t, content)
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PR == nil || s.PR.Provider != "api" || len(s.PR.Labels) != 1 || len(s.PR.Reviewers) != 2 {
+		t.Fatalf("pr = %+v", s.PR)
+	}
+	h := s.PR.Hosts["git.example.com"]
+	if h == nil || h.Type != "gitea" || h.TokenEnv != "CORP_TOKEN" {
+		t.Errorf("host = %+v", h)
+	}
+}
+
+func TestLoadSettings_Risk(t *testing.T) {
+	content := `
+risk:
+  deny_paths: [LICENSE, "deploy/**"]
+  rules:
+    - name: big
+      max_lines: 400
+    - name: deploy
+      when: denied
+      action: block
+`
+	s, err := LoadSettings(writeTemp(t, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Risk == nil || len(s.Risk.DenyPaths) != 2 || len(s.Risk.Rules) != 2 {
+		t.Fatalf("risk = %+v", s.Risk)
+	}
+	if r := s.Risk.Rules[1]; r.When != "denied" || r.Action != "block" {
+		t.Errorf("rule = %+v", r)
+	}
+
+	_, err = LoadSettings(writeTemp(t, "risk:\n  rules:\n    - name: x\n      action: stop\n"))
+	if err == nil || !strings.Contains(err.Error(), `risk rule x: unknown action "stop"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLoadSettings_RepoPaths(t *testing.T) {
+	content := `
+repos:
+  org/api:
+    allow_paths: ["src/**", "tests/"]
+    deny_paths: [go.sum]
+    path_policy: revert
+`
+	s, err := LoadSettings(writeTemp(t, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := s.Repos["org/api"]
+	if rc == nil || len(rc.AllowPaths) != 2 || rc.DenyPaths[0] != "go.sum" || rc.PathPolicy != "revert" {
+		t.Fatalf("repos = %+v", rc)
+	}
+
+	_, err = LoadSettings(writeTemp(t, "repos:\n  org/api:\n    path_policy: ignore\n"))
+	if err == nil || !strings.Contains(err.Error(), `repo org/api: unknown path_policy "ignore"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLoadSettings_Followups(t *testing.T) {
+	s, err := LoadSettings(writeTemp(t, "followups:\n  mode: ask\n  max_per_run: 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Followups == nil || s.Followups.Mode != FollowupAsk {
+		t.Fatalf("followups = %+v", s.Followups)
+	}
+	if depth, perRun := s.Followups.Limits(); depth != 1 || perRun != 3 {
+		t.Errorf("limits = %d, %d", depth, perRun)
+	}
+
+	_, err = LoadSettings(writeTemp(t, "followups:\n  mode: always\n"))
+	if err == nil || !strings.Contains(err.Error(), `followups: unknown mode "always"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
 func writeTemp(t *testing.T, content string) string {
 	t.Helper()
 	path := filepath.Join(t.TempDir(), ".tokencontrol.yml")
@@ -145,3 +296,105 @@ func writeTemp(t *testing.T, content string) string {
 	}
 	return path
 }
+
+func TestScanConfig_LoadRules(t *testing.T) {
+	path := writeTemp(t, "scan:\n  rules:\n    - id: no-replace\n      kind: file-contains\n      path: go.mod\n      pattern: '(?m)^replace'\n      negate: true\n      message: go.mod has replace directives\n")
+	dir := filepath.Join(filepath.Dir(path), DefaultRulesDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	pack := "rules:\n  - id: codeowners\n    kind: file-exists\n    path: CODEOWNERS\n    message: No CODEOWNERS\n"
+	if err := os.WriteFile(filepath.Join(dir, "org.yaml"), []byte(pack), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := s.Scan.LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].ID != "no-replace" || !rules[0].Negate || rules[1].ID != "codeowners" {
+		t.Fatalf("rules = %+v", rules)
+	}
+
+	// no scan section: rule packs are still read
+	var none *ScanConfig
+	if rules, err := none.LoadRules(path); err != nil || len(rules) != 1 {
+		t.Errorf("rules = %+v, err = %v", rules, err)
+	}
+}
+
+func TestScanConfig_BaselinePath(t *testing.T) {
+	configPath := filepath.Join("/etc", "tc", ".tokencontrol.yml")
+	var none *ScanConfig
+	if got := none.BaselinePath(configPath); got != filepath.Join("/etc", "tc", ".tokencontrol-baseline.json") {
+		t.Errorf("default = %q", got)
+	}
+	if got := (&ScanConfig{Baseline: "scan/known.json"}).BaselinePath(configPath); got != filepath.Join("/etc", "tc", "scan", "known.json") {
+		t.Errorf("relative = %q", got)
+	}
+	if got := (&ScanConfig{Baseline: "/var/known.json"}).BaselinePath(configPath); got != "/var/known.json" {
+		t.Errorf("absolute = %q", got)
+	}
+}
+
+func TestScanConfig_WorkersAndCache(t *testing.T) {
+	var none *ScanConfig
+	if none.ScanWorkers() != 0 || none.CachePath() != scan.DefaultCachePath() {
+		t.Errorf("defaults = %d, %q", none.ScanWorkers(), none.CachePath())
+	}
+	c := &ScanConfig{Workers: 3, NoCache: true}
+	if c.ScanWorkers() != 3 || c.CachePath() != "" {
+		t.Errorf("configured = %d, %q", c.ScanWorkers(), c.CachePath())
+	}
+}
+
+func TestScanConfig_HistoryDepth(t *testing.T) {
+	var none *ScanConfig
+	if got := none.ScanHistoryDepth(); got != 0 {
+		t.Errorf("default = %d", got)
+	}
+	if got := (&ScanConfig{HistoryDepth: -1}).ScanHistoryDepth(); got != -1 {
+		t.Errorf("configured = %d", got)
+	}
+}
+
+func TestScanConfig_AdvisoryDir(t *testing.T) {
+	configPath := filepath.Join("/etc", "tc", ".tokencontrol.yml")
+	var none *ScanConfig
+	if got := none.AdvisoryDir(configPath); got != scan.DefaultAdvisoryDir() {
+		t.Errorf("default = %q", got)
+	}
+	if got := (&ScanConfig{Advisories: "osv"}).AdvisoryDir(configPath); got != filepath.Join("/etc", "tc", "osv") {
+		t.Errorf("relative = %q", got)
+	}
+	if got := (&ScanConfig{Advisories: "/var/osv"}).AdvisoryDir(configPath); got != "/var/osv" {
+		t.Errorf("absolute = %q", got)
+	}
+}
+
+func TestScanConfig_ScoreWeights(t *testing.T) {
+	var none *ScanConfig
+	if got := none.ScoreWeights().Penalty("critical", "security"); got != 20 {
+		t.Errorf("default critical security penalty = %v", got)
+	}
+
+	path := writeTemp(t, "scan:\n  score:\n    severity:\n      info: 0\n    category:\n      docs: 0.5\n")
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := s.Scan.ScoreWeights()
+	if got := w.Penalty("info", "go"); got != 0 {
+		t.Errorf("configured info penalty = %v", got)
+	}
+	if got := w.Penalty("warning", "docs"); got != 1.5 {
+		t.Errorf("configured docs penalty = %v", got)
+	}
+	if got := w.Penalty("critical", "security"); got != 20 {
+		t.Errorf("unconfigured keys lost their defaults: %v", got)
+	}
+}
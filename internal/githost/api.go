@@ -0,0 +1,103 @@
+package githost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const apiTimeout = 30 * time.Second
+
+// apiClient is a minimal JSON REST client shared by the API providers.
+type apiClient struct {
+	base   string
+	token  string
+	auth   string // Authorization header value; empty uses PRIVATE-TOKEN (GitLab)
+	client *http.Client
+}
+
+func newAPIClient(base, token string) *apiClient {
+	return &apiClient{
+		base:   strings.TrimSuffix(base, "/"),
+		token:  token,
+		client: &http.Client{Timeout: apiTimeout},
+	}
+}
+
+// apiError is a non-2xx response.
+type apiError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.Path, e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+func isStatus(err error, codes ...int) bool {
+	ae, ok := err.(*apiError)
+	if !ok {
+		return false
+	}
+	for _, c := range codes {
+		if ae.StatusCode == c {
+			return true
+		}
+	}
+	return false
+}
+
+// do sends in as JSON (if non-nil) and decodes the response into out (if non-nil).
+func (c *apiClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "tokencontrol")
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	} else {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &apiError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package githost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ghCLI drives the gh command-line tool. It is the fallback when no API
+// token is available and works for any host gh is logged in to.
+type ghCLI struct {
+	dir string
+	run func(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+func (g *ghCLI) Name() string { return TypeGHCLI }
+
+func (g *ghCLI) CreatePR(ctx context.Context, req PRRequest) (*PR, error) {
+	args := []string{
+		"pr", "create",
+		"--head", req.Head,
+		"--base", req.Base,
+		"--title", req.Title,
+		"--body", req.Body,
+	}
+	if req.Draft {
+		args = append(args, "--draft")
+	}
+	for _, l := range req.Labels {
+		args = append(args, "--label", l)
+	}
+	for _, r := range req.Reviewers {
+		args = append(args, "--reviewer", r)
+	}
+	out, err := g.run(ctx, g.dir, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return nil, ErrPRExists
+		}
+		return nil, err
+	}
+	return &PR{URL: strings.TrimSpace(out), State: StateOpen, Head: req.Head, Base: req.Base}, nil
+}
+
+func (g *ghCLI) FindPR(ctx context.Context, branch string) (*PR, error) {
+	out, err := g.run(ctx, g.dir, "pr", "view", branch, "--json", "number,url,state,headRefName,baseRefName")
+	if err != nil {
+		if strings.Contains(err.Error(), "no pull requests found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var v struct {
+		Number      int    `json:"number"`
+		URL         string `json:"url"`
+		State       string `json:"state"`
+		HeadRefName string `json:"headRefName"`
+		BaseRefName string `json:"baseRefName"`
+	}
+	if err := json.Unmarshal([]byte(out), &v); err != nil {
+		return nil, fmt.Errorf("parse gh pr view: %w", err)
+	}
+	return &PR{
+		Number: v.Number,
+		URL:    v.URL,
+		State:  strings.ToLower(v.State), // OPEN, CLOSED, MERGED
+		Head:   v.HeadRefName,
+		Base:   v.BaseRefName,
+	}, nil
+}
+
+func (g *ghCLI) UpdatePR(ctx context.Context, pr *PR, update PRUpdate) error {
+	ref := pr.URL
+	if ref == "" && pr.Number > 0 {
+		ref = strconv.Itoa(pr.Number)
+	}
+	if ref == "" {
+		ref = pr.Head
+	}
+	args := []string{"pr", "edit", ref}
+	if update.Base != "" {
+		args = append(args, "--base", update.Base)
+	}
+	if update.Body != "" {
+		args = append(args, "--body", update.Body)
+	}
+	_, err := g.run(ctx, g.dir, args...)
+	return err
+}
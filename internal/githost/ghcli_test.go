@@ -0,0 +1,79 @@
+package githost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGHCLI_CreatePR(t *testing.T) {
+	var got []string
+	g := &ghCLI{dir: "/repo", run: func(_ context.Context, dir string, args ...string) (string, error) {
+		if dir != "/repo" {
+			t.Errorf("dir = %s", dir)
+		}
+		got = args
+		return "https://github.com/o/r/pull/1\n", nil
+	}}
+
+	pr, err := g.CreatePR(context.Background(), PRRequest{
+		Head: "feat", Base: "main", Title: "T", Body: "B", Draft: true,
+		Labels: []string{"bot"}, Reviewers: []string{"alice"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.URL != "https://github.com/o/r/pull/1" {
+		t.Errorf("url = %q", pr.URL)
+	}
+	want := "pr create --head feat --base main --title T --body B --draft --label bot --reviewer alice"
+	if strings.Join(got, " ") != want {
+		t.Errorf("args = %v", got)
+	}
+}
+
+func TestGHCLI_CreatePRExists(t *testing.T) {
+	g := &ghCLI{run: func(context.Context, string, ...string) (string, error) {
+		return "", fmt.Errorf("gh pr create: a pull request for branch \"feat\" into branch \"main\" already exists")
+	}}
+	if _, err := g.CreatePR(context.Background(), PRRequest{}); !errors.Is(err, ErrPRExists) {
+		t.Errorf("err = %v, want ErrPRExists", err)
+	}
+}
+
+func TestGHCLI_FindAndUpdatePR(t *testing.T) {
+	var calls [][]string
+	g := &ghCLI{run: func(_ context.Context, _ string, args ...string) (string, error) {
+		calls = append(calls, args)
+		if args[1] == "view" {
+			return `{"number":4,"url":"https://github.com/o/r/pull/4","state":"MERGED","headRefName":"feat","baseRefName":"parent"}`, nil
+		}
+		return "", nil
+	}}
+
+	pr, err := g.FindPR(context.Background(), "feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.State != StateMerged || pr.Base != "parent" || pr.Number != 4 {
+		t.Errorf("pr = %+v", pr)
+	}
+	if err := g.UpdatePR(context.Background(), pr, PRUpdate{Base: "main"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls[1], " "); got != "pr edit https://github.com/o/r/pull/4 --base main" {
+		t.Errorf("edit args = %s", got)
+	}
+}
+
+func TestGHCLI_FindPRNone(t *testing.T) {
+	g := &ghCLI{run: func(context.Context, string, ...string) (string, error) {
+		return "", fmt.Errorf("gh pr view: no pull requests found for branch \"feat\"")
+	}}
+	pr, err := g.FindPR(context.Background(), "feat")
+	if err != nil || pr != nil {
+		t.Errorf("FindPR = %+v, %v; want nil, nil", pr, err)
+	}
+}
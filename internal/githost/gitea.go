@@ -0,0 +1,128 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// gitea talks to the Gitea (and Forgejo) REST API.
+type gitea struct {
+	api    *apiClient
+	remote Remote
+}
+
+type giteaPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p giteaPull) toPR() *PR {
+	pr := &PR{Number: p.Number, URL: p.HTMLURL, State: p.State, Head: p.Head.Ref, Base: p.Base.Ref}
+	if p.Merged {
+		pr.State = StateMerged
+	}
+	return pr
+}
+
+func (g *gitea) Name() string { return TypeGitea }
+
+func (g *gitea) repoPath() string {
+	return "/repos/" + g.remote.Owner + "/" + g.remote.Name
+}
+
+func (g *gitea) CreatePR(ctx context.Context, req PRRequest) (*PR, error) {
+	title := req.Title
+	if req.Draft {
+		title = "WIP: " + title // Gitea marks PRs with a WIP prefix as drafts
+	}
+	in := map[string]any{
+		"head":  req.Head,
+		"base":  req.Base,
+		"title": title,
+		"body":  req.Body,
+	}
+	if len(req.Labels) > 0 {
+		ids, err := g.labelIDs(ctx, req.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("resolve labels: %w", err)
+		}
+		in["labels"] = ids
+	}
+
+	var out giteaPull
+	if err := g.api.do(ctx, http.MethodPost, g.repoPath()+"/pulls", in, &out); err != nil {
+		if isStatus(err, http.StatusConflict) {
+			return nil, ErrPRExists
+		}
+		return nil, err
+	}
+	pr := out.toPR()
+
+	if len(req.Reviewers) > 0 {
+		path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", g.repoPath(), pr.Number)
+		if err := g.api.do(ctx, http.MethodPost, path, map[string]any{"reviewers": req.Reviewers}, nil); err != nil {
+			return pr, fmt.Errorf("request reviewers: %w", err)
+		}
+	}
+	return pr, nil
+}
+
+// labelIDs maps label names to the repo's label IDs.
+func (g *gitea) labelIDs(ctx context.Context, names []string) ([]int, error) {
+	var labels []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := g.api.do(ctx, http.MethodGet, g.repoPath()+"/labels?limit=100", nil, &labels); err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, l := range labels {
+			if strings.EqualFold(l.Name, name) {
+				ids = append(ids, l.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown label %q", name)
+		}
+	}
+	return ids, nil
+}
+
+func (g *gitea) FindPR(ctx context.Context, branch string) (*PR, error) {
+	var out []giteaPull
+	if err := g.api.do(ctx, http.MethodGet, g.repoPath()+"/pulls?state=all&sort=recentupdate&limit=50", nil, &out); err != nil {
+		return nil, err
+	}
+	for _, p := range out {
+		if p.Head.Ref == branch {
+			return p.toPR(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (g *gitea) UpdatePR(ctx context.Context, pr *PR, update PRUpdate) error {
+	in := map[string]any{}
+	if update.Base != "" {
+		in["base"] = update.Base
+	}
+	if update.Body != "" {
+		in["body"] = update.Body
+	}
+	return g.api.do(ctx, http.MethodPatch, fmt.Sprintf("%s/pulls/%d", g.repoPath(), pr.Number), in, nil)
+}
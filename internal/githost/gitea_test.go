@@ -0,0 +1,94 @@
+package githost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestGitea(srv *httptest.Server) *gitea {
+	api := newAPIClient(srv.URL, "tok")
+	api.auth = "token tok"
+	return &gitea{api: api, remote: Remote{Host: "gitea.local", Owner: "team", Name: "app"}}
+}
+
+func TestGitea_CreatePR(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("GET /repos/team/app/labels?limit=100", http.StatusOK, []any{
+		map[string]any{"id": 1, "name": "bug"},
+		map[string]any{"id": 2, "name": "Automated"},
+	})
+	f.on("POST /repos/team/app/pulls", http.StatusCreated, map[string]any{
+		"number": 8, "html_url": "https://gitea.local/team/app/pulls/8", "state": "open",
+	})
+	f.on("POST /repos/team/app/pulls/8/requested_reviewers", http.StatusCreated, []any{})
+
+	pr, err := newTestGitea(srv).CreatePR(context.Background(), PRRequest{
+		Head: "feat", Base: "main", Title: "T", Draft: true,
+		Labels: []string{"automated"}, Reviewers: []string{"bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 8 || pr.URL != "https://gitea.local/team/app/pulls/8" {
+		t.Errorf("pr = %+v", pr)
+	}
+	body := f.bodies["POST /repos/team/app/pulls"]
+	if body["title"] != "WIP: T" {
+		t.Errorf("title = %v", body["title"])
+	}
+	if ids, _ := body["labels"].([]any); len(ids) != 1 || ids[0] != float64(2) {
+		t.Errorf("labels = %v", body["labels"])
+	}
+	if got := f.headers.Get("Authorization"); got != "token tok" {
+		t.Errorf("auth header = %q", got)
+	}
+}
+
+func TestGitea_UnknownLabel(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("GET /repos/team/app/labels?limit=100", http.StatusOK, []any{})
+	_, err := newTestGitea(srv).CreatePR(context.Background(), PRRequest{Head: "feat", Base: "main", Labels: []string{"nope"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown label "nope"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestGitea_CreatePRExists(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("POST /repos/team/app/pulls", http.StatusConflict, map[string]any{"message": "pull request already exists"})
+	_, err := newTestGitea(srv).CreatePR(context.Background(), PRRequest{Head: "feat", Base: "main"})
+	if !errors.Is(err, ErrPRExists) {
+		t.Errorf("err = %v, want ErrPRExists", err)
+	}
+}
+
+func TestGitea_FindAndUpdatePR(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("GET /repos/team/app/pulls?state=all&sort=recentupdate&limit=50", http.StatusOK, []any{
+		map[string]any{"number": 1, "state": "open", "head": map[string]any{"ref": "other"}},
+		map[string]any{"number": 8, "state": "closed", "merged": true, "head": map[string]any{"ref": "feat"}},
+	})
+	f.on("PATCH /repos/team/app/pulls/8", http.StatusCreated, map[string]any{})
+
+	g := newTestGitea(srv)
+	pr, err := g.FindPR(context.Background(), "feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr == nil || pr.Number != 8 || pr.State != StateMerged {
+		t.Fatalf("pr = %+v", pr)
+	}
+	if missing, _ := g.FindPR(context.Background(), "nope"); missing != nil {
+		t.Errorf("expected nil for unknown branch, got %+v", missing)
+	}
+	if err := g.UpdatePR(context.Background(), pr, PRUpdate{Body: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if body := f.bodies["PATCH /repos/team/app/pulls/8"]; body["body"] != "b" {
+		t.Errorf("update body = %v", body)
+	}
+}
@@ -0,0 +1,238 @@
+// Package githost creates and updates pull requests on git hosting services.
+// GitHub, GitLab and Gitea are reached through their REST APIs; the gh CLI
+// remains available as a fallback when no API token is configured.
+package githost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Provider types.
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeGitea  = "gitea"
+	TypeGHCLI  = "gh-cli"
+)
+
+// ErrPRExists is returned by CreatePR when an open PR for the head branch
+// already exists.
+var ErrPRExists = errors.New("pull request already exists")
+
+// Provider is a git hosting service that can open and update pull requests
+// (merge requests on GitLab).
+type Provider interface {
+	// Name returns the provider type, e.g. "github".
+	Name() string
+	// CreatePR opens a PR from req.Head into req.Base and applies labels and
+	// reviewers. Returns ErrPRExists if one is already open for req.Head.
+	CreatePR(ctx context.Context, req PRRequest) (*PR, error)
+	// FindPR returns the most recent PR whose head is branch, or nil if none.
+	FindPR(ctx context.Context, branch string) (*PR, error)
+	// UpdatePR changes the base branch and/or body of a PR. Empty fields
+	// are left unchanged.
+	UpdatePR(ctx context.Context, pr *PR, update PRUpdate) error
+}
+
+// PRRequest describes a pull request to create.
+type PRRequest struct {
+	Head      string
+	Base      string
+	Title     string
+	Body      string
+	Draft     bool
+	Labels    []string
+	Reviewers []string // usernames
+}
+
+// PRUpdate holds the fields to change on an existing PR.
+type PRUpdate struct {
+	Base string
+	Body string
+}
+
+// PR state values, normalized across providers.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateMerged = "merged"
+)
+
+// PR is a pull request as reported by the provider.
+type PR struct {
+	Number int
+	URL    string
+	State  string // StateOpen, StateClosed or StateMerged
+	Head   string
+	Base   string
+}
+
+// Remote identifies a repository on a hosting service.
+type Remote struct {
+	Host  string // e.g. "github.com" or "git.example.com:3000"
+	Owner string // user, org or (GitLab) group path
+	Name  string
+}
+
+// Path returns "owner/name".
+func (r Remote) Path() string {
+	return r.Owner + "/" + r.Name
+}
+
+// ParseRemote parses an https, ssh or scp-style git remote URL.
+func ParseRemote(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parse remote %q: %w", raw, err)
+		}
+		host = u.Host
+		if u.Scheme == "ssh" {
+			host = u.Hostname() // ssh ports are not the web port
+		}
+		path = u.Path
+	case strings.Contains(raw, ":"): // git@host:owner/name.git
+		host, path, _ = strings.Cut(raw, ":")
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	default:
+		return Remote{}, fmt.Errorf("unrecognized remote %q", raw)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if host == "" || i <= 0 || i == len(path)-1 {
+		return Remote{}, fmt.Errorf("remote %q has no owner/name path", raw)
+	}
+	return Remote{Host: host, Owner: path[:i], Name: path[i+1:]}, nil
+}
+
+// HostConfig overrides detection for one host.
+type HostConfig struct {
+	Type     string // TypeGitHub, TypeGitLab or TypeGitea
+	APIURL   string // defaults per type, derived from the host
+	TokenEnv string // env var holding the API token
+}
+
+// Config selects and configures providers.
+type Config struct {
+	// Mode is "auto" (API when a token is available, else gh CLI), "api"
+	// (API only) or "cli" (gh CLI only). Empty means auto.
+	Mode  string
+	Hosts map[string]HostConfig
+	// Getenv reads tokens; defaults to os.Getenv.
+	Getenv func(string) string
+	// RunGH runs the gh CLI for the CLI fallback.
+	RunGH func(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+// defaultTokenEnvs lists the env vars checked for each provider type.
+var defaultTokenEnvs = map[string][]string{
+	TypeGitHub: {"GITHUB_TOKEN", "GH_TOKEN"},
+	TypeGitLab: {"GITLAB_TOKEN"},
+	TypeGitea:  {"GITEA_TOKEN"},
+}
+
+// DetectType guesses the provider type from a host name. Self-hosted
+// instances with neutral names need a HostConfig entry.
+func DetectType(host string) string {
+	h := strings.ToLower(host)
+	switch {
+	case strings.Contains(h, "github"):
+		return TypeGitHub
+	case strings.Contains(h, "gitlab"):
+		return TypeGitLab
+	case strings.Contains(h, "gitea"), strings.Contains(h, "codeberg"), strings.Contains(h, "forgejo"):
+		return TypeGitea
+	}
+	return ""
+}
+
+// New returns the provider for a repository remote. dir is the local
+// checkout, used by the gh CLI fallback.
+func New(cfg Config, remoteURL, dir string) (Provider, error) {
+	getenv := cfg.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	cli := func(reason string) (Provider, error) {
+		if cfg.RunGH == nil {
+			return nil, fmt.Errorf("%s and no gh CLI fallback configured", reason)
+		}
+		return &ghCLI{dir: dir, run: cfg.RunGH}, nil
+	}
+
+	if cfg.Mode == "cli" {
+		return cli("cli mode")
+	}
+
+	remote, err := ParseRemote(remoteURL)
+	if err != nil {
+		if cfg.Mode == "api" {
+			return nil, err
+		}
+		return cli(err.Error())
+	}
+
+	hc := cfg.Hosts[remote.Host]
+	if hc.Type == "" {
+		hc.Type = DetectType(remote.Host)
+	}
+	if hc.Type == "" {
+		if cfg.Mode == "api" {
+			return nil, fmt.Errorf("unknown git host %q: set its type in config", remote.Host)
+		}
+		return cli("unknown git host " + remote.Host)
+	}
+
+	token := ""
+	if hc.TokenEnv != "" {
+		token = getenv(hc.TokenEnv)
+	} else {
+		for _, name := range defaultTokenEnvs[hc.Type] {
+			if token = getenv(name); token != "" {
+				break
+			}
+		}
+	}
+	if token == "" {
+		if cfg.Mode == "api" || hc.Type != TypeGitHub {
+			return nil, fmt.Errorf("no API token for %s (%s)", remote.Host, hc.Type)
+		}
+		return cli("no GitHub token")
+	}
+
+	api := newAPIClient(hc.APIURL, token)
+	switch hc.Type {
+	case TypeGitHub:
+		if api.base == "" {
+			api.base = "https://api.github.com"
+			if remote.Host != "github.com" {
+				api.base = "https://" + remote.Host + "/api/v3"
+			}
+		}
+		api.auth = "Bearer " + token
+		return &gitHub{api: api, remote: remote}, nil
+	case TypeGitLab:
+		if api.base == "" {
+			api.base = "https://" + remote.Host + "/api/v4"
+		}
+		return &gitLab{api: api, remote: remote}, nil
+	case TypeGitea:
+		if api.base == "" {
+			api.base = "https://" + remote.Host + "/api/v1"
+		}
+		api.auth = "token " + token
+		return &gitea{api: api, remote: remote}, nil
+	}
+	return nil, fmt.Errorf("unknown git host type %q", hc.Type)
+}
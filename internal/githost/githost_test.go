@@ -0,0 +1,112 @@
+package githost
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Remote
+	}{
+		{"https://github.com/ppiankov/tokencontrol.git", Remote{"github.com", "ppiankov", "tokencontrol"}},
+		{"git@github.com:ppiankov/tokencontrol.git", Remote{"github.com", "ppiankov", "tokencontrol"}},
+		{"ssh://git@gitlab.com:2222/group/sub/proj.git", Remote{"gitlab.com", "group/sub", "proj"}},
+		{"https://git.example.com:3000/team/app", Remote{"git.example.com:3000", "team", "app"}},
+		{"gitea.local:team/app", Remote{"gitea.local", "team", "app"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.raw)
+		if err != nil {
+			t.Errorf("ParseRemote(%q): %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "/local/path/repo", "https://github.com/onlyowner"} {
+		if _, err := ParseRemote(bad); err == nil {
+			t.Errorf("ParseRemote(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDetectType(t *testing.T) {
+	tests := map[string]string{
+		"github.com":        TypeGitHub,
+		"gitlab.com":        TypeGitLab,
+		"gitlab.corp.local": TypeGitLab,
+		"codeberg.org":      TypeGitea,
+		"gitea.local:3000":  TypeGitea,
+		"git.example.com":   "",
+	}
+	for host, want := range tests {
+		if got := DetectType(host); got != want {
+			t.Errorf("DetectType(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func fakeGH(context.Context, string, ...string) (string, error) { return "", nil }
+
+func TestNew_Selection(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	tests := []struct {
+		name    string
+		cfg     Config
+		remote  string
+		want    string
+		wantErr string
+	}{
+		{"github token", Config{Getenv: env(map[string]string{"GH_TOKEN": "t"})}, "git@github.com:o/r.git", TypeGitHub, ""},
+		{"github no token falls back to cli", Config{Getenv: env(nil)}, "git@github.com:o/r.git", TypeGHCLI, ""},
+		{"gitlab", Config{Getenv: env(map[string]string{"GITLAB_TOKEN": "t"})}, "https://gitlab.com/g/p.git", TypeGitLab, ""},
+		{"gitlab no token", Config{Getenv: env(nil)}, "https://gitlab.com/g/p.git", "", "no API token"},
+		{"configured host", Config{
+			Getenv: env(map[string]string{"CORP": "t"}),
+			Hosts:  map[string]HostConfig{"git.example.com": {Type: TypeGitea, TokenEnv: "CORP"}},
+		}, "https://git.example.com/team/app.git", TypeGitea, ""},
+		{"unknown host auto", Config{Getenv: env(nil)}, "https://git.example.com/team/app.git", TypeGHCLI, ""},
+		{"unknown host api", Config{Mode: "api", Getenv: env(nil)}, "https://git.example.com/team/app.git", "", "unknown git host"},
+		{"cli mode", Config{Mode: "cli", Getenv: env(map[string]string{"GH_TOKEN": "t"})}, "git@github.com:o/r.git", TypeGHCLI, ""},
+		{"no remote", Config{Getenv: env(nil)}, "", TypeGHCLI, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.RunGH = fakeGH
+			p, err := New(tt.cfg, tt.remote, "/repo")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name() != tt.want {
+				t.Errorf("provider = %s, want %s", p.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_GitHubEnterpriseAPIURL(t *testing.T) {
+	cfg := Config{
+		Getenv: func(string) string { return "t" },
+		Hosts:  map[string]HostConfig{"ghe.corp.com": {Type: TypeGitHub}},
+	}
+	p, err := New(cfg, "https://ghe.corp.com/o/r.git", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.(*gitHub).api.base; got != "https://ghe.corp.com/api/v3" {
+		t.Errorf("api base = %s", got)
+	}
+}
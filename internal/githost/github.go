@@ -0,0 +1,97 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitHub talks to the GitHub (or GitHub Enterprise) REST API.
+type gitHub struct {
+	api    *apiClient
+	remote Remote
+}
+
+type gitHubPull struct {
+	Number   int     `json:"number"`
+	HTMLURL  string  `json:"html_url"`
+	State    string  `json:"state"`
+	MergedAt *string `json:"merged_at"`
+	Head     struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p gitHubPull) toPR() *PR {
+	pr := &PR{Number: p.Number, URL: p.HTMLURL, State: p.State, Head: p.Head.Ref, Base: p.Base.Ref}
+	if p.MergedAt != nil && *p.MergedAt != "" {
+		pr.State = StateMerged
+	}
+	return pr
+}
+
+func (g *gitHub) Name() string { return TypeGitHub }
+
+func (g *gitHub) repoPath() string {
+	return "/repos/" + g.remote.Owner + "/" + g.remote.Name
+}
+
+func (g *gitHub) CreatePR(ctx context.Context, req PRRequest) (*PR, error) {
+	in := map[string]any{
+		"title": req.Title,
+		"head":  req.Head,
+		"base":  req.Base,
+		"body":  req.Body,
+		"draft": req.Draft,
+	}
+	var out gitHubPull
+	if err := g.api.do(ctx, http.MethodPost, g.repoPath()+"/pulls", in, &out); err != nil {
+		if isStatus(err, http.StatusUnprocessableEntity) && strings.Contains(err.Error(), "already exists") {
+			return nil, ErrPRExists
+		}
+		return nil, err
+	}
+	pr := out.toPR()
+
+	if len(req.Labels) > 0 {
+		path := fmt.Sprintf("%s/issues/%d/labels", g.repoPath(), pr.Number)
+		if err := g.api.do(ctx, http.MethodPost, path, map[string]any{"labels": req.Labels}, nil); err != nil {
+			return pr, fmt.Errorf("add labels: %w", err)
+		}
+	}
+	if len(req.Reviewers) > 0 {
+		path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", g.repoPath(), pr.Number)
+		if err := g.api.do(ctx, http.MethodPost, path, map[string]any{"reviewers": req.Reviewers}, nil); err != nil {
+			return pr, fmt.Errorf("request reviewers: %w", err)
+		}
+	}
+	return pr, nil
+}
+
+func (g *gitHub) FindPR(ctx context.Context, branch string) (*PR, error) {
+	q := url.Values{"head": {g.remote.Owner + ":" + branch}, "state": {"all"}}
+	var out []gitHubPull
+	if err := g.api.do(ctx, http.MethodGet, g.repoPath()+"/pulls?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0].toPR(), nil // newest first
+}
+
+func (g *gitHub) UpdatePR(ctx context.Context, pr *PR, update PRUpdate) error {
+	in := map[string]any{}
+	if update.Base != "" {
+		in["base"] = update.Base
+	}
+	if update.Body != "" {
+		in["body"] = update.Body
+	}
+	return g.api.do(ctx, http.MethodPatch, fmt.Sprintf("%s/pulls/%d", g.repoPath(), pr.Number), in, nil)
+}
@@ -0,0 +1,123 @@
+package githost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeAPI records requests and serves canned responses keyed by "METHOD path".
+type fakeAPI struct {
+	t        *testing.T
+	routes   map[string]func(body map[string]any) (int, any)
+	requests []string
+	bodies   map[string]map[string]any
+	headers  http.Header
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *httptest.Server) {
+	f := &fakeAPI{t: t, routes: map[string]func(map[string]any) (int, any){}, bodies: map[string]map[string]any{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.RequestURI()
+		f.requests = append(f.requests, key)
+		f.headers = r.Header.Clone()
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.bodies[key] = body
+		route, ok := f.routes[key]
+		if !ok {
+			t.Errorf("unexpected request %s", key)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status, out := route(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeAPI) on(key string, status int, out any) {
+	f.routes[key] = func(map[string]any) (int, any) { return status, out }
+}
+
+func newTestGitHub(srv *httptest.Server) *gitHub {
+	api := newAPIClient(srv.URL, "tok")
+	api.auth = "Bearer tok"
+	return &gitHub{api: api, remote: Remote{Host: "github.com", Owner: "o", Name: "r"}}
+}
+
+func TestGitHub_CreatePR(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("POST /repos/o/r/pulls", http.StatusCreated, map[string]any{
+		"number": 5, "html_url": "https://github.com/o/r/pull/5", "state": "open",
+		"head": map[string]any{"ref": "feat"}, "base": map[string]any{"ref": "main"},
+	})
+	f.on("POST /repos/o/r/issues/5/labels", http.StatusOK, []any{})
+	f.on("POST /repos/o/r/pulls/5/requested_reviewers", http.StatusCreated, map[string]any{})
+
+	gh := newTestGitHub(srv)
+	pr, err := gh.CreatePR(context.Background(), PRRequest{
+		Head: "feat", Base: "main", Title: "T", Body: "B", Draft: true,
+		Labels: []string{"bot"}, Reviewers: []string{"alice"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 5 || pr.URL != "https://github.com/o/r/pull/5" || pr.State != StateOpen {
+		t.Errorf("pr = %+v", pr)
+	}
+	if got := f.bodies["POST /repos/o/r/pulls"]["draft"]; got != true {
+		t.Errorf("draft = %v", got)
+	}
+	if got := f.headers.Get("Authorization"); got != "Bearer tok" {
+		t.Errorf("auth header = %q", got)
+	}
+	if len(f.requests) != 3 {
+		t.Errorf("requests = %v", f.requests)
+	}
+}
+
+func TestGitHub_CreatePRExists(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("POST /repos/o/r/pulls", http.StatusUnprocessableEntity, map[string]any{
+		"message": "Validation Failed",
+		"errors":  []any{map[string]any{"message": "A pull request already exists for o:feat."}},
+	})
+	_, err := newTestGitHub(srv).CreatePR(context.Background(), PRRequest{Head: "feat", Base: "main"})
+	if !errors.Is(err, ErrPRExists) {
+		t.Errorf("err = %v, want ErrPRExists", err)
+	}
+}
+
+func TestGitHub_FindAndUpdatePR(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("GET /repos/o/r/pulls?head=o%3Afeat&state=all", http.StatusOK, []any{
+		map[string]any{"number": 9, "html_url": "u", "state": "closed", "merged_at": "2026-01-01T00:00:00Z"},
+	})
+	f.on("PATCH /repos/o/r/pulls/9", http.StatusOK, map[string]any{})
+
+	gh := newTestGitHub(srv)
+	pr, err := gh.FindPR(context.Background(), "feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr == nil || pr.State != StateMerged {
+		t.Fatalf("pr = %+v, want merged", pr)
+	}
+	if err := gh.UpdatePR(context.Background(), pr, PRUpdate{Base: "main"}); err != nil {
+		t.Fatal(err)
+	}
+	body := f.bodies["PATCH /repos/o/r/pulls/9"]
+	if body["base"] != "main" {
+		t.Errorf("update body = %v", body)
+	}
+	if _, ok := body["body"]; ok {
+		t.Errorf("empty body should not be sent: %v", body)
+	}
+}
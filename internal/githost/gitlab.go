@@ -0,0 +1,118 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitLab talks to the GitLab REST API, where pull requests are merge requests.
+type gitLab struct {
+	api    *apiClient
+	remote Remote
+}
+
+type gitLabMR struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	State        string `json:"state"` // opened, closed, merged, locked
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+}
+
+func (m gitLabMR) toPR() *PR {
+	pr := &PR{Number: m.IID, URL: m.WebURL, Head: m.SourceBranch, Base: m.TargetBranch}
+	switch m.State {
+	case "merged":
+		pr.State = StateMerged
+	case "closed":
+		pr.State = StateClosed
+	default:
+		pr.State = StateOpen
+	}
+	return pr
+}
+
+func (g *gitLab) Name() string { return TypeGitLab }
+
+// projectPath returns the API path of the project; GitLab accepts the
+// URL-encoded namespace path in place of the numeric ID.
+func (g *gitLab) projectPath() string {
+	return "/projects/" + url.PathEscape(g.remote.Path())
+}
+
+func (g *gitLab) CreatePR(ctx context.Context, req PRRequest) (*PR, error) {
+	title := req.Title
+	if req.Draft {
+		title = "Draft: " + title
+	}
+	in := map[string]any{
+		"source_branch": req.Head,
+		"target_branch": req.Base,
+		"title":         title,
+		"description":   req.Body,
+	}
+	if len(req.Labels) > 0 {
+		in["labels"] = strings.Join(req.Labels, ",")
+	}
+	if len(req.Reviewers) > 0 {
+		ids, err := g.userIDs(ctx, req.Reviewers)
+		if err != nil {
+			return nil, fmt.Errorf("resolve reviewers: %w", err)
+		}
+		in["reviewer_ids"] = ids
+	}
+
+	var out gitLabMR
+	if err := g.api.do(ctx, http.MethodPost, g.projectPath()+"/merge_requests", in, &out); err != nil {
+		if isStatus(err, http.StatusConflict) {
+			return nil, ErrPRExists
+		}
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// userIDs maps usernames to GitLab user IDs.
+func (g *gitLab) userIDs(ctx context.Context, usernames []string) ([]int, error) {
+	ids := make([]int, 0, len(usernames))
+	for _, name := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		q := url.Values{"username": {name}}
+		if err := g.api.do(ctx, http.MethodGet, "/users?"+q.Encode(), nil, &users); err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("unknown user %q", name)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+func (g *gitLab) FindPR(ctx context.Context, branch string) (*PR, error) {
+	q := url.Values{"source_branch": {branch}, "order_by": {"created_at"}, "sort": {"desc"}}
+	var out []gitLabMR
+	if err := g.api.do(ctx, http.MethodGet, g.projectPath()+"/merge_requests?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0].toPR(), nil
+}
+
+func (g *gitLab) UpdatePR(ctx context.Context, pr *PR, update PRUpdate) error {
+	in := map[string]any{}
+	if update.Base != "" {
+		in["target_branch"] = update.Base
+	}
+	if update.Body != "" {
+		in["description"] = update.Body
+	}
+	return g.api.do(ctx, http.MethodPut, fmt.Sprintf("%s/merge_requests/%d", g.projectPath(), pr.Number), in, nil)
+}
@@ -0,0 +1,78 @@
+package githost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestGitLab(srv *httptest.Server) *gitLab {
+	return &gitLab{api: newAPIClient(srv.URL, "tok"), remote: Remote{Host: "gitlab.com", Owner: "grp/sub", Name: "proj"}}
+}
+
+func TestGitLab_CreateMR(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("GET /users?username=alice", http.StatusOK, []any{map[string]any{"id": 42}})
+	f.on("POST /projects/grp%2Fsub%2Fproj/merge_requests", http.StatusCreated, map[string]any{
+		"iid": 3, "web_url": "https://gitlab.com/grp/sub/proj/-/merge_requests/3", "state": "opened",
+		"source_branch": "feat", "target_branch": "main",
+	})
+
+	pr, err := newTestGitLab(srv).CreatePR(context.Background(), PRRequest{
+		Head: "feat", Base: "main", Title: "T", Body: "B", Draft: true,
+		Labels: []string{"a", "b"}, Reviewers: []string{"alice"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 3 || pr.State != StateOpen || pr.Base != "main" {
+		t.Errorf("pr = %+v", pr)
+	}
+	body := f.bodies["POST /projects/grp%2Fsub%2Fproj/merge_requests"]
+	if body["title"] != "Draft: T" || body["labels"] != "a,b" || body["description"] != "B" {
+		t.Errorf("request body = %v", body)
+	}
+	if ids, _ := body["reviewer_ids"].([]any); len(ids) != 1 || ids[0] != float64(42) {
+		t.Errorf("reviewer_ids = %v", body["reviewer_ids"])
+	}
+	if got := f.headers.Get("PRIVATE-TOKEN"); got != "tok" {
+		t.Errorf("PRIVATE-TOKEN = %q", got)
+	}
+}
+
+func TestGitLab_CreateMRExists(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("POST /projects/grp%2Fsub%2Fproj/merge_requests", http.StatusConflict, map[string]any{
+		"message": []any{"Another open merge request already exists for this source branch"},
+	})
+	_, err := newTestGitLab(srv).CreatePR(context.Background(), PRRequest{Head: "feat", Base: "main"})
+	if !errors.Is(err, ErrPRExists) {
+		t.Errorf("err = %v, want ErrPRExists", err)
+	}
+}
+
+func TestGitLab_FindAndUpdateMR(t *testing.T) {
+	f, srv := newFakeAPI(t)
+	f.on("GET /projects/grp%2Fsub%2Fproj/merge_requests?order_by=created_at&sort=desc&source_branch=feat", http.StatusOK, []any{
+		map[string]any{"iid": 3, "state": "merged", "source_branch": "feat", "target_branch": "parent"},
+	})
+	f.on("PUT /projects/grp%2Fsub%2Fproj/merge_requests/3", http.StatusOK, map[string]any{})
+
+	gl := newTestGitLab(srv)
+	pr, err := gl.FindPR(context.Background(), "feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr == nil || pr.State != StateMerged || pr.Base != "parent" {
+		t.Fatalf("pr = %+v", pr)
+	}
+	if err := gl.UpdatePR(context.Background(), pr, PRUpdate{Base: "main", Body: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	body := f.bodies["PUT /projects/grp%2Fsub%2Fproj/merge_requests/3"]
+	if body["target_branch"] != "main" || body["description"] != "new" {
+		t.Errorf("update body = %v", body)
+	}
+}
@@ -109,6 +109,25 @@ func TestTextReporter_PrintSummary(t *testing.T) {
 	}
 }
 
+func TestTextReporter_PrintSummaryPauses(t *testing.T) {
+	start := time.Date(2026, 3, 1, 14, 0, 0, 0, time.Local)
+	report := &task.RunReport{
+		TotalTasks: 2,
+		Completed:  2,
+		Pauses: []task.RunPause{
+			{TaskID: "a", StartedAt: start, EndedAt: start.Add(12 * time.Minute), Resumed: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	NewTextReporter(&buf, false).PrintSummary(report)
+
+	out := buf.String()
+	if !strings.Contains(out, "Paused for rate limit: 12m0s from 14:00, triggered by a (resumed)") {
+		t.Errorf("expected pause line, got:\n%s", out)
+	}
+}
+
 func TestTextReporter_NoColor(t *testing.T) {
 	var buf bytes.Buffer
 	r := NewTextReporter(&buf, false)
@@ -169,6 +169,9 @@ func (r *TextReporter) PrintSummary(report *task.RunReport) {
 	if report.MergeConflicts > 0 {
 		fmt.Fprintf(r.w, "%sMerge conflicts: %d%s  ", r.c(colorRed), report.MergeConflicts, r.c(colorReset))
 	}
+	if report.RiskReviews > 0 {
+		fmt.Fprintf(r.w, "%sRisk flagged: %d (%d blocked)%s  ", r.c(colorYellow), report.RiskReviews, report.RiskBlocked, r.c(colorReset))
+	}
 	if report.Remediations > 0 {
 		fmt.Fprintf(r.w, "%sRemediated: %d%s  ", r.c(colorYellow), report.Remediations, r.c(colorReset))
 	}
@@ -197,6 +200,16 @@ func (r *TextReporter) PrintSummary(report *task.RunReport) {
 	if report.TotalTokens != nil {
 		fmt.Fprintf(r.w, "Tokens: %s\n", formatTokens(report.TotalTokens))
 	}
+	for _, p := range report.Pauses {
+		status := "resumed"
+		if !p.Resumed {
+			status = "not resumed"
+		}
+		fmt.Fprintf(r.w, "%sPaused for rate limit:%s %s from %s, triggered by %s (%s)\n",
+			r.c(colorYellow), r.c(colorReset),
+			p.EndedAt.Sub(p.StartedAt).Truncate(time.Second),
+			p.StartedAt.Local().Format("15:04"), p.TaskID, status)
+	}
 }
 
 // PrintModelResolutions writes model auto-resolution information.
@@ -319,12 +332,20 @@ func runnerSuffix(res *task.TaskResult) string {
 		}
 		parts = append(parts, fmt.Sprintf("%d %s", retryCount, label))
 	}
+	if n := countRolledBack(res.Attempts); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d rolled back", n))
+	}
 	if res.AutoCommitted {
 		parts = append(parts, "auto-committed")
 	}
 	if res.Remediated {
 		parts = append(parts, fmt.Sprintf("remediated by %s", res.RemediatedBy))
 	}
+	if res.Changes.Blocked() {
+		parts = append(parts, "risk blocked: "+strings.Join(res.Changes.RuleNames(), ", "))
+	} else if res.Changes.NeedsReview() {
+		parts = append(parts, "needs review: "+strings.Join(res.Changes.RuleNames(), ", "))
+	}
 	if res.MergeConflict {
 		parts = append(parts, "merge conflict: "+res.WorktreeBranch)
 	} else if res.WorktreeBranch != "" {
@@ -357,6 +378,17 @@ func countRetries(attempts []task.AttemptInfo) int {
 	return n
 }
 
+// countRolledBack counts attempts whose changes were rolled back.
+func countRolledBack(attempts []task.AttemptInfo) int {
+	n := 0
+	for _, a := range attempts {
+		if a.RolledBack {
+			n++
+		}
+	}
+	return n
+}
+
 // uniqueAttemptRunners returns deduplicated runner names from attempts.
 func uniqueAttemptRunners(attempts []task.AttemptInfo) []string {
 	seen := make(map[string]bool)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"sort"
 	"strings"
 	"time"
@@ -120,6 +121,23 @@ type TaskControl struct {
 	CancelTask  func(id string)
 	RequeueTask func(id, runner string)
 	Runners     []string // available runner names for picker
+
+	// ActivePause reports a --wait-for-reset pause in progress (nil when dispatching).
+	ActivePause func() *task.RunPause
+
+	// Task editor — nil-safe. DraftTask writes an editable draft of a task
+	// (a new one when id is empty) and returns its path; ApplyDraft applies
+	// the edited draft to the run and returns the affected task ID.
+	DraftTask  func(id string) (string, error)
+	ApplyDraft func(id, path string) (string, error)
+	Editor     string // command used to open drafts, e.g. "vim" or "code -w"
+}
+
+// editDoneMsg is sent when the editor opened on a task draft exits.
+type editDoneMsg struct {
+	taskID string // empty for a new task
+	path   string
+	err    error
 }
 
 // overlayState tracks the runner picker modal.
@@ -171,7 +189,8 @@ type TUIModel struct {
 	agentCursor int
 
 	// Inline confirmation prompt
-	confirm confirmState
+	confirm   confirmState
+	followups []FollowupProposalMsg // proposals waiting for the confirm prompt
 
 	// Toast messages (temporary flash)
 	toasts []toast
@@ -194,6 +213,7 @@ type confirmState struct {
 	message  string
 	warnings []string
 	onYes    func()
+	onNo     func()
 }
 
 // toast is a temporary flash message with auto-expiry.
@@ -202,6 +222,15 @@ type toast struct {
 	expiry  time.Time
 }
 
+// FollowupProposalMsg asks the user to approve a follow-up task that an
+// agent proposed. Decide must be called exactly once with the answer.
+type FollowupProposalMsg struct {
+	ParentID string
+	TaskID   string
+	Title    string
+	Decide   func(approved bool)
+}
+
 // GraylistEventMsg is sent to the TUI when auto-graylist fires.
 type GraylistEventMsg struct {
 	Runner string
@@ -247,6 +276,12 @@ func (m TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.addToast(fmt.Sprintf("%s:%s auto-graylisted (%s)", msg.Runner, msg.Model, msg.Reason))
 		return m, nil
 
+	case FollowupProposalMsg:
+		m.addToast(fmt.Sprintf("%s proposed follow-up %s", msg.ParentID, msg.TaskID))
+		m.followups = append(m.followups, msg)
+		m.nextFollowup()
+		return m, nil
+
 	case tea.KeyMsg:
 		// Confirm prompt intercepts all keys when active.
 		if m.confirm.active {
@@ -380,6 +415,20 @@ func (m TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case "e":
+			// Edit queued/failed task in $EDITOR
+			if m.focusedPanel == panelTasks && m.canEdit() {
+				if id := m.cursorTaskID(); id != "" && editableState(m.results[id]) {
+					return m, m.editTask(id)
+				}
+			}
+
+		case "a":
+			// Add an ad-hoc task to the live graph
+			if m.focusedPanel == panelTasks && m.canEdit() && !m.done {
+				return m, m.editTask("")
+			}
+
 		case "enter":
 			// Drilldown: show selected task's stderr in bottom panel
 			if m.focusedPanel == panelTasks && m.showBottomPanel() {
@@ -433,6 +482,10 @@ func (m TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case editDoneMsg:
+		m.applyEdit(msg)
+		return m, nil
+
 	case tickMsg:
 		if !m.paused {
 			m.results = m.getResults()
@@ -475,6 +528,66 @@ func (m TUIModel) updateOverlay(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// canEdit reports whether the task editor is wired up.
+func (m TUIModel) canEdit() bool {
+	return m.taskCtrl != nil && m.taskCtrl.DraftTask != nil && m.taskCtrl.ApplyDraft != nil
+}
+
+// editableState reports whether a task can be edited: it has not started,
+// or it ended without success.
+func editableState(res *task.TaskResult) bool {
+	if res == nil {
+		return true // pending (no result yet)
+	}
+	switch res.State {
+	case task.StatePending, task.StateReady, task.StateFailed, task.StateSkipped, task.StateRateLimited:
+		return true
+	}
+	return false
+}
+
+// editTask writes a draft for id (a new task when empty) and suspends the
+// TUI while the editor runs on it.
+func (m *TUIModel) editTask(id string) tea.Cmd {
+	path, err := m.taskCtrl.DraftTask(id)
+	if err != nil {
+		m.addToast(fmt.Sprintf("✗ edit: %v", err))
+		return nil
+	}
+	args := strings.Fields(m.taskCtrl.Editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editDoneMsg{taskID: id, path: path, err: err}
+	})
+}
+
+// applyEdit applies a draft after its editor exits and reports the outcome.
+func (m *TUIModel) applyEdit(msg editDoneMsg) {
+	if msg.err != nil {
+		m.addToast(fmt.Sprintf("✗ editor: %v", msg.err))
+		return
+	}
+	id, err := m.taskCtrl.ApplyDraft(msg.taskID, msg.path)
+	switch {
+	case err != nil:
+		m.addToast(fmt.Sprintf("✗ edit: %v", err))
+	case id == "":
+		m.addToast("edit: no changes")
+	case msg.taskID == "":
+		m.addToast(fmt.Sprintf("added task %s", id))
+	default:
+		if res := m.results[id]; res != nil && (res.State == task.StateFailed || res.State == task.StateSkipped || res.State == task.StateRateLimited) {
+			m.addToast(fmt.Sprintf("updated task %s — R:requeue", id))
+		} else {
+			m.addToast(fmt.Sprintf("updated task %s", id))
+		}
+	}
+	m.results = m.getResults()
+}
+
 // cursorTaskID returns the task ID at the current cursor position.
 func (m TUIModel) cursorTaskID() string {
 	ids := m.buildTaskIDs()
@@ -652,11 +765,31 @@ func (m TUIModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.confirm = confirmState{}
 	case "n", "esc":
+		if m.confirm.onNo != nil {
+			m.confirm.onNo()
+		}
 		m.confirm = confirmState{}
 	}
+	m.nextFollowup()
 	return m, nil
 }
 
+// nextFollowup shows the oldest waiting follow-up proposal once no other
+// confirm prompt is open.
+func (m *TUIModel) nextFollowup() {
+	if m.confirm.active || len(m.followups) == 0 {
+		return
+	}
+	p := m.followups[0]
+	m.followups = m.followups[1:]
+	m.confirm = confirmState{
+		active:  true,
+		message: fmt.Sprintf("Add follow-up %s from %s (%s)? [y/n]", p.TaskID, p.ParentID, p.Title),
+		onYes:   func() { p.Decide(true) },
+		onNo:    func() { p.Decide(false) },
+	}
+}
+
 func (m *TUIModel) addToast(msg string) {
 	m.toasts = append(m.toasts, toast{message: msg, expiry: time.Now().Add(5 * time.Second)})
 }
@@ -1118,7 +1251,7 @@ func (m TUIModel) View() string {
 	}
 	helpKeys := "tab: switch [%s]  ↑↓/jk: scroll  g/G: top/bottom  p: pause  q: quit"
 	if m.taskCtrl != nil && m.focusedPanel == panelTasks {
-		helpKeys = "tab: switch [%s]  ↑↓/jk: cursor  enter: detail  x: cancel  R: requeue  r: runner  q: quit"
+		helpKeys = "tab: switch [%s]  ↑↓/jk: cursor  enter: detail  x: cancel  R: requeue  r: runner  e: edit  a: add  q: quit"
 	} else if m.focusedPanel == panelAgents {
 		helpKeys = "tab: switch [%s]  ↑↓/jk: cursor  g: graylist  w: whitelist  q: quit"
 	}
@@ -1158,6 +1291,23 @@ func padLines(content string, targetWidth int) string {
 	return strings.Join(lines, "\n")
 }
 
+// resetCountdown renders the time left until a rate-limit pause resumes
+// dispatch, or "" when the run is not waiting for a reset.
+func (m TUIModel) resetCountdown() string {
+	if m.taskCtrl == nil || m.taskCtrl.ActivePause == nil {
+		return ""
+	}
+	p := m.taskCtrl.ActivePause()
+	if p == nil {
+		return ""
+	}
+	left := time.Until(p.ResumeAt).Truncate(time.Second)
+	if left < 0 {
+		left = 0
+	}
+	return "  " + pauseStyle.Render(fmt.Sprintf("⏸ rate limited — resuming in %s (%s)", left, p.ResumeAt.Local().Format("15:04")))
+}
+
 // viewSinglePanel is the original single-panel layout (no log panel).
 func (m TUIModel) viewSinglePanel() string {
 	var b strings.Builder
@@ -1185,6 +1335,7 @@ func (m TUIModel) viewSinglePanel() string {
 	if m.paused {
 		header += "  " + pauseStyle.Render("⏸ PAUSED")
 	}
+	header += m.resetCountdown()
 	b.WriteString(headerStyle.Render(header))
 	b.WriteString("\n")
 
@@ -1235,7 +1386,7 @@ func (m TUIModel) viewSinglePanel() string {
 	}
 
 	if m.taskCtrl != nil {
-		b.WriteString(helpStyle.Render("  ↑↓/jk: cursor  x: cancel  R: requeue  r: runner  p: pause  q: quit"))
+		b.WriteString(helpStyle.Render("  ↑↓/jk: cursor  x: cancel  R: requeue  r: runner  e: edit  a: add  p: pause  q: quit"))
 	} else {
 		b.WriteString(helpStyle.Render("  ↑↓/jk: scroll  g/G: top/bottom  p: pause  q: quit"))
 	}
@@ -1269,6 +1420,7 @@ func (m TUIModel) renderTaskContent(panelHeight int) string {
 	if m.paused {
 		header += "  " + pauseStyle.Render("⏸ PAUSED")
 	}
+	header += m.resetCountdown()
 	b.WriteString(headerStyle.Render(header))
 	b.WriteString("\n")
 
@@ -454,6 +454,22 @@ func TestTaskCursorMovement(t *testing.T) {
 	}
 }
 
+func TestResetCountdown(t *testing.T) {
+	g, _ := task.BuildGraph([]task.Task{{ID: "t1", Repo: "org/a", Priority: 1, Title: "A"}})
+	var active *task.RunPause
+	ctrl := &TaskControl{ActivePause: func() *task.RunPause { return active }}
+	m := NewTUIModel(g, func() map[string]*task.TaskResult { return nil }, nil, "", time.Now(), nil, ctrl, "")
+
+	if got := m.resetCountdown(); got != "" {
+		t.Errorf("expected no countdown without a pause, got %q", got)
+	}
+	active = &task.RunPause{TaskID: "t1", ResumeAt: time.Now().Add(5 * time.Minute)}
+	got := m.resetCountdown()
+	if !strings.Contains(got, "rate limited — resuming in 4m5") {
+		t.Errorf("unexpected countdown: %q", got)
+	}
+}
+
 func TestCursorTaskID(t *testing.T) {
 	tasks := []task.Task{
 		{ID: "t1", Repo: "org/a", Priority: 1, Title: "A"},
@@ -605,3 +621,102 @@ func TestBuildTaskLines_ShowsCursor(t *testing.T) {
 		t.Error("second line should not have cursor marker")
 	}
 }
+
+func TestEditKey_OpensEditorOnQueuedTask(t *testing.T) {
+	tasks := []task.Task{
+		{ID: "t1", Repo: "org/a", Priority: 1, Title: "A"},
+		{ID: "t2", Repo: "org/a", Priority: 2, Title: "B"},
+	}
+	g, _ := task.BuildGraph(tasks)
+	var drafted []string
+	ctrl := &TaskControl{
+		CancelTask:  func(string) {},
+		RequeueTask: func(string, string) {},
+		DraftTask: func(id string) (string, error) {
+			drafted = append(drafted, id)
+			return "/tmp/draft.yaml", nil
+		},
+		ApplyDraft: func(id, path string) (string, error) { return id, nil },
+		Editor:     "true",
+	}
+	results := map[string]*task.TaskResult{
+		"t1": {TaskID: "t1", State: task.StateRunning},
+		"t2": {TaskID: "t2", State: task.StatePending},
+	}
+	m := NewTUIModel(g, func() map[string]*task.TaskResult { return results }, nil, "", time.Now(), nil, ctrl, "")
+	m.results = results
+	m.width = 120
+	m.height = 40
+
+	// running task is not editable
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}); cmd != nil || len(drafted) != 0 {
+		t.Error("e should not open the editor on a running task")
+	}
+
+	m.taskCursor = 1
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}); cmd == nil {
+		t.Error("e should open the editor on a queued task")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}); cmd == nil {
+		t.Error("a should open the editor for a new task")
+	}
+	if len(drafted) != 2 || drafted[0] != "t2" || drafted[1] != "" {
+		t.Errorf("drafted = %q", drafted)
+	}
+}
+
+func TestEditDone_AppliesDraft(t *testing.T) {
+	g, _ := task.BuildGraph([]task.Task{{ID: "t1", Repo: "org/a", Priority: 1, Title: "A"}})
+	var applied string
+	ctrl := &TaskControl{
+		DraftTask: func(string) (string, error) { return "", nil },
+		ApplyDraft: func(id, path string) (string, error) {
+			applied = path
+			return "adhoc-1", nil
+		},
+	}
+	results := map[string]*task.TaskResult{}
+	m := NewTUIModel(g, func() map[string]*task.TaskResult { return results }, nil, "", time.Now(), nil, ctrl, "")
+
+	m2, _ := m.Update(editDoneMsg{path: "/tmp/adhoc-1.yaml"})
+	model := m2.(TUIModel)
+	if applied != "/tmp/adhoc-1.yaml" {
+		t.Errorf("applied = %q", applied)
+	}
+	if len(model.toasts) != 1 || !strings.Contains(model.toasts[0].message, "added task adhoc-1") {
+		t.Errorf("toasts = %+v", model.toasts)
+	}
+}
+
+func TestFollowupProposal_ConfirmQueue(t *testing.T) {
+	g, _ := task.BuildGraph([]task.Task{{ID: "t1", Repo: "org/a", Priority: 1, Title: "A"}})
+	m := NewTUIModel(g, func() map[string]*task.TaskResult { return nil }, nil, "", time.Now(), nil, nil, "")
+	decisions := map[string]bool{}
+	propose := func(id string) FollowupProposalMsg {
+		return FollowupProposalMsg{ParentID: "t1", TaskID: id, Title: "fix " + id, Decide: func(ok bool) { decisions[id] = ok }}
+	}
+
+	m2, _ := m.Update(propose("f1"))
+	m3, _ := m2.Update(propose("f2"))
+	model := m3.(TUIModel)
+	if !model.confirm.active || !strings.Contains(model.confirm.message, "f1") {
+		t.Fatalf("confirm = %+v", model.confirm)
+	}
+
+	m4, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	model = m4.(TUIModel)
+	if !model.confirm.active || !strings.Contains(model.confirm.message, "f2") {
+		t.Fatalf("second proposal should be shown next, confirm = %+v", model.confirm)
+	}
+	m5, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	model = m5.(TUIModel)
+	if model.confirm.active {
+		t.Error("confirm should close when the queue is empty")
+	}
+	if ok, seen := decisions["f1"]; !seen || !ok {
+		t.Error("f1 should be approved")
+	}
+	if ok, seen := decisions["f2"]; !seen || ok {
+		t.Error("f2 should be declined")
+	}
+}
@@ -46,6 +46,28 @@ func AutoCommit(ctx context.Context, repoDir string, t *task.Task) (bool, error)
 	return true, nil
 }
 
+// CommitPaths stages and commits only the given paths with the task's
+// derived commit message, leaving any other local changes uncommitted.
+func CommitPaths(ctx context.Context, repoDir string, t *task.Task, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	commitCtx, cancel := context.WithTimeout(ctx, autoCommitTimeout)
+	defer cancel()
+
+	args := append([]string{"add", "--"}, paths...)
+	if err := runGitCmd(commitCtx, repoDir, args...); err != nil {
+		return fmt.Errorf("git add: %w", err)
+	}
+	msg := DeriveCommitMessage(t)
+	args = append([]string{"commit", "-m", msg, "--"}, paths...)
+	if err := runGitCmd(commitCtx, repoDir, args...); err != nil {
+		return fmt.Errorf("git commit: %w", err)
+	}
+	slog.Info("committed task changes", "task", t.ID, "files", len(paths), "message", msg)
+	return nil
+}
+
 // changedFiles returns file paths from git status --porcelain.
 // Untracked files (status ??) are filtered through git check-ignore to avoid
 // attempting to add files covered by .gitignore.
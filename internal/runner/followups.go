@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// FollowupsFile is the file an agent or script may write into its output
+// dir to propose follow-up tasks.
+const FollowupsFile = "followups.json"
+
+// followupsBlock matches a fenced block tagged "followups" in a message.
+var followupsBlock = regexp.MustCompile("(?s)```followups[ \\t]*\\n(.*?)\\n?```")
+
+// ReadFollowups collects follow-up tasks proposed by an attempt: entries in
+// attemptDir/followups.json and in ```followups fenced blocks of the agent's
+// last message. Each source holds a JSON array of tasks or an object with a
+// "tasks" array. Entries are returned as written; the caller validates them.
+func ReadFollowups(attemptDir, lastMsg string) ([]task.Task, error) {
+	var tasks []task.Task
+	var errs []error
+
+	data, err := os.ReadFile(filepath.Join(attemptDir, FollowupsFile))
+	switch {
+	case err == nil:
+		parsed, err := parseFollowups(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", FollowupsFile, err))
+		}
+		tasks = append(tasks, parsed...)
+	case !os.IsNotExist(err):
+		errs = append(errs, fmt.Errorf("read %s: %w", FollowupsFile, err))
+	}
+
+	for _, m := range followupsBlock.FindAllStringSubmatch(lastMsg, -1) {
+		parsed, err := parseFollowups([]byte(m[1]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("followups block: %w", err))
+		}
+		tasks = append(tasks, parsed...)
+	}
+	return tasks, errors.Join(errs...)
+}
+
+func parseFollowups(data []byte) ([]task.Task, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[0] == '[' {
+		var tasks []task.Task
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			return nil, err
+		}
+		return tasks, nil
+	}
+	var wrapped struct {
+		Tasks []task.Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.Tasks, nil
+}
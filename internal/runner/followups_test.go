@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFollowups(t *testing.T) {
+	dir := t.TempDir()
+	file := `{"tasks": [{"title": "Update tests", "prompt": "update tests in pkg/x", "depends_on": "other"}]}`
+	if err := os.WriteFile(filepath.Join(dir, FollowupsFile), []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	msg := "Done.\n\n```followups\n[{\"id\": \"docs\", \"prompt\": \"document the flag\"}]\n```\n"
+
+	tasks, err := ReadFollowups(dir, msg)
+	if err != nil {
+		t.Fatalf("ReadFollowups: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	if tasks[0].Title != "Update tests" || len(tasks[0].DependsOn) != 1 || tasks[0].DependsOn[0] != "other" {
+		t.Errorf("file task = %+v", tasks[0])
+	}
+	if tasks[1].ID != "docs" || tasks[1].Prompt != "document the flag" {
+		t.Errorf("message task = %+v", tasks[1])
+	}
+}
+
+func TestReadFollowups_NoneAndInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if tasks, err := ReadFollowups(dir, "all done"); err != nil || tasks != nil {
+		t.Errorf("no followups = %v, %v", tasks, err)
+	}
+
+	msg := "```followups\nnot json\n```\n```followups\n[{\"prompt\": \"ok\"}]\n```"
+	tasks, err := ReadFollowups(dir, msg)
+	if err == nil {
+		t.Error("expected error for invalid block")
+	}
+	if len(tasks) != 1 || tasks[0].Prompt != "ok" {
+		t.Errorf("valid block should still be returned, got %+v", tasks)
+	}
+}
@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// handoffLogBytes is how much of an attempt's log tail is read.
+const handoffLogBytes = 16 * 1024
+
+// handoffMaxFiles caps the changed-file list in a handoff.
+const handoffMaxFiles = 30
+
+// Handoff summarizes a failed attempt for the next runner in a cascade.
+type Handoff struct {
+	Runner     string
+	State      task.TaskState
+	Error      string
+	LastMsg    string   // agent's final message
+	Output     string   // tail of the attempt's error output
+	Files      []string // files the attempt changed
+	RolledBack bool     // the attempt's changes were discarded
+}
+
+// ReadHandoff builds a Handoff from an attempt's result and the files its
+// runner left in attemptDir: output.md (codex) when the result has no last
+// message, and the tail of stderr.log, or output.log for script runners.
+func ReadHandoff(runnerName, attemptDir string, result *task.TaskResult) *Handoff {
+	h := &Handoff{
+		Runner:  runnerName,
+		State:   result.State,
+		Error:   result.Error,
+		LastMsg: strings.TrimSpace(result.LastMsg),
+	}
+	if h.LastMsg == "" {
+		if data, err := os.ReadFile(filepath.Join(attemptDir, "output.md")); err == nil {
+			h.LastMsg = strings.TrimSpace(string(data))
+		}
+	}
+	for _, name := range []string{"stderr.log", "output.log"} {
+		if out := strings.TrimSpace(readTail(filepath.Join(attemptDir, name), handoffLogBytes)); out != "" {
+			h.Output = out
+			break
+		}
+	}
+	return h
+}
+
+// Render formats the handoff as a prompt section of at most maxBytes bytes.
+// The last message and error output share what the header leaves, keeping
+// the end of each since that is where agents and tools report the outcome.
+func (h *Handoff) Render(maxBytes int) string {
+	var b strings.Builder
+	b.WriteString("\n\n## Previous attempt\n")
+	fmt.Fprintf(&b, "A previous attempt at this task by runner %q did not succeed (%s)", h.Runner, strings.ToLower(h.State.String()))
+	if h.Error != "" {
+		fmt.Fprintf(&b, ": %s", firstLine(h.Error))
+	}
+	b.WriteString(".\n")
+	if h.RolledBack {
+		b.WriteString("Its changes were discarded; the repository is back at the state before that attempt.\n")
+	} else if len(h.Files) > 0 {
+		b.WriteString("Its changes are still in the working tree.\n")
+	}
+	b.WriteString("Use this to avoid repeating what did not work.\n")
+	if len(h.Files) > 0 {
+		files := h.Files
+		more := ""
+		if len(files) > handoffMaxFiles {
+			more = fmt.Sprintf(" (+%d more)", len(files)-handoffMaxFiles)
+			files = files[:handoffMaxFiles]
+		}
+		fmt.Fprintf(&b, "\nFiles it changed: %s%s\n", strings.Join(files, ", "), more)
+	}
+	header := b.String()
+	if len(header) >= maxBytes {
+		return headBytes(header, maxBytes)
+	}
+
+	const msgTitle, outTitle = "\nIts last message:\n```\n", "\nError output (tail):\n```\n"
+	const fence = "\n```\n"
+	budget := maxBytes - len(header)
+	msgBudget, outBudget := 0, 0
+	if h.LastMsg != "" {
+		msgBudget = len(h.LastMsg)
+	}
+	if h.Output != "" {
+		outBudget = len(h.Output)
+	}
+	overhead := 0
+	if msgBudget > 0 {
+		overhead += len(msgTitle) + len(fence)
+	}
+	if outBudget > 0 {
+		overhead += len(outTitle) + len(fence)
+	}
+	room := budget - overhead
+	if room <= 0 {
+		return header
+	}
+	msgBudget, outBudget = splitBudget(room, msgBudget, outBudget)
+
+	if msgBudget > 0 {
+		b.WriteString(msgTitle + tailBytes(h.LastMsg, msgBudget) + fence)
+	}
+	if outBudget > 0 {
+		b.WriteString(outTitle + tailBytes(h.Output, outBudget) + fence)
+	}
+	return b.String()
+}
+
+// splitBudget shares room between two wants, giving either one what the
+// other doesn't use.
+func splitBudget(room, a, b int) (int, int) {
+	if a+b <= room {
+		return a, b
+	}
+	half := room / 2
+	switch {
+	case a <= half:
+		return a, room - a
+	case b <= half:
+		return room - b, b
+	default:
+		return half, room - half
+	}
+}
+
+// tailBytes returns at most n bytes from the end of s, marking a cut with
+// "…" and never splitting a UTF-8 sequence.
+func tailBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	const mark = "…"
+	if n <= len(mark) {
+		return ""
+	}
+	s = s[len(s)-(n-len(mark)):]
+	for len(s) > 0 && !utf8.RuneStart(s[0]) {
+		s = s[1:]
+	}
+	return mark + s
+}
+
+// headBytes returns at most n bytes from the start of s without splitting a
+// UTF-8 sequence.
+func headBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// readTail returns up to n bytes from the end of the file at path.
+func readTail(path string, n int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	if info.Size() > n {
+		if _, err := f.Seek(info.Size()-n, io.SeekStart); err != nil {
+			return ""
+		}
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func TestReadHandoff(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "output.md"), []byte("I tried to fix the parser\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "stderr.log"), []byte("compiling...\nparser.go:12: undefined: tok\n"), 0o644)
+
+	h := ReadHandoff("codex", dir, &task.TaskResult{State: task.StateFailed, Error: "exit status 1"})
+	if h.LastMsg != "I tried to fix the parser" || !strings.HasSuffix(h.Output, "undefined: tok") {
+		t.Errorf("handoff = %+v", h)
+	}
+
+	h = ReadHandoff("claude", dir, &task.TaskResult{State: task.StateFailed, LastMsg: "from events"})
+	if h.LastMsg != "from events" {
+		t.Errorf("result last message should win, got %q", h.LastMsg)
+	}
+}
+
+func TestHandoffRender(t *testing.T) {
+	h := &Handoff{
+		Runner:     "codex",
+		State:      task.StateFailed,
+		Error:      "build failed\nmore detail",
+		LastMsg:    "Done, but tests fail.",
+		Output:     "FAIL ./pkg",
+		Files:      []string{"a.go", "b.go"},
+		RolledBack: true,
+	}
+	out := h.Render(task.DefaultHandoffBytes)
+	for _, want := range []string{
+		`runner "codex" did not succeed (failed): build failed.`,
+		"Its changes were discarded",
+		"Files it changed: a.go, b.go",
+		"Its last message:\n```\nDone, but tests fail.\n```",
+		"Error output (tail):\n```\nFAIL ./pkg\n```",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("render missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "more detail") {
+		t.Error("only the first error line belongs in the header")
+	}
+}
+
+func TestHandoffRender_Cap(t *testing.T) {
+	h := &Handoff{
+		Runner:  "codex",
+		State:   task.StateFailed,
+		LastMsg: strings.Repeat("m", 5000) + "END-MSG",
+		Output:  strings.Repeat("é", 3000) + "END-OUT",
+	}
+	out := h.Render(1000)
+	if len(out) > 1000 {
+		t.Fatalf("len = %d, want <= 1000", len(out))
+	}
+	if !strings.Contains(out, "END-MSG") || !strings.Contains(out, "END-OUT") {
+		t.Errorf("tails should be kept:\n%s", out)
+	}
+	if !strings.Contains(out, "…") {
+		t.Error("truncation should be marked")
+	}
+	if !strings.HasPrefix(h.Render(20), "\n\n## Previous") || len(h.Render(20)) > 20 {
+		t.Errorf("tiny cap = %q", h.Render(20))
+	}
+}
+
+func TestSplitBudget(t *testing.T) {
+	tests := []struct{ room, a, b, wantA, wantB int }{
+		{100, 30, 40, 30, 40},
+		{100, 20, 500, 20, 80},
+		{100, 500, 10, 90, 10},
+		{100, 500, 500, 50, 50},
+	}
+	for _, tt := range tests {
+		if a, b := splitBudget(tt.room, tt.a, tt.b); a != tt.wantA || b != tt.wantB {
+			t.Errorf("splitBudget(%d, %d, %d) = %d, %d", tt.room, tt.a, tt.b, a, b)
+		}
+	}
+}
@@ -1,12 +1,86 @@
 package runner
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
-// ProviderLimiter limits concurrent usage of runners by name.
+// ProviderLimiter limits concurrent usage of runners by name and paces
+// dispatch against per-runner request and token rates.
 // A zero or negative limit means no limiting for that runner.
 type ProviderLimiter struct {
 	sems map[string]chan struct{}
 	mu   sync.RWMutex
+
+	paceMu sync.Mutex
+	pacers map[string]*pacer
+	now    func() time.Time
+}
+
+// RateLimit describes the sustained throughput a runner may use.
+// Zero fields are unlimited. EstTokensPerTask is reserved from the token
+// bucket before dispatch; the difference to actual usage is settled afterwards.
+type RateLimit struct {
+	RequestsPerMinute float64
+	TokensPerMinute   float64
+	EstTokensPerTask  int
+}
+
+// pacer holds the request and token buckets for one runner.
+type pacer struct {
+	requests *tokenBucket
+	tokens   *tokenBucket
+	estimate float64
+}
+
+// tokenBucket is a classic token bucket that allows its balance to go
+// negative: a reservation is debited immediately and the caller waits
+// until the deficit has been refilled. This keeps waiting callers in
+// arrival order without a separate queue.
+type tokenBucket struct {
+	capacity float64
+	balance  float64
+	rate     float64 // per second
+	last     time.Time
+}
+
+func newTokenBucket(perMinute float64, now time.Time) *tokenBucket {
+	return &tokenBucket{capacity: perMinute, balance: perMinute, rate: perMinute / 60, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.balance += elapsed * b.rate
+		if b.balance > b.capacity {
+			b.balance = b.capacity
+		}
+	}
+	b.last = now
+}
+
+// reserve debits n and returns how long the caller must wait before the
+// debit is covered. Requests larger than the bucket are clamped so a single
+// oversized task cannot block forever.
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	if n > b.capacity {
+		n = b.capacity
+	}
+	b.balance -= n
+	if b.balance >= 0 {
+		return 0
+	}
+	return time.Duration(-b.balance / b.rate * float64(time.Second))
+}
+
+// adjust applies a post-hoc correction: positive n debits, negative n credits.
+func (b *tokenBucket) adjust(n float64, now time.Time) {
+	b.refill(now)
+	b.balance -= n
+	if b.balance > b.capacity {
+		b.balance = b.capacity
+	}
 }
 
 // NewProviderLimiter creates a limiter from a map of runner name → max concurrency.
@@ -18,7 +92,100 @@ func NewProviderLimiter(limits map[string]int) *ProviderLimiter {
 			sems[name] = make(chan struct{}, limit)
 		}
 	}
-	return &ProviderLimiter{sems: sems}
+	return &ProviderLimiter{sems: sems, pacers: make(map[string]*pacer), now: time.Now}
+}
+
+// SetRate configures dispatch pacing for the named runner. A RateLimit with
+// no request or token rate removes pacing for that runner.
+func (pl *ProviderLimiter) SetRate(name string, rl RateLimit) {
+	pl.paceMu.Lock()
+	defer pl.paceMu.Unlock()
+	if rl.RequestsPerMinute <= 0 && rl.TokensPerMinute <= 0 {
+		delete(pl.pacers, name)
+		return
+	}
+	now := pl.now()
+	p := &pacer{}
+	if rl.RequestsPerMinute > 0 {
+		p.requests = newTokenBucket(rl.RequestsPerMinute, now)
+	}
+	if rl.TokensPerMinute > 0 {
+		p.tokens = newTokenBucket(rl.TokensPerMinute, now)
+		p.estimate = float64(rl.EstTokensPerTask)
+	}
+	pl.pacers[name] = p
+}
+
+// Rate returns the pacing configured for the named runner.
+func (pl *ProviderLimiter) Rate(name string) (RateLimit, bool) {
+	pl.paceMu.Lock()
+	defer pl.paceMu.Unlock()
+	p, ok := pl.pacers[name]
+	if !ok {
+		return RateLimit{}, false
+	}
+	var rl RateLimit
+	if p.requests != nil {
+		rl.RequestsPerMinute = p.requests.capacity
+	}
+	if p.tokens != nil {
+		rl.TokensPerMinute = p.tokens.capacity
+		rl.EstTokensPerTask = int(p.estimate)
+	}
+	return rl, true
+}
+
+// reserve books one request (and the estimated tokens) for the named runner
+// and returns how long the caller must wait before dispatching.
+func (pl *ProviderLimiter) reserve(name string) time.Duration {
+	pl.paceMu.Lock()
+	defer pl.paceMu.Unlock()
+	p, ok := pl.pacers[name]
+	if !ok {
+		return 0
+	}
+	now := pl.now()
+	var wait time.Duration
+	if p.requests != nil {
+		wait = p.requests.reserve(1, now)
+	}
+	if p.tokens != nil && p.estimate > 0 {
+		if w := p.tokens.reserve(p.estimate, now); w > wait {
+			wait = w
+		}
+	}
+	return wait
+}
+
+// Wait blocks until the named runner's request and token budget allows
+// another dispatch, or ctx is done. Returns immediately if no rate is
+// configured for the name.
+func (pl *ProviderLimiter) Wait(ctx context.Context, name string) error {
+	wait := pl.reserve(name)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Settle corrects the token bucket with a finished task's actual usage.
+// The estimate reserved in Wait is replaced by tokensUsed, so heavy tasks
+// slow down subsequent dispatches and light ones free up headroom.
+func (pl *ProviderLimiter) Settle(name string, tokensUsed int) {
+	pl.paceMu.Lock()
+	defer pl.paceMu.Unlock()
+	p, ok := pl.pacers[name]
+	if !ok || p.tokens == nil || tokensUsed <= 0 {
+		return
+	}
+	p.tokens.adjust(float64(tokensUsed)-p.estimate, pl.now())
 }
 
 // Acquire blocks until a slot is available for the named runner.
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -66,3 +67,82 @@ func TestProviderLimiter_IndependentRunners(t *testing.T) {
 	pl.Release("codex")
 	pl.Release("claude")
 }
+
+func TestProviderLimiter_RequestPacing(t *testing.T) {
+	pl := NewProviderLimiter(nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pl.now = func() time.Time { return now }
+	pl.SetRate("claude", RateLimit{RequestsPerMinute: 2})
+
+	// burst up to the per-minute capacity is immediate
+	if w := pl.reserve("claude"); w != 0 {
+		t.Fatalf("first request should not wait, got %s", w)
+	}
+	if w := pl.reserve("claude"); w != 0 {
+		t.Fatalf("second request should not wait, got %s", w)
+	}
+	// third request must wait for one refill (30s at 2 rpm)
+	if w := pl.reserve("claude"); w != 30*time.Second {
+		t.Fatalf("third request: got wait %s, want 30s", w)
+	}
+	// queued behind the third — waits for two refills
+	if w := pl.reserve("claude"); w != time.Minute {
+		t.Fatalf("fourth request: got wait %s, want 1m", w)
+	}
+
+	// unpaced runners never wait
+	if w := pl.reserve("codex"); w != 0 {
+		t.Errorf("unpaced runner should not wait, got %s", w)
+	}
+}
+
+func TestProviderLimiter_TokenPacingSettle(t *testing.T) {
+	pl := NewProviderLimiter(nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pl.now = func() time.Time { return now }
+	pl.SetRate("codex", RateLimit{TokensPerMinute: 60_000, EstTokensPerTask: 20_000})
+
+	for i := 0; i < 3; i++ {
+		if w := pl.reserve("codex"); w != 0 {
+			t.Fatalf("reservation %d should fit in the bucket, got %s", i, w)
+		}
+	}
+	// actual usage was far lower than estimated — headroom is returned
+	pl.Settle("codex", 5_000)
+	pl.Settle("codex", 5_000)
+	if w := pl.reserve("codex"); w != 0 {
+		t.Fatalf("settled headroom should allow dispatch, got %s", w)
+	}
+	// a heavy task overdraws the bucket and delays the next dispatch
+	pl.Settle("codex", 80_000)
+	if w := pl.reserve("codex"); w <= 0 {
+		t.Fatal("expected wait after heavy usage")
+	}
+}
+
+func TestProviderLimiter_WaitCancelled(t *testing.T) {
+	pl := NewProviderLimiter(nil)
+	pl.SetRate("claude", RateLimit{RequestsPerMinute: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := pl.Wait(ctx, "claude"); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	cancel()
+	if err := pl.Wait(ctx, "claude"); err == nil {
+		t.Error("expected context error while paced")
+	}
+}
+
+func TestProviderLimiter_SetRateRemoves(t *testing.T) {
+	pl := NewProviderLimiter(nil)
+	pl.SetRate("claude", RateLimit{RequestsPerMinute: 10, TokensPerMinute: 1000, EstTokensPerTask: 100})
+	rl, ok := pl.Rate("claude")
+	if !ok || rl.RequestsPerMinute != 10 || rl.TokensPerMinute != 1000 || rl.EstTokensPerTask != 100 {
+		t.Fatalf("unexpected rate: %+v ok=%v", rl, ok)
+	}
+	pl.SetRate("claude", RateLimit{})
+	if _, ok := pl.Rate("claude"); ok {
+		t.Error("zero rate should remove pacing")
+	}
+}
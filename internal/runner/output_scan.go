@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -29,6 +30,12 @@ var secretPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
 }
 
+// SecretPatterns returns the credential patterns output scanning redacts,
+// for scanners that look for the same secrets elsewhere.
+func SecretPatterns() []*regexp.Regexp {
+	return slices.Clone(secretPatterns)
+}
+
 const redactPlaceholder = "[REDACTED]"
 
 // ScanOutput checks text for leaked secrets and returns a redacted copy.
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// ExportPatch stages everything in dir — committed or not, respecting
+// .gitignore — and writes the diff against base to patchPath as a binary-safe
+// patch. Returns the per-file summary; an empty summary means no changes.
+func ExportPatch(ctx context.Context, dir, base, patchPath string) ([]task.FileChange, error) {
+	if _, err := gitOutput(ctx, dir, "add", "-A"); err != nil {
+		return nil, err
+	}
+	files, err := DiffFiles(ctx, dir, "--cached", base)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--binary", "--no-renames", base)
+	cmd.Dir = dir
+	cmd.Env = gitEnv
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+	if err := os.WriteFile(patchPath, out.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write patch: %w", err)
+	}
+	return files, nil
+}
+
+// ApplyPatch applies a patch exported by ExportPatch to the working tree of
+// repoDir. The patch is checked first so it is applied fully or not at all;
+// with checkOnly nothing is changed.
+func ApplyPatch(ctx context.Context, repoDir, patchPath string, checkOnly bool) error {
+	if _, err := gitOutput(ctx, repoDir, "apply", "--check", patchPath); err != nil {
+		return fmt.Errorf("patch does not apply: %w", err)
+	}
+	if checkOnly {
+		return nil
+	}
+	_, err := gitOutput(ctx, repoDir, "apply", patchPath)
+	return err
+}
+
+// DiffFiles returns per-file change stats for `git diff <args>` in dir,
+// e.g. DiffFiles(ctx, dir, base, "HEAD") for a commit range.
+func DiffFiles(ctx context.Context, dir string, args ...string) ([]task.FileChange, error) {
+	status, err := gitOutput(ctx, dir, append([]string{"diff", "--no-renames", "--name-status"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	numstat, err := gitOutput(ctx, dir, append([]string{"diff", "--no-renames", "--numstat"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []task.FileChange
+	index := make(map[string]int)
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		index[fields[1]] = len(files)
+		files = append(files, task.FileChange{Path: fields[1], Status: fields[0]})
+	}
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		i, ok := index[fields[2]]
+		if !ok {
+			continue
+		}
+		if fields[0] == "-" { // binary files have no line counts
+			files[i].Binary = true
+			continue
+		}
+		files[i].Added, _ = strconv.Atoi(fields[0])
+		files[i].Deleted, _ = strconv.Atoi(fields[1])
+	}
+	return files, nil
+}
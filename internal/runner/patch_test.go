@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportAndApplyPatch(t *testing.T) {
+	src := initTestRepo(t)
+	dst := t.TempDir()
+	runGit(t, t.TempDir(), "clone", "-q", src, dst)
+	base := runGit(t, src, "rev-parse", "HEAD")
+	ctx := context.Background()
+
+	// one committed change, one uncommitted, one new binary file
+	_ = os.WriteFile(filepath.Join(src, "file.txt"), []byte("changed\nmore\n"), 0o644)
+	runGit(t, src, "commit", "-qam", "agent commit")
+	_ = os.WriteFile(filepath.Join(src, "new.txt"), []byte("a\nb\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(src, "blob.bin"), []byte{0, 1, 2, 0}, 0o644)
+
+	patch := filepath.Join(t.TempDir(), "changes.patch")
+	files, err := ExportPatch(ctx, src, base, patch)
+	if err != nil {
+		t.Fatalf("ExportPatch: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("files = %+v", files)
+	}
+	byPath := make(map[string]int)
+	for i, f := range files {
+		byPath[f.Path] = i
+	}
+	if f := files[byPath["file.txt"]]; f.Status != "M" || f.Added != 2 || f.Deleted != 1 {
+		t.Errorf("file.txt = %+v", f)
+	}
+	if f := files[byPath["new.txt"]]; f.Status != "A" || f.Added != 2 {
+		t.Errorf("new.txt = %+v", f)
+	}
+	if f := files[byPath["blob.bin"]]; !f.Binary {
+		t.Errorf("blob.bin = %+v", f)
+	}
+
+	if err := ApplyPatch(ctx, dst, patch, true); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "new.txt")); !os.IsNotExist(err) {
+		t.Fatal("check-only apply must not change the tree")
+	}
+	if err := ApplyPatch(ctx, dst, patch, false); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dst, "file.txt"))
+	if string(got) != "changed\nmore\n" {
+		t.Errorf("file.txt = %q", got)
+	}
+	if blob, _ := os.ReadFile(filepath.Join(dst, "blob.bin")); len(blob) != 4 {
+		t.Errorf("blob.bin = %v", blob)
+	}
+}
+
+func TestExportPatch_NoChanges(t *testing.T) {
+	dir := initTestRepo(t)
+	patch := filepath.Join(t.TempDir(), "changes.patch")
+	files, err := ExportPatch(context.Background(), dir, runGit(t, dir, "rev-parse", "HEAD"), patch)
+	if err != nil || len(files) != 0 {
+		t.Fatalf("files = %v, err = %v", files, err)
+	}
+	if _, err := os.Stat(patch); !os.IsNotExist(err) {
+		t.Error("no patch file expected for a clean tree")
+	}
+}
+
+func TestApplyPatch_Conflict(t *testing.T) {
+	src := initTestRepo(t)
+	base := runGit(t, src, "rev-parse", "HEAD")
+	_ = os.WriteFile(filepath.Join(src, "file.txt"), []byte("patched"), 0o644)
+	patch := filepath.Join(t.TempDir(), "changes.patch")
+	if _, err := ExportPatch(context.Background(), src, base, patch); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := initTestRepo(t)
+	_ = os.WriteFile(filepath.Join(dst, "file.txt"), []byte("diverged"), 0o644)
+	if err := ApplyPatch(context.Background(), dst, patch, false); err == nil {
+		t.Fatal("expected apply to fail on diverged file")
+	}
+	got, _ := os.ReadFile(filepath.Join(dst, "file.txt"))
+	if string(got) != "diverged" {
+		t.Errorf("file changed by failed apply: %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// ChangedPaths lists files in dir that differ from the snapshot, whether
+// the change is committed, staged, unstaged or an untracked file. Uncommitted
+// and untracked files that already existed at snapshot time are not listed
+// unless they changed since. tokencontrol's own state files are ignored.
+func (s *Snapshot) ChangedPaths(ctx context.Context, dir string) ([]string, error) {
+	tree, err := workingTree(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if tree == s.Tree {
+		return nil, nil
+	}
+	diff, err := gitOutput(ctx, dir, "diff", "--name-only", "--no-renames", s.Tree, tree)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(diff, "\n") {
+		if f = strings.TrimSpace(f); f != "" && !isTokencontrolPath(f) {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// PathViolations returns the files changed in dir since the snapshot that
+// match a deny pattern or, when allow is non-empty, match no allow pattern.
+func PathViolations(ctx context.Context, dir string, snap *Snapshot, allow, deny []string) ([]string, error) {
+	files, err := snap.ChangedPaths(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	var violations []string
+	for _, f := range files {
+		if task.MatchAnyPath(deny, f) || (len(allow) > 0 && !task.MatchAnyPath(allow, f)) {
+			violations = append(violations, f)
+		}
+	}
+	return violations, nil
+}
+
+// RevertPaths puts files back the way they were when the snapshot was taken:
+// their working tree content from the snapshot (so the user's own
+// uncommitted edits and untracked files come back), or removed if they
+// didn't exist then. When the attempt committed changes to them, a commit
+// restoring their content at the snapshot's HEAD is added on top.
+func RevertPaths(ctx context.Context, dir string, snap *Snapshot, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	for _, f := range files {
+		if _, err := gitOutput(ctx, dir, "cat-file", "-e", snap.Tree+":"+f); err == nil {
+			if _, err := gitOutput(ctx, dir, "restore", "--source="+snap.Tree, "--worktree", "--", f); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, f)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %w", f, err)
+		}
+		removeEmptyParents(dir, filepath.Dir(filepath.Join(dir, f)))
+	}
+
+	head, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if head != snap.Head {
+		if err := commitRevert(ctx, dir, snap.Head, head, files); err != nil {
+			return fmt.Errorf("commit revert: %w", err)
+		}
+	}
+	// the index follows HEAD for these files; the snapshot's uncommitted
+	// content stays in the working tree only
+	_, err = gitOutput(ctx, dir, append([]string{"reset", "-q", "HEAD", "--"}, files...)...)
+	return err
+}
+
+// commitRevert commits files at their content in base on top of head,
+// using a scratch index so nothing else staged ends up in the commit.
+func commitRevert(ctx context.Context, dir, base, head string, files []string) error {
+	tmp, err := os.CreateTemp("", "tokencontrol-index-*")
+	if err != nil {
+		return fmt.Errorf("create temp index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	_ = os.Remove(tmpPath)
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	env := []string{"GIT_INDEX_FILE=" + tmpPath}
+	if _, err := gitOutputEnv(ctx, dir, env, "read-tree", head); err != nil {
+		return err
+	}
+	if _, err := gitOutputEnv(ctx, dir, env, append([]string{"reset", "-q", base, "--"}, files...)...); err != nil {
+		return err
+	}
+	tree, err := gitOutputEnv(ctx, dir, env, "write-tree")
+	if err != nil {
+		return err
+	}
+	headTree, err := gitOutput(ctx, dir, "rev-parse", head+"^{tree}")
+	if err != nil || tree == headTree {
+		return err
+	}
+	commit, err := gitOutput(ctx, dir, "commit-tree", tree, "-p", head, "-m", "Revert changes to protected paths")
+	if err != nil {
+		return err
+	}
+	_, err = gitOutput(ctx, dir, "update-ref", "-m", "tokencontrol: revert protected paths", "HEAD", commit, head)
+	return err
+}
@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPathViolations(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+
+	// the user's own work in progress, present before the attempt
+	_ = os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("mine"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("user edit"), 0o644)
+	snap, err := TakeSnapshot(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.MkdirAll(filepath.Join(dir, "src"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "src", "app.go"), []byte("package src\n"), 0o644)
+	runGit(t, dir, "add", "src")
+	runGit(t, dir, "commit", "-qm", "agent")
+	_ = os.WriteFile(filepath.Join(dir, "go.sum"), []byte("x\n"), 0o644)
+	_ = os.MkdirAll(filepath.Join(dir, ".tokencontrol"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, ".tokencontrol", "state.json"), []byte("{}"), 0o644)
+
+	changed, err := snap.ChangedPaths(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go.sum", "src/app.go"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+
+	got, err := PathViolations(ctx, dir, snap, []string{"src/"}, []string{"go.sum"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go.sum"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v (pre-existing changes are not the agent's)", got, want)
+	}
+
+	got, _ = PathViolations(ctx, dir, snap, nil, []string{"*.go"})
+	if want := []string{"src/app.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deny-only violations = %v, want %v", got, want)
+	}
+}
+
+func TestRevertPaths(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+	snap, err := TakeSnapshot(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// committed edit and new file, plus an uncommitted untracked file
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("edited"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("keep"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "secret.key"), []byte("k"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-qm", "agent")
+	_ = os.WriteFile(filepath.Join(dir, "scratch.tmp"), []byte("x"), 0o644)
+
+	if err := RevertPaths(ctx, dir, snap, []string{"file.txt", "secret.key", "scratch.tmp"}); err != nil {
+		t.Fatalf("RevertPaths: %v", err)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(dir, "file.txt")); string(got) != "initial" {
+		t.Errorf("file.txt = %q", got)
+	}
+	for _, f := range []string{"secret.key", "scratch.tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", f)
+		}
+	}
+	if status := runGit(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("revert should be committed, status = %q", status)
+	}
+	if changed, _ := snap.ChangedPaths(ctx, dir); !reflect.DeepEqual(changed, []string{"keep.txt"}) {
+		t.Errorf("changed after revert = %v", changed)
+	}
+}
+
+func TestRevertPaths_KeepsPreexistingLocalChanges(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	// before the attempt: an untracked file outside allow_paths, an
+	// uncommitted edit and a staged file of the user's
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("my notes"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("user edit"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "staged.txt"), []byte("staged"), 0o644)
+	runGit(t, dir, "add", "staged.txt")
+	snap, err := TakeSnapshot(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the agent commits a change to file.txt and drops a stray file
+	_ = os.MkdirAll(filepath.Join(dir, "src"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "src", "app.go"), []byte("package src\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("agent edit"), 0o644)
+	runGit(t, dir, "add", "src", "file.txt")
+	runGit(t, dir, "commit", "-qm", "agent", "--", "src", "file.txt")
+	_ = os.WriteFile(filepath.Join(dir, "stray.log"), []byte("x"), 0o644)
+
+	violations, err := PathViolations(ctx, dir, snap, []string{"src/**"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"file.txt", "stray.log"}; !reflect.DeepEqual(violations, want) {
+		t.Fatalf("violations = %v, want %v", violations, want)
+	}
+	if err := RevertPaths(ctx, dir, snap, violations); err != nil {
+		t.Fatalf("RevertPaths: %v", err)
+	}
+
+	if got, err := os.ReadFile(filepath.Join(dir, "notes.md")); err != nil || string(got) != "my notes" {
+		t.Errorf("pre-existing untracked file lost: %q, %v", got, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "file.txt")); string(got) != "user edit" {
+		t.Errorf("file.txt = %q, want the user's uncommitted edit back", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stray.log")); !os.IsNotExist(err) {
+		t.Error("stray.log should be removed")
+	}
+	// the revert commit restores the committed content only
+	if got := runGit(t, dir, "show", "HEAD:file.txt"); got != "initial" {
+		t.Errorf("committed file.txt = %q, want initial", got)
+	}
+	if got := runGit(t, dir, "show", "--name-only", "--format=", "HEAD"); got != "file.txt" {
+		t.Errorf("revert commit files = %q", got)
+	}
+	if status := runGit(t, dir, "status", "--porcelain"); status != "M file.txt\nA  staged.txt\n?? notes.md" {
+		t.Errorf("status = %q", status)
+	}
+}
@@ -9,7 +9,10 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
 )
 
 const quotaHTTPTimeout = 10 * time.Second
@@ -285,6 +288,38 @@ func RunnerToProvider(runnerType string) string {
 	}
 }
 
+// ProviderFromModel extracts provider from "provider/model" format used by opencode runners.
+func ProviderFromModel(model string) string {
+	if model == "" {
+		return ""
+	}
+	parts := strings.SplitN(model, "/", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "deepseek":
+		return "deepseek"
+	case "zai":
+		return "" // ZAI uses its own API key, not a shared provider quota
+	default:
+		return ""
+	}
+}
+
+// ProviderForRunner resolves the API provider behind a runner profile name.
+// Profile type takes precedence, then the model prefix; names without a
+// profile are treated as built-in runner types.
+func ProviderForRunner(name string, profiles map[string]*task.RunnerProfileConfig) string {
+	if p, ok := profiles[name]; ok && p != nil {
+		if prov := RunnerToProvider(p.Type); prov != "" {
+			return prov
+		}
+		return ProviderFromModel(p.Model)
+	}
+	return RunnerToProvider(name)
+}
+
 // ProviderEnvVar returns the env var name for a provider's API key.
 func ProviderEnvVar(provider string) string {
 	switch provider {
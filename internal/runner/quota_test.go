@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
 )
 
 func TestCheckOpenAIQuota(t *testing.T) {
@@ -282,6 +284,32 @@ func TestRunnerToProvider(t *testing.T) {
 	}
 }
 
+func TestProviderForRunner(t *testing.T) {
+	profiles := map[string]*task.RunnerProfileConfig{
+		"zai":      {Type: "codex"},
+		"sonnet":   {Type: "claude", Model: "claude-sonnet-4-6"},
+		"deepseek": {Type: "opencode", Model: "deepseek/deepseek-chat"},
+		"oc-zai":   {Type: "opencode", Model: "zai/glm-5"},
+	}
+	tests := []struct {
+		name     string
+		provider string
+	}{
+		{"zai", "openai"},
+		{"sonnet", "anthropic"},
+		{"deepseek", "deepseek"},
+		{"oc-zai", ""},
+		{"codex", "openai"}, // built-in, no profile
+		{"claude", "anthropic"},
+		{"gemini", ""},
+	}
+	for _, tt := range tests {
+		if got := ProviderForRunner(tt.name, profiles); got != tt.provider {
+			t.Errorf("ProviderForRunner(%q) = %q, want %q", tt.name, got, tt.provider)
+		}
+	}
+}
+
 func TestProviderEnvVar(t *testing.T) {
 	tests := []struct {
 		provider string
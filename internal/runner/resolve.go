@@ -0,0 +1,337 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ConflictHunk is one conflict region of a file written with diff3 markers.
+// During a rebase "ours" is the upstream being rebased onto and "theirs" is
+// the branch commit being replayed.
+type ConflictHunk struct {
+	Line    int // 1-based line of the <<<<<<< marker in the rewritten content
+	Ours    []string
+	Base    []string
+	Theirs  []string
+	HasBase bool
+}
+
+const (
+	markerOurs   = "<<<<<<<"
+	markerBase   = "|||||||"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// HasConflictMarkers reports whether content still contains conflict markers.
+func HasConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if isMarker(line, markerOurs) || isMarker(line, markerTheirs) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+// ResolveTrivialHunks rewrites the conflict regions of content that have an
+// unambiguous resolution and returns the new content together with the hunks
+// left for manual resolution. A hunk is trivial when both sides are equal
+// (ignoring whitespace) or when one side left the base unchanged. Hunks where
+// both sides added lines are left too: keeping both could duplicate an
+// import, field or function, or be wrong in ways the build does not catch.
+func ResolveTrivialHunks(content string) (string, []ConflictHunk) {
+	lines := strings.Split(content, "\n")
+	var out []string
+	var remaining []ConflictHunk
+
+	for i := 0; i < len(lines); i++ {
+		if !isMarker(lines[i], markerOurs) {
+			out = append(out, lines[i])
+			continue
+		}
+
+		// collect one hunk; keep its raw lines in case it stays unresolved
+		start := i
+		var h ConflictHunk
+		section := &h.Ours
+		closed := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isMarker(line, markerBase):
+				h.HasBase = true
+				section = &h.Base
+				continue
+			case line == markerSep:
+				section = &h.Theirs
+				continue
+			case isMarker(line, markerTheirs):
+				closed = true
+			}
+			if closed {
+				break
+			}
+			*section = append(*section, line)
+		}
+		if !closed { // malformed: copy the rest verbatim
+			out = append(out, lines[start:]...)
+			break
+		}
+
+		if resolved, ok := trivialResolution(h); ok {
+			out = append(out, resolved...)
+			continue
+		}
+		h.Line = len(out) + 1
+		out = append(out, lines[start:i+1]...)
+		remaining = append(remaining, h)
+	}
+	return strings.Join(out, "\n"), remaining
+}
+
+func trivialResolution(h ConflictHunk) ([]string, bool) {
+	switch {
+	case equalLines(h.Ours, h.Theirs, false):
+		return h.Ours, true
+	case h.HasBase && equalLines(h.Ours, h.Base, false):
+		return h.Theirs, true
+	case h.HasBase && equalLines(h.Theirs, h.Base, false):
+		return h.Ours, true
+	case equalLines(h.Ours, h.Theirs, true):
+		return h.Ours, true // whitespace-only difference: keep upstream formatting
+	}
+	return nil, false
+}
+
+func equalLines(a, b []string, ignoreSpace bool) bool {
+	if ignoreSpace {
+		return strings.Join(strings.Fields(strings.Join(a, "\n")), " ") ==
+			strings.Join(strings.Fields(strings.Join(b, "\n")), " ")
+	}
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ConflictRebase replays a branch onto the main repo's HEAD inside its own
+// worktree, so conflicts can be resolved without touching the main checkout.
+// rerere is enabled so resolutions recorded in earlier runs are reused.
+type ConflictRebase struct {
+	RepoDir  string
+	Dir      string // worktree where the rebase runs
+	Branch   string
+	OrigHead string // branch tip before the rebase, restored by Abort
+	Done     bool   // no rebase in progress
+	Replayed int    // files rerere resolved and staged in the current step
+}
+
+// StartConflictRebase checks out branch in a resolve worktree and rebases it
+// onto the current HEAD of repoDir. When the rebase stops on conflicts the
+// returned ConflictRebase has Done == false.
+func StartConflictRebase(ctx context.Context, repoDir, reposDir, branch string) (*ConflictRebase, error) {
+	onto, err := gitOutput(ctx, repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	orig, err := gitOutput(ctx, repoDir, "rev-parse", branch)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := worktreePath(reposDir, "resolve-"+strings.ReplaceAll(branch, "/", "-"))
+	if _, err := os.Stat(dir); err == nil {
+		removeWorktreeForce(ctx, repoDir, dir)
+		_ = os.RemoveAll(dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, fmt.Errorf("create resolve worktree parent: %w", err)
+	}
+	if _, err := gitOutput(ctx, repoDir, "worktree", "add", dir, branch); err != nil {
+		return nil, err
+	}
+
+	r := &ConflictRebase{RepoDir: repoDir, Dir: dir, Branch: branch, OrigHead: orig}
+	rebaseErr := r.rebase(ctx, onto)
+	if err := r.refresh(ctx); err != nil {
+		r.Abort(ctx)
+		return nil, err
+	}
+	if rebaseErr != nil && r.Done {
+		r.Abort(ctx)
+		return nil, rebaseErr
+	}
+	return r, nil
+}
+
+// Unmerged lists files with unresolved conflicts in the current step.
+func (r *ConflictRebase) Unmerged(ctx context.Context) ([]string, error) {
+	out, err := r.git(ctx, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ConflictNote describes a conflicted file that has no conflict markers,
+// such as a modify/delete, binary or file mode conflict, from its index
+// stages. rerere already staged what it could resolve, so such a file is
+// still undecided.
+func (r *ConflictRebase) ConflictNote(ctx context.Context, file string) (string, error) {
+	out, err := r.git(ctx, "ls-files", "-u", "--", file)
+	if err != nil {
+		return "", err
+	}
+	// each line is "<mode> <object> <stage>\t<path>"; stage 1 is the base,
+	// 2 is main and 3 is the branch
+	modes := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		meta, _, ok := strings.Cut(line, "\t")
+		if f := strings.Fields(meta); ok && len(f) == 3 {
+			modes[f[2]] = f[0]
+		}
+	}
+	main, onMain := modes["2"]
+	branch, onBranch := modes["3"]
+	_, inBase := modes["1"]
+	switch {
+	case !onMain:
+		return "deleted on main and modified on this branch", nil
+	case !onBranch:
+		return "modified on main and deleted on this branch", nil
+	case main != branch:
+		return fmt.Sprintf("file mode changed differently on main (%s) and this branch (%s)", main, branch), nil
+	case !inBase:
+		return "added on both sides with content that cannot be merged line by line", nil
+	}
+	return "changed on both sides with content that cannot be merged line by line, e.g. a binary file", nil
+}
+
+// Stage marks files as resolved, including files resolved by deletion.
+func (r *ConflictRebase) Stage(ctx context.Context, files ...string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	_, err := r.git(ctx, append([]string{"add", "-A", "--"}, files...)...)
+	return err
+}
+
+// Continue commits the resolved step and replays the next commit. Steps
+// whose resolution leaves nothing to commit are skipped.
+func (r *ConflictRebase) Continue(ctx context.Context) error {
+	err := r.rebase(ctx, "--continue")
+	if err != nil {
+		if unmerged, uerr := r.Unmerged(ctx); uerr == nil && len(unmerged) == 0 {
+			if _, serr := r.git(ctx, "rebase", "--skip"); serr == nil {
+				err = nil
+			}
+		}
+	}
+	if rerr := r.refresh(ctx); rerr != nil {
+		return rerr
+	}
+	if err != nil && r.Done {
+		return err
+	}
+	return nil
+}
+
+// Abort stops the rebase, removes the worktree and restores the branch to
+// its original tip so it can be inspected or resolved by hand.
+func (r *ConflictRebase) Abort(ctx context.Context) {
+	if !r.Done {
+		_, _ = r.git(ctx, "rebase", "--abort")
+	}
+	RemoveWorktree(ctx, r.RepoDir, r.Dir)
+	if _, err := gitOutput(ctx, r.RepoDir, "branch", "-f", r.Branch, r.OrigHead); err != nil {
+		slog.Warn("could not restore branch after aborted resolution", "branch", r.Branch, "error", err)
+	}
+	r.Done = true
+}
+
+// Finish removes the resolve worktree, keeping the rebased branch.
+func (r *ConflictRebase) Finish(ctx context.Context) {
+	RemoveWorktree(ctx, r.RepoDir, r.Dir)
+}
+
+// refresh updates Done from the presence of rebase state in the worktree.
+func (r *ConflictRebase) refresh(ctx context.Context) error {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		p, err := r.git(ctx, "rev-parse", "--git-path", name)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(r.Dir, p)
+		}
+		if _, err := os.Stat(p); err == nil {
+			r.Done = false
+			return nil
+		}
+	}
+	r.Done = true
+	return nil
+}
+
+// rebase runs git rebase with args and counts the files rerere resolved
+// when it stops.
+func (r *ConflictRebase) rebase(ctx context.Context, args ...string) error {
+	out, err := r.git(ctx, append([]string{"rebase"}, args...)...)
+	if err != nil {
+		out = err.Error() // carries the command output
+	}
+	r.Replayed = strings.Count(out, "using previous resolution")
+	return err
+}
+
+// git runs a git command in the resolve worktree with rerere and diff3
+// conflict markers enabled, a non-interactive editor and untranslated
+// messages.
+func (r *ConflictRebase) git(ctx context.Context, args ...string) (string, error) {
+	full := append([]string{
+		"-c", "rerere.enabled=true",
+		"-c", "rerere.autoUpdate=true",
+		"-c", "merge.conflictStyle=diff3",
+		"-c", "core.editor=true",
+	}, args...)
+	return gitOutputEnv(ctx, r.Dir, []string{"LC_ALL=C"}, full...)
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitOutputEnv(ctx, dir, nil, args...)
+}
+
+// gitOutputEnv is gitOutput with extra environment variables.
+func gitOutputEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(append([]string(nil), gitEnv...), "GIT_EDITOR=true"), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %w", strings.Join(args, " "), strings.TrimSpace(string(out)), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
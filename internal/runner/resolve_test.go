@@ -0,0 +1,248 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveTrivialHunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		want      string
+		remaining int
+	}{
+		{
+			name: "identical sides",
+			in:   "a\n<<<<<<< HEAD\nx\n||||||| base\nold\n=======\nx\n>>>>>>> branch\nb",
+			want: "a\nx\nb",
+		},
+		{
+			name: "only theirs changed",
+			in:   "<<<<<<< HEAD\nold\n||||||| base\nold\n=======\nnew\n>>>>>>> branch",
+			want: "new",
+		},
+		{
+			name: "whitespace only",
+			in:   "<<<<<<< HEAD\nfoo(a, b)\n||||||| base\nfoo()\n=======\nfoo(a,  b)\n>>>>>>> branch",
+			want: "foo(a, b)",
+		},
+		{
+			name:      "both added kept",
+			in:        "list:\n<<<<<<< HEAD\n- one\n||||||| base\n=======\n- two\n>>>>>>> branch\nend",
+			want:      "list:\n<<<<<<< HEAD\n- one\n||||||| base\n=======\n- two\n>>>>>>> branch\nend",
+			remaining: 1,
+		},
+		{
+			name:      "real conflict kept",
+			in:        "top\n<<<<<<< HEAD\nx = 1\n||||||| base\nx = 0\n=======\nx = 2\n>>>>>>> branch\nbottom",
+			want:      "top\n<<<<<<< HEAD\nx = 1\n||||||| base\nx = 0\n=======\nx = 2\n>>>>>>> branch\nbottom",
+			remaining: 1,
+		},
+		{
+			name:      "no base markers",
+			in:        "<<<<<<< HEAD\nx = 1\n=======\nx = 2\n>>>>>>> branch",
+			want:      "<<<<<<< HEAD\nx = 1\n=======\nx = 2\n>>>>>>> branch",
+			remaining: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hunks := ResolveTrivialHunks(tt.in)
+			if got != tt.want {
+				t.Errorf("content:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if len(hunks) != tt.remaining {
+				t.Errorf("remaining = %d, want %d", len(hunks), tt.remaining)
+			}
+		})
+	}
+}
+
+func TestResolveTrivialHunks_LinePositions(t *testing.T) {
+	in := "a\n<<<<<<< HEAD\nsame\n||||||| base\n=======\nsame\n>>>>>>> b\nc\n<<<<<<< HEAD\n1\n||||||| base\n0\n=======\n2\n>>>>>>> b"
+	got, hunks := ResolveTrivialHunks(in)
+	if len(hunks) != 1 {
+		t.Fatalf("hunks = %d", len(hunks))
+	}
+	h := hunks[0]
+	if h.Line != 4 || strings.Split(got, "\n")[h.Line-1] != "<<<<<<< HEAD" {
+		t.Errorf("hunk line = %d in:\n%s", h.Line, got)
+	}
+	if h.Ours[0] != "1" || h.Base[0] != "0" || h.Theirs[0] != "2" || !h.HasBase {
+		t.Errorf("hunk = %+v", h)
+	}
+	if !HasConflictMarkers(got) || HasConflictMarkers("plain\ntext") {
+		t.Error("HasConflictMarkers mismatch")
+	}
+}
+
+// conflictRepo returns a repo whose main branch and tokencontrol/feat both
+// changed line 2 of file.txt.
+func conflictRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-b", "main")
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one\ntwo\nthree\n"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial")
+
+	runGit(t, dir, "checkout", "-b", "tokencontrol/feat")
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one\nTWO-branch\nthree\n"), 0o644)
+	runGit(t, dir, "commit", "-am", "branch change")
+
+	runGit(t, dir, "checkout", "main")
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one\nTWO-main\nthree\n"), 0o644)
+	runGit(t, dir, "commit", "-am", "main change")
+	return dir
+}
+
+func TestConflictRebase_ResolveAndContinue(t *testing.T) {
+	repoDir := conflictRepo(t)
+	reposDir := t.TempDir()
+	ctx := context.Background()
+
+	r, err := StartConflictRebase(ctx, repoDir, reposDir, "tokencontrol/feat")
+	if err != nil {
+		t.Fatalf("StartConflictRebase: %v", err)
+	}
+	if r.Done {
+		t.Fatal("expected rebase to stop on conflict")
+	}
+	files, err := r.Unmerged(ctx)
+	if err != nil || len(files) != 1 || files[0] != "file.txt" {
+		t.Fatalf("unmerged = %v, %v", files, err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(r.Dir, "file.txt"))
+	if _, hunks := ResolveTrivialHunks(string(data)); len(hunks) != 1 {
+		t.Fatalf("expected one real conflict hunk, got %d in:\n%s", len(hunks), data)
+	}
+
+	_ = os.WriteFile(filepath.Join(r.Dir, "file.txt"), []byte("one\nTWO-both\nthree\n"), 0o644)
+	if err := r.Stage(ctx, "file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Continue(ctx); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	if !r.Done {
+		t.Fatal("rebase should be finished")
+	}
+	r.Finish(ctx)
+
+	if err := MergeBack(ctx, repoDir, "tokencontrol/feat"); err != nil {
+		t.Fatalf("MergeBack after resolution: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(repoDir, "file.txt"))
+	if string(got) != "one\nTWO-both\nthree\n" {
+		t.Errorf("merged content = %q", got)
+	}
+}
+
+func TestConflictRebase_AbortRestoresBranch(t *testing.T) {
+	repoDir := conflictRepo(t)
+	ctx := context.Background()
+	orig := runGit(t, repoDir, "rev-parse", "tokencontrol/feat")
+
+	r, err := StartConflictRebase(ctx, repoDir, t.TempDir(), "tokencontrol/feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Abort(ctx)
+
+	if got := runGit(t, repoDir, "rev-parse", "tokencontrol/feat"); got != orig {
+		t.Errorf("branch = %s, want original %s", got, orig)
+	}
+	if _, err := os.Stat(r.Dir); !os.IsNotExist(err) {
+		t.Errorf("resolve worktree should be removed: %v", err)
+	}
+}
+
+func TestConflictRebase_CleanRebase(t *testing.T) {
+	repoDir := initTestRepo(t)
+	runGit(t, repoDir, "branch", "tokencontrol/clean")
+	_ = os.WriteFile(filepath.Join(repoDir, "other.txt"), []byte("x"), 0o644)
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "main moves")
+
+	ctx := context.Background()
+	r, err := StartConflictRebase(ctx, repoDir, t.TempDir(), "tokencontrol/clean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Done {
+		t.Error("expected clean rebase to finish immediately")
+	}
+	r.Finish(ctx)
+}
+
+func TestConflictRebase_RerereReplayCounted(t *testing.T) {
+	repoDir := conflictRepo(t)
+	ctx := context.Background()
+
+	// resolve once so rerere records the resolution, then start over
+	r, err := StartConflictRebase(ctx, repoDir, t.TempDir(), "tokencontrol/feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(r.Dir, "file.txt"), []byte("one\nTWO-both\nthree\n"), 0o644)
+	if err := r.Stage(ctx, "file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Continue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	r.Abort(ctx)
+
+	r, err = StartConflictRebase(ctx, repoDir, t.TempDir(), "tokencontrol/feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Abort(ctx)
+	if r.Done || r.Replayed != 1 {
+		t.Fatalf("done = %v, replayed = %d, want a stop with 1 replayed file", r.Done, r.Replayed)
+	}
+	if files, err := r.Unmerged(ctx); err != nil || len(files) != 0 {
+		t.Errorf("unmerged = %v, %v, want none after replay", files, err)
+	}
+}
+
+func TestConflictRebase_ConflictNote(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-b", "main")
+	_ = os.WriteFile(filepath.Join(dir, "gone.txt"), []byte("a\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "blob.bin"), []byte("\x00base"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial")
+
+	runGit(t, dir, "checkout", "-b", "tokencontrol/feat")
+	_ = os.WriteFile(filepath.Join(dir, "gone.txt"), []byte("b\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "blob.bin"), []byte("\x00branch"), 0o644)
+	runGit(t, dir, "commit", "-am", "branch change")
+
+	runGit(t, dir, "checkout", "main")
+	runGit(t, dir, "rm", "-q", "gone.txt")
+	_ = os.WriteFile(filepath.Join(dir, "blob.bin"), []byte("\x00main"), 0o644)
+	runGit(t, dir, "commit", "-am", "main change")
+
+	ctx := context.Background()
+	r, err := StartConflictRebase(ctx, dir, t.TempDir(), "tokencontrol/feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Abort(ctx)
+
+	for file, want := range map[string]string{
+		"gone.txt": "deleted on main and modified on this branch",
+		"blob.bin": "changed on both sides",
+	} {
+		note, err := r.ConflictNote(ctx, file)
+		if err != nil || !strings.HasPrefix(note, want) {
+			t.Errorf("ConflictNote(%s) = %q, %v, want %q", file, note, err, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// Snapshot records a repo's state before an agent attempt: HEAD plus a tree
+// object of the working directory, including uncommitted and untracked files
+// (ignored files are left out). Nothing in the repo is modified to take it.
+type Snapshot struct {
+	Head string
+	Tree string
+}
+
+// TakeSnapshot captures the current state of dir.
+func TakeSnapshot(ctx context.Context, dir string) (*Snapshot, error) {
+	head, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tree, err := workingTree(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Head: head, Tree: tree}, nil
+}
+
+// Changed reports whether dir has moved away from the snapshot, either by
+// new commits or by working directory changes.
+func (s *Snapshot) Changed(ctx context.Context, dir string) (bool, error) {
+	head, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	if head != s.Head {
+		return true, nil
+	}
+	tree, err := workingTree(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+	return tree != s.Tree, nil
+}
+
+// Diff writes everything changed in dir since the snapshot — committed or
+// not — to patchPath as a binary-safe patch and returns the per-file summary.
+// Nothing is written when there are no changes.
+func (s *Snapshot) Diff(ctx context.Context, dir, patchPath string) ([]task.FileChange, error) {
+	tree, err := workingTree(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if tree == s.Tree {
+		return nil, nil
+	}
+	files, err := DiffFiles(ctx, dir, s.Tree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "diff", "--binary", "--no-renames", s.Tree, tree)
+	cmd.Dir = dir
+	cmd.Env = gitEnv
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+	if err := os.WriteFile(patchPath, out.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write patch: %w", err)
+	}
+	return files, nil
+}
+
+// Restore puts dir back into the snapshot state: HEAD is reset, commits and
+// files added since are dropped, and uncommitted changes that existed at
+// snapshot time are put back as unstaged changes. tokencontrol's own state
+// files and ignored files are left alone.
+func (s *Snapshot) Restore(ctx context.Context, dir string) error {
+	if _, err := gitOutput(ctx, dir, "reset", "-q", "--hard", s.Head); err != nil {
+		return err
+	}
+
+	untracked, err := gitOutput(ctx, dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return err
+	}
+	for _, f := range strings.Split(untracked, "\x00") {
+		if f == "" || isTokencontrolPath(f) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, f)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %w", f, err)
+		}
+		removeEmptyParents(dir, filepath.Dir(filepath.Join(dir, f)))
+	}
+
+	headTree, err := gitOutput(ctx, dir, "rev-parse", s.Head+"^{tree}")
+	if err != nil {
+		return err
+	}
+	if headTree == s.Tree {
+		return nil
+	}
+	// check out the snapshot's files, then unstage them against HEAD
+	if _, err := gitOutput(ctx, dir, "read-tree", "-u", "--reset", s.Tree); err != nil {
+		return err
+	}
+	_, err = gitOutput(ctx, dir, "reset", "-q")
+	return err
+}
+
+// snapshotExcludes keeps tokencontrol's own state out of snapshots; see
+// isTokencontrolPath.
+var snapshotExcludes = []string{".tokencontrol", ".tokencontrol.lock", "docs/tokencontrol"}
+
+// workingTree writes the working directory of dir as a tree object using a
+// copy of the index, so the real index is untouched.
+func workingTree(ctx context.Context, dir string) (string, error) {
+	indexPath, err := gitOutput(ctx, dir, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(dir, indexPath)
+	}
+	tmp, err := os.CreateTemp("", "tokencontrol-index-*")
+	if err != nil {
+		return "", fmt.Errorf("create temp index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	// seed with the real index so unchanged files aren't rehashed
+	if data, err := os.ReadFile(indexPath); err == nil {
+		if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+			return "", fmt.Errorf("copy index: %w", err)
+		}
+	} else {
+		_ = os.Remove(tmpPath)
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmpPath}
+	args := []string{"add", "-A", "--", "."}
+	for _, p := range snapshotExcludes {
+		args = append(args, ":(exclude)"+p)
+	}
+	if _, err := gitOutputEnv(ctx, dir, env, args...); err != nil {
+		return "", err
+	}
+	return gitOutputEnv(ctx, dir, env, "write-tree")
+}
+
+// removeEmptyParents removes empty directories from sub up to, but not
+// including, root.
+func removeEmptyParents(root, sub string) {
+	for sub != root && strings.HasPrefix(sub, root) {
+		if err := os.Remove(sub); err != nil {
+			return
+		}
+		sub = filepath.Dir(sub)
+	}
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot_RestoreDiscardsAttempt(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+
+	// pre-existing uncommitted work must survive the rollback
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("user edit"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "user.txt"), []byte("mine"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, ".tokencontrol.lock"), []byte("pid"), 0o644)
+
+	snap, err := TakeSnapshot(ctx, dir)
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	if changed, _ := snap.Changed(ctx, dir); changed {
+		t.Fatal("fresh snapshot should be unchanged")
+	}
+
+	// the failed agent commits one change and leaves others behind
+	_ = os.MkdirAll(filepath.Join(dir, "pkg", "sub"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "pkg", "sub", "half.go"), []byte("package sub\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "committed.txt"), []byte("agent"), 0o644)
+	runGit(t, dir, "add", "committed.txt")
+	runGit(t, dir, "commit", "-qm", "agent")
+	_ = os.WriteFile(filepath.Join(dir, "file.txt"), []byte("agent edit"), 0o644)
+	_ = os.Remove(filepath.Join(dir, "user.txt"))
+
+	if changed, _ := snap.Changed(ctx, dir); !changed {
+		t.Fatal("expected changes after attempt")
+	}
+
+	patch := filepath.Join(t.TempDir(), "failed.patch")
+	files, err := snap.Diff(ctx, dir, patch)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("files = %+v", files)
+	}
+	data, _ := os.ReadFile(patch)
+	if !strings.Contains(string(data), "+agent edit") || !strings.Contains(string(data), "pkg/sub/half.go") {
+		t.Errorf("patch:\n%s", data)
+	}
+
+	if err := snap.Restore(ctx, dir); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if head := runGit(t, dir, "rev-parse", "HEAD"); head != snap.Head {
+		t.Errorf("HEAD = %s, want %s", head, snap.Head)
+	}
+	for name, want := range map[string]string{"file.txt": "user edit", "user.txt": "mine", ".tokencontrol.lock": "pid"} {
+		if got, _ := os.ReadFile(filepath.Join(dir, name)); string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	for _, gone := range []string{"committed.txt", "pkg"} {
+		if _, err := os.Stat(filepath.Join(dir, gone)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", gone)
+		}
+	}
+	if staged := runGit(t, dir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("restored changes should be unstaged, got %q", staged)
+	}
+	if changed, _ := snap.Changed(ctx, dir); changed {
+		t.Error("repo should match snapshot after restore")
+	}
+}
+
+func TestSnapshot_DiffNoChanges(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+	snap, err := TakeSnapshot(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := filepath.Join(t.TempDir(), "failed.patch")
+	files, err := snap.Diff(ctx, dir, patch)
+	if err != nil || files != nil {
+		t.Errorf("Diff = %v, %v", files, err)
+	}
+	if _, err := os.Stat(patch); !os.IsNotExist(err) {
+		t.Error("no patch should be written without changes")
+	}
+	if status := runGit(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("snapshot must not touch the repo, status = %q", status)
+	}
+}
@@ -25,6 +25,16 @@ const worktreeDir = ".tokencontrol-worktrees"
 // Returns the worktree directory path and branch name. The worktree is
 // created from HEAD of the main repo with a deterministic branch name.
 func CreateWorktree(ctx context.Context, repoDir, reposDir, taskID string) (wtDir, branch string, err error) {
+	return CreateWorktreeFrom(ctx, repoDir, reposDir, taskID, "HEAD")
+}
+
+// CreateWorktreeFrom is CreateWorktree with an explicit start point. Stacked
+// tasks pass their parent task's branch so the dependent's branch contains
+// only its own commits on top of the parent.
+func CreateWorktreeFrom(ctx context.Context, repoDir, reposDir, taskID, baseRef string) (wtDir, branch string, err error) {
+	if baseRef == "" {
+		baseRef = "HEAD"
+	}
 	wtDir = worktreePath(reposDir, taskID)
 	branch = branchName(taskID)
 
@@ -47,14 +57,14 @@ func CreateWorktree(ctx context.Context, repoDir, reposDir, taskID string) (wtDi
 	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "git", "worktree", "add", wtDir, "-b", branch, "HEAD")
+	cmd := exec.CommandContext(cmdCtx, "git", "worktree", "add", wtDir, "-b", branch, baseRef)
 	cmd.Dir = repoDir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", "", fmt.Errorf("git worktree add: %s: %w", strings.TrimSpace(string(out)), err)
 	}
 
-	slog.Debug("created worktree", "task", taskID, "path", wtDir, "branch", branch)
+	slog.Debug("created worktree", "task", taskID, "path", wtDir, "branch", branch, "base", baseRef)
 	return wtDir, branch, nil
 }
 
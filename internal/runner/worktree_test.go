@@ -75,6 +75,34 @@ func TestCreateWorktree(t *testing.T) {
 	}
 }
 
+func TestCreateWorktreeFrom_ParentBranch(t *testing.T) {
+	repoDir := initTestRepo(t)
+	reposDir := t.TempDir()
+	runGit(t, repoDir, "checkout", "-b", "tokencontrol/parent")
+	_ = os.WriteFile(filepath.Join(repoDir, "parent.txt"), []byte("parent"), 0o644)
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "parent work")
+	parentHead := runGit(t, repoDir, "rev-parse", "HEAD")
+	runGit(t, repoDir, "checkout", "-")
+
+	ctx := context.Background()
+	wtDir, branch, err := CreateWorktreeFrom(ctx, repoDir, reposDir, "child", "tokencontrol/parent")
+	if err != nil {
+		t.Fatalf("CreateWorktreeFrom: %v", err)
+	}
+	defer RemoveWorktree(ctx, repoDir, wtDir)
+
+	if branch != "tokencontrol/child" {
+		t.Errorf("branch = %s", branch)
+	}
+	if got := runGit(t, wtDir, "rev-parse", "HEAD"); got != parentHead {
+		t.Errorf("child worktree HEAD = %s, want parent head %s", got, parentHead)
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "parent.txt")); err != nil {
+		t.Errorf("parent commit missing in child worktree: %v", err)
+	}
+}
+
 func TestCreateWorktree_BranchExists(t *testing.T) {
 	repoDir := initTestRepo(t)
 	reposDir := t.TempDir()
@@ -0,0 +1,371 @@
+package scan
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OSV ecosystem names of the dependencies the scanner understands.
+const (
+	EcosystemGo   = "Go"
+	EcosystemPyPI = "PyPI"
+	EcosystemNpm  = "npm"
+)
+
+// DefaultAdvisoryDir returns ~/.tokencontrol/advisories.
+func DefaultAdvisoryDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".tokencontrol", "advisories")
+}
+
+// osvAdvisory is the subset of the OSV schema (https://ossf.github.io/osv-schema/)
+// used for matching.
+type osvAdvisory struct {
+	ID               string        `json:"id"`
+	Aliases          []string      `json:"aliases"`
+	Summary          string        `json:"summary"`
+	Withdrawn        string        `json:"withdrawn"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+func (e osvEvent) version() string {
+	return e.Introduced + e.Fixed + e.LastAffected
+}
+
+// AdvisoryDB is an offline set of OSV advisories, indexed by package.
+type AdvisoryDB struct {
+	byPackage map[string][]*osvAdvisory // ecosystem + "\x00" + normalized name
+	count     int
+	digest    string // hash of the advisory files, part of the scan cache key
+}
+
+// AdvisoryMatch is an advisory affecting a dependency version.
+type AdvisoryMatch struct {
+	ID       string
+	Aliases  []string
+	Summary  string
+	Severity string // database severity such as HIGH; "" if unrated
+	Fixed    string // first fixed version after the affected one; "" if none
+}
+
+// LoadAdvisories reads every OSV advisory (*.json) in dir and its
+// subdirectories, including those inside OSV's per-ecosystem all.zip
+// exports. Withdrawn advisories and files that aren't advisories are
+// skipped.
+func LoadAdvisories(dir string) (*AdvisoryDB, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("advisory dir: %w", err)
+	}
+	db := &AdvisoryDB{byPackage: make(map[string][]*osvAdvisory)}
+	h := sha256.New()
+	invalid := 0
+	add := func(name string, data []byte) {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+		h.Write(data)
+		var adv osvAdvisory
+		if err := json.Unmarshal(data, &adv); err != nil || adv.ID == "" {
+			invalid++
+			return
+		}
+		db.add(&adv)
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			add(rel, data)
+		case ".zip":
+			return readAdvisoryZip(path, func(name string, data []byte) { add(rel+"!"+name, data) })
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load advisories: %w", err)
+	}
+	if invalid > 0 {
+		slog.Warn("skipped files that are not OSV advisories", "dir", dir, "count", invalid)
+	}
+	db.digest = hex.EncodeToString(h.Sum(nil))
+	return db, nil
+}
+
+func readAdvisoryZip(path string, add func(name string, data []byte)) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", filepath.Base(path), err)
+	}
+	defer func() { _ = zr.Close() }()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("read %s in %s: %w", f.Name, filepath.Base(path), err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("read %s in %s: %w", f.Name, filepath.Base(path), err)
+		}
+		add(f.Name, data)
+	}
+	return nil
+}
+
+func (db *AdvisoryDB) add(adv *osvAdvisory) {
+	if adv.Withdrawn != "" {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, a := range adv.Affected {
+		key := packageKey(a.Package.Ecosystem, a.Package.Name)
+		if !seen[key] {
+			seen[key] = true
+			db.byPackage[key] = append(db.byPackage[key], adv)
+		}
+	}
+	db.count++
+}
+
+// Len returns the number of advisories loaded.
+func (db *AdvisoryDB) Len() int { return db.count }
+
+// Match returns the advisories affecting version of the named package,
+// sorted by ID.
+func (db *AdvisoryDB) Match(ecosystem, name, version string) []AdvisoryMatch {
+	key := packageKey(ecosystem, name)
+	var matches []AdvisoryMatch
+	for _, adv := range db.byPackage[key] {
+		for _, a := range adv.Affected {
+			if packageKey(a.Package.Ecosystem, a.Package.Name) != key {
+				continue
+			}
+			affected, fixed := a.affects(version)
+			if !affected {
+				continue
+			}
+			matches = append(matches, AdvisoryMatch{
+				ID:       adv.ID,
+				Aliases:  adv.Aliases,
+				Summary:  adv.Summary,
+				Severity: strings.ToUpper(adv.DatabaseSpecific.Severity),
+				Fixed:    fixed,
+			})
+			break
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches
+}
+
+// packageKey normalizes package names the way their ecosystem compares
+// them: PyPI names are case-insensitive with -, _ and . equivalent.
+func packageKey(ecosystem, name string) string {
+	if strings.EqualFold(ecosystem, EcosystemPyPI) {
+		name = pypiNameSep.ReplaceAllString(strings.ToLower(name), "-")
+	}
+	// OSV qualifies some ecosystems with a release, e.g. "Debian:12"
+	eco, _, _ := strings.Cut(ecosystem, ":")
+	return strings.ToLower(eco) + "\x00" + name
+}
+
+var pypiNameSep = regexp.MustCompile(`[-_.]+`)
+
+// affects reports whether version falls in one of a's SEMVER or ECOSYSTEM
+// ranges or its explicit version list, and the version that fixes it.
+func (a *osvAffected) affects(version string) (bool, string) {
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if affected, fixed := r.affects(version); affected {
+			return true, fixed
+		}
+	}
+	for _, v := range a.Versions {
+		if compareVersions(v, version) == 0 {
+			return true, ""
+		}
+	}
+	return false, ""
+}
+
+// affects walks the range events in version order; the state at the last
+// event not after version decides.
+func (r *osvRange) affects(version string) (bool, string) {
+	events := append([]osvEvent(nil), r.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Introduced == "0" || events[j].Introduced == "0" {
+			return events[i].Introduced == "0" && events[j].Introduced != "0"
+		}
+		return compareVersions(events[i].version(), events[j].version()) < 0
+	})
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced != "0" && compareVersions(version, e.Introduced) < 0 {
+				return affected, ""
+			}
+			affected = true
+		case e.Fixed != "":
+			if compareVersions(version, e.Fixed) < 0 {
+				if affected {
+					return true, e.Fixed
+				}
+				return false, ""
+			}
+			affected = false
+		case e.LastAffected != "":
+			if compareVersions(version, e.LastAffected) <= 0 {
+				return affected, ""
+			}
+			affected = false
+		}
+	}
+	return affected, ""
+}
+
+// compareVersions orders semver, Go and PEP 440 style versions: numeric
+// segments compare as numbers, a leading "v" and build metadata are ignored,
+// and pre-releases (-rc.1, a1, .dev0) sort before their release while
+// .postN sorts after it.
+func compareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		x, y := tokenAt(ta, i), tokenAt(tb, i)
+		if c := x.compare(y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// versionToken is a numeric segment, a tag such as "rc", or the end of the
+// version (the release itself).
+type versionToken struct {
+	num  int
+	rank int // tag rank; tagRelease for the end of the version
+	kind int
+}
+
+const (
+	tokenNum = iota
+	tokenTag
+	tokenEnd
+
+	tagRelease = 4
+)
+
+func (t versionToken) compare(o versionToken) int {
+	switch {
+	case t.kind == tokenNum && o.kind == tokenNum:
+		return cmpInt(t.num, o.num)
+	case t.kind == tokenNum:
+		// 1.2.1 > 1.2 = 1.2.0, and any number beats a tag: 1.0.0 > 1.0rc1
+		if o.kind == tokenEnd && t.num == 0 {
+			return 0
+		}
+		return 1
+	case o.kind == tokenNum:
+		return -o.compare(t)
+	default:
+		return cmpInt(t.rank, o.rank)
+	}
+}
+
+// tokenAt returns the i-th token, or the end marker past the end.
+func tokenAt(ts []versionToken, i int) versionToken {
+	if i < len(ts) {
+		return ts[i]
+	}
+	return versionToken{rank: tagRelease, kind: tokenEnd}
+}
+
+var versionTokenRe = regexp.MustCompile(`\d+|[a-zA-Z]+`)
+
+func versionTokens(v string) []versionToken {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	var ts []versionToken
+	for _, s := range versionTokenRe.FindAllString(v, -1) {
+		if n, err := strconv.Atoi(s); err == nil {
+			ts = append(ts, versionToken{num: n, kind: tokenNum})
+			continue
+		}
+		ts = append(ts, versionToken{rank: tagRank(strings.ToLower(s)), kind: tokenTag})
+	}
+	return ts
+}
+
+func tagRank(tag string) int {
+	switch tag {
+	case "dev":
+		return 0
+	case "a", "alpha":
+		return 1
+	case "b", "beta":
+		return 2
+	case "post":
+		return 5
+	default: // rc, c, pre, preview and anything unknown
+		return 3
+	}
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
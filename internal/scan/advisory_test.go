@@ -0,0 +1,156 @@
+package scan
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// osvJSON returns an advisory for one package with a single range.
+func osvJSON(id, ecosystem, name, introduced, fixed string) string {
+	events := `{"introduced":"` + introduced + `"}`
+	if fixed != "" {
+		events += `,{"fixed":"` + fixed + `"}`
+	}
+	return `{"id":"` + id + `","aliases":["CVE-` + id + `"],"summary":"bad ` + name + `",` +
+		`"affected":[{"package":{"ecosystem":"` + ecosystem + `","name":"` + name + `"},` +
+		`"ranges":[{"type":"ECOSYSTEM","events":[` + events + `]}]}]}`
+}
+
+func makeAdvisoryDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for rel, content := range files {
+		writeFile(t, filepath.Join(dir, rel), content)
+	}
+	return dir
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.1", "1.2", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0a1", "1.0b1", -1},
+		{"1.0.dev0", "1.0a1", -1},
+		{"1.0.post1", "1.0", 1},
+		{"1.0.post1", "1.0.1", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+		{"2.0.0", "10.0.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestOSVRange_Affects(t *testing.T) {
+	r := osvRange{Type: "SEMVER", Events: []osvEvent{
+		{Fixed: "1.2.0"}, {Introduced: "0"}, {Introduced: "2.0.0"}, {Fixed: "2.1.5"}, {Introduced: "3.0.0"}, {LastAffected: "3.0.2"},
+	}}
+	tests := []struct {
+		version  string
+		affected bool
+		fixed    string
+	}{
+		{"1.0.0", true, "1.2.0"},
+		{"1.2.0", false, ""},
+		{"1.9.9", false, ""},
+		{"2.1.0", true, "2.1.5"},
+		{"2.1.5", false, ""},
+		{"3.0.2", true, ""},
+		{"3.0.3", false, ""},
+	}
+	for _, tt := range tests {
+		affected, fixed := r.affects(tt.version)
+		if affected != tt.affected || fixed != tt.fixed {
+			t.Errorf("affects(%q) = %v, %q, want %v, %q", tt.version, affected, fixed, tt.affected, tt.fixed)
+		}
+	}
+}
+
+func TestLoadAdvisories(t *testing.T) {
+	dir := makeAdvisoryDir(t, map[string]string{
+		"go/GO-1.json":    osvJSON("GO-1", "Go", "golang.org/x/net", "0", "0.17.0"),
+		"pypi/PYSEC.json": osvJSON("PYSEC-1", "PyPI", "Django_REST.framework", "3.0", "3.15.2"),
+		"withdrawn.json":  `{"id":"GHSA-w","withdrawn":"2024-01-01T00:00:00Z","affected":[{"package":{"ecosystem":"npm","name":"lodash"},"versions":["4.17.20"]}]}`,
+		"notes.json":      `not an advisory`,
+		"README.md":       "ignored",
+	})
+
+	// OSV's all.zip export, dropped in unextracted
+	zf, err := os.Create(filepath.Join(dir, "npm-all.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(zf)
+	w, _ := zw.Create("GHSA-1.json")
+	_, _ = w.Write([]byte(osvJSON("GHSA-1", "npm", "lodash", "0", "4.17.21")))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = zf.Close()
+
+	db, err := LoadAdvisories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", db.Len())
+	}
+
+	m := db.Match(EcosystemGo, "golang.org/x/net", "v0.10.0")
+	if len(m) != 1 || m[0].ID != "GO-1" || m[0].Fixed != "0.17.0" || m[0].Aliases[0] != "CVE-GO-1" {
+		t.Errorf("Go match = %+v", m)
+	}
+	if m := db.Match(EcosystemGo, "golang.org/x/net", "v0.17.0"); len(m) != 0 {
+		t.Errorf("fixed version matched: %+v", m)
+	}
+	// PyPI names normalize
+	if m := db.Match(EcosystemPyPI, "django-rest-framework", "3.14.0"); len(m) != 1 {
+		t.Errorf("PyPI match = %+v", m)
+	}
+	if m := db.Match(EcosystemNpm, "lodash", "4.17.20"); len(m) != 1 || m[0].ID != "GHSA-1" {
+		t.Errorf("npm match = %+v, want only the zipped advisory", m)
+	}
+
+	again, err := LoadAdvisories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.digest != db.digest {
+		t.Error("digest not stable")
+	}
+	writeFile(t, filepath.Join(dir, "go", "GO-1.json"), osvJSON("GO-1", "Go", "golang.org/x/net", "0", "0.18.0"))
+	if changed, _ := LoadAdvisories(dir); changed.digest == db.digest {
+		t.Error("digest ignores advisory content")
+	}
+
+	if _, err := LoadAdvisories(filepath.Join(dir, "missing")); !os.IsNotExist(unwrapAll(err)) {
+		t.Errorf("missing dir error = %v, want not exist", err)
+	}
+}
+
+// unwrapAll returns the innermost wrapped error.
+func unwrapAll(err error) error {
+	for {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok || u.Unwrap() == nil {
+			return err
+		}
+		err = u.Unwrap()
+	}
+}
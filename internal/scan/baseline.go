@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// BaselineFile is the default name of the baseline written by
+// `scan --update-baseline`.
+const BaselineFile = ".tokencontrol-baseline.json"
+
+// Finding statuses. New findings have an empty status.
+const (
+	StatusBaselined  = "baselined"
+	StatusSuppressed = "suppressed"
+)
+
+// fingerprint identifies a finding across scans by repo, check and location,
+// plus the package for dependency findings, which share their manifest.
+// Messages and versions are left out since they may include counts or line
+// numbers, or change with an incomplete bump.
+func (f *Finding) fingerprint() string {
+	key := f.Repo + "\x00" + f.Check + "\x00" + f.Location
+	if f.Dependency != nil {
+		key += "\x00" + f.Dependency.Ecosystem + "/" + f.Dependency.Name
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// BaselineEntry is a known finding recorded in a baseline.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Repo        string `json:"repo"`
+	Check       string `json:"check"`
+	Message     string `json:"message"`
+}
+
+// Baseline is the set of findings accepted as known. Scans hide findings
+// whose fingerprint is in the baseline.
+type Baseline struct {
+	UpdatedAt time.Time       `json:"updated_at"`
+	Findings  []BaselineEntry `json:"findings"`
+
+	index map[string]bool
+}
+
+// LoadBaseline reads a baseline file. A missing file yields nil.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	b.reindex()
+	return &b, nil
+}
+
+// Contains reports whether the baseline holds a finding with fingerprint fp.
+func (b *Baseline) Contains(fp string) bool {
+	return b != nil && b.index[fp]
+}
+
+// Update replaces the entries of the repos in result with its findings,
+// keeping entries of repos that were not scanned.
+func (b *Baseline) Update(result *ScanResult, now time.Time) {
+	scanned := make(map[string]bool, len(result.ReposScanned))
+	for _, r := range result.ReposScanned {
+		scanned[r] = true
+	}
+	kept := b.Findings[:0]
+	for _, e := range b.Findings {
+		if !scanned[e.Repo] {
+			kept = append(kept, e)
+		}
+	}
+	for _, f := range result.Findings {
+		if f.Status == StatusSuppressed {
+			continue
+		}
+		kept = append(kept, BaselineEntry{Fingerprint: f.Fingerprint, Repo: f.Repo, Check: f.Check, Message: f.Message})
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Repo != kept[j].Repo {
+			return kept[i].Repo < kept[j].Repo
+		}
+		if kept[i].Check != kept[j].Check {
+			return kept[i].Check < kept[j].Check
+		}
+		return kept[i].Fingerprint < kept[j].Fingerprint
+	})
+	b.Findings = kept
+	b.UpdatedAt = now
+	b.reindex()
+}
+
+// Save writes the baseline as indented JSON.
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+func (b *Baseline) reindex() {
+	b.index = make(map[string]bool, len(b.Findings))
+	for _, e := range b.Findings {
+		b.index[e.Fingerprint] = true
+	}
+}
@@ -0,0 +1,145 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// makeScanDir creates repos under one base dir for Scan tests.
+func makeScanDir(t *testing.T, repos map[string]map[string]string) string {
+	t.Helper()
+	base := t.TempDir()
+	for name, files := range repos {
+		dir := filepath.Join(base, name)
+		if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for rel, content := range files {
+			p := filepath.Join(dir, rel)
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return base
+}
+
+func TestFinding_Fingerprint(t *testing.T) {
+	a := Finding{Repo: "app", Check: "sec-hardcoded-token", Location: "config.go", Message: "at config.go:3"}
+	b := a
+	b.Message = "at config.go:9"
+	if a.fingerprint() != b.fingerprint() {
+		t.Error("fingerprint should not depend on the message")
+	}
+	b.Location = "other.go"
+	if a.fingerprint() == b.fingerprint() {
+		t.Error("fingerprint should depend on the location")
+	}
+
+	dep := Finding{Repo: "app", Check: "sec-vulnerable-dependency", Location: "go.mod",
+		Dependency: &Dependency{Ecosystem: EcosystemGo, Name: "golang.org/x/net", Version: "v0.1.0"}}
+	other := dep
+	other.Dependency = &Dependency{Ecosystem: EcosystemGo, Name: "golang.org/x/text", Version: "v0.1.0"}
+	if dep.fingerprint() == other.fingerprint() {
+		t.Error("fingerprint should depend on the dependency")
+	}
+	other.Dependency = &Dependency{Ecosystem: EcosystemGo, Name: "golang.org/x/net", Version: "v0.2.0"}
+	if dep.fingerprint() != other.fingerprint() {
+		t.Error("fingerprint should not depend on the dependency version")
+	}
+}
+
+func TestScan_Baseline(t *testing.T) {
+	base := makeScanDir(t, map[string]map[string]string{"app": nil, "lib": nil})
+	structure := []string{"structure"}
+
+	first, err := Scan(ScanOptions{ReposDir: base, Categories: structure})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Findings) == 0 {
+		t.Fatal("expected findings for bare repos")
+	}
+	for _, f := range first.Findings {
+		if f.Fingerprint == "" {
+			t.Fatalf("finding without fingerprint: %+v", f)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), BaselineFile)
+	b := &Baseline{}
+	b.Update(first, time.Now())
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// new findings show up, baselined ones are hidden
+	if err := os.WriteFile(filepath.Join(base, "app", "README.md"), []byte("# app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules := []Rule{{ID: "codeowners", Kind: RuleFileExists, Path: "CODEOWNERS", Category: "structure", Message: "No CODEOWNERS"}}
+	second, err := Scan(ScanOptions{ReposDir: base, Categories: structure, Rules: rules, Baseline: loaded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Findings) != 2 {
+		t.Fatalf("findings = %+v, want the 2 codeowners findings", second.Findings)
+	}
+	for _, f := range second.Findings {
+		if f.Check != "codeowners" || f.Status != "" {
+			t.Errorf("unexpected finding %+v", f)
+		}
+	}
+	if second.Baselined != len(first.Findings)-1 { // README.md now exists in app
+		t.Errorf("Baselined = %d, want %d", second.Baselined, len(first.Findings)-1)
+	}
+
+	all, err := Scan(ScanOptions{ReposDir: base, Categories: structure, Rules: rules, Baseline: loaded, IncludeKnown: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Findings) != len(second.Findings)+second.Baselined {
+		t.Errorf("IncludeKnown findings = %d, want %d", len(all.Findings), len(second.Findings)+second.Baselined)
+	}
+	if n := countNew(all.Findings); n != len(second.Findings) {
+		t.Errorf("new findings = %d, want %d", n, len(second.Findings))
+	}
+	if n := len(all.WithoutKnown().Findings); n != len(second.Findings) {
+		t.Errorf("WithoutKnown findings = %d, want %d", n, len(second.Findings))
+	}
+}
+
+func TestBaseline_UpdateKeepsUnscannedRepos(t *testing.T) {
+	b := &Baseline{Findings: []BaselineEntry{
+		{Fingerprint: "1", Repo: "app", Check: "missing-readme"},
+		{Fingerprint: "2", Repo: "lib", Check: "missing-readme"},
+	}}
+	b.Update(&ScanResult{
+		ReposScanned: []string{"app"},
+		Findings: []Finding{
+			{Repo: "app", Check: "missing-license", Fingerprint: "3"},
+			{Repo: "app", Check: "missing-ci", Fingerprint: "4", Status: StatusSuppressed},
+		},
+	}, time.Now())
+
+	if b.Contains("1") || !b.Contains("2") || !b.Contains("3") || b.Contains("4") {
+		t.Errorf("baseline = %+v", b.Findings)
+	}
+
+	if missing, err := LoadBaseline(filepath.Join(t.TempDir(), "none.json")); err != nil || missing != nil {
+		t.Errorf("missing baseline = %v, err = %v", missing, err)
+	}
+	var none *Baseline
+	if none.Contains("1") {
+		t.Error("nil baseline contains nothing")
+	}
+}
@@ -0,0 +1,182 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// cacheFormat is bumped when cached findings change shape.
+	cacheFormat = 1
+
+	cacheGitTimeout = 10 * time.Second
+)
+
+// DefaultCachePath returns ~/.tokencontrol/scan-cache.json.
+func DefaultCachePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".tokencontrol", "scan-cache.json")
+}
+
+// scanCache holds the findings of each repo keyed on its HEAD commit and
+// working tree, so unchanged repos are not re-checked.
+type scanCache struct {
+	Entries map[string]cacheEntry `json:"entries"` // by absolute repo path
+
+	mu      sync.Mutex
+	changed bool
+}
+
+type cacheEntry struct {
+	Key       string    `json:"key"`
+	Findings  []Finding `json:"findings"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// loadCache reads the cache at path. A missing or unreadable cache is empty.
+func loadCache(path string) *scanCache {
+	c := &scanCache{Entries: make(map[string]cacheEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("ignoring scan cache", "path", path, "error", err)
+		}
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		slog.Warn("ignoring scan cache", "path", path, "error", err)
+		return &scanCache{Entries: make(map[string]cacheEntry)}
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]cacheEntry)
+	}
+	return c
+}
+
+func (c *scanCache) get(repoPath, key string) ([]Finding, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.Entries[repoPath]
+	if !ok || e.Key != key {
+		return nil, false
+	}
+	return append([]Finding(nil), e.Findings...), true
+}
+
+func (c *scanCache) put(repoPath, key string, findings []Finding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Entries[repoPath] = cacheEntry{Key: key, Findings: findings, ScannedAt: time.Now().UTC()}
+	c.changed = true
+}
+
+// save writes the cache if it changed, dropping repos that no longer exist.
+// The file is replaced atomically so concurrent scans never read a partial
+// cache.
+func (c *scanCache) save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for repoPath := range c.Entries {
+		if !isDir(repoPath) {
+			delete(c.Entries, repoPath)
+			c.changed = true
+		}
+	}
+	if !c.changed {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode scan cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create scan cache dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write scan cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write scan cache: %w", err)
+	}
+	c.changed = false
+	return nil
+}
+
+// cacheSalt covers everything besides the repo that decides its findings:
+// the tokencontrol version, the category filter, the custom rules, the
+// digest of the advisory database and the history depth.
+func cacheSalt(version string, categories []string, rules []Rule, advisories string, historyDepth int) string {
+	cats := append([]string(nil), categories...)
+	sort.Strings(cats)
+	rulesJSON, _ := json.Marshal(rules)
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s\x00%s\x00%s\x00%d\x00", cacheFormat, version, strings.Join(cats, ","), advisories, historyDepth)
+	h.Write(rulesJSON)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// repoCacheKey hashes the salt, the repo's HEAD commit and its working tree
+// state: the paths git reports as modified, untracked or ignored, and the
+// content of the modified and untracked files. It returns "" when git can't
+// describe the repo (no commits yet, git missing), which disables caching
+// for it.
+func repoCacheKey(repoPath, salt string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheGitTimeout)
+	defer cancel()
+
+	head, err := gitOutput(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	// ignored paths are listed by name only (a directory once, not its
+	// contents): checks look at whether files such as .env exist, never at
+	// build output
+	status, err := gitOutput(ctx, repoPath, "status", "--porcelain=v1", "-z", "--untracked-files=all", "--ignored=matching")
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", salt, bytes.TrimSpace(head))
+	h.Write(status)
+	for _, entry := range bytes.Split(status, []byte{0}) {
+		// "XY path"; rename sources follow their entry without a status
+		if len(entry) < 4 || entry[2] != ' ' || bytes.HasPrefix(entry, []byte("!!")) {
+			continue
+		}
+		rel := string(entry[3:])
+		if strings.HasSuffix(rel, "/") {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(rel))); err == nil {
+			fmt.Fprintf(h, "\x00%s\x00%d\x00", rel, len(data))
+			h.Write(data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,127 @@
+package scan
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// makeGitRepo creates a committed git repo at base/name with files.
+func makeGitRepo(t *testing.T, base, name string, files map[string]string) string {
+	t.Helper()
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@test.com")
+	}
+	dir := filepath.Join(base, name)
+	for rel, content := range files {
+		writeFile(t, filepath.Join(dir, rel), content)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-qm", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepoCacheKey(t *testing.T) {
+	dir := makeGitRepo(t, t.TempDir(), "app", map[string]string{
+		"go.mod":     "module m\n\ngo 1.24\n",
+		".gitignore": ".env\n",
+	})
+
+	key := repoCacheKey(dir, "salt")
+	if key == "" {
+		t.Fatal("no cache key for a committed repo")
+	}
+	if again := repoCacheKey(dir, "salt"); again != key {
+		t.Error("cache key not stable")
+	}
+	if repoCacheKey(dir, "other") == key {
+		t.Error("cache key ignores the salt")
+	}
+
+	// each edit of a dirty file changes the key, not just the first
+	writeFile(t, filepath.Join(dir, "go.mod"), "module m\n\ngo 1.21\n")
+	dirty := repoCacheKey(dir, "salt")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module m\n\ngo 1.22\n")
+	if dirty == key || repoCacheKey(dir, "salt") == dirty {
+		t.Error("cache key ignores working tree content")
+	}
+
+	// ignored files count by name
+	before := repoCacheKey(dir, "salt")
+	writeFile(t, filepath.Join(dir, ".env"), "A=1\n")
+	if repoCacheKey(dir, "salt") == before {
+		t.Error("cache key ignores ignored files")
+	}
+
+	if got := repoCacheKey(makeRepo(t, "nogit", nil), "salt"); got != "" {
+		t.Errorf("cache key for a repo without commits = %q, want empty", got)
+	}
+}
+
+func TestCacheSalt(t *testing.T) {
+	base := cacheSalt("1.0.0", []string{"go", "ci"}, nil, "", 0)
+	if cacheSalt("1.0.0", []string{"ci", "go"}, nil, "", 0) != base {
+		t.Error("salt depends on category order")
+	}
+	if cacheSalt("1.1.0", []string{"go", "ci"}, nil, "", 0) == base {
+		t.Error("salt ignores the version")
+	}
+	if cacheSalt("1.0.0", []string{"go", "ci"}, []Rule{{ID: "r", Kind: RuleFileExists, Path: "x"}}, "", 0) == base {
+		t.Error("salt ignores custom rules")
+	}
+	if cacheSalt("1.0.0", []string{"go", "ci"}, nil, "digest", 0) == base {
+		t.Error("salt ignores the advisory database")
+	}
+	if cacheSalt("1.0.0", []string{"go", "ci"}, nil, "", 50) == base {
+		t.Error("salt ignores the history depth")
+	}
+}
+
+func TestScanCache_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "scan-cache.json")
+	repo := t.TempDir()
+	gone := filepath.Join(t.TempDir(), "gone")
+
+	c := loadCache(path)
+	c.put(repo, "k1", []Finding{{Repo: "app", Check: "missing-ci"}})
+	c.put(gone, "k2", nil)
+	if err := c.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	c = loadCache(path)
+	findings, ok := c.get(repo, "k1")
+	if !ok || len(findings) != 1 || findings[0].Check != "missing-ci" {
+		t.Errorf("get = %v, %v", findings, ok)
+	}
+	if _, ok := c.get(repo, "stale"); ok {
+		t.Error("hit for a different key")
+	}
+	if _, ok := c.get(gone, "k2"); ok {
+		t.Error("entry for a removed repo survived save")
+	}
+
+	writeFile(t, path, "not json")
+	if c := loadCache(path); len(c.Entries) != 0 {
+		t.Error("corrupt cache not ignored")
+	}
+}
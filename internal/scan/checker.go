@@ -8,6 +8,20 @@ type Checker interface {
 	Run(repo *RepoInfo) []Finding
 }
 
+// Fixer is implemented by checks whose findings can be fixed
+// deterministically, without an agent.
+type Fixer interface {
+	// Fix returns the files that resolve f, or nil when f has no
+	// deterministic fix.
+	Fix(repo *RepoInfo, f *Finding) []FixFile
+}
+
+// FixFile is a file created by a deterministic fix.
+type FixFile struct {
+	Path    string // repo-relative
+	Content string
+}
+
 // AllCheckers returns the complete list of check implementations.
 func AllCheckers() []Checker {
 	return []Checker{
@@ -26,21 +40,21 @@ func AllCheckers() []Checker {
 			promptFn: promptContributing},
 		&fileCheck{id: "missing-license", cat: "structure", file: "LICENSE", sev: SeverityWarning,
 			msg: "No LICENSE file found", sug: "Add a LICENSE file. The project typically uses MIT license.",
-			promptFn: promptLicense},
+			promptFn: promptLicense, fixFn: fixLicense},
 		&fileCheck{id: "missing-changelog", cat: "structure", file: "CHANGELOG.md", sev: SeverityInfo,
 			msg: "No CHANGELOG.md found", sug: "Create a CHANGELOG.md following Keep a Changelog format. Document notable changes per version.",
-			promptFn: promptChangelog},
+			promptFn: promptChangelog, fixFn: fixChangelog},
 		&fileCheck{id: "missing-ci", cat: "structure", file: ".github/workflows/ci.yml", sev: SeverityCritical,
 			msg: "No CI pipeline found", sug: "Create .github/workflows/ci.yml with jobs for: test (go test -race), lint (golangci-lint), fmt (gofmt check), and build (multi-platform).",
 			promptFn: promptCI},
 		&fileCheck{id: "missing-gitignore", cat: "structure", file: ".gitignore", sev: SeverityWarning,
 			msg: "No .gitignore found", sug: "Create a .gitignore with standard Go ignores: binary name, *.exe, vendor/, coverage.out, .env, *.pem.",
-			promptFn: promptGitignore},
+			promptFn: promptGitignore, fixFn: fixGitignore},
 
 		// go
 		&fileCheck{id: "go-missing-golangci", cat: "go", file: ".golangci.yml", sev: SeverityWarning,
 			msg: "No golangci-lint config found", sug: "Create .golangci.yml with linter settings. At minimum configure errcheck exclude-functions for fmt.Fprint variants.",
-			langFilter: LangGo, promptFn: promptGolangciLint},
+			langFilter: LangGo, promptFn: promptGolangciLint, fixFn: fixGolangciLint},
 		&fileCheck{id: "go-missing-goreleaser", cat: "go", file: ".goreleaser.yml", sev: SeverityInfo,
 			msg: "No GoReleaser config found", sug: "Create .goreleaser.yml for automated releases with multi-platform builds, Docker images, and Homebrew tap updates.",
 			langFilter: LangGo, promptFn: promptGoreleaser},
@@ -54,10 +68,24 @@ func AllCheckers() []Checker {
 		&pyMissingProjectCheck{},
 		&pyNoTestsCheck{},
 
+		// node
+		&nodeNoTestsCheck{},
+		&nodeNoLockfileCheck{},
+		&nodeNoLintCheck{},
+		&nodeNoEnginesCheck{},
+
+		// rust
+		&rustNoTestsCheck{},
+		&rustNoLockfileCheck{},
+		&rustNoClippyCheck{},
+		&rustNoToolchainCheck{},
+
 		// security
 		&secNoSecurityScanCheck{},
 		&secEnvCommittedCheck{},
 		&secHardcodedTokenCheck{},
+		&secVulnerableDependencyCheck{},
+		&secSecretInHistoryCheck{depth: DefaultHistoryDepth},
 
 		// ci
 		&ciNoTestJobCheck{},
@@ -67,7 +95,7 @@ func AllCheckers() []Checker {
 			promptFn: promptRelease},
 		&fileCheck{id: "ci-no-dependabot", cat: "ci", file: ".github/dependabot.yml", sev: SeverityInfo,
 			msg: "No Dependabot config found", sug: "Create .github/dependabot.yml for automated dependency updates. Configure for gomod ecosystem with weekly schedule.",
-			promptFn: promptDependabot},
+			promptFn: promptDependabot, fixFn: fixDependabot},
 
 		// quality
 		&qualityNoCoverageCheck{},
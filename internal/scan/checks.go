@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -29,13 +30,25 @@ func (b *promptBuilder) blank() *promptBuilder {
 	return b
 }
 
-func (b *promptBuilder) verification(lang Language) *promptBuilder {
+func (b *promptBuilder) verification(r *RepoInfo) *promptBuilder {
 	b.line("Verification:")
-	switch lang {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		b.line("  make test && make lint")
-	case LangPython:
+	case r.Uses(LangPython):
 		b.line("  pytest && ruff check . && black --check .")
+	case r.Uses(LangNode):
+		pm := r.BuildTool
+		if pm != "pnpm" && pm != "yarn" {
+			pm = "npm"
+		}
+		b.line(fmt.Sprintf("  %s test && %s run lint", pm, pm))
+	case r.Uses(LangRust):
+		b.line("  cargo test && cargo clippy -- -D warnings")
+	case r.Uses(LangJava) && r.BuildTool == "maven":
+		b.line("  mvn verify")
+	case r.Uses(LangJava):
+		b.line("  ./gradlew check")
 	default:
 		b.line("  Run existing tests and lint checks.")
 	}
@@ -64,6 +77,7 @@ type fileCheck struct {
 	msg        string
 	sug        string
 	promptFn   func(*RepoInfo) string // builds detailed prompt; nil = use sug
+	fixFn      func(*RepoInfo) string // content of the missing file; nil = no deterministic fix
 	langFilter Language               // 0 = applies to all
 }
 
@@ -73,7 +87,7 @@ func (c *fileCheck) Applies(r *RepoInfo) bool {
 	if c.langFilter == LangUnknown {
 		return true
 	}
-	return r.Language == c.langFilter || r.Language == LangMulti
+	return r.Uses(c.langFilter)
 }
 
 func (c *fileCheck) Run(r *RepoInfo) []Finding {
@@ -90,6 +104,13 @@ func (c *fileCheck) Run(r *RepoInfo) []Finding {
 	return []Finding{f}
 }
 
+func (c *fileCheck) Fix(r *RepoInfo, _ *Finding) []FixFile {
+	if c.fixFn == nil {
+		return nil
+	}
+	return []FixFile{{Path: c.file, Content: c.fixFn(r)}}
+}
+
 // --- fileCheck prompt builders ---
 
 func promptMakefile(r *RepoInfo) string {
@@ -97,14 +118,14 @@ func promptMakefile(r *RepoInfo) string {
 	p.line(fmt.Sprintf("Create a Makefile for the %s project %s.", r.Language, r.Name))
 	p.blank()
 	p.line("Required targets:")
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line(fmt.Sprintf("  build: go build -o bin/%s ./cmd/%s (adjust path if cmd/ layout differs)", r.Name, r.Name))
 		p.line("  test: go test -race ./...")
 		p.line("  lint: golangci-lint run ./...")
 		p.line("  fmt: gofmt -w .")
 		p.line("  clean: rm -rf bin/ coverage.out")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("  test: pytest")
 		p.line("  lint: ruff check . && black --check .")
 		p.line("  fmt: black . && ruff check --fix .")
@@ -200,8 +221,8 @@ func promptCI(r *RepoInfo) string {
 	p := newPrompt()
 	p.line("Create .github/workflows/ci.yml with a CI pipeline.")
 	p.blank()
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("Trigger: push and pull_request on main branch.")
 		p.blank()
 		p.line("Jobs:")
@@ -217,7 +238,7 @@ func promptCI(r *RepoInfo) string {
 		p.line("    - uses: actions/checkout@v4")
 		p.line("    - uses: actions/setup-go@v5")
 		p.line("    - run: make build")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("Trigger: push and pull_request on main branch.")
 		p.blank()
 		p.line("Jobs:")
@@ -238,8 +259,8 @@ func promptGitignore(r *RepoInfo) string {
 	p := newPrompt()
 	p.line("Create .gitignore with appropriate patterns.")
 	p.blank()
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("Include:")
 		p.line(fmt.Sprintf("  %s", r.Name))
 		p.line("  bin/")
@@ -249,7 +270,7 @@ func promptGitignore(r *RepoInfo) string {
 		p.line("  .env")
 		p.line("  *.pem")
 		p.line("  .DS_Store")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("Include:")
 		p.line("  __pycache__/")
 		p.line("  *.pyc")
@@ -299,8 +320,8 @@ func promptRelease(r *RepoInfo) string {
 	p := newPrompt()
 	p.line("Create .github/workflows/release.yml triggered on version tags.")
 	p.blank()
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("Trigger: push tags matching 'v*'.")
 		p.blank()
 		p.line("Jobs:")
@@ -330,13 +351,13 @@ func promptDependabot(r *RepoInfo) string {
 	p.blank()
 	p.line("version: 2")
 	p.line("updates:")
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("  - package-ecosystem: gomod")
 		p.line("    directory: /")
 		p.line("    schedule:")
 		p.line("      interval: weekly")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("  - package-ecosystem: pip")
 		p.line("    directory: /")
 		p.line("    schedule:")
@@ -362,24 +383,28 @@ type goOutdatedCheck struct{}
 func (c *goOutdatedCheck) ID() string       { return "go-outdated-version" }
 func (c *goOutdatedCheck) Category() string { return "go" }
 func (c *goOutdatedCheck) Applies(r *RepoInfo) bool {
-	return r.Language == LangGo || r.Language == LangMulti
+	return r.Uses(LangGo)
 }
 
 func (c *goOutdatedCheck) Run(r *RepoInfo) []Finding {
-	gomod := filepath.Join(r.Path, "go.mod")
-	data, err := os.ReadFile(gomod)
-	if err != nil {
-		return nil
-	}
-	ver := parseGoVersion(string(data))
-	if ver == "" {
-		return nil
-	}
-	if isGoVersionOutdated(ver) {
+	var findings []Finding
+	for _, m := range r.ModulesOf(LangGo) {
+		gomod := m.File("go.mod")
+		data, err := os.ReadFile(filepath.Join(r.Path, filepath.FromSlash(gomod)))
+		if err != nil {
+			continue
+		}
+		ver := parseGoVersion(string(data))
+		if ver == "" || !isGoVersionOutdated(ver) {
+			continue
+		}
 		p := newPrompt()
-		p.line(fmt.Sprintf("Update Go version in go.mod from %s to 1.24.", ver))
+		p.line(fmt.Sprintf("Update Go version in %s from %s to 1.24.", gomod, ver))
 		p.blank()
 		p.line("Steps:")
+		if m.Dir != "." {
+			p.line(fmt.Sprintf("  cd %s", m.Dir))
+		}
 		p.line("  go mod edit -go=1.24")
 		p.line("  go mod tidy")
 		p.blank()
@@ -387,17 +412,19 @@ func (c *goOutdatedCheck) Run(r *RepoInfo) []Finding {
 		p.line("Fix any compilation errors from API changes.")
 		p.constraints()
 		p.blank()
-		p.verification(r.Language)
+		p.verification(r)
 
-		return []Finding{{
+		findings = append(findings, Finding{
 			Repo: r.Name, Check: c.ID(), Category: c.Category(),
 			Severity:   SeverityWarning,
-			Message:    fmt.Sprintf("go.mod specifies Go %s (< 1.24)", ver),
-			Suggestion: fmt.Sprintf("Update go.mod to use Go 1.24 or later. Run: go mod edit -go=1.24 && go mod tidy. Current version: %s.", ver),
+			Message:    fmt.Sprintf("%s specifies Go %s (< 1.24)", gomod, ver),
+			Location:   gomod,
+			Line:       lineOf(string(data), "go "+ver),
+			Suggestion: fmt.Sprintf("Update %s to use Go 1.24 or later. Run: go mod edit -go=1.24 && go mod tidy. Current version: %s.", gomod, ver),
 			Prompt:     p.String(),
-		}}
+		})
 	}
-	return nil
+	return findings
 }
 
 var goVersionRe = regexp.MustCompile(`(?m)^go\s+([\d.]+)`)
@@ -433,38 +460,43 @@ type goNoTestsCheck struct{}
 func (c *goNoTestsCheck) ID() string       { return "go-no-tests" }
 func (c *goNoTestsCheck) Category() string { return "go" }
 func (c *goNoTestsCheck) Applies(r *RepoInfo) bool {
-	return r.Language == LangGo || r.Language == LangMulti
+	return r.Uses(LangGo)
 }
 
 func (c *goNoTestsCheck) Run(r *RepoInfo) []Finding {
-	found := false
-	_ = filepath.WalkDir(r.Path, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		base := d.Name()
-		if d.IsDir() && (base == "vendor" || base == ".git" || base == "node_modules") {
-			return filepath.SkipDir
+	var findings []Finding
+	for _, m := range r.ModulesOf(LangGo) {
+		if f := c.runModule(r, m); f != nil {
+			findings = append(findings, *f)
 		}
-		if !d.IsDir() && strings.HasSuffix(base, "_test.go") {
-			found = true
-			return filepath.SkipAll
+	}
+	return findings
+}
+
+func (c *goNoTestsCheck) runModule(r *RepoInfo, m ModuleRoot) *Finding {
+	root := filepath.Join(r.Path, filepath.FromSlash(m.Dir))
+	files := r.moduleFiles(m, "vendor", "node_modules")
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			return nil
 		}
-		return nil
-	})
-	if found {
-		return nil
 	}
 
 	// collect Go packages for context
-	pkgs := listGoPackages(r.Path)
+	pkgs := listGoPackages(m, files)
 	p := newPrompt()
-	p.line(fmt.Sprintf("Add test files for the Go project %s.", r.Name))
+	msg := "No Go test files found"
+	if m.Dir == "." {
+		p.line(fmt.Sprintf("Add test files for the Go project %s.", r.Name))
+	} else {
+		msg = fmt.Sprintf("No Go test files found in module %s", m.Dir)
+		p.line(fmt.Sprintf("Add test files for the Go module %s in %s (directory %s).", m.Name, r.Name, m.Dir))
+	}
 	p.blank()
 	if len(pkgs) > 0 {
 		p.line("Packages that need tests:")
 		for _, pkg := range pkgs {
-			p.line(fmt.Sprintf("  %s — create %s_test.go", pkg, filepath.Base(pkg)))
+			p.line(fmt.Sprintf("  %s — create %s_test.go", m.File(pkg), filepath.Base(pkg)))
 		}
 		p.blank()
 	}
@@ -476,45 +508,64 @@ func (c *goNoTestsCheck) Run(r *RepoInfo) []Finding {
 	p.line("- Test file naming: <source>_test.go alongside the source file")
 	p.constraints()
 	p.blank()
-	p.verification(r.Language)
+	p.verification(r)
 
-	return []Finding{{
+	return &Finding{
 		Repo: r.Name, Check: c.ID(), Category: c.Category(),
 		Severity:   SeverityCritical,
-		Message:    "No Go test files found",
+		Message:    msg,
+		Location:   m.File("go.mod"),
+		Line:       goModuleLine(root),
 		Suggestion: "Add test files (*_test.go) for all packages. Tests are mandatory — target > 85% coverage. Use table-driven tests, -race flag, and deterministic assertions.",
 		Prompt:     p.String(),
-	}}
+	}
 }
 
-// listGoPackages returns relative paths of Go packages in the repo (max 20).
-func listGoPackages(root string) []string {
+// goModuleLine returns the line of the module directive in go.mod, or 0.
+func goModuleLine(root string) int {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return 0
+	}
+	return lineOf(string(data), "module ")
+}
+
+// lineOf returns the 1-based number of the first line containing substr,
+// or 0 when there is none.
+func lineOf(content, substr string) int {
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, substr) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// listGoPackages returns the module-relative dirs of the Go packages among
+// files (max 20), skipping testdata.
+func listGoPackages(m ModuleRoot, files []string) []string {
 	const maxPkgs = 20
 	seen := make(map[string]bool)
 	var pkgs []string
-	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		base := d.Name()
-		if d.IsDir() && (base == "vendor" || base == ".git" || base == "node_modules" || base == "testdata") {
-			return filepath.SkipDir
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") || strings.HasSuffix(f, "_test.go") || inDir(f, "testdata") {
+			continue
 		}
-		if !d.IsDir() && strings.HasSuffix(base, ".go") && !strings.HasSuffix(base, "_test.go") {
-			dir, _ := filepath.Rel(root, filepath.Dir(path))
+		dir := path.Dir(f)
+		if m.Dir != "." {
+			dir = strings.TrimPrefix(strings.TrimPrefix(dir, m.Dir), "/")
 			if dir == "" {
 				dir = "."
 			}
-			if !seen[dir] {
-				seen[dir] = true
-				pkgs = append(pkgs, dir)
-				if len(pkgs) >= maxPkgs {
-					return filepath.SkipAll
-				}
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			pkgs = append(pkgs, dir)
+			if len(pkgs) >= maxPkgs {
+				break
 			}
 		}
-		return nil
-	})
+	}
 	return pkgs
 }
 
@@ -523,46 +574,52 @@ type goNoRaceCheck struct{}
 func (c *goNoRaceCheck) ID() string       { return "go-no-race-flag" }
 func (c *goNoRaceCheck) Category() string { return "go" }
 func (c *goNoRaceCheck) Applies(r *RepoInfo) bool {
-	return r.Language == LangGo || r.Language == LangMulti
+	return r.Uses(LangGo)
 }
 
 func (c *goNoRaceCheck) Run(r *RepoInfo) []Finding {
-	makefile := filepath.Join(r.Path, "Makefile")
-	data, err := os.ReadFile(makefile)
-	if err != nil {
-		return nil
-	}
-	content := string(data)
-	if strings.Contains(content, "-race") {
-		return nil
-	}
-	if !strings.Contains(content, "go test") && !strings.Contains(content, "test:") {
-		return nil
-	}
+	var findings []Finding
+	for _, m := range r.ModulesOf(LangGo) {
+		makefile := m.File("Makefile")
+		data, err := os.ReadFile(filepath.Join(r.Path, filepath.FromSlash(makefile)))
+		if err != nil {
+			continue
+		}
+		content := string(data)
+		if strings.Contains(content, "-race") {
+			continue
+		}
+		if !strings.Contains(content, "go test") && !strings.Contains(content, "test:") {
+			continue
+		}
 
-	p := newPrompt()
-	p.line("Add -race flag to all go test commands in the Makefile.")
-	p.blank()
-	p.line("Open Makefile and find every line containing 'go test'.")
-	p.line("Add the -race flag if not already present.")
-	p.blank()
-	p.line("Example:")
-	p.line("  Before: go test ./...")
-	p.line("  After:  go test -race ./...")
-	p.blank()
-	p.line("Data races are undefined behavior in Go. The race detector")
-	p.line("must always be enabled during tests.")
-	p.constraints()
-	p.blank()
-	p.verification(r.Language)
+		p := newPrompt()
+		p.line(fmt.Sprintf("Add -race flag to all go test commands in %s.", makefile))
+		p.blank()
+		p.line(fmt.Sprintf("Open %s and find every line containing 'go test'.", makefile))
+		p.line("Add the -race flag if not already present.")
+		p.blank()
+		p.line("Example:")
+		p.line("  Before: go test ./...")
+		p.line("  After:  go test -race ./...")
+		p.blank()
+		p.line("Data races are undefined behavior in Go. The race detector")
+		p.line("must always be enabled during tests.")
+		p.constraints()
+		p.blank()
+		p.verification(r)
 
-	return []Finding{{
-		Repo: r.Name, Check: c.ID(), Category: c.Category(),
-		Severity:   SeverityWarning,
-		Message:    "Makefile test target missing -race flag",
-		Suggestion: "Add -race flag to go test commands in Makefile. Data races are undefined behavior in Go — the race detector must always be enabled during tests.",
-		Prompt:     p.String(),
-	}}
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s test target missing -race flag", makefile),
+			Location:   makefile,
+			Line:       lineOf(content, "go test"),
+			Suggestion: fmt.Sprintf("Add -race flag to go test commands in %s. Data races are undefined behavior in Go — the race detector must always be enabled during tests.", makefile),
+			Prompt:     p.String(),
+		})
+	}
+	return findings
 }
 
 type goMissingDockerfileCheck struct{}
@@ -570,40 +627,65 @@ type goMissingDockerfileCheck struct{}
 func (c *goMissingDockerfileCheck) ID() string       { return "go-missing-dockerfile" }
 func (c *goMissingDockerfileCheck) Category() string { return "go" }
 func (c *goMissingDockerfileCheck) Applies(r *RepoInfo) bool {
-	return (r.Language == LangGo || r.Language == LangMulti) && r.HasCmd
+	return len(c.binaryModules(r)) > 0
 }
 
-func (c *goMissingDockerfileCheck) Run(r *RepoInfo) []Finding {
-	matches, _ := filepath.Glob(filepath.Join(r.Path, "Dockerfile*"))
-	if len(matches) > 0 {
-		return nil
+// binaryModules returns the Go modules with a cmd/ dir.
+func (c *goMissingDockerfileCheck) binaryModules(r *RepoInfo) []ModuleRoot {
+	var mods []ModuleRoot
+	for _, m := range r.ModulesOf(LangGo) {
+		if isDir(filepath.Join(r.Path, filepath.FromSlash(m.File("cmd")))) {
+			mods = append(mods, m)
+		}
 	}
+	return mods
+}
 
-	p := newPrompt()
-	p.line(fmt.Sprintf("Create a multi-stage Dockerfile for %s.", r.Name))
-	p.blank()
-	p.line("Stage 1 (build):")
-	p.line("  FROM golang:1.24-alpine AS build")
-	p.line("  WORKDIR /src")
-	p.line("  COPY go.mod go.sum ./")
-	p.line("  RUN go mod download")
-	p.line("  COPY . .")
-	p.line(fmt.Sprintf("  RUN CGO_ENABLED=0 go build -o /bin/%s ./cmd/%s", r.Name, r.Name))
-	p.blank()
-	p.line("Stage 2 (runtime):")
-	p.line("  FROM alpine:latest")
-	p.line("  RUN apk --no-cache add ca-certificates")
-	p.line(fmt.Sprintf("  COPY --from=build /bin/%s /usr/local/bin/", r.Name))
-	p.line(fmt.Sprintf("  ENTRYPOINT [\"%s\"]", r.Name))
-	p.constraints()
+func (c *goMissingDockerfileCheck) Run(r *RepoInfo) []Finding {
+	var findings []Finding
+	for _, m := range c.binaryModules(r) {
+		matches, _ := filepath.Glob(filepath.Join(r.Path, filepath.FromSlash(m.Dir), "Dockerfile*"))
+		if len(matches) > 0 {
+			continue
+		}
+		name, target := r.Name, ""
+		msg := "No Dockerfile found for Go binary project"
+		if m.Dir != "." {
+			name, target = filepath.Base(m.Dir), " at "+m.File("Dockerfile")
+			msg = fmt.Sprintf("No Dockerfile found for Go binary module %s", m.Dir)
+		}
 
-	return []Finding{{
-		Repo: r.Name, Check: c.ID(), Category: c.Category(),
-		Severity:   SeverityInfo,
-		Message:    "No Dockerfile found for Go binary project",
-		Suggestion: "Create a multi-stage Dockerfile: build stage with golang:1.24-alpine, runtime stage with alpine:latest or scratch. Copy the compiled binary, set ENTRYPOINT.",
-		Prompt:     p.String(),
-	}}
+		p := newPrompt()
+		p.line(fmt.Sprintf("Create a multi-stage Dockerfile for %s%s.", name, target))
+		p.blank()
+		p.line("Stage 1 (build):")
+		p.line("  FROM golang:1.24-alpine AS build")
+		p.line("  WORKDIR /src")
+		p.line("  COPY go.mod go.sum ./")
+		p.line("  RUN go mod download")
+		p.line("  COPY . .")
+		p.line(fmt.Sprintf("  RUN CGO_ENABLED=0 go build -o /bin/%s ./cmd/%s", name, name))
+		p.blank()
+		p.line("Stage 2 (runtime):")
+		p.line("  FROM alpine:latest")
+		p.line("  RUN apk --no-cache add ca-certificates")
+		p.line(fmt.Sprintf("  COPY --from=build /bin/%s /usr/local/bin/", name))
+		p.line(fmt.Sprintf("  ENTRYPOINT [\"%s\"]", name))
+		p.constraints()
+
+		f := Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityInfo,
+			Message:    msg,
+			Suggestion: "Create a multi-stage Dockerfile: build stage with golang:1.24-alpine, runtime stage with alpine:latest or scratch. Copy the compiled binary, set ENTRYPOINT.",
+			Prompt:     p.String(),
+		}
+		if m.Dir != "." {
+			f.Location = m.Dir
+		}
+		findings = append(findings, f)
+	}
+	return findings
 }
 
 // --- Python checks ---
@@ -613,13 +695,22 @@ type pyMissingProjectCheck struct{}
 func (c *pyMissingProjectCheck) ID() string       { return "py-missing-pyproject" }
 func (c *pyMissingProjectCheck) Category() string { return "python" }
 func (c *pyMissingProjectCheck) Applies(r *RepoInfo) bool {
-	return r.Language == LangPython || r.Language == LangMulti
+	return r.Uses(LangPython)
 }
 
 func (c *pyMissingProjectCheck) Run(r *RepoInfo) []Finding {
 	if fileExists(filepath.Join(r.Path, "pyproject.toml")) || fileExists(filepath.Join(r.Path, "setup.py")) {
 		return nil
 	}
+	// a nested pyproject.toml or setup.py means the Python code is packaged
+	// in a subdirectory of a monorepo
+	location := ""
+	for _, m := range r.ModulesOf(LangPython) {
+		if m.Manifest != "requirements.txt" {
+			return nil
+		}
+		location = m.File(m.Manifest)
+	}
 
 	p := newPrompt()
 	p.line(fmt.Sprintf("Create pyproject.toml for %s.", r.Name))
@@ -643,12 +734,13 @@ func (c *pyMissingProjectCheck) Run(r *RepoInfo) []Finding {
 	p.line("  dev = [\"pytest\", \"ruff\", \"black\"]")
 	p.constraints()
 	p.blank()
-	p.verification(r.Language)
+	p.verification(r)
 
 	return []Finding{{
 		Repo: r.Name, Check: c.ID(), Category: c.Category(),
 		Severity:   SeverityWarning,
 		Message:    "No pyproject.toml or setup.py found",
+		Location:   location,
 		Suggestion: "Create pyproject.toml with build system, project metadata, and tool configurations for Black (100 chars) and Ruff.",
 		Prompt:     p.String(),
 	}}
@@ -659,27 +751,15 @@ type pyNoTestsCheck struct{}
 func (c *pyNoTestsCheck) ID() string       { return "py-no-tests" }
 func (c *pyNoTestsCheck) Category() string { return "python" }
 func (c *pyNoTestsCheck) Applies(r *RepoInfo) bool {
-	return r.Language == LangPython || r.Language == LangMulti
+	return r.Uses(LangPython)
 }
 
 func (c *pyNoTestsCheck) Run(r *RepoInfo) []Finding {
-	found := false
-	_ = filepath.WalkDir(r.Path, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
+	for _, f := range r.Files(".venv", "venv", "__pycache__", "node_modules") {
+		base := path.Base(f)
+		if (strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py")) && strings.HasSuffix(base, ".py") {
 			return nil
 		}
-		base := d.Name()
-		if d.IsDir() && (base == ".venv" || base == "venv" || base == ".git" || base == "__pycache__" || base == "node_modules") {
-			return filepath.SkipDir
-		}
-		if !d.IsDir() && (strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py")) && strings.HasSuffix(base, ".py") {
-			found = true
-			return filepath.SkipAll
-		}
-		return nil
-	})
-	if found {
-		return nil
 	}
 
 	p := newPrompt()
@@ -697,7 +777,7 @@ func (c *pyNoTestsCheck) Run(r *RepoInfo) []Finding {
 	p.line("- Target > 85% coverage")
 	p.constraints()
 	p.blank()
-	p.verification(r.Language)
+	p.verification(r)
 
 	return []Finding{{
 		Repo: r.Name, Check: c.ID(), Category: c.Category(),
@@ -794,6 +874,7 @@ func (c *secEnvCommittedCheck) Run(r *RepoInfo) []Finding {
 		Repo: r.Name, Check: c.ID(), Category: c.Category(),
 		Severity:   SeverityCritical,
 		Message:    ".env file exists and is not in .gitignore",
+		Location:   ".env",
 		Suggestion: "Add .env to .gitignore and remove it from git tracking: git rm --cached .env. Check git history for leaked secrets and rotate any exposed credentials.",
 		Prompt:     p.String(),
 	}}
@@ -815,79 +896,81 @@ var sourceExts = map[string]bool{
 
 func (c *secHardcodedTokenCheck) Run(r *RepoInfo) []Finding {
 	var findings []Finding
-	_ = filepath.WalkDir(r.Path, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		base := d.Name()
-		if d.IsDir() {
-			if base == "vendor" || base == ".git" || base == "node_modules" || base == "testdata" || base == ".venv" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		ext := filepath.Ext(base)
-		if !sourceExts[ext] {
-			return nil
+	for _, rel := range r.Files("vendor", "node_modules", "testdata", ".venv") {
+		base := path.Base(rel)
+		if !sourceExts[path.Ext(base)] {
+			continue
 		}
 		// skip test files
 		if strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") {
-			return nil
+			continue
 		}
-
-		f, err := os.Open(path)
-		if err != nil {
-			return nil
+		// one finding per file is enough
+		if f := c.scanFile(r, rel); f != nil {
+			findings = append(findings, *f)
 		}
-		defer func() { _ = f.Close() }()
-
-		scanner := bufio.NewScanner(f)
-		lineNum := 0
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-			if tokenPattern.MatchString(line) {
-				// skip common false positives
-				lower := strings.ToLower(line)
-				if strings.Contains(lower, "example") || strings.Contains(lower, "placeholder") ||
-					strings.Contains(lower, "xxx") || strings.Contains(lower, "todo") ||
-					strings.Contains(lower, "env:") || strings.Contains(lower, "os.getenv") ||
-					strings.Contains(lower, "${") || strings.Contains(lower, "$(") {
-					continue
-				}
-				rel, _ := filepath.Rel(r.Path, path)
-
-				p := newPrompt()
-				p.line(fmt.Sprintf("Remove hardcoded secret from %s:%d.", rel, lineNum))
-				p.blank()
-				p.line("Replace the hardcoded value with an environment variable:")
-				switch filepath.Ext(base) {
-				case ".go":
-					p.line(fmt.Sprintf("  value := os.Getenv(\"SECRET_NAME\")  // was hardcoded at %s:%d", rel, lineNum))
-				case ".py":
-					p.line(fmt.Sprintf("  value = os.environ[\"SECRET_NAME\"]  # was hardcoded at %s:%d", rel, lineNum))
-				default:
-					p.line(fmt.Sprintf("  Replace literal value at %s:%d with environment variable reference.", rel, lineNum))
-				}
-				p.blank()
-				p.line("Add the variable name to .env.example with a placeholder value.")
-				p.line("Never commit real credentials to source control.")
-				p.constraints()
-
-				findings = append(findings, Finding{
-					Repo: r.Name, Check: c.ID(), Category: c.Category(),
-					Severity:   SeverityCritical,
-					Message:    fmt.Sprintf("Possible hardcoded secret at %s:%d", rel, lineNum),
-					Suggestion: fmt.Sprintf("Move the secret in %s:%d to an environment variable or secrets manager. Never commit credentials to source control.", rel, lineNum),
-					Prompt:     p.String(),
-				})
-				// one finding per file is enough
-				return nil
+	}
+	return findings
+}
+
+// scanFile returns a finding for the first likely secret in the file.
+func (c *secHardcodedTokenCheck) scanFile(r *RepoInfo, rel string) *Finding {
+	f, err := os.Open(filepath.Join(r.Path, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if tokenPattern.MatchString(line) {
+			if isPlaceholderLine(line) {
+				continue
+			}
+			p := newPrompt()
+			p.line(fmt.Sprintf("Remove hardcoded secret from %s:%d.", rel, lineNum))
+			p.blank()
+			p.line("Replace the hardcoded value with an environment variable:")
+			switch path.Ext(rel) {
+			case ".go":
+				p.line(fmt.Sprintf("  value := os.Getenv(\"SECRET_NAME\")  // was hardcoded at %s:%d", rel, lineNum))
+			case ".py":
+				p.line(fmt.Sprintf("  value = os.environ[\"SECRET_NAME\"]  # was hardcoded at %s:%d", rel, lineNum))
+			default:
+				p.line(fmt.Sprintf("  Replace literal value at %s:%d with environment variable reference.", rel, lineNum))
+			}
+			p.blank()
+			p.line("Add the variable name to .env.example with a placeholder value.")
+			p.line("Never commit real credentials to source control.")
+			p.constraints()
+
+			return &Finding{
+				Repo: r.Name, Check: c.ID(), Category: c.Category(),
+				Severity:   SeverityCritical,
+				Message:    fmt.Sprintf("Possible hardcoded secret at %s:%d", rel, lineNum),
+				Location:   rel,
+				Line:       lineNum,
+				Suggestion: fmt.Sprintf("Move the secret in %s:%d to an environment variable or secrets manager. Never commit credentials to source control.", rel, lineNum),
+				Prompt:     p.String(),
 			}
 		}
-		return nil
-	})
-	return findings
+	}
+	return nil
+}
+
+// isPlaceholderLine reports whether a line that looks like it holds a secret
+// is a common false positive: an example value or an environment lookup.
+func isPlaceholderLine(line string) bool {
+	lower := strings.ToLower(line)
+	for _, s := range []string{"example", "placeholder", "xxx", "todo", "env:", "os.getenv", "${", "$("} {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // --- CI checks ---
@@ -915,8 +998,8 @@ func (c *ciNoTestJobCheck) Run(r *RepoInfo) []Finding {
 	p.blank()
 	p.line("Add this job to the existing workflow (do NOT remove existing jobs):")
 	p.blank()
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("  test:")
 		p.line("    runs-on: ubuntu-latest")
 		p.line("    steps:")
@@ -925,7 +1008,7 @@ func (c *ciNoTestJobCheck) Run(r *RepoInfo) []Finding {
 		p.line("        with:")
 		p.line("          go-version-file: 'go.mod'")
 		p.line("      - run: make test")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("  test:")
 		p.line("    runs-on: ubuntu-latest")
 		p.line("    steps:")
@@ -947,6 +1030,7 @@ func (c *ciNoTestJobCheck) Run(r *RepoInfo) []Finding {
 		Repo: r.Name, Check: c.ID(), Category: c.Category(),
 		Severity:   SeverityWarning,
 		Message:    "CI workflow has no test step",
+		Location:   ".github/workflows/ci.yml",
 		Suggestion: "Add a test job to .github/workflows/ci.yml that runs 'make test' or 'go test -race ./...' on every push and PR.",
 		Prompt:     p.String(),
 	}}
@@ -975,8 +1059,8 @@ func (c *ciNoLintJobCheck) Run(r *RepoInfo) []Finding {
 	p.blank()
 	p.line("Add this job to the existing workflow (do NOT remove existing jobs):")
 	p.blank()
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("  lint:")
 		p.line("    runs-on: ubuntu-latest")
 		p.line("    steps:")
@@ -985,7 +1069,7 @@ func (c *ciNoLintJobCheck) Run(r *RepoInfo) []Finding {
 		p.line("        with:")
 		p.line("          go-version-file: 'go.mod'")
 		p.line("      - uses: golangci/golangci-lint-action@v6")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("  lint:")
 		p.line("    runs-on: ubuntu-latest")
 		p.line("    steps:")
@@ -1007,6 +1091,7 @@ func (c *ciNoLintJobCheck) Run(r *RepoInfo) []Finding {
 		Repo: r.Name, Check: c.ID(), Category: c.Category(),
 		Severity:   SeverityWarning,
 		Message:    "CI workflow has no lint step",
+		Location:   ".github/workflows/ci.yml",
 		Suggestion: "Add a lint job to .github/workflows/ci.yml. For Go: use golangci/golangci-lint-action. For Python: use ruff check and black --check.",
 		Prompt:     p.String(),
 	}}
@@ -1019,7 +1104,7 @@ type qualityNoCoverageCheck struct{}
 func (c *qualityNoCoverageCheck) ID() string       { return "quality-no-coverage" }
 func (c *qualityNoCoverageCheck) Category() string { return "quality" }
 func (c *qualityNoCoverageCheck) Applies(r *RepoInfo) bool {
-	return r.Language == LangGo || r.Language == LangPython || r.Language == LangMulti
+	return r.Uses(LangGo) || r.Uses(LangPython)
 }
 
 func (c *qualityNoCoverageCheck) Run(r *RepoInfo) []Finding {
@@ -1042,8 +1127,8 @@ func (c *qualityNoCoverageCheck) Run(r *RepoInfo) []Finding {
 	p := newPrompt()
 	p.line("Add test coverage tracking.")
 	p.blank()
-	switch r.Language {
-	case LangGo, LangMulti:
+	switch {
+	case r.Uses(LangGo):
 		p.line("In Makefile, update the test target:")
 		p.line("  test:")
 		p.line("  \tgo test -race -coverprofile=coverage.out ./...")
@@ -1051,7 +1136,7 @@ func (c *qualityNoCoverageCheck) Run(r *RepoInfo) []Finding {
 		p.line("Add a coverage target:")
 		p.line("  coverage:")
 		p.line("  \tgo tool cover -html=coverage.out -o coverage.html")
-	case LangPython:
+	case r.Uses(LangPython):
 		p.line("Add pytest-cov to dev dependencies and update test command:")
 		p.line("  pytest --cov=src --cov-report=html")
 	}
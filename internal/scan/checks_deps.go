@@ -0,0 +1,400 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dependency is a pinned package version read from a manifest or lockfile.
+type dependency struct {
+	ecosystem string // OSV ecosystem name
+	name      string
+	version   string
+	manifest  string // repo-relative file the version comes from
+	line      int
+	direct    bool // npm: listed in package.json rather than pulled in
+}
+
+// repoDependencies returns the pinned dependencies of the repo's Go, Python
+// and Node modules. Ranges and unpinned requirements are skipped: only an
+// exact version can be matched against advisories.
+func repoDependencies(r *RepoInfo) []dependency {
+	var deps []dependency
+	for _, m := range r.ModulesOf(LangGo) {
+		deps = append(deps, goDependencies(r, m)...)
+	}
+	for _, m := range r.ModulesOf(LangPython) {
+		deps = append(deps, pythonDependencies(r, m)...)
+	}
+	for _, m := range r.topModulesOf(LangNode) {
+		deps = append(deps, npmDependencies(r, m)...)
+	}
+	return deps
+}
+
+// goDependencies reads the require directives of go.mod, plus the modules
+// only go.sum lists (at their highest version there).
+func goDependencies(r *RepoInfo, m ModuleRoot) []dependency {
+	manifest := m.File("go.mod")
+	var deps []dependency
+	seen := make(map[string]bool)
+	inBlock := false
+	for i, line := range strings.Split(readFileString(filepath.Join(r.Path, filepath.FromSlash(manifest))), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case inBlock && len(fields) == 1 && fields[0] == ")":
+			inBlock = false
+			continue
+		case len(fields) == 2 && fields[0] == "require" && fields[1] == "(":
+			inBlock = true
+			continue
+		case len(fields) == 3 && fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock || len(fields) != 2:
+			continue
+		}
+		deps = append(deps, dependency{ecosystem: EcosystemGo, name: fields[0], version: fields[1], manifest: manifest, line: i + 1})
+		seen[fields[0]] = true
+	}
+
+	sumFile := m.File("go.sum")
+	sumOnly := make(map[string]dependency)
+	for i, line := range strings.Split(readFileString(filepath.Join(r.Path, filepath.FromSlash(sumFile))), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") || seen[fields[0]] {
+			continue
+		}
+		if prev, ok := sumOnly[fields[0]]; ok && compareVersions(prev.version, fields[1]) >= 0 {
+			continue
+		}
+		sumOnly[fields[0]] = dependency{ecosystem: EcosystemGo, name: fields[0], version: fields[1], manifest: sumFile, line: i + 1}
+	}
+	names := make([]string, 0, len(sumOnly))
+	for name := range sumOnly {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		deps = append(deps, sumOnly[name])
+	}
+	return deps
+}
+
+// pythonPin matches an exact requirement such as requests[socks]==2.31.0.
+var pythonPin = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*===?\s*([A-Za-z0-9.+!_-]+)`)
+
+// pythonDependencies reads the == pins of requirements*.txt and of the
+// dependency strings in pyproject.toml.
+func pythonDependencies(r *RepoInfo, m ModuleRoot) []dependency {
+	var deps []dependency
+	reqs, _ := filepath.Glob(filepath.Join(r.Path, filepath.FromSlash(m.File("requirements*.txt"))))
+	for _, req := range reqs {
+		manifest := m.File(filepath.Base(req))
+		for i, line := range strings.Split(readFileString(req), "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			line, _, _ = strings.Cut(line, ";")
+			if match := pythonPin.FindStringSubmatch(line); match != nil {
+				deps = append(deps, dependency{ecosystem: EcosystemPyPI, name: match[1], version: match[2], manifest: manifest, line: i + 1})
+			}
+		}
+	}
+
+	manifest := m.File("pyproject.toml")
+	for i, line := range strings.Split(readFileString(filepath.Join(r.Path, filepath.FromSlash(manifest))), "\n") {
+		for _, quoted := range pyprojectString.FindAllStringSubmatch(line, -1) {
+			if match := pythonPin.FindStringSubmatch(quoted[1]); match != nil {
+				deps = append(deps, dependency{ecosystem: EcosystemPyPI, name: match[1], version: match[2], manifest: manifest, line: i + 1})
+			}
+		}
+	}
+	return deps
+}
+
+var pyprojectString = regexp.MustCompile(`["']([^"']+)["']`)
+
+// packageLock is the subset of package-lock.json (lockfile v1 to v3) needed
+// to list installed versions.
+type packageLock struct {
+	Packages map[string]struct {
+		Version string `json:"version"`
+		Link    bool   `json:"link"`
+	} `json:"packages"`
+	Dependencies map[string]lockDependency `json:"dependencies"`
+}
+
+type lockDependency struct {
+	Version      string                    `json:"version"`
+	Dependencies map[string]lockDependency `json:"dependencies"`
+}
+
+// npmDependencies reads the installed versions from package-lock.json or
+// npm-shrinkwrap.json. Other package managers' lockfiles are not read.
+func npmDependencies(r *RepoInfo, m ModuleRoot) []dependency {
+	var manifest, content string
+	for _, name := range []string{"npm-shrinkwrap.json", "package-lock.json"} {
+		manifest = m.File(name)
+		if content = readFileString(filepath.Join(r.Path, filepath.FromSlash(manifest))); content != "" {
+			break
+		}
+	}
+	var lock packageLock
+	if content == "" || json.Unmarshal([]byte(content), &lock) != nil {
+		return nil
+	}
+	direct := make(map[string]bool)
+	if pkg, _, ok := readPackageJSON(r, m); ok {
+		for name := range pkg.Dependencies {
+			direct[name] = true
+		}
+		for name := range pkg.DevDependencies {
+			direct[name] = true
+		}
+	}
+
+	// one entry per name and version, at its shallowest install path
+	versions := make(map[[2]string]string)
+	if len(lock.Packages) > 0 {
+		for p, pkg := range lock.Packages {
+			i := strings.LastIndex(p, "node_modules/")
+			if i < 0 || pkg.Link || pkg.Version == "" {
+				continue
+			}
+			name := p[i+len("node_modules/"):]
+			if key := [2]string{name, pkg.Version}; versions[key] == "" || p < versions[key] {
+				versions[key] = p
+			}
+		}
+	} else {
+		var walk func(prefix string, deps map[string]lockDependency)
+		walk = func(prefix string, deps map[string]lockDependency) {
+			for name, d := range deps {
+				p := prefix + "node_modules/" + name
+				if key := [2]string{name, d.Version}; d.Version != "" && (versions[key] == "" || p < versions[key]) {
+					versions[key] = p
+				}
+				walk(p+"/", d.Dependencies)
+			}
+		}
+		walk("", lock.Dependencies)
+	}
+
+	var deps []dependency
+	for key, p := range versions {
+		needle := fmt.Sprintf("%q:", p)
+		if len(lock.Packages) == 0 {
+			needle = fmt.Sprintf("%q:", key[0])
+		}
+		deps = append(deps, dependency{
+			ecosystem: EcosystemNpm, name: key[0], version: key[1],
+			manifest: manifest, line: lineOf(content, needle),
+			direct: direct[key[0]] && !strings.Contains(strings.TrimPrefix(p, "node_modules/"), "node_modules/"),
+		})
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].name != deps[j].name {
+			return deps[i].name < deps[j].name
+		}
+		return compareVersions(deps[i].version, deps[j].version) < 0
+	})
+	return deps
+}
+
+// --- dependency vulnerability check ---
+
+// secVulnerableDependencyCheck matches pinned dependencies against an
+// offline OSV advisory database. It only applies once Scan has loaded one.
+type secVulnerableDependencyCheck struct {
+	db *AdvisoryDB
+}
+
+func (c *secVulnerableDependencyCheck) ID() string       { return "sec-vulnerable-dependency" }
+func (c *secVulnerableDependencyCheck) Category() string { return "security" }
+func (c *secVulnerableDependencyCheck) Applies(r *RepoInfo) bool {
+	return c.db != nil && (r.Uses(LangGo) || r.Uses(LangPython) || r.Uses(LangNode))
+}
+
+// vulnerableDependency collects the advisories of one package in one
+// manifest; npm lockfiles may hold several versions of it.
+type vulnerableDependency struct {
+	dep        dependency
+	versions   []string
+	advisories []AdvisoryMatch
+}
+
+func (c *secVulnerableDependencyCheck) Run(r *RepoInfo) []Finding {
+	var vulns []*vulnerableDependency
+	byPackage := make(map[[2]string]*vulnerableDependency)
+	for _, d := range repoDependencies(r) {
+		matches := c.db.Match(d.ecosystem, d.name, d.version)
+		if len(matches) == 0 {
+			continue
+		}
+		key := [2]string{d.manifest, d.name}
+		v := byPackage[key]
+		if v == nil {
+			v = &vulnerableDependency{dep: d}
+			byPackage[key] = v
+			vulns = append(vulns, v)
+		}
+		v.dep.direct = v.dep.direct || d.direct
+		v.versions = append(v.versions, d.version)
+		for _, m := range matches {
+			if !containsAdvisory(v.advisories, m.ID) {
+				v.advisories = append(v.advisories, m)
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, v := range vulns {
+		findings = append(findings, c.finding(r, v))
+	}
+	return findings
+}
+
+func containsAdvisory(matches []AdvisoryMatch, id string) bool {
+	for _, m := range matches {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *secVulnerableDependencyCheck) finding(r *RepoInfo, v *vulnerableDependency) Finding {
+	d := v.dep
+	sort.Slice(v.advisories, func(i, j int) bool { return v.advisories[i].ID < v.advisories[j].ID })
+
+	// the bump must clear every advisory: take the highest fixed version,
+	// and note the advisories nothing fixes yet
+	var fixed string
+	var ids, unfixed []string
+	severity := SeverityWarning
+	for _, a := range v.advisories {
+		ids = append(ids, a.ID)
+		if a.Fixed == "" {
+			unfixed = append(unfixed, a.ID)
+		} else if fixed == "" || compareVersions(a.Fixed, fixed) > 0 {
+			fixed = a.Fixed
+		}
+		if a.Severity == "HIGH" || a.Severity == "CRITICAL" {
+			severity = SeverityCritical
+		}
+	}
+	if fixed != "" && d.ecosystem == EcosystemGo && !strings.HasPrefix(fixed, "v") {
+		fixed = "v" + fixed
+	}
+	version := strings.Join(v.versions, ", ")
+
+	noun := "vulnerability"
+	if len(ids) > 1 {
+		noun = "vulnerabilities"
+	}
+	msg := fmt.Sprintf("%s %s has %d known %s (%s)", d.name, version, len(ids), noun, strings.Join(ids, ", "))
+	var sug string
+	if fixed != "" {
+		msg += "; fixed in " + fixed
+		sug = fmt.Sprintf("Upgrade %s to %s or later in %s.", d.name, fixed, d.manifest)
+	} else {
+		msg += "; no fixed version"
+		sug = fmt.Sprintf("No release of %s fixes this yet: replace it, or confirm the vulnerable code is unreachable.", d.name)
+	}
+
+	p := newPrompt()
+	p.line(fmt.Sprintf("Upgrade the vulnerable dependency %s %s (%s).", d.name, version, d.manifest))
+	p.blank()
+	p.line("Advisories:")
+	for _, a := range v.advisories {
+		line := "- " + a.ID
+		if len(a.Aliases) > 0 {
+			line += " (" + strings.Join(a.Aliases, ", ") + ")"
+		}
+		if a.Summary != "" {
+			line += ": " + a.Summary
+		}
+		if a.Fixed != "" {
+			line += " — fixed in " + a.Fixed
+		}
+		p.line(line)
+	}
+	if fixed != "" {
+		p.blank()
+		p.line(fmt.Sprintf("Bump %s to exactly %s:", d.name, fixed))
+		for _, cmd := range bumpCommands(r, d, fixed) {
+			p.line("  " + cmd)
+		}
+	}
+	if len(unfixed) > 0 {
+		p.blank()
+		p.line(fmt.Sprintf("No fixed version exists for %s. Do not invent one; report it instead of changing the pin.", strings.Join(unfixed, ", ")))
+	}
+	p.line("Do not bump other dependencies. If the upgrade breaks the build, fix the call sites it breaks.")
+	p.constraints()
+	p.blank()
+	p.verification(r)
+
+	return Finding{
+		Repo: r.Name, Check: c.ID(), Category: c.Category(),
+		Severity:   severity,
+		Message:    msg,
+		Location:   d.manifest,
+		Line:       d.line,
+		Suggestion: sug,
+		Prompt:     p.String(),
+		Dependency: &Dependency{
+			Ecosystem:    d.ecosystem,
+			Name:         d.name,
+			Version:      version,
+			FixedVersion: fixed,
+			Advisories:   ids,
+		},
+	}
+}
+
+// bumpCommands returns the steps that pin d at version fixed.
+func bumpCommands(r *RepoInfo, d dependency, fixed string) []string {
+	dir := path.Dir(d.manifest)
+	cd := ""
+	if dir != "." {
+		cd = "cd " + dir + " && "
+	}
+	switch d.ecosystem {
+	case EcosystemGo:
+		return []string{cd + fmt.Sprintf("go get %s@%s && go mod tidy", d.name, fixed)}
+	case EcosystemPyPI:
+		return []string{
+			fmt.Sprintf("In %s change the pin to %s==%s", d.manifest, d.name, fixed),
+			"then reinstall and rerun the tests in a clean virtualenv",
+		}
+	default:
+		if !d.direct {
+			return []string{
+				fmt.Sprintf("%s is a transitive dependency: upgrade the package that pulls it in,", d.name),
+				fmt.Sprintf("or add \"overrides\": {%q: %q} to package.json, then run %snpm install", d.name, fixed, cd),
+			}
+		}
+		switch r.BuildTool {
+		case "pnpm":
+			return []string{cd + fmt.Sprintf("pnpm add %s@%s", d.name, fixed)}
+		case "yarn":
+			return []string{cd + fmt.Sprintf("yarn add %s@%s", d.name, fixed)}
+		default:
+			return []string{cd + fmt.Sprintf("npm install %s@%s", d.name, fixed)}
+		}
+	}
+}
+
+// setAdvisories gives the dependency check the loaded advisory database.
+func setAdvisories(checkers []Checker, db *AdvisoryDB) {
+	for _, c := range checkers {
+		if v, ok := c.(*secVulnerableDependencyCheck); ok {
+			v.db = db
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGoDependencies(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "app", map[string]string{
+		"go.mod": "module m\n\ngo 1.22\n\nrequire github.com/a/single v1.0.0\n\nrequire (\n\tgolang.org/x/net v0.10.0 // indirect\n\tgolang.org/x/text v0.9.0\n)\n\nreplace golang.org/x/text => ../text\n",
+		"go.sum": "golang.org/x/net v0.10.0 h1:a=\ngolang.org/x/net v0.10.0/go.mod h1:b=\ngithub.com/old/dep v1.0.0 h1:c=\ngithub.com/old/dep v1.2.0 h1:d=\ngithub.com/old/dep v1.3.0/go.mod h1:e=\n",
+	}))
+	var got []string
+	for _, d := range goDependencies(repo, repo.ModulesOf(LangGo)[0]) {
+		got = append(got, fmt.Sprintf("%s@%s %s:%d", d.name, d.version, d.manifest, d.line))
+	}
+	want := "github.com/a/single@v1.0.0 go.mod:5,golang.org/x/net@v0.10.0 go.mod:8,golang.org/x/text@v0.9.0 go.mod:9,github.com/old/dep@v1.2.0 go.sum:4"
+	if strings.Join(got, ",") != want {
+		t.Errorf("deps = %v\nwant %s", got, want)
+	}
+}
+
+func TestPythonDependencies(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "py", map[string]string{
+		"requirements.txt":     "# pinned\nrequests[socks]==2.31.0 ; python_version >= '3.8'\nflask>=2.0\n-r requirements-dev.txt\n",
+		"requirements-dev.txt": "pytest==7.4.0  # tests\n",
+		"pyproject.toml":       "[project]\nname = \"app\"\ndependencies = [\n  \"Django==4.2.1\",\n  \"httpx>=0.24\",\n]\n",
+	}))
+	var got []string
+	for _, m := range repo.ModulesOf(LangPython) {
+		for _, d := range pythonDependencies(repo, m) {
+			got = append(got, fmt.Sprintf("%s@%s %s:%d", d.name, d.version, d.manifest, d.line))
+		}
+	}
+	want := "pytest@7.4.0 requirements-dev.txt:1,requests@2.31.0 requirements.txt:2,Django@4.2.1 pyproject.toml:4"
+	if strings.Join(got, ",") != want {
+		t.Errorf("deps = %v\nwant %s", got, want)
+	}
+}
+
+func TestNpmDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		lock string
+		want string
+	}{
+		{"lockfile v3", `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "web"},
+    "node_modules/lodash": {"version": "4.17.20"},
+    "node_modules/@scope/pkg": {"version": "1.0.0"},
+    "node_modules/@scope/pkg/node_modules/lodash": {"version": "3.10.1"},
+    "node_modules/local": {"resolved": "../local", "link": true}
+  }
+}`, "@scope/pkg@1.0.0:6 false,lodash@3.10.1:7 false,lodash@4.17.20:5 true"},
+		{"lockfile v1", `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "lodash": {"version": "4.17.20"},
+    "@scope/pkg": {"version": "1.0.0", "dependencies": {"minimist": {"version": "0.0.8"}}}
+  }
+}`, "@scope/pkg@1.0.0:5 false,lodash@4.17.20:4 true,minimist@0.0.8:5 false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "web", map[string]string{
+				"package.json":      `{"name":"web","dependencies":{"lodash":"^4.17.0"}}`,
+				"package-lock.json": tt.lock,
+			}))
+			var got []string
+			for _, d := range npmDependencies(repo, repo.ModulesOf(LangNode)[0]) {
+				got = append(got, fmt.Sprintf("%s@%s:%d %v", d.name, d.version, d.line, d.direct))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("deps = %v\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecVulnerableDependency(t *testing.T) {
+	dir := makeAdvisoryDir(t, map[string]string{
+		"GO-1.json":    osvJSON("GO-1", "Go", "golang.org/x/net", "0", "0.13.0"),
+		"GO-2.json":    osvJSON("GO-2", "Go", "golang.org/x/net", "0", "0.17.0"),
+		"GHSA-1.json":  strings.Replace(osvJSON("GHSA-1", "npm", "lodash", "4.0.0", "4.17.21"), `"affected"`, `"database_specific":{"severity":"HIGH"},"affected"`, 1),
+		"PYSEC-1.json": osvJSON("PYSEC-1", "PyPI", "requests", "2.0.0", ""),
+	})
+	db, err := LoadAdvisories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := &secVulnerableDependencyCheck{db: db}
+
+	repo := DetectRepo(makeRepo(t, "mono", map[string]string{
+		"go.mod":                "module m\n\nrequire golang.org/x/net v0.10.0\n",
+		"web/package.json":      `{"dependencies":{"lodash":"^4.17.0"}}`,
+		"web/package-lock.json": `{"packages":{"node_modules/lodash":{"version":"4.17.20"}}}`,
+		"tools/pyproject.toml":  "[project]\ndependencies = [\"requests==2.31.0\"]\n",
+	}))
+	if !check.Applies(repo) {
+		t.Fatal("check should apply with an advisory database")
+	}
+	if (&secVulnerableDependencyCheck{}).Applies(repo) {
+		t.Error("check should not apply without an advisory database")
+	}
+
+	findings := check.Run(repo)
+	if len(findings) != 3 {
+		t.Fatalf("got %d findings, want 3: %+v", len(findings), findings)
+	}
+
+	goFinding := findings[0]
+	if goFinding.Location != "go.mod" || goFinding.Line != 3 || goFinding.Severity != SeverityWarning {
+		t.Errorf("go finding = %+v", goFinding)
+	}
+	if d := goFinding.Dependency; d == nil || d.FixedVersion != "v0.17.0" || strings.Join(d.Advisories, ",") != "GO-1,GO-2" {
+		t.Errorf("go dependency = %+v, want fixed v0.17.0 for both advisories", d)
+	}
+	if !strings.Contains(goFinding.Message, "2 known vulnerabilities") {
+		t.Errorf("message = %q", goFinding.Message)
+	}
+	if !strings.Contains(goFinding.Prompt, "go get golang.org/x/net@v0.17.0 && go mod tidy") {
+		t.Errorf("prompt missing the bump command:\n%s", goFinding.Prompt)
+	}
+
+	py := findings[1]
+	if py.Location != "tools/pyproject.toml" || py.Dependency.FixedVersion != "" || !strings.Contains(py.Message, "no fixed version") {
+		t.Errorf("python finding = %+v", py)
+	}
+
+	npm := findings[2]
+	if npm.Location != "web/package-lock.json" || npm.Line != 1 || npm.Severity != SeverityCritical {
+		t.Errorf("npm finding = %+v", npm)
+	}
+	if !strings.Contains(npm.Prompt, "cd web && npm install lodash@4.17.21") {
+		t.Errorf("prompt missing the bump command:\n%s", npm.Prompt)
+	}
+}
+
+func TestScan_AdvisoryDir(t *testing.T) {
+	base := makeScanDir(t, map[string]map[string]string{
+		"app": {"go.mod": "module m\n\nrequire golang.org/x/net v0.10.0\n"},
+	})
+	dir := makeAdvisoryDir(t, map[string]string{
+		"GO-1.json": osvJSON("GO-1", "Go", "golang.org/x/net", "0", "0.17.0"),
+	})
+
+	hasVuln := func(r *ScanResult) bool {
+		for _, f := range r.Findings {
+			if f.Check == "sec-vulnerable-dependency" {
+				return true
+			}
+		}
+		return false
+	}
+	result, err := Scan(ScanOptions{ReposDir: base, Categories: []string{"security"}, AdvisoryDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasVuln(result) {
+		t.Error("expected a vulnerable dependency finding")
+	}
+
+	// a missing advisory dir turns the check off
+	result, err = Scan(ScanOptions{ReposDir: base, Categories: []string{"security"}, AdvisoryDir: dir + "-missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasVuln(result) {
+		t.Error("dependency check ran without advisories")
+	}
+}
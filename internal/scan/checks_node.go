@@ -0,0 +1,254 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// packageJSON is the subset of package.json the Node checks read.
+type packageJSON struct {
+	Name            string            `json:"name"`
+	Private         bool              `json:"private"`
+	Scripts         map[string]string `json:"scripts"`
+	Engines         map[string]string `json:"engines"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// readPackageJSON parses a module's package.json and returns it with its
+// raw content for line lookups.
+func readPackageJSON(r *RepoInfo, m ModuleRoot) (*packageJSON, string, bool) {
+	data, err := os.ReadFile(filepath.Join(r.Path, filepath.FromSlash(m.File("package.json"))))
+	if err != nil {
+		return nil, "", false
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, "", false
+	}
+	return &pkg, string(data), true
+}
+
+// testFramework returns the test framework the package depends on or runs,
+// or "" if none.
+func (p *packageJSON) testFramework() string {
+	for _, fw := range []string{"vitest", "jest", "mocha"} {
+		if _, ok := p.DevDependencies[fw]; ok {
+			return fw
+		}
+		if _, ok := p.Dependencies[fw]; ok {
+			return fw
+		}
+	}
+	if strings.Contains(p.Scripts["test"], "node --test") {
+		return "node:test"
+	}
+	return ""
+}
+
+// nodeLockfiles are the lockfiles of npm, yarn, pnpm and bun.
+var nodeLockfiles = []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock", "bun.lockb"}
+
+// nodeRun returns the command that runs a package script with the repo's
+// package manager.
+func nodeRun(r *RepoInfo, script string) string {
+	switch r.BuildTool {
+	case "pnpm", "yarn":
+		return r.BuildTool + " " + script
+	default:
+		if script == "test" {
+			return "npm test"
+		}
+		return "npm run " + script
+	}
+}
+
+// --- Node checks ---
+
+type nodeNoTestsCheck struct{}
+
+func (c *nodeNoTestsCheck) ID() string       { return "node-no-tests" }
+func (c *nodeNoTestsCheck) Category() string { return "node" }
+func (c *nodeNoTestsCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangNode)
+}
+
+func (c *nodeNoTestsCheck) Run(r *RepoInfo) []Finding {
+	var findings []Finding
+	for _, m := range r.ModulesOf(LangNode) {
+		pkg, content, ok := readPackageJSON(r, m)
+		if !ok {
+			continue
+		}
+		script := pkg.Scripts["test"]
+		if script != "" && !strings.Contains(script, "no test specified") {
+			continue
+		}
+		manifest := m.File("package.json")
+		framework := orDefault(pkg.testFramework(), "vitest")
+
+		p := newPrompt()
+		p.line(fmt.Sprintf("Add tests for the Node package %s (%s).", orDefault(pkg.Name, r.Name), manifest))
+		p.blank()
+		p.line(fmt.Sprintf("Use %s. Add it as a devDependency if missing and set the \"test\" script in %s.", framework, manifest))
+		p.line("Place tests next to the source as <name>.test.ts (or .js), one per module.")
+		p.blank()
+		p.line("Testing conventions:")
+		p.line("- Cover exported functions and request handlers")
+		p.line("- Tests must be deterministic — no network or clock dependencies")
+		p.line("- Target > 85% coverage")
+		p.constraints()
+		p.blank()
+		p.verification(r)
+
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityCritical,
+			Message:    fmt.Sprintf("%s has no test script", manifest),
+			Location:   manifest,
+			Line:       lineOf(content, `"scripts"`),
+			Suggestion: fmt.Sprintf("Add tests with %s and a \"test\" script in %s.", framework, manifest),
+			Prompt:     p.String(),
+		})
+	}
+	return findings
+}
+
+type nodeNoLockfileCheck struct{}
+
+func (c *nodeNoLockfileCheck) ID() string       { return "node-no-lockfile" }
+func (c *nodeNoLockfileCheck) Category() string { return "node" }
+func (c *nodeNoLockfileCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangNode)
+}
+
+func (c *nodeNoLockfileCheck) Run(r *RepoInfo) []Finding {
+	var findings []Finding
+	// workspace members share the lockfile of the workspace root, which may
+	// also sit at the repo root without a package.json of its own
+	for _, m := range r.topModulesOf(LangNode) {
+		dir := filepath.Join(r.Path, filepath.FromSlash(m.Dir))
+		if anyFileExists(dir, nodeLockfiles) || anyFileExists(r.Path, nodeLockfiles) {
+			continue
+		}
+		manifest := m.File("package.json")
+
+		p := newPrompt()
+		p.line(fmt.Sprintf("Commit a lockfile for %s.", manifest))
+		p.blank()
+		p.line(fmt.Sprintf("Run `%s install` in %s and commit the generated lockfile.", orDefault(r.BuildTool, "npm"), m.Dir))
+		p.line("Do not add the lockfile to .gitignore. CI should install with `npm ci` (or the frozen-lockfile equivalent).")
+		p.constraints()
+
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s has no lockfile", manifest),
+			Location:   manifest,
+			Suggestion: "Commit package-lock.json (or yarn.lock / pnpm-lock.yaml) so installs are reproducible, and install with npm ci in CI.",
+			Prompt:     p.String(),
+		})
+	}
+	return findings
+}
+
+type nodeNoLintCheck struct{}
+
+func (c *nodeNoLintCheck) ID() string       { return "node-no-lint" }
+func (c *nodeNoLintCheck) Category() string { return "node" }
+func (c *nodeNoLintCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangNode)
+}
+
+// nodeLintConfigs are ESLint and Biome config files.
+var nodeLintConfigs = []string{
+	"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+	".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml",
+	"biome.json", "biome.jsonc",
+}
+
+func (c *nodeNoLintCheck) Run(r *RepoInfo) []Finding {
+	if anyFileExists(r.Path, nodeLintConfigs) {
+		return nil
+	}
+	var findings []Finding
+	for _, m := range r.topModulesOf(LangNode) {
+		pkg, _, ok := readPackageJSON(r, m)
+		if !ok || pkg.Scripts["lint"] != "" || anyFileExists(filepath.Join(r.Path, filepath.FromSlash(m.Dir)), nodeLintConfigs) {
+			continue
+		}
+		manifest := m.File("package.json")
+
+		p := newPrompt()
+		p.line(fmt.Sprintf("Add linting to the Node package in %s.", m.Dir))
+		p.blank()
+		p.line("Add ESLint with a flat config (eslint.config.js) using the recommended rules,")
+		p.line("plus typescript-eslint if the package uses TypeScript.")
+		p.line(fmt.Sprintf("Add a \"lint\" script to %s: \"eslint .\"", manifest))
+		p.line("Fix or explicitly disable any existing violations so lint passes.")
+		p.constraints()
+		p.blank()
+		p.verification(r)
+
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s has no lint script or ESLint/Biome config", manifest),
+			Location:   manifest,
+			Suggestion: fmt.Sprintf("Add ESLint (eslint.config.js) or Biome and a \"lint\" script; run it in CI with %s.", nodeRun(r, "lint")),
+			Prompt:     p.String(),
+		})
+	}
+	return findings
+}
+
+type nodeNoEnginesCheck struct{}
+
+func (c *nodeNoEnginesCheck) ID() string       { return "node-no-engines" }
+func (c *nodeNoEnginesCheck) Category() string { return "node" }
+func (c *nodeNoEnginesCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangNode)
+}
+
+func (c *nodeNoEnginesCheck) Run(r *RepoInfo) []Finding {
+	var findings []Finding
+	for _, m := range r.topModulesOf(LangNode) {
+		pkg, _, ok := readPackageJSON(r, m)
+		if !ok || pkg.Engines["node"] != "" {
+			continue
+		}
+		dir := filepath.Join(r.Path, filepath.FromSlash(m.Dir))
+		if anyFileExists(dir, []string{".nvmrc", ".node-version"}) {
+			continue
+		}
+		manifest := m.File("package.json")
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityInfo,
+			Message:    fmt.Sprintf("%s does not pin a Node version", manifest),
+			Location:   manifest,
+			Suggestion: fmt.Sprintf("Add \"engines\": {\"node\": \">=20\"} to %s (or an .nvmrc) so local, CI and production use the same Node major.", manifest),
+		})
+	}
+	return findings
+}
+
+// anyFileExists reports whether any of names exists in dir.
+func anyFileExists(dir string, names []string) bool {
+	for _, name := range names {
+		if fileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
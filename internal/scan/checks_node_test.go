@@ -0,0 +1,141 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNodeNoTests(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"has test script", map[string]string{
+			"package.json": `{"scripts":{"test":"vitest run"}}`,
+		}, nil},
+		{"npm init placeholder", map[string]string{
+			"package.json": "{\n  \"scripts\": {\n    \"test\": \"echo \\\"Error: no test specified\\\" && exit 1\"\n  }\n}\n",
+		}, []string{"package.json"}},
+		{"workspace member", map[string]string{
+			"package.json":             `{"private":true,"scripts":{"test":"pnpm -r test"}}`,
+			"packages/ui/package.json": `{"name":"ui"}`,
+		}, []string{"packages/ui/package.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "node", tt.files))
+			findings := (&nodeNoTestsCheck{}).Run(repo)
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Location)
+				if f.Severity != SeverityCritical {
+					t.Errorf("Severity = %v, want critical", f.Severity)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("locations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeNoTests_PromptUsesDependency(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "jesty", map[string]string{
+		"package.json": `{"name":"jesty","devDependencies":{"jest":"^29.0.0"}}`,
+	}))
+	findings := (&nodeNoTestsCheck{}).Run(repo)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if !strings.Contains(findings[0].Prompt, "Use jest.") {
+		t.Errorf("prompt should use jest:\n%s", findings[0].Prompt)
+	}
+	if !strings.Contains(findings[0].Prompt, "npm test") {
+		t.Errorf("prompt should verify with npm test:\n%s", findings[0].Prompt)
+	}
+}
+
+func TestNodeNoLockfile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"missing", map[string]string{"package.json": `{}`}, 1},
+		{"npm", map[string]string{"package.json": `{}`, "package-lock.json": `{}`}, 0},
+		{"yarn", map[string]string{"package.json": `{}`, "yarn.lock": ""}, 0},
+		{"workspace root lock", map[string]string{
+			"pnpm-lock.yaml":        "",
+			"apps/web/package.json": `{}`,
+		}, 0},
+		{"members share root", map[string]string{
+			"package.json":          `{}`,
+			"apps/web/package.json": `{}`,
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "node", tt.files))
+			if got := len((&nodeNoLockfileCheck{}).Run(repo)); got != tt.want {
+				t.Errorf("findings = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeNoLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"missing", map[string]string{"package.json": `{}`}, 1},
+		{"lint script", map[string]string{"package.json": `{"scripts":{"lint":"eslint ."}}`}, 0},
+		{"flat config", map[string]string{"package.json": `{}`, "eslint.config.js": ""}, 0},
+		{"root biome", map[string]string{"biome.json": "{}", "web/package.json": `{}`}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "node", tt.files))
+			if got := len((&nodeNoLintCheck{}).Run(repo)); got != tt.want {
+				t.Errorf("findings = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeNoEngines(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"missing", map[string]string{"package.json": `{}`}, 1},
+		{"engines", map[string]string{"package.json": `{"engines":{"node":">=20"}}`}, 0},
+		{"nvmrc", map[string]string{"package.json": `{}`, ".nvmrc": "20\n"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "node", tt.files))
+			findings := (&nodeNoEnginesCheck{}).Run(repo)
+			if len(findings) != tt.want {
+				t.Fatalf("findings = %d, want %d", len(findings), tt.want)
+			}
+			if tt.want > 0 && findings[0].Severity != SeverityInfo {
+				t.Errorf("Severity = %v, want info", findings[0].Severity)
+			}
+		})
+	}
+}
+
+func TestNodeChecks_SkipGoRepo(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "gorepo", map[string]string{
+		"go.mod": "module m\n\ngo 1.24\n",
+	}))
+	for _, c := range []Checker{&nodeNoTestsCheck{}, &nodeNoLockfileCheck{}, &nodeNoLintCheck{}, &nodeNoEnginesCheck{}} {
+		if c.Applies(repo) {
+			t.Errorf("%s applies to a Go repo", c.ID())
+		}
+	}
+}
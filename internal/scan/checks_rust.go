@@ -0,0 +1,177 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rustCIFiles are the files searched for a clippy invocation, besides the
+// GitHub workflows.
+var rustCIFiles = []string{"Makefile", "justfile", "Justfile", ".gitlab-ci.yml", ".circleci/config.yml"}
+
+// isRustPackage reports whether a Cargo.toml declares a package rather than
+// only a workspace.
+func isRustPackage(r *RepoInfo, m ModuleRoot) bool {
+	data, err := os.ReadFile(filepath.Join(r.Path, filepath.FromSlash(m.File("Cargo.toml"))))
+	return err == nil && strings.Contains(string(data), "[package]")
+}
+
+// --- Rust checks ---
+
+type rustNoTestsCheck struct{}
+
+func (c *rustNoTestsCheck) ID() string       { return "rust-no-tests" }
+func (c *rustNoTestsCheck) Category() string { return "rust" }
+func (c *rustNoTestsCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangRust)
+}
+
+func (c *rustNoTestsCheck) Run(r *RepoInfo) []Finding {
+	var findings []Finding
+	for _, m := range r.ModulesOf(LangRust) {
+		if !isRustPackage(r, m) || c.hasTests(r, m) {
+			continue
+		}
+		manifest := m.File("Cargo.toml")
+
+		p := newPrompt()
+		p.line(fmt.Sprintf("Add tests for the Rust crate %s (%s).", orDefault(m.Name, r.Name), manifest))
+		p.blank()
+		p.line("Add unit tests in a #[cfg(test)] mod tests block at the bottom of each source file,")
+		p.line("and integration tests for the public API under tests/.")
+		p.blank()
+		p.line("Testing conventions:")
+		p.line("- One #[test] per behavior, named after what it asserts")
+		p.line("- Tests must be deterministic — no network or clock dependencies")
+		p.line("- Return Result from tests instead of unwrap() where errors are expected")
+		p.constraints()
+		p.blank()
+		p.verification(r)
+
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityCritical,
+			Message:    fmt.Sprintf("No Rust tests found in crate %s", orDefault(m.Name, m.Dir)),
+			Location:   manifest,
+			Line:       lineOf(readFileString(filepath.Join(r.Path, filepath.FromSlash(manifest))), "[package]"),
+			Suggestion: "Add #[cfg(test)] unit tests next to the code and integration tests under tests/. Run them in CI with cargo test.",
+			Prompt:     p.String(),
+		})
+	}
+	return findings
+}
+
+// hasTests reports whether the crate has a tests/ dir or any #[test] in its
+// sources, skipping target/ and nested crates.
+func (c *rustNoTestsCheck) hasTests(r *RepoInfo, m ModuleRoot) bool {
+	if isDir(filepath.Join(r.Path, filepath.FromSlash(m.File("tests")))) {
+		return true
+	}
+	for _, f := range r.moduleFiles(m, "target") {
+		if !strings.HasSuffix(f, ".rs") {
+			continue
+		}
+		src := readFileString(filepath.Join(r.Path, filepath.FromSlash(f)))
+		if strings.Contains(src, "#[test]") || strings.Contains(src, "#[cfg(test)]") {
+			return true
+		}
+	}
+	return false
+}
+
+type rustNoLockfileCheck struct{}
+
+func (c *rustNoLockfileCheck) ID() string       { return "rust-no-lockfile" }
+func (c *rustNoLockfileCheck) Category() string { return "rust" }
+func (c *rustNoLockfileCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangRust)
+}
+
+func (c *rustNoLockfileCheck) Run(r *RepoInfo) []Finding {
+	var findings []Finding
+	// workspace members share the workspace root's Cargo.lock
+	for _, m := range r.topModulesOf(LangRust) {
+		if fileExists(filepath.Join(r.Path, filepath.FromSlash(m.File("Cargo.lock")))) {
+			continue
+		}
+		manifest := m.File("Cargo.toml")
+		findings = append(findings, Finding{
+			Repo: r.Name, Check: c.ID(), Category: c.Category(),
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s has no Cargo.lock", manifest),
+			Location:   manifest,
+			Suggestion: fmt.Sprintf("Run cargo generate-lockfile in %s and commit Cargo.lock so builds are reproducible; build in CI with --locked.", m.Dir),
+		})
+	}
+	return findings
+}
+
+type rustNoClippyCheck struct{}
+
+func (c *rustNoClippyCheck) ID() string       { return "rust-no-clippy" }
+func (c *rustNoClippyCheck) Category() string { return "rust" }
+func (c *rustNoClippyCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangRust)
+}
+
+func (c *rustNoClippyCheck) Run(r *RepoInfo) []Finding {
+	if fileContains(r.Path, rustCIFiles, "clippy") {
+		return nil
+	}
+	workflows, _ := filepath.Glob(filepath.Join(r.Path, ".github", "workflows", "*.y*ml"))
+	for _, wf := range workflows {
+		if strings.Contains(readFileString(wf), "clippy") {
+			return nil
+		}
+	}
+
+	p := newPrompt()
+	p.line(fmt.Sprintf("Run clippy for %s locally and in CI.", r.Name))
+	p.blank()
+	p.line("Add a lint target to the Makefile (or justfile):")
+	p.line("  cargo clippy --all-targets --all-features -- -D warnings")
+	p.line("and run the same command in a CI lint job.")
+	p.line("Fix any existing warnings, or allow them explicitly with a justification comment.")
+	p.constraints()
+	p.blank()
+	p.verification(r)
+
+	return []Finding{{
+		Repo: r.Name, Check: c.ID(), Category: c.Category(),
+		Severity:   SeverityWarning,
+		Message:    "clippy is not run by the Makefile or CI",
+		Suggestion: "Run cargo clippy --all-targets -- -D warnings in a Makefile lint target and in CI.",
+		Prompt:     p.String(),
+	}}
+}
+
+type rustNoToolchainCheck struct{}
+
+func (c *rustNoToolchainCheck) ID() string       { return "rust-no-toolchain" }
+func (c *rustNoToolchainCheck) Category() string { return "rust" }
+func (c *rustNoToolchainCheck) Applies(r *RepoInfo) bool {
+	return r.Uses(LangRust)
+}
+
+func (c *rustNoToolchainCheck) Run(r *RepoInfo) []Finding {
+	if anyFileExists(r.Path, []string{"rust-toolchain.toml", "rust-toolchain"}) {
+		return nil
+	}
+	return []Finding{{
+		Repo: r.Name, Check: c.ID(), Category: c.Category(),
+		Severity:   SeverityInfo,
+		Message:    "No rust-toolchain.toml found",
+		Suggestion: "Add rust-toolchain.toml pinning the channel and the clippy and rustfmt components so local and CI builds use the same compiler.",
+	}}
+}
+
+// readFileString returns the content of path, or "" if it cannot be read.
+func readFileString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,128 @@
+package scan
+
+import (
+	"testing"
+)
+
+func TestRustNoTests(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"no tests", map[string]string{
+			"Cargo.toml":  "[package]\nname = \"crab\"\n",
+			"src/main.rs": "fn main() {}\n",
+		}, 1},
+		{"unit tests", map[string]string{
+			"Cargo.toml": "[package]\nname = \"crab\"\n",
+			"src/lib.rs": "pub fn f() {}\n\n#[cfg(test)]\nmod tests {}\n",
+		}, 0},
+		{"integration tests", map[string]string{
+			"Cargo.toml":   "[package]\nname = \"crab\"\n",
+			"tests/api.rs": "",
+			"src/lib.rs":   "pub fn f() {}\n",
+		}, 0},
+		{"target ignored", map[string]string{
+			"Cargo.toml":          "[package]\nname = \"crab\"\n",
+			"src/lib.rs":          "pub fn f() {}\n",
+			"target/debug/gen.rs": "#[test]\nfn t() {}\n",
+		}, 1},
+		{"virtual workspace", map[string]string{
+			"Cargo.toml":      "[workspace]\nmembers = [\"core\"]\n",
+			"core/Cargo.toml": "[package]\nname = \"core\"\n",
+			"core/src/lib.rs": "pub fn f() {}\n",
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "crab", tt.files))
+			findings := (&rustNoTestsCheck{}).Run(repo)
+			if len(findings) != tt.want {
+				t.Fatalf("findings = %d, want %d", len(findings), tt.want)
+			}
+		})
+	}
+}
+
+func TestRustNoTests_Location(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "ws", map[string]string{
+		"Cargo.toml":      "[workspace]\nmembers = [\"core\"]\n",
+		"core/Cargo.toml": "[package]\nname = \"core\"\n",
+	}))
+	findings := (&rustNoTestsCheck{}).Run(repo)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Location != "core/Cargo.toml" || f.Line != 1 {
+		t.Errorf("location = %s:%d, want core/Cargo.toml:1", f.Location, f.Line)
+	}
+	if f.Message != "No Rust tests found in crate core" {
+		t.Errorf("Message = %q", f.Message)
+	}
+}
+
+func TestRustNoLockfile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"missing", map[string]string{"Cargo.toml": "[package]\n"}, 1},
+		{"present", map[string]string{"Cargo.toml": "[package]\n", "Cargo.lock": ""}, 0},
+		{"workspace member", map[string]string{
+			"Cargo.toml":      "[workspace]\n",
+			"Cargo.lock":      "",
+			"core/Cargo.toml": "[package]\n",
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "crab", tt.files))
+			if got := len((&rustNoLockfileCheck{}).Run(repo)); got != tt.want {
+				t.Errorf("findings = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRustNoClippy(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{"missing", map[string]string{"Cargo.toml": "[package]\n"}, 1},
+		{"makefile", map[string]string{
+			"Cargo.toml": "[package]\n",
+			"Makefile":   "lint:\n\tcargo clippy -- -D warnings\n",
+		}, 0},
+		{"workflow", map[string]string{
+			"Cargo.toml":                  "[package]\n",
+			".github/workflows/lint.yaml": "run: cargo clippy\n",
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := DetectRepo(makeRepo(t, "crab", tt.files))
+			if got := len((&rustNoClippyCheck{}).Run(repo)); got != tt.want {
+				t.Errorf("findings = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRustNoToolchain(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "crab", map[string]string{"Cargo.toml": "[package]\n"}))
+	if got := len((&rustNoToolchainCheck{}).Run(repo)); got != 1 {
+		t.Errorf("findings = %d, want 1", got)
+	}
+	repo = DetectRepo(makeRepo(t, "crab", map[string]string{
+		"Cargo.toml":          "[package]\n",
+		"rust-toolchain.toml": "[toolchain]\nchannel = \"stable\"\n",
+	}))
+	if got := len((&rustNoToolchainCheck{}).Run(repo)); got != 0 {
+		t.Errorf("findings = %d, want 0", got)
+	}
+}
@@ -126,6 +126,12 @@ func TestDetectRepo_HasCmd(t *testing.T) {
 	if !info.HasCmd {
 		t.Error("HasCmd = false, want true")
 	}
+	if info.Module != "example.com/cmdrepo" {
+		t.Errorf("Module = %q, want example.com/cmdrepo", info.Module)
+	}
+	if len(info.Binaries) != 1 || info.Binaries[0] != "main" {
+		t.Errorf("Binaries = %v, want [main]", info.Binaries)
+	}
 }
 
 func TestDetectRepo_HasDocs(t *testing.T) {
@@ -291,6 +297,9 @@ func TestGoNoTests_NoTests(t *testing.T) {
 	if findings[0].Severity != SeverityCritical {
 		t.Errorf("Severity = %v, want critical", findings[0].Severity)
 	}
+	if findings[0].Location != "go.mod" || findings[0].Line != 1 {
+		t.Errorf("location = %s:%d, want go.mod:1", findings[0].Location, findings[0].Line)
+	}
 }
 
 func TestGoNoRace_MissingFlag(t *testing.T) {
@@ -453,6 +462,9 @@ func TestSecHardcodedToken_Found(t *testing.T) {
 	if findings[0].Severity != SeverityCritical {
 		t.Errorf("Severity = %v, want critical", findings[0].Severity)
 	}
+	if findings[0].Location != "main.go" || findings[0].Line != 2 {
+		t.Errorf("location = %s:%d, want main.go:2", findings[0].Location, findings[0].Line)
+	}
 }
 
 func TestSecHardcodedToken_FalsePositiveFiltered(t *testing.T) {
@@ -497,6 +509,22 @@ func TestSecHardcodedToken_SkipsVendor(t *testing.T) {
 	}
 }
 
+func TestSecHardcodedToken_ScansBuildAndVirtualenvDirs(t *testing.T) {
+	dir := makeRepo(t, "targetrepo", map[string]string{
+		"go.mod":                   "module m\n\ngo 1.24\n",
+		"target/conf.go":           "package target\nvar apiKey = \"sk-1234567890abcdef1234\"\n",
+		"venv/settings.py":         "API_KEY = \"sk-1234567890abcdef1234\"\n",
+		"__pycache__/generated.py": "API_KEY = \"sk-1234567890abcdef1234\"\n",
+	})
+	repo := DetectRepo(dir)
+
+	c := &secHardcodedTokenCheck{}
+	findings := c.Run(repo)
+	if len(findings) != 3 {
+		t.Fatalf("expected findings in target/, venv/ and __pycache__/, got %+v", findings)
+	}
+}
+
 // --- CI checks ---
 
 func TestCiNoTestJob_HasTest(t *testing.T) {
@@ -681,6 +709,9 @@ func TestLanguageString(t *testing.T) {
 		{LangGo, "go"},
 		{LangPython, "python"},
 		{LangMulti, "multi"},
+		{LangNode, "node"},
+		{LangRust, "rust"},
+		{LangJava, "java"},
 		{LangUnknown, "unknown"},
 	}
 	for _, tt := range tests {
@@ -692,8 +723,8 @@ func TestLanguageString(t *testing.T) {
 
 func TestAllCheckers_Count(t *testing.T) {
 	checkers := AllCheckers()
-	if len(checkers) != 26 {
-		t.Errorf("AllCheckers() returned %d checks, want 26", len(checkers))
+	if len(checkers) != 36 {
+		t.Errorf("AllCheckers() returned %d checks, want 36", len(checkers))
 	}
 }
 
@@ -836,3 +867,39 @@ func TestGoNoTests_PromptListsPackages(t *testing.T) {
 		t.Error("Prompt should mention testing conventions")
 	}
 }
+
+func TestGoChecks_NestedModules(t *testing.T) {
+	dir := makeRepo(t, "mono", map[string]string{
+		"go.mod":              "module m\n\ngo 1.24\n",
+		"main_test.go":        "package main\n",
+		"svc/go.mod":          "module m/svc\n\ngo 1.21\n",
+		"svc/main.go":         "package main\n",
+		"svc/cmd/svc/main.go": "package main\n",
+		"py/pyproject.toml":   "[project]\nname = \"py\"\n",
+	})
+	repo := DetectRepo(dir)
+
+	// the root test file must not count for the nested module
+	findings := (&goNoTestsCheck{}).Run(repo)
+	if len(findings) != 1 || findings[0].Location != "svc/go.mod" {
+		t.Fatalf("go-no-tests = %+v, want one finding at svc/go.mod", findings)
+	}
+	if findings[0].Message != "No Go test files found in module svc" {
+		t.Errorf("Message = %q", findings[0].Message)
+	}
+
+	findings = (&goOutdatedCheck{}).Run(repo)
+	if len(findings) != 1 || findings[0].Location != "svc/go.mod" || findings[0].Line != 3 {
+		t.Fatalf("go-outdated-version = %+v, want one finding at svc/go.mod:3", findings)
+	}
+
+	findings = (&goMissingDockerfileCheck{}).Run(repo)
+	if len(findings) != 1 || findings[0].Location != "svc" {
+		t.Fatalf("go-missing-dockerfile = %+v, want one finding at svc", findings)
+	}
+
+	// the nested pyproject.toml packages the Python code
+	if got := (&pyMissingProjectCheck{}).Run(repo); len(got) != 0 {
+		t.Errorf("py-missing-pyproject = %d findings, want 0", len(got))
+	}
+}
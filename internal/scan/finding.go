@@ -47,6 +47,25 @@ type Finding struct {
 	Message    string   `json:"message"`
 	Suggestion string   `json:"suggestion"`
 	Prompt     string   `json:"prompt,omitempty"` // detailed prompt for autonomous agent execution
+
+	Location    string `json:"location,omitempty"`    // repo-relative file the finding is about, when it is about one
+	Line        int    `json:"line,omitempty"`        // 1-based line in Location; 0 = unknown
+	Fingerprint string `json:"fingerprint,omitempty"` // stable ID: hash of repo, check and location
+	Status      string `json:"status,omitempty"`      // baselined or suppressed; empty for new findings
+	Fixable     bool   `json:"fixable,omitempty"`     // the check has a deterministic fix, see ApplyFixes
+
+	Dependency *Dependency `json:"dependency,omitempty"` // the vulnerable package, for sec-vulnerable-dependency
+	Commit     string      `json:"commit,omitempty"`     // commit that introduced the issue, for history checks
+}
+
+// Dependency describes a vulnerable package version and the version to bump
+// it to.
+type Dependency struct {
+	Ecosystem    string   `json:"ecosystem"` // OSV ecosystem: Go, PyPI or npm
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`                 // comma-separated when a lockfile holds several
+	FixedVersion string   `json:"fixed_version,omitempty"` // lowest version fixing every advisory; empty if none does
+	Advisories   []string `json:"advisories"`
 }
 
 // TaskPrompt returns the detailed prompt for task generation.
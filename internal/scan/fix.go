@@ -0,0 +1,300 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const fixCommitTimeout = 30 * time.Second
+
+// FixOptions controls ApplyFixes.
+type FixOptions struct {
+	ReposDir string
+	DryRun   bool // report the fixes without writing files
+	Commit   bool // commit each repo's fixed files
+}
+
+// FixResult is the outcome of the deterministic fixes for one repo.
+type FixResult struct {
+	Repo      string   `json:"repo"`
+	Checks    []string `json:"checks"`
+	Files     []string `json:"files"` // repo-relative files written, or to be written in a dry run
+	Committed bool     `json:"committed,omitempty"`
+	Err       error    `json:"-"`
+
+	fingerprints []string
+}
+
+// ApplyFixes writes the deterministic fixes for the fixable findings in
+// result, one FixResult per repo with at least one fix. Fixes only create
+// missing files; a file that exists by now is left alone.
+func ApplyFixes(result *ScanResult, opts FixOptions) ([]FixResult, error) {
+	reposDir, err := filepath.Abs(opts.ReposDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve repos dir: %w", err)
+	}
+
+	fixers := make(map[string]Fixer)
+	for _, c := range AllCheckers() {
+		if fx, ok := c.(Fixer); ok {
+			fixers[c.ID()] = fx
+		}
+	}
+
+	var results []FixResult
+	for _, repoName := range result.ReposScanned {
+		repo := DetectRepo(filepath.Join(reposDir, repoName))
+		if repo == nil {
+			continue
+		}
+		fr := FixResult{Repo: repoName}
+		for i := range result.Findings {
+			f := &result.Findings[i]
+			fx := fixers[f.Check]
+			if f.Repo != repoName || !f.Fixable || fx == nil {
+				continue
+			}
+			var written []string
+			for _, file := range fx.Fix(repo, f) {
+				dst := filepath.Join(repo.Path, filepath.FromSlash(file.Path))
+				if _, err := os.Stat(dst); err == nil {
+					continue
+				}
+				if !opts.DryRun {
+					if err := writeFixFile(dst, file.Content); err != nil {
+						fr.Err = err
+						break
+					}
+				}
+				written = append(written, file.Path)
+			}
+			if fr.Err != nil {
+				break
+			}
+			if len(written) > 0 {
+				fr.Checks = append(fr.Checks, f.Check)
+				fr.Files = append(fr.Files, written...)
+				fr.fingerprints = append(fr.fingerprints, f.Fingerprint)
+			}
+		}
+		if len(fr.Files) == 0 && fr.Err == nil {
+			continue
+		}
+		if opts.Commit && !opts.DryRun && fr.Err == nil {
+			if err := commitFixes(repo.Path, fr.Files); err != nil {
+				fr.Err = err
+			} else {
+				fr.Committed = true
+			}
+		}
+		results = append(results, fr)
+	}
+	return results, nil
+}
+
+// WithoutFixed returns a copy of r without the findings fixed by fixes.
+func (r *ScanResult) WithoutFixed(fixes []FixResult) *ScanResult {
+	fixed := make(map[string]bool)
+	for _, fr := range fixes {
+		if fr.Err != nil {
+			continue
+		}
+		for _, fp := range fr.fingerprints {
+			fixed[fp] = true
+		}
+	}
+	out := *r
+	out.Findings = nil
+	for _, f := range r.Findings {
+		if !fixed[f.Fingerprint] {
+			out.Findings = append(out.Findings, f)
+		}
+	}
+	return &out
+}
+
+func writeFixFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create dir for %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write fix: %w", err)
+	}
+	return nil
+}
+
+// commitFixes commits exactly the given files, leaving any other changes in
+// the working tree alone.
+func commitFixes(dir string, files []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), fixCommitTimeout)
+	defer cancel()
+
+	msg := "chore: add " + strings.Join(files, ", ")
+	if len(msg) > 72 {
+		msg = msg[:72]
+	}
+	args := append([]string{"add", "--"}, files...)
+	if err := runGit(ctx, dir, args...); err != nil {
+		return fmt.Errorf("git add: %w", err)
+	}
+	args = append([]string{"commit", "-m", msg, "--"}, files...)
+	if err := runGit(ctx, dir, args...); err != nil {
+		return fmt.Errorf("git commit: %w", err)
+	}
+	return nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// --- fix templates ---
+
+func fixGitignore(r *RepoInfo) string {
+	var b strings.Builder
+	if r.Uses(LangGo) {
+		b.WriteString("# binaries\n")
+		bins := r.Binaries
+		if len(bins) == 0 {
+			bins = []string{r.Name}
+		}
+		for _, bin := range bins {
+			fmt.Fprintf(&b, "/%s\n", bin)
+		}
+		b.WriteString("bin/\ndist/\n*.exe\n*.test\n\n# coverage\ncoverage.out\n\n")
+	}
+	if r.Uses(LangPython) {
+		b.WriteString("# python\n__pycache__/\n*.pyc\n.venv/\nvenv/\ndist/\nbuild/\n*.egg-info/\n.pytest_cache/\n.coverage\n\n")
+	}
+	if r.Uses(LangNode) {
+		b.WriteString("# node\nnode_modules/\ndist/\ncoverage/\n*.tsbuildinfo\nnpm-debug.log*\n\n")
+	}
+	if r.Uses(LangRust) {
+		b.WriteString("# rust\ntarget/\n\n")
+	}
+	if r.Uses(LangJava) {
+		b.WriteString("# java\n*.class\ntarget/\nbuild/\n.gradle/\n\n")
+	}
+	if b.Len() == 0 {
+		b.WriteString("*.log\n\n")
+	}
+	b.WriteString("# secrets\n.env\n*.pem\n\n# editors and OS\n.idea/\n.vscode/\n.DS_Store\n")
+	return b.String()
+}
+
+// licenseHolder returns the owner from a hosted module path
+// (github.com/<owner>/...), falling back to the default owner.
+func licenseHolder(r *RepoInfo) string {
+	parts := strings.Split(r.Module, "/")
+	if len(parts) >= 2 && strings.Contains(parts[0], ".") {
+		return parts[1]
+	}
+	return "ppiankov"
+}
+
+func fixLicense(r *RepoInfo) string {
+	return fmt.Sprintf(`MIT License
+
+Copyright (c) %d %s
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`, time.Now().Year(), licenseHolder(r))
+}
+
+func fixChangelog(_ *RepoInfo) string {
+	return `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [Unreleased]
+
+### Added
+
+### Changed
+
+### Fixed
+`
+}
+
+func fixGolangciLint(_ *RepoInfo) string {
+	return `version: "2"
+
+run:
+  timeout: 5m
+
+linters:
+  enable:
+    - errcheck
+    - govet
+    - ineffassign
+    - staticcheck
+    - unused
+  settings:
+    errcheck:
+      exclude-functions:
+        - fmt.Fprint
+        - fmt.Fprintf
+        - fmt.Fprintln
+`
+}
+
+// dependabotEcosystems maps manifests to Dependabot package ecosystems.
+var dependabotEcosystems = map[string]string{
+	"go.mod":           "gomod",
+	"pyproject.toml":   "pip",
+	"setup.py":         "pip",
+	"requirements.txt": "pip",
+	"package.json":     "npm",
+	"Cargo.toml":       "cargo",
+	"pom.xml":          "maven",
+	"build.gradle":     "gradle",
+	"build.gradle.kts": "gradle",
+}
+
+// fixDependabot configures weekly updates for every module and for GitHub
+// Actions.
+func fixDependabot(r *RepoInfo) string {
+	var b strings.Builder
+	b.WriteString("version: 2\nupdates:\n")
+	ecosystem := func(name, dir string) {
+		fmt.Fprintf(&b, "  - package-ecosystem: %s\n    directory: %s\n    schedule:\n      interval: weekly\n", name, dir)
+	}
+	for _, m := range r.Modules {
+		if eco := dependabotEcosystems[m.Manifest]; eco != "" {
+			ecosystem(eco, path.Join("/", m.Dir))
+		}
+	}
+	ecosystem("github-actions", "/")
+	return b.String()
+}
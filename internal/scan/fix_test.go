@@ -0,0 +1,145 @@
+package scan
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixTemplates(t *testing.T) {
+	goRepo := &RepoInfo{Name: "app", Language: LangGo, Module: "github.com/acme/app", Binaries: []string{"app", "appctl"}}
+
+	gi := fixGitignore(goRepo)
+	for _, want := range []string{"/app\n", "/appctl\n", "coverage.out", ".env"} {
+		if !strings.Contains(gi, want) {
+			t.Errorf("gitignore missing %q:\n%s", want, gi)
+		}
+	}
+	if gi := fixGitignore(&RepoInfo{Name: "lib", Language: LangGo}); !strings.Contains(gi, "/lib\n") {
+		t.Errorf("gitignore without cmd/ should ignore the repo binary:\n%s", gi)
+	}
+	if gi := fixGitignore(&RepoInfo{Name: "py", Language: LangPython}); !strings.Contains(gi, "__pycache__/") {
+		t.Errorf("python gitignore:\n%s", gi)
+	}
+
+	if lic := fixLicense(goRepo); !strings.HasPrefix(lic, "MIT License") || !strings.Contains(lic, " acme\n") {
+		t.Errorf("license holder not from module path:\n%s", lic)
+	}
+	if got := licenseHolder(&RepoInfo{Module: "app"}); got != "ppiankov" {
+		t.Errorf("licenseHolder(unhosted) = %q", got)
+	}
+
+	dep := fixDependabot(&RepoInfo{Language: LangMulti, Modules: []ModuleRoot{
+		{Language: LangGo, Dir: ".", Manifest: "go.mod"},
+		{Language: LangNode, Dir: "web", Manifest: "package.json"},
+	}})
+	for _, want := range []string{"gomod\n    directory: /\n", "npm\n    directory: /web\n", "github-actions"} {
+		if !strings.Contains(dep, "package-ecosystem: "+want) {
+			t.Errorf("dependabot missing %q:\n%s", want, dep)
+		}
+	}
+	if gi := fixGitignore(&RepoInfo{Language: LangRust}); !strings.Contains(gi, "target/") {
+		t.Errorf("rust gitignore:\n%s", gi)
+	}
+}
+
+func TestScan_Fixable(t *testing.T) {
+	base := makeScanDir(t, map[string]map[string]string{"app": {"go.mod": "module github.com/acme/app\n\ngo 1.24\n"}})
+
+	result, err := Scan(ScanOptions{ReposDir: base, Categories: []string{"structure"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixable := make(map[string]bool)
+	for _, f := range result.Findings {
+		fixable[f.Check] = f.Fixable
+	}
+	if !fixable["missing-license"] || !fixable["missing-gitignore"] || !fixable["missing-changelog"] {
+		t.Errorf("boilerplate findings not fixable: %v", fixable)
+	}
+	if fixable["missing-readme"] || fixable["missing-ci"] {
+		t.Errorf("agent findings marked fixable: %v", fixable)
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@test.com")
+	}
+	base := t.TempDir()
+	repoDir := filepath.Join(base, "app")
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	for name, content := range map[string]string{"go.mod": "module m\n\ngo 1.24\n", "README.md": "# app\n"} {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-qm", "init")
+	// unrelated work in progress must not be committed
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("# app\nwip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Scan(ScanOptions{ReposDir: base, Categories: []string{"structure", "ci"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixes, err := ApplyFixes(result, FixOptions{ReposDir: base, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixes) != 1 || len(fixes[0].Files) != 4 {
+		t.Fatalf("dry run fixes = %+v", fixes)
+	}
+	if fileExists(filepath.Join(repoDir, "LICENSE")) {
+		t.Fatal("dry run wrote LICENSE")
+	}
+
+	fixes, err = ApplyFixes(result, FixOptions{ReposDir: base, Commit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixes) != 1 || fixes[0].Err != nil || !fixes[0].Committed {
+		t.Fatalf("fixes = %+v", fixes)
+	}
+	for _, f := range []string{"LICENSE", "CHANGELOG.md", ".gitignore", ".github/dependabot.yml"} {
+		if !fileExists(filepath.Join(repoDir, f)) {
+			t.Errorf("%s not written", f)
+		}
+	}
+	out, err := exec.Command("git", "-C", repoDir, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "M README.md" {
+		t.Errorf("status after commit = %q, want only the README change", out)
+	}
+
+	remaining := result.WithoutFixed(fixes)
+	for _, f := range remaining.Findings {
+		if f.Fixable {
+			t.Errorf("fixed finding %s still reported", f.Check)
+		}
+	}
+	if len(remaining.Findings) == 0 {
+		t.Error("unfixable findings dropped")
+	}
+}
@@ -62,13 +62,20 @@ func (f *TextFormatter) Format(w io.Writer, result *ScanResult) error {
 
 		for _, finding := range findings {
 			sevLabel := f.severityLabel(finding.Severity)
-			fmt.Fprintf(w, "  %s  %-25s %s\n", sevLabel, finding.Check, finding.Message)
+			status := ""
+			if finding.Status != "" {
+				status = fmt.Sprintf(" %s[%s]%s", f.c(colorDim), finding.Status, f.c(colorReset))
+			}
+			fmt.Fprintf(w, "  %s  %-25s %s%s\n", sevLabel, finding.Check, finding.Message, status)
 		}
 		fmt.Fprintln(w)
 	}
 
 	// summary
 	fmt.Fprintf(w, "Summary: %d repos scanned", len(result.ReposScanned))
+	if result.Cached > 0 {
+		fmt.Fprintf(w, " (%d cached)", result.Cached)
+	}
 	if critCount > 0 {
 		fmt.Fprintf(w, ", %s%d critical%s", f.c(colorRed), critCount, f.c(colorReset))
 	}
@@ -82,6 +89,15 @@ func (f *TextFormatter) Format(w io.Writer, result *ScanResult) error {
 		fmt.Fprintf(w, ", %d skipped", len(result.Skipped))
 	}
 	fmt.Fprintln(w)
+	if n := countFixable(result.Findings); n > 0 {
+		fmt.Fprintf(w, "%sFixable: %d findings have deterministic fixes (--fix to apply)%s\n",
+			f.c(colorDim), n, f.c(colorReset))
+	}
+	// known findings are only listed with --all
+	if known := result.Baselined + result.Suppressed; known > 0 && countNew(result.Findings) == len(result.Findings) {
+		fmt.Fprintf(w, "%sKnown: %d baselined, %d suppressed (hidden; --all to show)%s\n",
+			f.c(colorDim), result.Baselined, result.Suppressed, f.c(colorReset))
+	}
 
 	return nil
 }
@@ -144,6 +160,10 @@ func (f *TaskFormatter) Format(w io.Writer, result *ScanResult) error {
 		if finding.Severity == SeverityInfo {
 			continue
 		}
+		// deterministic fixes don't need an agent, see ApplyFixes
+		if finding.Fixable {
+			continue
+		}
 
 		owner := f.owner
 		if owner == "" {
@@ -169,7 +189,7 @@ func (f *TaskFormatter) Format(w io.Writer, result *ScanResult) error {
 	}
 
 	if len(tf.Tasks) == 0 {
-		fmt.Fprintln(w, "No actionable findings (critical/warning) to generate tasks for.")
+		fmt.Fprintln(w, "No actionable findings (critical/warning without a deterministic fix) to generate tasks for.")
 		return nil
 	}
 
@@ -194,6 +214,29 @@ func countSeverities(findings []Finding) (crit, warn, info int) {
 	return
 }
 
+// countNew returns the number of findings that are neither baselined nor
+// suppressed.
+func countNew(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Status == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// countFixable returns the number of findings with a deterministic fix.
+func countFixable(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Fixable {
+			n++
+		}
+	}
+	return n
+}
+
 func groupByRepo(findings []Finding) map[string][]Finding {
 	m := make(map[string][]Finding)
 	for _, f := range findings {
@@ -81,6 +81,43 @@ func TestTextFormatter_EmptyResult(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_KnownFindings(t *testing.T) {
+	result := sampleResult()
+	result.Baselined, result.Suppressed = 3, 1
+
+	var buf bytes.Buffer
+	if err := NewTextFormatter(false).Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Known: 3 baselined, 1 suppressed (hidden; --all to show)") {
+		t.Errorf("missing known note:\n%s", buf.String())
+	}
+
+	// with --all the known findings are listed and marked instead
+	result.Findings[0].Status = StatusBaselined
+	buf.Reset()
+	if err := NewTextFormatter(false).Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "No CI pipeline found [baselined]") || strings.Contains(out, "Known:") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestTextFormatter_Cached(t *testing.T) {
+	result := sampleResult()
+	result.Cached = 1
+
+	var buf bytes.Buffer
+	if err := NewTextFormatter(false).Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "repos scanned (1 cached)") {
+		t.Errorf("missing cached count:\n%s", buf.String())
+	}
+}
+
 func TestJSONFormatter(t *testing.T) {
 	var buf bytes.Buffer
 	f := NewJSONFormatter()
@@ -235,6 +272,30 @@ func TestTaskFormatter_InfoOnly(t *testing.T) {
 	}
 }
 
+func TestTaskFormatter_SkipsFixable(t *testing.T) {
+	var buf bytes.Buffer
+	result := &ScanResult{
+		ReposScanned: []string{"r"},
+		Findings: []Finding{
+			{Repo: "r", Check: "missing-license", Category: "structure", Severity: SeverityWarning, Message: "No LICENSE", Fixable: true},
+		},
+	}
+	if err := NewTaskFormatter("o", "r").Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No actionable findings") {
+		t.Errorf("expected fixable finding to be skipped, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewTextFormatter(false).Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Fixable: 1 findings") {
+		t.Errorf("missing fixable hint:\n%s", buf.String())
+	}
+}
+
 func TestTaskFormatter_EmptyFindings(t *testing.T) {
 	var buf bytes.Buffer
 	f := NewTaskFormatter("o", "r")
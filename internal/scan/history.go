@@ -0,0 +1,405 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/runner"
+)
+
+// DefaultHistoryDepth is the number of commits sec-secret-in-history reads
+// when ScanOptions.HistoryDepth is 0.
+const DefaultHistoryDepth = 200
+
+const (
+	historyGitTimeout = 2 * time.Minute
+
+	// minSecretLen and minSecretEntropy (bits per char) decide when a value
+	// assigned to a key, token or password is random enough to be real.
+	minSecretLen     = 20
+	minSecretEntropy = 4.0
+)
+
+// secretPatterns are the credential formats output scanning redacts.
+var secretPatterns = runner.SecretPatterns()
+
+// secretAssignment finds values assigned to secret-looking names, for the
+// entropy check.
+var secretAssignment = regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|credential|auth)[\w.-]*["']?\s*[:=]\s*["']?([A-Za-z0-9+/_.=-]{20,})`)
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)`)
+
+// historySkipFiles hold checksums and hashes that look like secrets.
+var historySkipFiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true,
+	"pnpm-lock.yaml": true, "Cargo.lock": true, "poetry.lock": true, "uv.lock": true, "Pipfile.lock": true,
+}
+
+// historySecret is a secret added by a commit.
+type historySecret struct {
+	commit string
+	date   string // committer date, YYYY-MM-DD
+	path   string
+	line   int // in the commit's version of path
+	kind   string
+	value  string
+}
+
+// --- history secret check ---
+
+type secSecretInHistoryCheck struct {
+	depth int // commits to read; <= 0 = check off
+}
+
+func (c *secSecretInHistoryCheck) ID() string       { return "sec-secret-in-history" }
+func (c *secSecretInHistoryCheck) Category() string { return "security" }
+func (c *secSecretInHistoryCheck) Applies(_ *RepoInfo) bool {
+	return c.depth > 0
+}
+
+func (c *secSecretInHistoryCheck) Run(r *RepoInfo) []Finding {
+	secrets, err := scanHistory(r.Path, c.depth)
+	if err != nil {
+		slog.Debug("history secret scan skipped", "repo", r.Name, "error", err)
+		return nil
+	}
+
+	// git log is newest first: the last commit seen for a path introduced
+	// its oldest secret
+	byPath := make(map[string][]historySecret)
+	var paths []string
+	for _, s := range secrets {
+		if _, ok := byPath[s.path]; !ok {
+			paths = append(paths, s.path)
+		}
+		byPath[s.path] = append(byPath[s.path], s)
+	}
+	sort.Strings(paths)
+
+	var findings []Finding
+	for _, p := range paths {
+		findings = append(findings, c.finding(r, p, byPath[p]))
+	}
+	return findings
+}
+
+func (c *secSecretInHistoryCheck) finding(r *RepoInfo, rel string, secrets []historySecret) Finding {
+	first := secrets[len(secrets)-1]
+	values := make(map[string]bool)
+	commits := make(map[string]bool)
+	for _, s := range secrets {
+		values[s.value] = true
+		commits[s.commit] = true
+	}
+
+	// a secret still in the working tree also has to be removed from the code
+	current := readFileString(filepath.Join(r.Path, filepath.FromSlash(rel)))
+	line := 0
+	for _, s := range secrets {
+		if current != "" && strings.Contains(current, s.value) {
+			line = lineOf(current, s.value)
+			break
+		}
+	}
+
+	what := first.kind
+	if len(values) > 1 {
+		what = fmt.Sprintf("%d secrets", len(values))
+	}
+	msg := fmt.Sprintf("%s committed to %s in %s (%s)", what, rel, shortCommit(first.commit), first.date)
+	if len(commits) > 1 {
+		msg += fmt.Sprintf(" and %d later commits", len(commits)-1)
+	}
+	if line > 0 {
+		msg += "; still in the working tree"
+	} else {
+		msg += "; removed since, but still in history"
+	}
+
+	p := newPrompt()
+	p.line(fmt.Sprintf("Remediate credentials committed to %s in the git history of %s.", rel, r.Name))
+	p.blank()
+	p.line("Leaked values (redacted):")
+	seen := make(map[string]bool)
+	for i := len(secrets) - 1; i >= 0; i-- {
+		s := secrets[i]
+		if seen[s.value] {
+			continue
+		}
+		seen[s.value] = true
+		p.line(fmt.Sprintf("- %s %s, added at line %d in %s (%s)", s.kind, redactSecret(s.value), s.line, shortCommit(s.commit), s.date))
+	}
+	p.blank()
+	p.line("Every clone, fork and CI cache already has these values: deleting the line does not revoke them.")
+	p.blank()
+	p.line("Steps:")
+	step := 1
+	if line > 0 {
+		p.line(fmt.Sprintf("%d. Replace the value at %s:%d with an environment variable or secrets manager lookup,", step, rel, line))
+		p.line("   and add the variable name to .env.example with a placeholder value.")
+		step++
+	}
+	p.line(fmt.Sprintf("%d. Add a section to SECURITY-REMEDIATION.md (create it if missing) for %s that lists, for a maintainer:", step, rel))
+	p.line("   a. Rotation: revoke each value above at its issuer and issue a new one — " + rotationHint(secrets))
+	p.line("      Check the issuer's access logs for use since the commit date.")
+	p.line("   b. History rewrite, after rotation:")
+	p.line("        echo '<leaked value>==>REMOVED' > replacements.txt   # one line per value, kept outside the repo")
+	p.line(fmt.Sprintf("        git filter-repo --replace-text replacements.txt   # or: git filter-repo --invert-paths --path %s", rel))
+	p.line("      then force-push every branch and tag, ask collaborators to re-clone,")
+	p.line("      and ask the host to purge cached views and pull request refs.")
+	p.line("   Never write the secret values into that file.")
+	p.blank()
+	p.line("Do NOT rotate credentials, rewrite history or force-push yourself.")
+	p.constraints()
+	if line > 0 {
+		p.blank()
+		p.verification(r)
+	}
+
+	return Finding{
+		Repo: r.Name, Check: c.ID(), Category: c.Category(),
+		Severity:   SeverityCritical,
+		Message:    msg,
+		Location:   rel,
+		Line:       line,
+		Commit:     first.commit,
+		Suggestion: "Rotate the credential first — removing it from the code does not revoke it. Then purge it from history with git filter-repo and force-push.",
+		Prompt:     p.String(),
+	}
+}
+
+// rotationHint points at where the leaked kinds of credential are revoked.
+func rotationHint(secrets []historySecret) string {
+	kinds := make(map[string]bool)
+	for _, s := range secrets {
+		kinds[s.kind] = true
+	}
+	var hints []string
+	for _, h := range []struct{ kind, hint string }{
+		{"GitHub token", "GitHub tokens under Settings > Developer settings"},
+		{"AWS access key", "AWS keys in IAM"},
+		{"OpenAI key", "OpenAI keys in the platform dashboard"},
+		{"Anthropic key", "Anthropic keys in the console"},
+		{"Groq key", "Groq keys in the console"},
+	} {
+		if kinds[h.kind] {
+			hints = append(hints, h.hint)
+		}
+	}
+	if len(hints) == 0 {
+		return "the service the value belongs to."
+	}
+	return strings.Join(hints, "; ") + "."
+}
+
+// scanHistory returns the secrets added by the last depth commits on HEAD,
+// newest first.
+func scanHistory(repoPath string, depth int) ([]historySecret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), historyGitTimeout)
+	defer cancel()
+
+	// --git-dir stops git from searching parent dirs when .git is not a repo
+	cmd := exec.CommandContext(ctx, "git", "--git-dir", filepath.Join(repoPath, ".git"), "-c", "core.quotePath=false", "log", "-p",
+		"--no-color", "--no-ext-diff", "--no-textconv", "--unified=0", "--diff-filter=AM",
+		"--format=%x00%H %cs", "-n", strconv.Itoa(depth), "HEAD", "--")
+	cmd.Dir = repoPath
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	secrets, parseErr := parseHistory(out)
+	_, _ = io.Copy(io.Discard, out)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	return secrets, parseErr
+}
+
+// parseHistory reads `git log -p --unified=0` output and returns the secrets
+// on added lines.
+func parseHistory(r io.Reader) ([]historySecret, error) {
+	br := bufio.NewReader(r)
+	var secrets []historySecret
+	var commit, date, file string
+	inHeader := false
+	lineNum := 0
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			commit, date, _ = strings.Cut(line[1:], " ")
+			file = ""
+		case strings.HasPrefix(line, "diff --git "):
+			inHeader = true
+			file = ""
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			file = diffPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				lineNum, _ = strconv.Atoi(m[1])
+			}
+		case !inHeader && file != "" && strings.HasPrefix(line, "+"):
+			for _, s := range findSecrets(line[1:]) {
+				secrets = append(secrets, historySecret{commit: commit, date: date, path: file, line: lineNum, kind: s[0], value: s[1]})
+			}
+			lineNum++
+		}
+		if errors.Is(err, io.EOF) {
+			return secrets, nil
+		}
+		if err != nil {
+			return secrets, fmt.Errorf("read git log: %w", err)
+		}
+	}
+}
+
+// diffPath returns the repo-relative path of a "+++ b/path" header, or ""
+// for deletions, files whose hashes would look like secrets, and test
+// fixtures, which sec-hardcoded-token skips too.
+func diffPath(s string) string {
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	}
+	rel, ok := strings.CutPrefix(s, "b/")
+	if !ok || hasAnyPrefix(rel, []string{"vendor/", "node_modules/"}) || inDir(rel, "testdata") {
+		return ""
+	}
+	base := path.Base(rel)
+	if historySkipFiles[base] || strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") {
+		return ""
+	}
+	return rel
+}
+
+// findSecrets returns the kind and value of each likely secret in line:
+// known credential formats, and random-looking values assigned to secret
+// names.
+func findSecrets(line string) [][2]string {
+	if isPlaceholderLine(line) {
+		return nil
+	}
+	var found [][2]string
+	for _, re := range secretPatterns {
+		for _, m := range re.FindAllString(line, -1) {
+			kind := secretKind(m)
+			if kind == "hex token" && isChecksumLine(line) {
+				continue
+			}
+			found = append(found, [2]string{kind, m})
+		}
+	}
+	for _, m := range secretAssignment.FindAllStringSubmatch(line, -1) {
+		if v := m[1]; len(v) >= minSecretLen && shannonEntropy(v) >= minSecretEntropy && hasLetterAndDigit(v) {
+			found = append(found, [2]string{"high-entropy secret", v})
+		}
+	}
+
+	// one secret can match several patterns (an Anthropic key is also an
+	// OpenAI key): keep the longest match, under its first kind
+	sort.SliceStable(found, func(i, j int) bool { return len(found[i][1]) > len(found[j][1]) })
+	var out [][2]string
+	for _, f := range found {
+		if !slices.ContainsFunc(out, func(o [2]string) bool { return strings.Contains(o[1], f[1]) }) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// secretKind names a value matched by secretPatterns.
+func secretKind(v string) string {
+	switch {
+	case strings.HasPrefix(v, "gsk_"):
+		return "Groq key"
+	case strings.HasPrefix(v, "sk-ant-"):
+		return "Anthropic key"
+	case strings.HasPrefix(v, "sk-"):
+		return "OpenAI key"
+	case strings.HasPrefix(v, "ghp_"), strings.HasPrefix(v, "gho_"), strings.HasPrefix(v, "github_pat_"):
+		return "GitHub token"
+	case strings.HasPrefix(v, "AKIA"):
+		return "AWS access key"
+	case strings.HasPrefix(strings.ToLower(v), "bearer"):
+		return "bearer token"
+	default:
+		return "hex token"
+	}
+}
+
+// isChecksumLine reports whether long hex on the line is a digest rather
+// than a token.
+func isChecksumLine(line string) bool {
+	lower := strings.ToLower(line)
+	for _, s := range []string{"sha256", "sha512", "sha1", "digest", "checksum", "integrity", "hash"} {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the bits of entropy per character of s.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func hasLetterAndDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789") &&
+		strings.ContainsFunc(s, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') })
+}
+
+// setHistoryDepth sets the number of commits the history secret check reads.
+func setHistoryDepth(checkers []Checker, depth int) {
+	for _, c := range checkers {
+		if h, ok := c.(*secSecretInHistoryCheck); ok {
+			h.depth = depth
+		}
+	}
+}
+
+// redactSecret keeps enough of a secret to recognize it.
+func redactSecret(v string) string {
+	keep := 4
+	for _, prefix := range []string{"sk-ant-", "github_pat_"} {
+		if strings.HasPrefix(v, prefix) {
+			keep = len(prefix)
+		}
+	}
+	return fmt.Sprintf("%s… (%d chars)", v[:keep], len(v))
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
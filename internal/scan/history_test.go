@@ -0,0 +1,154 @@
+package scan
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeSecret builds a fake credential at runtime so the test file itself
+// doesn't trip secret scanners.
+func fakeSecret(prefix string, length int) string {
+	body := strings.Repeat("aB3xK9mQ7pL2", length/12+1)
+	return prefix + body[:length]
+}
+
+// gitCommitAll commits the working tree of dir and returns the commit hash.
+func gitCommitAll(t *testing.T, dir, msg string) string {
+	t.Helper()
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-qm", msg}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return gitHead(t, dir)
+}
+
+func gitHead(t *testing.T, dir string) string {
+	t.Helper()
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestFindSecrets(t *testing.T) {
+	ghToken := fakeSecret("ghp_", 36)
+	antKey := fakeSecret("sk-ant-", 30)
+	random := "Zq8vR2nW5tY1uH4jK7mC0xB6"
+	hex := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		name string
+		line string
+		want string // kind:value pairs
+	}{
+		{"github token", `token: "` + ghToken + `"`, "GitHub token:" + ghToken},
+		{"anthropic key once", "ANTHROPIC_KEY=" + antKey, "Anthropic key:" + antKey},
+		{"high entropy", `db_password = "` + random + `"`, "high-entropy secret:" + random},
+		{"low entropy", `password = "aaaaaaaaaaaaaaaaaaaaaaaa"`, ""},
+		{"placeholder", `api_key: "` + ghToken + `" # example`, ""},
+		{"checksum", "sha256 " + hex, ""},
+		{"hex token", "key " + hex, "hex token:" + hex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range findSecrets(tt.line) {
+				got = append(got, s[0]+":"+s[1])
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("findSecrets = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHistory(t *testing.T) {
+	key := fakeSecret("gsk_", 24)
+	log := "\x00c2 2024-05-02\n\n" +
+		// lockfiles and tests are skipped
+		"diff --git a/go.sum b/go.sum\n--- a/go.sum\n+++ b/go.sum\n@@ -1 +1 @@\n+x " + key + "\n" +
+		"diff --git a/a_test.go b/a_test.go\n--- a/a_test.go\n+++ b/a_test.go\n@@ -1 +1 @@\n+k := \"" + key + "\"\n" +
+		// an added line that looks like a header is content
+		"diff --git a/app.env b/app.env\nnew file mode 100644\n--- /dev/null\n+++ b/app.env\n@@ -0,0 +1,3 @@\n+A=1\n++++ b/fake\n+GROQ=" + key + "\n" +
+		"\x00c1 2024-05-01\n\n" +
+		// removed lines don't count
+		"diff --git a/cfg.yml b/cfg.yml\n--- a/cfg.yml\n+++ b/cfg.yml\n@@ -10,0 +11 @@\n+key: " + key + "\n-old: " + key + "\n"
+	secrets, err := parseHistory(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range secrets {
+		got = append(got, fmt.Sprintf("%s %s %s:%d %s", s.commit, s.date, s.path, s.line, s.kind))
+	}
+	want := "c2 2024-05-02 app.env:3 Groq key,c1 2024-05-01 cfg.yml:11 Groq key"
+	if strings.Join(got, ",") != want {
+		t.Errorf("secrets = %q\nwant %q", got, want)
+	}
+}
+
+func TestSecSecretInHistory(t *testing.T) {
+	oldKey := fakeSecret("ghp_", 36)
+	liveKey := "AKIA" + strings.Repeat("Q7", 8)
+
+	dir := makeGitRepo(t, t.TempDir(), "app", map[string]string{
+		"go.mod":     "module m\n",
+		"config.yml": "name: app\ngithub_token: " + oldKey + "\n",
+	})
+	leaked := gitHead(t, dir)
+	writeFile(t, filepath.Join(dir, "config.yml"), "name: app\n")
+	gitCommitAll(t, dir, "remove token")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n\nvar awsKey = \""+liveKey+"\"\n")
+	live := gitCommitAll(t, dir, "add aws")
+
+	repo := DetectRepo(dir)
+	findings := (&secSecretInHistoryCheck{depth: DefaultHistoryDepth}).Run(repo)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+
+	removed := findings[0]
+	if removed.Location != "config.yml" || removed.Commit != leaked || removed.Line != 0 || removed.Severity != SeverityCritical {
+		t.Errorf("removed secret finding = %+v", removed)
+	}
+	if !strings.Contains(removed.Message, "GitHub token committed to config.yml in "+leaked[:7]) ||
+		!strings.Contains(removed.Message, "still in history") {
+		t.Errorf("message = %q", removed.Message)
+	}
+	for _, text := range []string{removed.Message, removed.Prompt, removed.Suggestion} {
+		if strings.Contains(text, oldKey) {
+			t.Errorf("finding leaks the secret: %q", text)
+		}
+	}
+	if !strings.Contains(removed.Prompt, "git filter-repo") || !strings.Contains(removed.Prompt, "Developer settings") {
+		t.Errorf("prompt lacks rotation and rewrite guidance:\n%s", removed.Prompt)
+	}
+
+	current := findings[1]
+	if current.Location != "main.go" || current.Commit != live || current.Line != 3 ||
+		!strings.Contains(current.Message, "still in the working tree") {
+		t.Errorf("live secret finding = %+v", current)
+	}
+
+	// the depth bounds the walk
+	if got := (&secSecretInHistoryCheck{depth: 1}).Run(repo); len(got) != 1 || got[0].Location != "main.go" {
+		t.Errorf("depth 1 findings = %+v, want only main.go", got)
+	}
+	if (&secSecretInHistoryCheck{depth: -1}).Applies(repo) {
+		t.Error("check applies with a negative depth")
+	}
+
+	// a dir whose .git is not a repo has no history
+	fake := makeRepo(t, "fake", map[string]string{"config.yml": "token: " + oldKey + "\n"})
+	if got := (&secSecretInHistoryCheck{depth: 10}).Run(DetectRepo(fake)); len(got) != 0 {
+		t.Errorf("findings for a fake repo: %+v", got)
+	}
+}
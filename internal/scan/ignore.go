@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IgnoreFile is the per-repo suppression file, read from the repo root.
+const IgnoreFile = ".tokencontrol-ignore"
+
+// Suppression hides findings in one repo. It matches by fingerprint, or by
+// check and optionally location. Reason is required; after Expires
+// (YYYY-MM-DD, inclusive) the suppression no longer applies.
+type Suppression struct {
+	Check       string `yaml:"check,omitempty"`
+	Location    string `yaml:"location,omitempty"`
+	Fingerprint string `yaml:"fingerprint,omitempty"`
+	Reason      string `yaml:"reason"`
+	Expires     string `yaml:"expires,omitempty"`
+}
+
+func (s *Suppression) matches(f *Finding) bool {
+	if s.Fingerprint != "" {
+		return s.Fingerprint == f.Fingerprint
+	}
+	return s.Check == f.Check && (s.Location == "" || s.Location == f.Location)
+}
+
+// loadSuppressions reads the repo's ignore file and returns the entries in
+// effect at now. Invalid and expired entries are skipped with a warning.
+func loadSuppressions(repo *RepoInfo, now time.Time) []Suppression {
+	data, err := os.ReadFile(filepath.Join(repo.Path, IgnoreFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("cannot read ignore file", "repo", repo.Name, "error", err)
+		}
+		return nil
+	}
+	var entries []Suppression
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		slog.Warn("invalid ignore file", "repo", repo.Name, "error", err)
+		return nil
+	}
+
+	var active []Suppression
+	for i, s := range entries {
+		if err := s.validate(now); err != nil {
+			slog.Warn("skipping suppression", "repo", repo.Name, "entry", i+1, "reason", err)
+			continue
+		}
+		active = append(active, s)
+	}
+	return active
+}
+
+// validate checks an entry and whether it is still in effect at now.
+func (s *Suppression) validate(now time.Time) error {
+	if s.Check == "" && s.Fingerprint == "" {
+		return fmt.Errorf("needs check or fingerprint")
+	}
+	if s.Reason == "" {
+		return fmt.Errorf("needs a reason")
+	}
+	if s.Expires == "" {
+		return nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s.Expires, now.Location())
+	if err != nil {
+		return fmt.Errorf("expires %q: want YYYY-MM-DD", s.Expires)
+	}
+	if !now.Before(day.AddDate(0, 0, 1)) {
+		return fmt.Errorf("expired on %s", s.Expires)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScan_IgnoreFile(t *testing.T) {
+	ignore := `- check: missing-license
+  reason: internal tool, not distributed
+- check: missing-changelog
+  reason: expired waiver
+  expires: 2026-01-31
+- check: missing-ci
+- fingerprint: ` + (&Finding{Repo: "app", Check: "missing-readme"}).fingerprint() + `
+  reason: readme lives in the wiki
+  expires: 2026-03-01
+`
+	base := makeScanDir(t, map[string]map[string]string{"app": {IgnoreFile: ignore}})
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.Local)
+
+	result, err := Scan(ScanOptions{ReposDir: base, Categories: []string{"structure"}, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := make(map[string]bool)
+	for _, f := range result.Findings {
+		checks[f.Check] = true
+	}
+	if checks["missing-license"] || checks["missing-readme"] {
+		t.Errorf("suppressed findings reported: %v", checks)
+	}
+	if !checks["missing-changelog"] {
+		t.Error("expired suppression should no longer apply")
+	}
+	if !checks["missing-ci"] {
+		t.Error("suppression without a reason should be ignored")
+	}
+	if result.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, want 2", result.Suppressed)
+	}
+
+	// the day after expiry the readme finding is back
+	result, err = Scan(ScanOptions{ReposDir: base, Categories: []string{"structure"}, Now: now.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Suppressed != 1 {
+		t.Errorf("Suppressed = %d, want 1", result.Suppressed)
+	}
+}
+
+func TestSuppression_Validate(t *testing.T) {
+	now := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		s       Suppression
+		wantErr bool
+	}{
+		{Suppression{Check: "c", Reason: "r"}, false},
+		{Suppression{Fingerprint: "abc", Reason: "r", Expires: "2026-05-10"}, false},
+		{Suppression{Check: "c", Reason: "r", Expires: "2026-05-09"}, true},
+		{Suppression{Check: "c", Reason: "r", Expires: "10/05/2026"}, true},
+		{Suppression{Reason: "r"}, true},
+		{Suppression{Check: "c"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.s.validate(now); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: err = %v, wantErr %v", tt.s, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// prunedDirs are the dependency, build and cache dirs that the checks skip.
+// The index leaves them out of its walk and lists them only when a check
+// that does not skip them asks.
+var prunedDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+	"target":       true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+}
+
+// fileIndex is the list of files in a repo, built on first use and shared
+// by every check that would otherwise walk the tree itself.
+type fileIndex struct {
+	once   sync.Once
+	files  []string
+	pruned []*prunedDir
+}
+
+// prunedDir is a dir left out of the index walk, listed on first use.
+type prunedDir struct {
+	rel   string // slash-separated, repo-relative
+	at    int    // position in fileIndex.files where its files belong
+	once  sync.Once
+	files []string
+}
+
+// Files returns the repo-relative, slash-separated paths of the regular
+// files in the repo in walk order, leaving out .git and any file below a
+// directory named in skip. Each check passes the dirs it ignores, such as
+// vendor or node_modules; dirs in prunedDirs are only walked when a check
+// does not skip them.
+func (r *RepoInfo) Files(skip ...string) []string {
+	if r.index == nil {
+		r.index = &fileIndex{}
+	}
+	idx := r.index
+	idx.once.Do(func() { idx.files, idx.pruned = indexFiles(r.Path) })
+	if len(skip) == 0 && len(idx.pruned) == 0 {
+		return idx.files
+	}
+	var out []string
+	add := func(files []string) {
+		for _, f := range files {
+			if !inAnyDir(f, skip) {
+				out = append(out, f)
+			}
+		}
+	}
+	next := 0
+	for _, p := range idx.pruned {
+		add(idx.files[next:p.at])
+		next = p.at
+		if inAnyDir(p.rel+"/", skip) {
+			continue
+		}
+		p.once.Do(func() { p.files, _ = walkFiles(r.Path, p.rel, false) })
+		add(p.files)
+	}
+	add(idx.files[next:])
+	return out
+}
+
+// moduleFiles returns the indexed files inside m, excluding the modules of
+// the same language nested below it and the dirs named in skip.
+func (r *RepoInfo) moduleFiles(m ModuleRoot, skip ...string) []string {
+	var nested []string
+	for _, o := range r.ModulesOf(m.Language) {
+		if o.Dir != m.Dir && (m.Dir == "." || strings.HasPrefix(o.Dir, m.Dir+"/")) {
+			nested = append(nested, o.Dir+"/")
+		}
+	}
+	var out []string
+	for _, f := range r.Files(skip...) {
+		if m.Dir != "." && !strings.HasPrefix(f, m.Dir+"/") {
+			continue
+		}
+		if !hasAnyPrefix(f, nested) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// indexFiles lists the repo's files, leaving out the dirs in prunedDirs,
+// which it returns with the position their files would have had.
+func indexFiles(root string) ([]string, []*prunedDir) {
+	return walkFiles(root, ".", true)
+}
+
+// walkFiles lists the regular files below the repo-relative dir, skipping
+// .git. With prune set it does not descend into dirs in prunedDirs and
+// returns them instead.
+func walkFiles(root, dir string, prune bool) ([]string, []*prunedDir) {
+	var files []string
+	var pruned []*prunedDir
+	_ = filepath.WalkDir(filepath.Join(root, dir), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if prune && prunedDirs[d.Name()] {
+				pruned = append(pruned, &prunedDir{rel: filepath.ToSlash(rel), at: len(files)})
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, pruned
+}
+
+// inDir reports whether the slash-separated path has a directory named dir
+// among its parents.
+func inDir(path, dir string) bool {
+	return strings.HasPrefix(path, dir+"/") || strings.Contains(path, "/"+dir+"/")
+}
+
+// inAnyDir reports whether the slash-separated path is below a directory
+// named in dirs.
+func inAnyDir(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if inDir(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRepoInfo_Files(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "app", map[string]string{
+		"go.mod":                "module m\n",
+		"internal/a.go":         "package internal\n",
+		".github/workflows/x":   "",
+		"vendor/dep/dep.go":     "",
+		"node_modules/x/i.js":   "",
+		"web/node_modules/y.js": "",
+		"crate/target/out.rs":   "",
+	}))
+	got := repo.Files("vendor", "node_modules")
+	want := []string{".github/workflows/x", "crate/target/out.rs", "go.mod", "internal/a.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Files(vendor, node_modules) = %v, want %v", got, want)
+	}
+	if got := repo.Files(); len(got) != 7 {
+		t.Errorf("Files() = %v, want every file but .git", got)
+	}
+}
+
+func TestRepoInfo_FilesPrunesDependencyDirs(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "app", map[string]string{
+		"a.go":                  "package a\n",
+		"node_modules/x/i.js":   "",
+		"target/debug/out":      "",
+		"target/vendor/dep.rs":  "",
+		"web/app.js":            "",
+		"web/node_modules/y.js": "",
+	}))
+	files, pruned := indexFiles(repo.Path)
+	if !slices.Equal(files, []string{"a.go", "web/app.js"}) {
+		t.Errorf("indexed files = %v", files)
+	}
+	var dirs []string
+	for _, p := range pruned {
+		dirs = append(dirs, p.rel)
+	}
+	if !slices.Equal(dirs, []string{"node_modules", "target", "web/node_modules"}) {
+		t.Errorf("pruned dirs = %v", dirs)
+	}
+
+	// dirs a check does not skip are listed on demand, in walk order
+	want := []string{"a.go", "target/debug/out", "web/app.js"}
+	if got := repo.Files("node_modules", "vendor"); !slices.Equal(got, want) {
+		t.Errorf("Files(node_modules, vendor) = %v, want %v", got, want)
+	}
+	want = []string{"a.go", "node_modules/x/i.js", "target/debug/out", "target/vendor/dep.rs", "web/app.js", "web/node_modules/y.js"}
+	if got := repo.Files(); !slices.Equal(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestRepoInfo_ModuleFiles(t *testing.T) {
+	repo := DetectRepo(makeRepo(t, "mono", map[string]string{
+		"go.mod":           "module m\n",
+		"main.go":          "package main\n",
+		"svc/go.mod":       "module m/svc\n",
+		"svc/svc.go":       "package svc\n",
+		"svcx/x.go":        "package svcx\n",
+		"web/package.json": "{}",
+	}))
+	modules := repo.ModulesOf(LangGo)
+	if got := repo.moduleFiles(modules[0]); !slices.Equal(got, []string{"go.mod", "main.go", "svcx/x.go", "web/package.json"}) {
+		t.Errorf("root module files = %v", got)
+	}
+	if got := repo.moduleFiles(modules[1]); !slices.Equal(got, []string{"svc/go.mod", "svc/svc.go"}) {
+		t.Errorf("svc module files = %v", got)
+	}
+}
+
+func TestInDir(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"testdata/a.go", true},
+		{"pkg/testdata/a.go", true},
+		{"pkg/testdatax/a.go", false},
+		{"testdata.go", false},
+	}
+	for _, tt := range tests {
+		if got := inDir(tt.path, "testdata"); got != tt.want {
+			t.Errorf("inDir(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// --- JUnit Formatter ---
+
+// JUnitFormatter writes scan results as JUnit XML: one test suite per repo,
+// one failing test case per finding. Baselined and suppressed findings are
+// reported as skipped, and a repo without findings gets a single passing case.
+type JUnitFormatter struct{}
+
+func NewJUnitFormatter() *JUnitFormatter { return &JUnitFormatter{} }
+
+func (f *JUnitFormatter) Format(w io.Writer, result *ScanResult) error {
+	byRepo := groupByRepo(result.Findings)
+	out := junitTestSuites{Name: "tokencontrol scan"}
+
+	for _, repo := range result.ReposScanned {
+		suite := junitTestSuite{Name: repo}
+		findings := byRepo[repo]
+		perCheck := make(map[string]int)
+		for _, finding := range findings {
+			perCheck[finding.Check]++
+		}
+
+		for _, finding := range findings {
+			tc := junitTestCase{
+				Name:      finding.Check,
+				ClassName: repo + "." + finding.Category,
+				File:      finding.Location,
+				Line:      finding.Line,
+			}
+			// keep names unique when a check reports several locations
+			if perCheck[finding.Check] > 1 && finding.Location != "" {
+				tc.Name = fmt.Sprintf("%s (%s)", finding.Check, finding.Location)
+			}
+			if finding.Status != "" {
+				tc.Skipped = &junitSkipped{Message: finding.Status + ": " + finding.Message}
+				suite.Skipped++
+			} else {
+				tc.Failure = &junitFailure{
+					Message: finding.Message,
+					Type:    finding.Severity.String(),
+					Text:    junitDetail(&finding),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: "scan", ClassName: repo})
+		}
+		suite.Tests = len(suite.Cases)
+
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		out.Skipped += suite.Skipped
+		out.Suites = append(out.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitDetail is the failure body: location and suggestion.
+func junitDetail(f *Finding) string {
+	var b strings.Builder
+	if f.Location != "" {
+		b.WriteString(f.Location)
+		if f.Line > 0 {
+			fmt.Fprintf(&b, ":%d", f.Line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(f.Suggestion)
+	return b.String()
+}
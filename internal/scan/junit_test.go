@@ -0,0 +1,54 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestJUnitFormatter(t *testing.T) {
+	result := sampleResult()
+	result.ReposScanned = append(result.ReposScanned, "clean")
+	result.Findings = append(result.Findings,
+		Finding{Repo: "app2", Check: "sec-hardcoded-token", Category: "security", Severity: SeverityCritical,
+			Message: "Possible hardcoded secret", Suggestion: "Move the secret", Location: "main.go", Line: 2},
+		Finding{Repo: "app2", Check: "sec-hardcoded-token", Category: "security", Severity: SeverityCritical,
+			Message: "Possible hardcoded secret", Location: "cfg.go", Line: 9, Status: StatusSuppressed},
+	)
+
+	var buf bytes.Buffer
+	if err := NewJUnitFormatter().Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Error("missing XML header")
+	}
+	var out junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if out.Tests != 7 || out.Failures != 5 || out.Skipped != 1 || len(out.Suites) != 3 {
+		t.Fatalf("totals = %d tests, %d failures, %d skipped, %d suites", out.Tests, out.Failures, out.Skipped, len(out.Suites))
+	}
+
+	app2 := out.Suites[1]
+	if app2.Name != "app2" || app2.Tests != 4 || app2.Failures != 3 || app2.Skipped != 1 {
+		t.Fatalf("app2 suite = %+v", app2)
+	}
+	tc := app2.Cases[2]
+	if tc.Name != "sec-hardcoded-token (main.go)" || tc.ClassName != "app2.security" || tc.File != "main.go" || tc.Line != 2 {
+		t.Errorf("testcase = %+v", tc)
+	}
+	if tc.Failure == nil || tc.Failure.Type != "critical" || !strings.Contains(tc.Failure.Text, "main.go:2\nMove the secret") {
+		t.Errorf("failure = %+v", tc.Failure)
+	}
+	if app2.Cases[3].Skipped == nil || app2.Cases[3].Failure != nil {
+		t.Errorf("suppressed finding not skipped: %+v", app2.Cases[3])
+	}
+
+	clean := out.Suites[2]
+	if clean.Tests != 1 || clean.Failures != 0 || clean.Cases[0].Failure != nil {
+		t.Errorf("clean suite = %+v", clean)
+	}
+}
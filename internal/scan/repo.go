@@ -1,8 +1,12 @@
 package scan
 
 import (
+	"encoding/json"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // Language represents the detected primary language of a repo.
@@ -12,7 +16,10 @@ const (
 	LangUnknown Language = iota
 	LangGo
 	LangPython
-	LangMulti
+	LangMulti // more than one language; see RepoInfo.Uses
+	LangNode
+	LangRust
+	LangJava
 )
 
 func (l Language) String() string {
@@ -23,11 +30,76 @@ func (l Language) String() string {
 		return "python"
 	case LangMulti:
 		return "multi"
+	case LangNode:
+		return "node"
+	case LangRust:
+		return "rust"
+	case LangJava:
+		return "java"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseLanguage converts a name to a single Language. Returns LangUnknown if
+// unrecognized.
+func ParseLanguage(s string) Language {
+	switch strings.ToLower(s) {
+	case "go":
+		return LangGo
+	case "python":
+		return LangPython
+	case "node", "javascript", "typescript":
+		return LangNode
+	case "rust":
+		return LangRust
+	case "java":
+		return LangJava
+	default:
+		return LangUnknown
+	}
+}
+
+// moduleManifests maps manifest files to the language of the module they
+// declare, in detection order.
+var moduleManifests = []struct {
+	file string
+	lang Language
+}{
+	{"go.mod", LangGo},
+	{"pyproject.toml", LangPython},
+	{"setup.py", LangPython},
+	{"requirements.txt", LangPython},
+	{"package.json", LangNode},
+	{"Cargo.toml", LangRust},
+	{"pom.xml", LangJava},
+	{"build.gradle", LangJava},
+	{"build.gradle.kts", LangJava},
+}
+
+// maxModuleDepth bounds how deep DetectRepo looks for nested modules.
+const maxModuleDepth = 4
+
+// skipModuleDirs are never searched for nested modules.
+var skipModuleDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "testdata": true, "target": true,
+	"dist": true, "build": true, "venv": true, "__pycache__": true,
+}
+
+// ModuleRoot is a module or package found in a repo: a directory with a
+// manifest such as go.mod, package.json or Cargo.toml.
+type ModuleRoot struct {
+	Language Language
+	Dir      string // repo-relative, "." for the repo root
+	Manifest string // manifest file name, e.g. go.mod
+	Name     string // declared module or package name, if any
+}
+
+// File returns the repo-relative path of name inside the module dir.
+func (m ModuleRoot) File(name string) string {
+	return path.Join(m.Dir, name)
+}
+
 // RepoInfo holds metadata about a scanned repository.
 type RepoInfo struct {
 	Name     string
@@ -35,6 +107,59 @@ type RepoInfo struct {
 	Language Language
 	HasCmd   bool
 	HasDocs  bool
+	Module   string   // Go module path from go.mod; empty if none
+	Binaries []string // directories under cmd/, sorted
+
+	Modules       []ModuleRoot // every module found, the repo root's first
+	BuildTool     string       // make, npm, pnpm, yarn, cargo, gradle, maven, go, pip; "" if unknown
+	TestFramework string       // go test, pytest, jest, vitest, mocha, node:test, cargo test, junit; "" if unknown
+	CIProvider    string       // github-actions, gitlab-ci, circleci, jenkins, azure-pipelines, bitbucket; "" if none
+
+	index *fileIndex // see Files
+}
+
+// Uses reports whether the repo has code in language l, at the root or in
+// a nested module.
+func (r *RepoInfo) Uses(l Language) bool {
+	if r.Language == l {
+		return true
+	}
+	for _, m := range r.Modules {
+		if m.Language == l {
+			return true
+		}
+	}
+	return false
+}
+
+// ModulesOf returns the modules in language l, the repo root's first.
+func (r *RepoInfo) ModulesOf(l Language) []ModuleRoot {
+	var out []ModuleRoot
+	for _, m := range r.Modules {
+		if m.Language == l {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// topModulesOf returns the modules in language l that are not inside
+// another module of l, such as workspace roots.
+func (r *RepoInfo) topModulesOf(l Language) []ModuleRoot {
+	var out []ModuleRoot
+	for _, m := range r.ModulesOf(l) {
+		top := true
+		for _, o := range out {
+			if o.Dir == "." || strings.HasPrefix(m.Dir, o.Dir+"/") {
+				top = false
+				break
+			}
+		}
+		if top {
+			out = append(out, m)
+		}
+	}
+	return out
 }
 
 // DetectRepo examines a directory and returns RepoInfo.
@@ -45,30 +170,250 @@ func DetectRepo(path string) *RepoInfo {
 	}
 
 	info := &RepoInfo{
-		Name: filepath.Base(path),
-		Path: path,
+		Name:  filepath.Base(path),
+		Path:  path,
+		index: &fileIndex{},
 	}
 
-	hasGo := fileExists(filepath.Join(path, "go.mod"))
-	hasPy := fileExists(filepath.Join(path, "pyproject.toml")) || fileExists(filepath.Join(path, "setup.py"))
-
-	switch {
-	case hasGo && hasPy:
-		info.Language = LangMulti
-	case hasGo:
-		info.Language = LangGo
-	case hasPy:
-		info.Language = LangPython
-	default:
+	info.Modules = findModules(path)
+	langs := make(map[Language]bool)
+	for _, m := range info.Modules {
+		langs[m.Language] = true
+	}
+	switch len(langs) {
+	case 0:
 		info.Language = LangUnknown
+	case 1:
+		info.Language = info.Modules[0].Language
+	default:
+		info.Language = LangMulti
 	}
 
 	info.HasCmd = isDir(filepath.Join(path, "cmd"))
 	info.HasDocs = isDir(filepath.Join(path, "docs"))
+	if goMods := info.ModulesOf(LangGo); len(goMods) > 0 {
+		info.Module = goMods[0].Name
+	}
+	if info.HasCmd {
+		info.Binaries = cmdBinaries(path)
+	}
+	info.BuildTool = detectBuildTool(info)
+	info.TestFramework = detectTestFramework(info)
+	info.CIProvider = detectCIProvider(path)
 
 	return info
 }
 
+// findModules walks the repo up to maxModuleDepth for manifests. A Python
+// requirements.txt only counts at the repo root, without pyproject.toml or
+// setup.py.
+func findModules(root string) []ModuleRoot {
+	var mods []ModuleRoot
+	_ = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if rel != "." {
+			if strings.HasPrefix(d.Name(), ".") || skipModuleDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			if strings.Count(rel, "/")+1 > maxModuleDepth {
+				return filepath.SkipDir
+			}
+		}
+		found := make(map[Language]bool)
+		for _, mf := range moduleManifests {
+			if found[mf.lang] || !fileExists(filepath.Join(p, mf.file)) {
+				continue
+			}
+			// nested requirements files are usually tooling (docs, scripts)
+			if mf.file == "requirements.txt" && rel != "." {
+				continue
+			}
+			found[mf.lang] = true
+			mods = append(mods, ModuleRoot{
+				Language: mf.lang,
+				Dir:      rel,
+				Manifest: mf.file,
+				Name:     moduleName(filepath.Join(p, mf.file)),
+			})
+		}
+		return nil
+	})
+	// WalkDir visits the root first; keep that and order the rest by dir
+	sort.SliceStable(mods, func(i, j int) bool {
+		if (mods[i].Dir == ".") != (mods[j].Dir == ".") {
+			return mods[i].Dir == "."
+		}
+		return mods[i].Dir < mods[j].Dir
+	})
+	return mods
+}
+
+// moduleName returns the name declared in a manifest, or "".
+func moduleName(manifest string) string {
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return ""
+	}
+	switch filepath.Base(manifest) {
+	case "go.mod":
+		for _, line := range strings.Split(string(data), "\n") {
+			if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+				return strings.Trim(strings.TrimSpace(rest), `"`)
+			}
+		}
+	case "package.json":
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			return pkg.Name
+		}
+	case "Cargo.toml", "pyproject.toml":
+		// first name = "..." line; [package] and [project] come first in practice
+		for _, line := range strings.Split(string(data), "\n") {
+			if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "name"); ok {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(rest), "="); ok {
+					return strings.Trim(strings.TrimSpace(rest), `"'`)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// detectBuildTool picks the build tool at the repo root, falling back to the
+// first module's.
+func detectBuildTool(r *RepoInfo) string {
+	if fileExists(filepath.Join(r.Path, "Makefile")) {
+		return "make"
+	}
+	dirs := []string{"."}
+	for _, m := range r.Modules {
+		dirs = append(dirs, m.Dir)
+	}
+	for _, dir := range dirs {
+		abs := filepath.Join(r.Path, filepath.FromSlash(dir))
+		switch {
+		case fileExists(filepath.Join(abs, "package.json")):
+			return nodePackageManager(r.Path, abs)
+		case fileExists(filepath.Join(abs, "Cargo.toml")):
+			return "cargo"
+		case fileExists(filepath.Join(abs, "build.gradle")), fileExists(filepath.Join(abs, "build.gradle.kts")):
+			return "gradle"
+		case fileExists(filepath.Join(abs, "pom.xml")):
+			return "maven"
+		case fileExists(filepath.Join(abs, "go.mod")):
+			return "go"
+		case fileExists(filepath.Join(abs, "pyproject.toml")), fileExists(filepath.Join(abs, "setup.py")), fileExists(filepath.Join(abs, "requirements.txt")):
+			return "pip"
+		}
+	}
+	return ""
+}
+
+// nodePackageManager returns npm, pnpm or yarn from the lockfile in dir or
+// any parent up to the repo root.
+func nodePackageManager(root, dir string) string {
+	for {
+		switch {
+		case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+			return "pnpm"
+		case fileExists(filepath.Join(dir, "yarn.lock")):
+			return "yarn"
+		case fileExists(filepath.Join(dir, "package-lock.json")):
+			return "npm"
+		}
+		if dir == root || !strings.HasPrefix(dir, root) {
+			return "npm"
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// detectTestFramework returns the test framework of the first module.
+func detectTestFramework(r *RepoInfo) string {
+	if len(r.Modules) == 0 {
+		return ""
+	}
+	m := r.Modules[0]
+	dir := filepath.Join(r.Path, filepath.FromSlash(m.Dir))
+	switch m.Language {
+	case LangGo:
+		return "go test"
+	case LangRust:
+		return "cargo test"
+	case LangPython:
+		if fileExists(filepath.Join(dir, "conftest.py")) || fileExists(filepath.Join(dir, "pytest.ini")) ||
+			fileContains(dir, []string{"pyproject.toml", "setup.cfg", "requirements.txt", "requirements-dev.txt", "tox.ini"}, "pytest") {
+			return "pytest"
+		}
+		return "unittest"
+	case LangNode:
+		if pkg, _, ok := readPackageJSON(r, m); ok {
+			return pkg.testFramework()
+		}
+	case LangJava:
+		if fileContains(dir, []string{"pom.xml", "build.gradle", "build.gradle.kts"}, "junit") {
+			return "junit"
+		}
+		if fileContains(dir, []string{"pom.xml", "build.gradle", "build.gradle.kts"}, "testng") {
+			return "testng"
+		}
+	}
+	return ""
+}
+
+// fileContains reports whether any of the named files in dir contains substr.
+func fileContains(dir string, names []string, substr string) bool {
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil && strings.Contains(string(data), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectCIProvider returns the CI system configured at the repo root.
+func detectCIProvider(root string) string {
+	switch {
+	case isDir(filepath.Join(root, ".github", "workflows")):
+		return "github-actions"
+	case fileExists(filepath.Join(root, ".gitlab-ci.yml")):
+		return "gitlab-ci"
+	case fileExists(filepath.Join(root, ".circleci", "config.yml")):
+		return "circleci"
+	case fileExists(filepath.Join(root, "Jenkinsfile")):
+		return "jenkins"
+	case fileExists(filepath.Join(root, "azure-pipelines.yml")):
+		return "azure-pipelines"
+	case fileExists(filepath.Join(root, "bitbucket-pipelines.yml")):
+		return "bitbucket"
+	default:
+		return ""
+	}
+}
+
+// cmdBinaries returns the names of the directories under cmd/.
+func cmdBinaries(root string) []string {
+	entries, err := os.ReadDir(filepath.Join(root, "cmd"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func fileExists(path string) bool {
 	fi, err := os.Stat(path)
 	return err == nil && !fi.IsDir()
@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Language
+	}{
+		{"go", LangGo},
+		{"Python", LangPython},
+		{"node", LangNode},
+		{"typescript", LangNode},
+		{"rust", LangRust},
+		{"java", LangJava},
+		{"cobol", LangUnknown},
+	}
+	for _, tt := range tests {
+		if got := ParseLanguage(tt.in); got != tt.want {
+			t.Errorf("ParseLanguage(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDetectRepo_Ecosystems(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		lang      Language
+		buildTool string
+		framework string
+	}{
+		{"node", map[string]string{
+			"package.json":   `{"name":"web","devDependencies":{"vitest":"^1.0.0"}}`,
+			"pnpm-lock.yaml": "lockfileVersion: 9\n",
+		}, LangNode, "pnpm", "vitest"},
+		{"rust", map[string]string{
+			"Cargo.toml": "[package]\nname = \"crab\"\n",
+		}, LangRust, "cargo", "cargo test"},
+		{"maven", map[string]string{
+			"pom.xml": "<project><dependency>junit</dependency></project>",
+		}, LangJava, "maven", "junit"},
+		{"gradle", map[string]string{
+			"build.gradle.kts": "plugins { java }\n",
+		}, LangJava, "gradle", ""},
+		{"python", map[string]string{
+			"requirements.txt": "pytest\n",
+		}, LangPython, "pip", "pytest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := DetectRepo(makeRepo(t, tt.name, tt.files))
+			if info.Language != tt.lang {
+				t.Errorf("Language = %v, want %v", info.Language, tt.lang)
+			}
+			if info.BuildTool != tt.buildTool {
+				t.Errorf("BuildTool = %q, want %q", info.BuildTool, tt.buildTool)
+			}
+			if info.TestFramework != tt.framework {
+				t.Errorf("TestFramework = %q, want %q", info.TestFramework, tt.framework)
+			}
+		})
+	}
+}
+
+func TestDetectRepo_Monorepo(t *testing.T) {
+	dir := makeRepo(t, "mono", map[string]string{
+		"go.mod":                    "module github.com/acme/mono\n\ngo 1.24\n",
+		"Makefile":                  "test:\n\tgo test ./...\n",
+		"svc/api/go.mod":            "module github.com/acme/mono/svc/api\n\ngo 1.24\n",
+		"web/package.json":          `{"name":"@acme/web"}`,
+		"web/node_modules/x/go.mod": "module x\n",
+		".gitlab-ci.yml":            "test:\n  script: make test\n",
+		"docs/requirements.txt":     "mkdocs\n",
+	})
+	info := DetectRepo(dir)
+
+	if info.Language != LangMulti {
+		t.Errorf("Language = %v, want LangMulti", info.Language)
+	}
+	want := []ModuleRoot{
+		{Language: LangGo, Dir: ".", Manifest: "go.mod", Name: "github.com/acme/mono"},
+		{Language: LangGo, Dir: "svc/api", Manifest: "go.mod", Name: "github.com/acme/mono/svc/api"},
+		{Language: LangNode, Dir: "web", Manifest: "package.json", Name: "@acme/web"},
+	}
+	if !reflect.DeepEqual(info.Modules, want) {
+		t.Errorf("Modules = %+v, want %+v", info.Modules, want)
+	}
+	if info.Module != "github.com/acme/mono" {
+		t.Errorf("Module = %q", info.Module)
+	}
+	if info.BuildTool != "make" || info.TestFramework != "go test" || info.CIProvider != "gitlab-ci" {
+		t.Errorf("BuildTool/TestFramework/CIProvider = %q/%q/%q", info.BuildTool, info.TestFramework, info.CIProvider)
+	}
+	if !info.Uses(LangGo) || !info.Uses(LangNode) || info.Uses(LangPython) {
+		t.Error("Uses should report Go and Node only")
+	}
+	if got := info.ModulesOf(LangGo); len(got) != 2 {
+		t.Errorf("ModulesOf(go) = %d modules, want 2", len(got))
+	}
+	if got := info.topModulesOf(LangGo); len(got) != 1 || got[0].Dir != "." {
+		t.Errorf("topModulesOf(go) = %+v, want the root module", got)
+	}
+}
+
+func TestDetectRepo_CIProvider(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{".github/workflows/ci.yml", "github-actions"},
+		{".circleci/config.yml", "circleci"},
+		{"Jenkinsfile", "jenkins"},
+		{"README.md", ""},
+	}
+	for _, tt := range tests {
+		info := DetectRepo(makeRepo(t, "ci", map[string]string{tt.file: "x\n"}))
+		if info.CIProvider != tt.want {
+			t.Errorf("%s: CIProvider = %q, want %q", tt.file, info.CIProvider, tt.want)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+// Rule kinds supported by custom rule packs.
+const (
+	RuleFileExists   = "file-exists"   // finding when none of the paths exist
+	RuleFileAbsent   = "file-absent"   // finding when any of the paths exist
+	RuleFileContains = "file-contains" // finding when no file matches pattern (negate: when any does)
+	RuleGlobCount    = "glob-count"    // finding when the number of matches is outside min/max
+	RuleYAMLPath     = "yaml-path"     // finding when key is missing or its value doesn't match
+	RuleJSONPath     = "json-path"     // same as yaml-path for JSON files
+	RuleCommand      = "command"       // finding when the command exits with another code
+)
+
+// defaultRuleTimeout bounds command rules without an explicit timeout.
+const defaultRuleTimeout = 30 * time.Second
+
+// maxRuleOutput caps the command output kept for prompts.
+const maxRuleOutput = 2000
+
+// Rule is a declarative scan check loaded from .tokencontrol.yml or a rule
+// pack file. Paths are relative to the repo root; a path containing glob
+// characters is matched against every file in the repo ("**" spans
+// directories, a pattern without "/" matches base names at any depth).
+// Message and Prompt are text/template strings executed with a RuleContext.
+type Rule struct {
+	ID         string `yaml:"id"`
+	Kind       string `yaml:"kind"`
+	Category   string `yaml:"category,omitempty"` // default "custom"
+	Severity   string `yaml:"severity,omitempty"` // default warning
+	Language   string `yaml:"language,omitempty"` // go, python, node, rust or java; empty = all repos
+	Message    string `yaml:"message"`
+	Suggestion string `yaml:"suggestion,omitempty"`
+	Prompt     string `yaml:"prompt,omitempty"` // empty = built from message and suggestion
+
+	Path    string   `yaml:"path,omitempty"`
+	Paths   []string `yaml:"paths,omitempty"`   // alternatives; file-exists passes when any exists
+	Pattern string   `yaml:"pattern,omitempty"` // regexp for file-contains, yaml-path, json-path
+	Negate  bool     `yaml:"negate,omitempty"`  // invert file-contains, yaml-path, json-path
+
+	Min *int `yaml:"min,omitempty"` // glob-count bounds
+	Max *int `yaml:"max,omitempty"`
+
+	Key    string  `yaml:"key,omitempty"`    // dotted path, list indexes as numbers: jobs.test.steps.0.run
+	Equals *string `yaml:"equals,omitempty"` // expected value, compared as text
+
+	Command  string        `yaml:"command,omitempty"` // run with sh -c in the repo dir
+	ExitCode int           `yaml:"exit_code,omitempty"`
+	Timeout  time.Duration `yaml:"timeout,omitempty"`
+}
+
+// RulePack is the format of a rule pack file.
+type RulePack struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// RuleContext is the data available to Message and Prompt templates.
+type RuleContext struct {
+	Repo    *RepoInfo
+	Rule    *Rule
+	Matches []string // files that matched, repo-relative
+	File    string   // file-absent and negated file-contains: the file of this finding
+	Detail  string   // what the rule observed, e.g. "3 files (max 1)"
+	Output  string   // command rules: combined output, capped
+}
+
+// LoadRuleDir reads every *.yml and *.yaml rule pack in dir, in name order.
+// A missing directory yields no rules.
+func LoadRuleDir(dir string) ([]Rule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read rules dir: %w", err)
+	}
+	var rules []Rule
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read rule pack: %w", err)
+		}
+		var pack RulePack
+		if err := yaml.Unmarshal(data, &pack); err != nil {
+			return nil, fmt.Errorf("parse rule pack %s: %w", path, err)
+		}
+		rules = append(rules, pack.Rules...)
+	}
+	return rules, nil
+}
+
+// CompileRules validates rules and returns them as checkers. Rule IDs must
+// be unique and must not shadow a built-in check.
+func CompileRules(rules []Rule) ([]Checker, error) {
+	seen := make(map[string]bool)
+	for _, c := range AllCheckers() {
+		seen[c.ID()] = true
+	}
+	checkers := make([]Checker, 0, len(rules))
+	for i := range rules {
+		c, err := compileRule(rules[i])
+		if err != nil {
+			name := rules[i].ID
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		if seen[c.rule.ID] {
+			return nil, fmt.Errorf("rule %s: duplicate check id", c.rule.ID)
+		}
+		seen[c.rule.ID] = true
+		checkers = append(checkers, c)
+	}
+	return checkers, nil
+}
+
+// ruleCheck runs a compiled Rule.
+type ruleCheck struct {
+	rule    Rule
+	sev     Severity
+	lang    Language
+	paths   []string
+	pattern *regexp.Regexp
+	msg     *template.Template
+	prompt  *template.Template // nil = default prompt
+}
+
+func compileRule(r Rule) (*ruleCheck, error) {
+	if r.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if r.Message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if r.Category == "" {
+		r.Category = "custom"
+	}
+	c := &ruleCheck{rule: r, sev: SeverityWarning, paths: r.Paths}
+	if r.Path != "" {
+		c.paths = append([]string{r.Path}, c.paths...)
+	}
+	if r.Severity != "" {
+		if c.sev = ParseSeverity(r.Severity); c.sev == 0 {
+			return nil, fmt.Errorf("unknown severity %q", r.Severity)
+		}
+	}
+	if r.Language != "" {
+		if c.lang = ParseLanguage(r.Language); c.lang == LangUnknown {
+			return nil, fmt.Errorf("unknown language %q (want go, python, node, rust or java)", r.Language)
+		}
+	}
+
+	needPattern := false
+	switch r.Kind {
+	case RuleFileExists, RuleFileAbsent, RuleGlobCount:
+	case RuleFileContains:
+		needPattern = true
+	case RuleYAMLPath, RuleJSONPath:
+		if r.Key == "" {
+			return nil, fmt.Errorf("%s needs key", r.Kind)
+		}
+	case RuleCommand:
+		if r.Command == "" {
+			return nil, fmt.Errorf("command needs command")
+		}
+	default:
+		return nil, fmt.Errorf("unknown kind %q", r.Kind)
+	}
+	if r.Kind != RuleCommand && len(c.paths) == 0 {
+		return nil, fmt.Errorf("%s needs path or paths", r.Kind)
+	}
+	if r.Kind == RuleGlobCount && r.Min == nil && r.Max == nil {
+		return nil, fmt.Errorf("glob-count needs min or max")
+	}
+	if r.Negate && r.Kind != RuleFileContains && r.Kind != RuleYAMLPath && r.Kind != RuleJSONPath {
+		return nil, fmt.Errorf("negate is not supported by %s", r.Kind)
+	}
+	if needPattern && r.Pattern == "" {
+		return nil, fmt.Errorf("%s needs pattern", r.Kind)
+	}
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+		c.pattern = re
+	}
+
+	funcs := template.FuncMap{"join": strings.Join}
+	msg, err := template.New(r.ID).Funcs(funcs).Parse(r.Message)
+	if err != nil {
+		return nil, fmt.Errorf("message: %w", err)
+	}
+	c.msg = msg
+	if r.Prompt != "" {
+		if c.prompt, err = template.New(r.ID).Funcs(funcs).Parse(r.Prompt); err != nil {
+			return nil, fmt.Errorf("prompt: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *ruleCheck) ID() string       { return c.rule.ID }
+func (c *ruleCheck) Category() string { return c.rule.Category }
+func (c *ruleCheck) Applies(r *RepoInfo) bool {
+	if c.lang == LangUnknown {
+		return true
+	}
+	return r.Uses(c.lang)
+}
+
+// volatile reports whether the rule runs a command, whose outcome the scan
+// cache can't key on.
+func (c *ruleCheck) volatile() bool { return c.rule.Kind == RuleCommand }
+
+func (c *ruleCheck) Run(r *RepoInfo) []Finding {
+	ctx := &RuleContext{Repo: r, Rule: &c.rule}
+	if !c.evaluate(ctx) {
+		return nil
+	}
+	if !c.perFile() {
+		return []Finding{c.finding(ctx)}
+	}
+	// one finding per offending file, so each has its own location and
+	// fingerprint and a new offending file is a new finding
+	findings := make([]Finding, 0, len(ctx.Matches))
+	for _, file := range ctx.Matches {
+		fc := *ctx
+		fc.File = file
+		f := c.finding(&fc)
+		f.Location = file
+		if c.pattern != nil {
+			f.Line = matchLine(filepath.Join(r.Path, filepath.FromSlash(file)), c.pattern)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// perFile reports whether the rule reports each offending file separately.
+func (c *ruleCheck) perFile() bool {
+	return c.rule.Kind == RuleFileAbsent || (c.rule.Kind == RuleFileContains && c.rule.Negate)
+}
+
+func (c *ruleCheck) finding(ctx *RuleContext) Finding {
+	f := Finding{
+		Repo: ctx.Repo.Name, Check: c.rule.ID, Category: c.rule.Category,
+		Severity: c.sev, Message: c.render(c.msg, ctx), Suggestion: c.rule.Suggestion,
+	}
+	if c.prompt != nil {
+		f.Prompt = c.render(c.prompt, ctx)
+	} else {
+		f.Prompt = c.defaultPrompt(ctx, f.Message)
+	}
+	return f
+}
+
+// evaluate reports whether the rule is violated, filling in ctx.
+func (c *ruleCheck) evaluate(ctx *RuleContext) bool {
+	root := ctx.Repo.Path
+	switch c.rule.Kind {
+	case RuleFileExists:
+		if ctx.Matches = matchRepoFiles(ctx.Repo, c.paths); len(ctx.Matches) > 0 {
+			return false
+		}
+		ctx.Detail = "missing " + strings.Join(c.paths, " or ")
+		return true
+
+	case RuleFileAbsent:
+		ctx.Matches = matchRepoFiles(ctx.Repo, c.paths)
+		ctx.Detail = "found " + strings.Join(ctx.Matches, ", ")
+		return len(ctx.Matches) > 0
+
+	case RuleFileContains:
+		var matched []string
+		for _, rel := range matchRepoFiles(ctx.Repo, c.paths) {
+			data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+			if err == nil && c.pattern.Match(data) {
+				matched = append(matched, rel)
+			}
+		}
+		ctx.Matches = matched
+		if c.rule.Negate {
+			ctx.Detail = fmt.Sprintf("%s matched in %s", c.rule.Pattern, strings.Join(matched, ", "))
+			return len(matched) > 0
+		}
+		ctx.Detail = fmt.Sprintf("%s not found in %s", c.rule.Pattern, strings.Join(c.paths, ", "))
+		return len(matched) == 0
+
+	case RuleGlobCount:
+		ctx.Matches = matchRepoFiles(ctx.Repo, c.paths)
+		n := len(ctx.Matches)
+		switch {
+		case c.rule.Min != nil && n < *c.rule.Min:
+			ctx.Detail = fmt.Sprintf("%d files (min %d)", n, *c.rule.Min)
+			return true
+		case c.rule.Max != nil && n > *c.rule.Max:
+			ctx.Detail = fmt.Sprintf("%d files (max %d)", n, *c.rule.Max)
+			return true
+		}
+		return false
+
+	case RuleYAMLPath, RuleJSONPath:
+		ok := false
+		for _, rel := range matchRepoFiles(ctx.Repo, c.paths) {
+			ctx.Matches = append(ctx.Matches, rel)
+			if v, found := lookupKey(filepath.Join(root, filepath.FromSlash(rel)), c.rule.Kind, c.rule.Key); found && c.valueMatches(v) {
+				ok = true
+				break
+			}
+		}
+		if c.rule.Negate {
+			ctx.Detail = fmt.Sprintf("%s matched", c.rule.Key)
+			return ok
+		}
+		ctx.Detail = fmt.Sprintf("%s missing or not matching", c.rule.Key)
+		return !ok
+
+	case RuleCommand:
+		code, out := c.runCommand(root)
+		ctx.Output = out
+		if code == c.rule.ExitCode {
+			return false
+		}
+		ctx.Detail = fmt.Sprintf("exit code %d (want %d)", code, c.rule.ExitCode)
+		return true
+	}
+	return false
+}
+
+func (c *ruleCheck) valueMatches(v any) bool {
+	s := fmt.Sprint(v)
+	switch {
+	case c.rule.Equals != nil:
+		return s == *c.rule.Equals
+	case c.pattern != nil:
+		return c.pattern.MatchString(s)
+	}
+	return true
+}
+
+// runCommand returns the exit code of the rule's command, -1 when it could
+// not run or timed out, and its combined output.
+func (c *ruleCheck) runCommand(dir string) (int, string) {
+	timeout := c.rule.Timeout
+	if timeout <= 0 {
+		timeout = defaultRuleTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.rule.Command)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if len(output) > maxRuleOutput {
+		output = output[len(output)-maxRuleOutput:]
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, output
+	case ctx.Err() != nil:
+		return -1, strings.TrimSpace(output + "\ntimed out after " + timeout.String())
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode(), output
+	default:
+		return -1, strings.TrimSpace(output + "\n" + err.Error())
+	}
+}
+
+// render executes a template, falling back to the raw text on error.
+func (c *ruleCheck) render(t *template.Template, ctx *RuleContext) string {
+	var b bytes.Buffer
+	if err := t.Execute(&b, ctx); err != nil {
+		return fmt.Sprintf("%s (template error: %v)", t.Root.String(), err)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (c *ruleCheck) defaultPrompt(ctx *RuleContext, msg string) string {
+	p := newPrompt()
+	p.line(fmt.Sprintf("Fix the %s finding in %s: %s.", c.rule.ID, ctx.Repo.Name, msg))
+	if ctx.Detail != "" {
+		p.line("Observed: " + ctx.Detail)
+	}
+	if c.rule.Suggestion != "" {
+		p.blank()
+		p.line(c.rule.Suggestion)
+	}
+	if ctx.Output != "" {
+		p.blank()
+		p.line(fmt.Sprintf("Output of `%s`:", c.rule.Command))
+		p.line(ctx.Output)
+	}
+	p.blank()
+	p.verification(ctx.Repo)
+	p.constraints()
+	return p.String()
+}
+
+// matchRepoFiles returns the repo-relative files matching any of patterns,
+// sorted. Patterns without glob characters are looked up directly; globs
+// match against the repo's file index.
+func matchRepoFiles(r *RepoInfo, patterns []string) []string {
+	set := make(map[string]bool)
+	var globs []string
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?[") {
+			globs = append(globs, p)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(r.Path, filepath.FromSlash(p))); err == nil {
+			set[strings.TrimPrefix(p, "/")] = true
+		}
+	}
+	if len(globs) > 0 {
+		for _, rel := range r.Files("vendor", "node_modules") {
+			if task.MatchAnyPath(globs, rel) {
+				set[rel] = true
+			}
+		}
+	}
+	files := make([]string, 0, len(set))
+	for f := range set {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// lookupKey reads a YAML or JSON file and returns the value at a dotted key.
+func lookupKey(path, kind, key string) (any, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var doc any
+	if kind == RuleJSONPath {
+		err = json.Unmarshal(data, &doc)
+	} else {
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, false
+	}
+	for _, part := range strings.Split(key, ".") {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			doc = v
+		case map[any]any:
+			v, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			doc = v
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			doc = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// matchLine returns the 1-based line of the first match of re in the file,
+// or 0.
+func matchLine(path string, re *regexp.Regexp) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	loc := re.FindIndex(data)
+	if loc == nil {
+		return 0
+	}
+	return bytes.Count(data[:loc[0]], []byte("\n")) + 1
+}
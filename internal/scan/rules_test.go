@@ -0,0 +1,196 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func intPtr(n int) *int { return &n }
+
+func runRule(t *testing.T, r Rule, files map[string]string) []Finding {
+	t.Helper()
+	checkers, err := CompileRules([]Rule{r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := DetectRepo(makeRepo(t, "app", files))
+	if !checkers[0].Applies(repo) {
+		return nil
+	}
+	return checkers[0].Run(repo)
+}
+
+func TestRules_Kinds(t *testing.T) {
+	release := `(?m)^release:`
+	tests := []struct {
+		name  string
+		rule  Rule
+		files map[string]string
+		want  bool // finding expected
+	}{
+		{"exists any of paths", Rule{Kind: RuleFileExists, Paths: []string{"CODEOWNERS", ".github/CODEOWNERS"}},
+			map[string]string{".github/CODEOWNERS": "* @team"}, false},
+		{"exists missing", Rule{Kind: RuleFileExists, Paths: []string{"CODEOWNERS", ".github/CODEOWNERS"}},
+			nil, true},
+		{"absent glob", Rule{Kind: RuleFileAbsent, Path: "**/*.pem"},
+			map[string]string{"certs/dev.pem": "x"}, true},
+		{"absent clean", Rule{Kind: RuleFileAbsent, Path: "**/*.pem"},
+			map[string]string{"main.go": "package main"}, false},
+		{"contains missing target", Rule{Kind: RuleFileContains, Path: "Makefile", Pattern: release},
+			map[string]string{"Makefile": "build:\n\tgo build\n"}, true},
+		{"contains target", Rule{Kind: RuleFileContains, Path: "Makefile", Pattern: release},
+			map[string]string{"Makefile": "release:\n\tgoreleaser\n"}, false},
+		{"negated contains", Rule{Kind: RuleFileContains, Path: "go.mod", Pattern: `(?m)^replace\b`, Negate: true},
+			map[string]string{"go.mod": "module m\n\nreplace a => ../a\n"}, true},
+		{"negated contains no file", Rule{Kind: RuleFileContains, Path: "go.mod", Pattern: `(?m)^replace\b`, Negate: true},
+			nil, false},
+		{"glob count over max", Rule{Kind: RuleGlobCount, Path: "cmd/*/main.go", Max: intPtr(1)},
+			map[string]string{"cmd/a/main.go": "", "cmd/b/main.go": ""}, true},
+		{"glob count under min", Rule{Kind: RuleGlobCount, Path: "*_test.go", Min: intPtr(1)},
+			map[string]string{"main.go": ""}, true},
+		{"yaml path equals", Rule{Kind: RuleYAMLPath, Path: ".github/workflows/ci.yml", Key: "jobs.test.steps.0.uses", Pattern: `^actions/checkout@`},
+			map[string]string{".github/workflows/ci.yml": "jobs:\n  test:\n    steps:\n      - uses: actions/checkout@v4\n"}, false},
+		{"yaml path missing key", Rule{Kind: RuleYAMLPath, Path: ".github/workflows/ci.yml", Key: "jobs.lint"},
+			map[string]string{".github/workflows/ci.yml": "jobs:\n  test: {}\n"}, true},
+		{"json path negated", Rule{Kind: RuleJSONPath, Path: "package.json", Key: "private", Equals: new(string), Negate: true},
+			map[string]string{"package.json": `{"private": ""}`}, true},
+		{"command passes", Rule{Kind: RuleCommand, Command: "test -f go.mod"},
+			map[string]string{"go.mod": "module m\n"}, false},
+		{"command fails", Rule{Kind: RuleCommand, Command: "test -f go.mod"},
+			nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.ID = "org-rule"
+			tt.rule.Message = "rule failed"
+			findings := runRule(t, tt.rule, tt.files)
+			if got := len(findings) > 0; got != tt.want {
+				t.Fatalf("finding = %v, want %v (%+v)", got, tt.want, findings)
+			}
+		})
+	}
+}
+
+func TestRules_FindingFields(t *testing.T) {
+	findings := runRule(t, Rule{
+		ID:       "no-pem",
+		Kind:     RuleFileAbsent,
+		Path:     "**/*.pem",
+		Category: "security",
+		Severity: "critical",
+		Message:  "{{.File}} committed in {{.Repo.Name}} ({{len .Matches}} key files)",
+		Prompt:   "Remove {{join .Matches \", \"}} from {{.Repo.Name}} and rotate the keys.",
+	}, map[string]string{"a.pem": "", "certs/b.pem": ""})
+	if len(findings) != 2 {
+		t.Fatalf("findings = %d, want one per file", len(findings))
+	}
+	f := findings[1]
+	if f.Check != "no-pem" || f.Category != "security" || f.Severity != SeverityCritical || f.Repo != "app" || f.Location != "certs/b.pem" {
+		t.Errorf("finding = %+v", f)
+	}
+	if f.Message != "certs/b.pem committed in app (2 key files)" {
+		t.Errorf("Message = %q", f.Message)
+	}
+	if f.TaskPrompt() != "Remove a.pem, certs/b.pem from app and rotate the keys." {
+		t.Errorf("prompt = %q", f.TaskPrompt())
+	}
+
+	// negated contains points at the matching line
+	findings = runRule(t, Rule{
+		ID: "no-replace", Kind: RuleFileContains, Path: "go.mod", Pattern: `(?m)^replace\b`, Negate: true, Message: "replace in go.mod",
+	}, map[string]string{"go.mod": "module m\n\ngo 1.24\n\nreplace a => ../a\n"})
+	if len(findings) != 1 || findings[0].Location != "go.mod" || findings[0].Line != 5 {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestRules_DefaultPromptAndLanguage(t *testing.T) {
+	r := Rule{
+		ID: "release-target", Kind: RuleFileContains, Path: "Makefile", Pattern: `(?m)^release:`,
+		Language: "go", Message: "Makefile has no release target", Suggestion: "Add a release target that runs goreleaser.",
+	}
+	findings := runRule(t, r, map[string]string{"go.mod": "module m\n", "Makefile": "build:\n"})
+	if len(findings) != 1 {
+		t.Fatalf("findings = %d, want 1", len(findings))
+	}
+	p := findings[0].TaskPrompt()
+	for _, want := range []string{"Makefile has no release target", "Add a release target", "Verification:", "Constraints:"} {
+		if !strings.Contains(p, want) {
+			t.Errorf("prompt missing %q:\n%s", want, p)
+		}
+	}
+
+	// python repo: go rule does not apply
+	if findings := runRule(t, r, map[string]string{"pyproject.toml": "", "Makefile": "build:\n"}); len(findings) != 0 {
+		t.Errorf("findings = %+v, want none for python repo", findings)
+	}
+}
+
+func TestCompileRules_Errors(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{Kind: RuleFileExists, Path: "x", Message: "m"}, "id is required"},
+		{Rule{ID: "r", Kind: "file-exist", Path: "x", Message: "m"}, `unknown kind "file-exist"`},
+		{Rule{ID: "r", Kind: RuleFileExists, Message: "m"}, "needs path"},
+		{Rule{ID: "r", Kind: RuleFileContains, Path: "x", Message: "m"}, "needs pattern"},
+		{Rule{ID: "r", Kind: RuleFileContains, Path: "x", Pattern: "(", Message: "m"}, "pattern:"},
+		{Rule{ID: "r", Kind: RuleGlobCount, Path: "*.go", Message: "m"}, "min or max"},
+		{Rule{ID: "r", Kind: RuleFileExists, Path: "x", Negate: true, Message: "m"}, "negate"},
+		{Rule{ID: "r", Kind: RuleFileExists, Path: "x", Severity: "high", Message: "m"}, `unknown severity "high"`},
+		{Rule{ID: "r", Kind: RuleFileExists, Path: "x", Message: "{{.Nope"}, "message:"},
+		{Rule{ID: "missing-readme", Kind: RuleFileExists, Path: "x", Message: "m"}, "duplicate check id"},
+	}
+	for _, tt := range tests {
+		_, err := CompileRules([]Rule{tt.rule})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("rule %+v: err = %v, want %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestLoadRuleDir(t *testing.T) {
+	dir := t.TempDir()
+	pack := "rules:\n  - id: codeowners\n    kind: file-exists\n    paths: [CODEOWNERS, .github/CODEOWNERS]\n    message: No CODEOWNERS\n"
+	if err := os.WriteFile(filepath.Join(dir, "org.yml"), []byte(pack), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a pack"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRuleDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].ID != "codeowners" || len(rules[0].Paths) != 2 {
+		t.Fatalf("rules = %+v", rules)
+	}
+
+	if rules, err := LoadRuleDir(filepath.Join(dir, "missing")); err != nil || rules != nil {
+		t.Errorf("missing dir: rules = %v, err = %v", rules, err)
+	}
+}
+
+func TestScan_CustomRules(t *testing.T) {
+	base := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(base, "app", ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rule := Rule{ID: "codeowners", Kind: RuleFileExists, Path: "CODEOWNERS", Category: "org", Message: "No CODEOWNERS"}
+
+	result, err := Scan(ScanOptions{ReposDir: base, Categories: []string{"org"}, Rules: []Rule{rule}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Check != "codeowners" {
+		t.Fatalf("findings = %+v", result.Findings)
+	}
+
+	rule.Kind = "bogus"
+	if _, err := Scan(ScanOptions{ReposDir: base, Rules: []Rule{rule}}); err == nil {
+		t.Error("expected error for invalid rule")
+	}
+}
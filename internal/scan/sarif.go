@@ -0,0 +1,175 @@
+package scan
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+)
+
+const (
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/main/sarif-2.1/schema/sarif-schema-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// sarifFingerprintKey names the finding fingerprint in partialFingerprints.
+	sarifFingerprintKey = "tokencontrol/v1"
+)
+
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	Help                 sarifMessage       `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifProperties    `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Category string `json:"category"`
+	Severity string `json:"severity"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	BaselineState       string             `json:"baselineState,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+// --- SARIF Formatter ---
+
+// SARIFFormatter writes scan results as a SARIF 2.1.0 log. Each check becomes
+// a rule; artifact URIs are relative to the repos dir ("<repo>/<location>").
+type SARIFFormatter struct{}
+
+func NewSARIFFormatter() *SARIFFormatter { return &SARIFFormatter{} }
+
+func (f *SARIFFormatter) Format(w io.Writer, result *ScanResult) error {
+	rules := []sarifRule{}
+	ruleIndex := make(map[string]int)
+	results := []sarifResult{}
+
+	for _, finding := range result.Findings {
+		idx, ok := ruleIndex[finding.Check]
+		if !ok {
+			idx = len(rules)
+			ruleIndex[finding.Check] = idx
+			rules = append(rules, sarifRule{
+				ID:                   finding.Check,
+				ShortDescription:     sarifMessage{Text: finding.Message},
+				Help:                 sarifMessage{Text: finding.Suggestion},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity)},
+				Properties:           sarifProperties{Category: finding.Category, Severity: finding.Severity.String()},
+			})
+		}
+
+		loc := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: finding.Repo + "/"},
+		}
+		if finding.Location != "" {
+			loc.ArtifactLocation.URI = path.Join(finding.Repo, finding.Location)
+		}
+		if finding.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: finding.Line}
+		}
+
+		sr := sarifResult{
+			RuleID:    finding.Check,
+			RuleIndex: idx,
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		}
+		if finding.Fingerprint != "" {
+			sr.PartialFingerprints = map[string]string{sarifFingerprintKey: finding.Fingerprint}
+		}
+		switch finding.Status {
+		case StatusBaselined:
+			sr.BaselineState = "unchanged"
+		case StatusSuppressed:
+			sr.Suppressions = []sarifSuppression{{Kind: "external"}}
+		}
+		results = append(results, sr)
+	}
+
+	report := sarifReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "tokencontrol",
+				InformationURI: "https://github.com/ppiankov/tokencontrol",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSARIFFormatter(t *testing.T) {
+	result := sampleResult()
+	result.Findings = append(result.Findings,
+		Finding{Repo: "app2", Check: "sec-hardcoded-token", Category: "security", Severity: SeverityCritical,
+			Message: "Possible hardcoded secret at main.go:2", Suggestion: "Move the secret",
+			Location: "main.go", Line: 2, Fingerprint: "abc123", Status: StatusBaselined},
+		Finding{Repo: "app1", Check: "sec-hardcoded-token", Category: "security", Severity: SeverityCritical,
+			Message: "Possible hardcoded secret at cfg.go:9", Location: "cfg.go", Line: 9, Status: StatusSuppressed},
+	)
+
+	var buf bytes.Buffer
+	if err := NewSARIFFormatter().Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	var report sarifReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Version != "2.1.0" || len(report.Runs) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	run := report.Runs[0]
+	if run.Tool.Driver.Name != "tokencontrol" {
+		t.Errorf("driver = %q", run.Tool.Driver.Name)
+	}
+	// one rule per check, results reference them by index
+	if len(run.Tool.Driver.Rules) != 5 || len(run.Results) != 6 {
+		t.Fatalf("rules = %d, results = %d", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	for _, r := range run.Results {
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %s has ruleIndex %d", r.RuleID, r.RuleIndex)
+		}
+	}
+
+	rule := run.Tool.Driver.Rules[0]
+	if rule.ID != "missing-ci" || rule.Help.Text != "Create ci.yml" || rule.DefaultConfiguration.Level != "error" || rule.Properties.Category != "structure" {
+		t.Errorf("rule = %+v", rule)
+	}
+	if got := run.Results[3].Level; got != "note" {
+		t.Errorf("info level = %q, want note", got)
+	}
+	if uri := run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "app1/" {
+		t.Errorf("repo-level uri = %q", uri)
+	}
+
+	token := run.Results[4]
+	loc := token.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "app2/main.go" || loc.Region == nil || loc.Region.StartLine != 2 {
+		t.Errorf("location = %+v", loc)
+	}
+	if token.PartialFingerprints[sarifFingerprintKey] != "abc123" || token.BaselineState != "unchanged" {
+		t.Errorf("baselined result = %+v", token)
+	}
+	if s := run.Results[5].Suppressions; len(s) != 1 || s[0].Kind != "external" {
+		t.Errorf("suppressions = %+v", s)
+	}
+}
+
+func TestSARIFFormatter_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewSARIFFormatter().Format(&buf, &ScanResult{ReposScanned: []string{"app"}}); err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	run := raw["runs"].([]any)[0].(map[string]any)
+	if results, ok := run["results"].([]any); !ok || len(results) != 0 {
+		t.Errorf("results = %v, want empty array", run["results"])
+	}
+}
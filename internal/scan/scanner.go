@@ -1,12 +1,18 @@
 package scan
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ScanOptions controls scanner behavior.
@@ -16,6 +22,28 @@ type ScanOptions struct {
 	MinSeverity  Severity
 	Categories   []string
 	ExcludeRepos []string
+	Rules        []Rule // custom rules run after the built-in checks
+
+	// Baseline hides known findings; nil = none.
+	Baseline *Baseline
+	// IncludeKnown keeps baselined and suppressed findings, marked by Status.
+	IncludeKnown bool
+	// Now is used for suppression expiry; zero = time.Now().
+	Now time.Time
+
+	// Workers is the number of repos scanned concurrently; 0 = one per CPU.
+	Workers int
+	// CachePath is the result cache file (see DefaultCachePath); "" = no cache.
+	CachePath string
+	// Version is the tokencontrol version, part of the cache key so an
+	// upgrade re-checks every repo.
+	Version string
+	// AdvisoryDir holds OSV advisories for sec-vulnerable-dependency (see
+	// DefaultAdvisoryDir); "" or a missing dir disables the check.
+	AdvisoryDir string
+	// HistoryDepth is the number of commits sec-secret-in-history reads;
+	// 0 = DefaultHistoryDepth, negative = check off.
+	HistoryDepth int
 }
 
 // ScanResult holds all findings from a scan.
@@ -23,9 +51,34 @@ type ScanResult struct {
 	ReposScanned []string  `json:"repos_scanned"`
 	Findings     []Finding `json:"findings"`
 	Skipped      []string  `json:"skipped"`
+	Baselined    int       `json:"baselined,omitempty"`  // known findings in the baseline
+	Suppressed   int       `json:"suppressed,omitempty"` // findings hidden by ignore files
+	Cached       int       `json:"cached,omitempty"`     // repos whose findings came from the cache
+}
+
+// volatileChecker is implemented by checks whose findings depend on more
+// than the repo's files, such as command rules. They run on every scan,
+// even for cached repos.
+type volatileChecker interface {
+	volatile() bool
+}
+
+func isVolatile(c Checker) bool {
+	v, ok := c.(volatileChecker)
+	return ok && v.volatile()
+}
+
+// repoScan is the outcome of scanning one repo dir.
+type repoScan struct {
+	name     string
+	excluded bool
+	repo     *RepoInfo // nil when the dir is not a git repo
+	findings []Finding
+	cached   bool
 }
 
-// Scan walks ReposDir and runs all applicable checks on each repo.
+// Scan walks ReposDir and runs all applicable checks on each repo, scanning
+// Workers repos at a time.
 func Scan(opts ScanOptions) (*ScanResult, error) {
 	reposDir, err := filepath.Abs(opts.ReposDir)
 	if err != nil {
@@ -47,10 +100,43 @@ func Scan(opts ScanOptions) (*ScanResult, error) {
 		catSet[c] = true
 	}
 
-	checkers := AllCheckers()
+	custom, err := CompileRules(opts.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("custom scan rules: %w", err)
+	}
+	var checkers []Checker
+	for _, c := range append(AllCheckers(), custom...) {
+		if len(catSet) == 0 || catSet[c.Category()] {
+			checkers = append(checkers, c)
+		}
+	}
 
-	result := &ScanResult{}
+	historyDepth := opts.HistoryDepth
+	if historyDepth == 0 {
+		historyDepth = DefaultHistoryDepth
+	}
+	setHistoryDepth(checkers, historyDepth)
 
+	var advisoryDigest string
+	if opts.AdvisoryDir != "" && (len(catSet) == 0 || catSet["security"]) {
+		db, err := LoadAdvisories(opts.AdvisoryDir)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			slog.Debug("no advisory dir, skipping dependency vulnerabilities", "dir", opts.AdvisoryDir)
+		case err != nil:
+			return nil, err
+		default:
+			setAdvisories(checkers, db)
+			advisoryDigest = db.digest
+		}
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var scans []*repoScan
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -66,41 +152,86 @@ func Scan(opts ScanOptions) (*ScanResult, error) {
 			continue
 		}
 
-		if excludeSet[name] {
-			slog.Debug("excluded repo", "repo", name)
-			result.Skipped = append(result.Skipped, name)
-			continue
+		scans = append(scans, &repoScan{name: name, excluded: excludeSet[name]})
+	}
+
+	var cache *scanCache
+	var salt string
+	if opts.CachePath != "" {
+		cache = loadCache(opts.CachePath)
+		salt = cacheSalt(opts.Version, opts.Categories, opts.Rules, advisoryDigest, historyDepth)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan *repoScan)
+	var wg sync.WaitGroup
+	for range min(workers, len(scans)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rs := range jobs {
+				rs.run(filepath.Join(reposDir, rs.name), checkers, cache, salt)
+			}
+		}()
+	}
+	for _, rs := range scans {
+		if !rs.excluded {
+			jobs <- rs
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if cache != nil {
+		if err := cache.save(opts.CachePath); err != nil {
+			slog.Warn("scan cache not saved", "error", err)
 		}
+	}
 
-		repoPath := filepath.Join(reposDir, name)
-		repo := DetectRepo(repoPath)
-		if repo == nil {
-			slog.Debug("not a git repo", "dir", name)
-			result.Skipped = append(result.Skipped, name)
+	result := &ScanResult{}
+	for _, rs := range scans {
+		if rs.excluded {
+			slog.Debug("excluded repo", "repo", rs.name)
+			result.Skipped = append(result.Skipped, rs.name)
+			continue
+		}
+		if rs.repo == nil {
+			slog.Debug("not a git repo", "dir", rs.name)
+			result.Skipped = append(result.Skipped, rs.name)
 			continue
 		}
 
-		result.ReposScanned = append(result.ReposScanned, name)
+		result.ReposScanned = append(result.ReposScanned, rs.name)
+		if rs.cached {
+			result.Cached++
+		}
+		suppressions := loadSuppressions(rs.repo, now)
 
-		for _, checker := range checkers {
-			if len(catSet) > 0 && !catSet[checker.Category()] {
+		for _, f := range rs.findings {
+			if opts.MinSeverity > 0 && f.Severity > opts.MinSeverity {
 				continue
 			}
-			if !checker.Applies(repo) {
-				continue
+			switch {
+			case slices.ContainsFunc(suppressions, func(s Suppression) bool { return s.matches(&f) }):
+				f.Status = StatusSuppressed
+				result.Suppressed++
+			case opts.Baseline.Contains(f.Fingerprint):
+				f.Status = StatusBaselined
+				result.Baselined++
 			}
-			findings := checker.Run(repo)
-			for _, f := range findings {
-				if opts.MinSeverity > 0 && f.Severity > opts.MinSeverity {
-					continue
-				}
-				result.Findings = append(result.Findings, f)
+			if f.Status != "" && !opts.IncludeKnown {
+				continue
 			}
+			result.Findings = append(result.Findings, f)
 		}
 	}
 
-	// sort findings: critical first, then by repo
-	sort.Slice(result.Findings, func(i, j int) bool {
+	// sort findings: critical first, then by repo; stable so each repo keeps
+	// the checker order whatever order the workers finished in
+	sort.SliceStable(result.Findings, func(i, j int) bool {
 		if result.Findings[i].Severity != result.Findings[j].Severity {
 			return result.Findings[i].Severity < result.Findings[j].Severity
 		}
@@ -109,3 +240,63 @@ func Scan(opts ScanOptions) (*ScanResult, error) {
 
 	return result, nil
 }
+
+// run detects the repo at path and collects the findings of checkers,
+// reusing the cached findings when the repo is unchanged.
+func (rs *repoScan) run(path string, checkers []Checker, cache *scanCache, salt string) {
+	rs.repo = DetectRepo(path)
+	if rs.repo == nil {
+		return
+	}
+
+	var key string
+	if cache != nil {
+		key = repoCacheKey(rs.repo.Path, salt)
+	}
+	if key != "" {
+		rs.findings, rs.cached = cache.get(rs.repo.Path, key)
+	}
+	if !rs.cached {
+		for _, c := range checkers {
+			if !isVolatile(c) {
+				rs.findings = append(rs.findings, runChecker(rs.repo, c)...)
+			}
+		}
+		if key != "" {
+			cache.put(rs.repo.Path, key, slices.Clone(rs.findings))
+		}
+	}
+	for _, c := range checkers {
+		if isVolatile(c) {
+			rs.findings = append(rs.findings, runChecker(rs.repo, c)...)
+		}
+	}
+}
+
+// runChecker runs c if it applies to repo and fingerprints its findings.
+func runChecker(repo *RepoInfo, c Checker) []Finding {
+	if !c.Applies(repo) {
+		return nil
+	}
+	findings := c.Run(repo)
+	for i := range findings {
+		f := &findings[i]
+		f.Fingerprint = f.fingerprint()
+		if fx, ok := c.(Fixer); ok && len(fx.Fix(repo, f)) > 0 {
+			f.Fixable = true
+		}
+	}
+	return findings
+}
+
+// WithoutKnown returns a copy of r without baselined and suppressed findings.
+func (r *ScanResult) WithoutKnown() *ScanResult {
+	out := *r
+	out.Findings = nil
+	for _, f := range r.Findings {
+		if f.Status == "" {
+			out.Findings = append(out.Findings, f)
+		}
+	}
+	return &out
+}
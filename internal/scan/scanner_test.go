@@ -222,3 +222,96 @@ func TestScan_SkipsFiles(t *testing.T) {
 		t.Errorf("ReposScanned = %d, want 0", len(result.ReposScanned))
 	}
 }
+
+func TestScan_WorkersKeepOrder(t *testing.T) {
+	base := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		if err := os.MkdirAll(filepath.Join(base, name, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, name, "go.mod"), []byte("module m\n\ngo 1.21\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serial, err := Scan(ScanOptions{ReposDir: base, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parallel, err := Scan(ScanOptions{ReposDir: base, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parallel.ReposScanned) != 6 || parallel.ReposScanned[0] != "a" || parallel.ReposScanned[5] != "f" {
+		t.Errorf("ReposScanned = %v", parallel.ReposScanned)
+	}
+	if len(serial.Findings) != len(parallel.Findings) {
+		t.Fatalf("findings: serial %d, parallel %d", len(serial.Findings), len(parallel.Findings))
+	}
+	for i := range serial.Findings {
+		if serial.Findings[i].Fingerprint != parallel.Findings[i].Fingerprint {
+			t.Fatalf("finding %d differs: %s vs %s", i, serial.Findings[i].Check, parallel.Findings[i].Check)
+		}
+	}
+}
+
+func TestScan_Cache(t *testing.T) {
+	base := t.TempDir()
+	dir := makeGitRepo(t, base, "app", map[string]string{
+		"go.mod": "module m\n\ngo 1.24\n",
+	})
+	marker := filepath.Join(t.TempDir(), "marker")
+	opts := ScanOptions{
+		ReposDir:  base,
+		CachePath: filepath.Join(t.TempDir(), "scan-cache.json"),
+		Version:   "test",
+		Rules: []Rule{{
+			ID: "needs-marker", Kind: RuleCommand, Message: "marker missing",
+			Command: "test -f " + marker,
+		}},
+	}
+	checks := func(r *ScanResult) map[string]bool {
+		m := make(map[string]bool)
+		for _, f := range r.Findings {
+			m[f.Check] = true
+		}
+		return m
+	}
+
+	first, err := Scan(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached != 0 || !checks(first)["missing-license"] || !checks(first)["needs-marker"] {
+		t.Fatalf("first scan: cached %d, checks %v", first.Cached, checks(first))
+	}
+
+	// unchanged repo: served from the cache, command rules still run
+	writeFile(t, marker, "")
+	second, err := Scan(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Cached != 1 {
+		t.Errorf("second scan: cached %d, want 1", second.Cached)
+	}
+	if !checks(second)["missing-license"] || checks(second)["needs-marker"] {
+		t.Errorf("second scan checks = %v", checks(second))
+	}
+
+	// a new file invalidates the entry
+	writeFile(t, filepath.Join(dir, "LICENSE"), "MIT\n")
+	third, err := Scan(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Cached != 0 || checks(third)["missing-license"] {
+		t.Errorf("third scan: cached %d, checks %v", third.Cached, checks(third))
+	}
+
+	// a different category filter has its own entries
+	opts.Categories = []string{"go"}
+	if r, err := Scan(opts); err != nil || r.Cached != 0 {
+		t.Errorf("filtered scan: cached %d, err %v", r.Cached, err)
+	}
+}
@@ -0,0 +1,55 @@
+package scan
+
+import "math"
+
+// ScoreWeights sets how much each finding costs a repo's health score.
+// A finding deducts its severity's points times its category's multiplier
+// from 100; the score floors at 0.
+type ScoreWeights struct {
+	Severity map[string]float64 `yaml:"severity,omitempty" json:"severity,omitempty"` // points per finding: critical, warning, info
+	Category map[string]float64 `yaml:"category,omitempty" json:"category,omitempty"` // multiplier per category; default 1
+}
+
+// DefaultScoreWeights returns the weights used when none are configured.
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Severity: map[string]float64{"critical": 10, "warning": 3, "info": 1},
+		Category: map[string]float64{"security": 2},
+	}
+}
+
+// WithDefaults returns w with any unset severity or category weight taken
+// from DefaultScoreWeights.
+func (w ScoreWeights) WithDefaults() ScoreWeights {
+	merged := DefaultScoreWeights()
+	for k, v := range w.Severity {
+		merged.Severity[k] = v
+	}
+	for k, v := range w.Category {
+		merged.Category[k] = v
+	}
+	return merged
+}
+
+// Penalty returns the points one finding of the given severity and
+// category deducts.
+func (w ScoreWeights) Penalty(severity, category string) float64 {
+	mult, ok := w.Category[category]
+	if !ok {
+		mult = 1
+	}
+	return w.Severity[severity] * mult
+}
+
+// HealthScore returns a 0–100 score for one repo's findings. Suppressed
+// findings don't count; baselined ones still do.
+func HealthScore(findings []Finding, w ScoreWeights) int {
+	var penalty float64
+	for _, f := range findings {
+		if f.Status == StatusSuppressed {
+			continue
+		}
+		penalty += w.Penalty(f.Severity.String(), f.Category)
+	}
+	return int(math.Max(0, math.Round(100-penalty)))
+}
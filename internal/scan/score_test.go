@@ -0,0 +1,57 @@
+package scan
+
+import "testing"
+
+func TestHealthScore(t *testing.T) {
+	f := func(sev Severity, category string) Finding {
+		return Finding{Severity: sev, Category: category}
+	}
+	suppressed := f(SeverityCritical, "structure")
+	suppressed.Status = StatusSuppressed
+	baselined := f(SeverityWarning, "structure")
+	baselined.Status = StatusBaselined
+
+	w := DefaultScoreWeights()
+	tests := []struct {
+		name     string
+		findings []Finding
+		want     int
+	}{
+		{"clean", nil, 100},
+		{"mixed", []Finding{f(SeverityCritical, "structure"), f(SeverityWarning, "go"), f(SeverityInfo, "ci")}, 86},
+		{"security doubles", []Finding{f(SeverityCritical, "security")}, 80},
+		{"suppressed ignored, baselined counted", []Finding{suppressed, baselined}, 97},
+		{"floors at zero", []Finding{
+			f(SeverityCritical, "security"), f(SeverityCritical, "security"), f(SeverityCritical, "security"),
+			f(SeverityCritical, "security"), f(SeverityCritical, "security"), f(SeverityCritical, "security"),
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HealthScore(tt.findings, w); got != tt.want {
+				t.Errorf("HealthScore = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreWeights_WithDefaults(t *testing.T) {
+	w := ScoreWeights{
+		Severity: map[string]float64{"info": 0},
+		Category: map[string]float64{"security": 3, "docs": 0.5},
+	}.WithDefaults()
+
+	if got := w.Penalty("info", "go"); got != 0 {
+		t.Errorf("info penalty = %v, want 0", got)
+	}
+	if got := w.Penalty("critical", "security"); got != 30 {
+		t.Errorf("critical security penalty = %v, want 30", got)
+	}
+	if got := w.Penalty("warning", "docs"); got != 1.5 {
+		t.Errorf("warning docs penalty = %v, want 1.5", got)
+	}
+	// defaults are not shared between calls
+	if DefaultScoreWeights().Category["security"] != 2 {
+		t.Error("WithDefaults modified the defaults")
+	}
+}
@@ -30,14 +30,22 @@ type RunFunc func(ctx context.Context, tasks []task.Task, tf *task.TaskFile) (*R
 
 // LoopConfig holds configuration for the sentinel loop daemon.
 type LoopConfig struct {
-	ReposDir     string
-	Owner        string
-	StateDir     string // persistent state directory (~/.tokencontrol/sentinel/)
-	Cooldown     time.Duration
-	ScanOnly     bool
-	GenerateOnly bool
-	Settings     *config.Settings
-	RunFn        RunFunc // injected execution function
+	ReposDir       string
+	Owner          string
+	StateDir       string // persistent state directory (~/.tokencontrol/sentinel/)
+	Cooldown       time.Duration
+	ScanOnly       bool
+	GenerateOnly   bool
+	Settings       *config.Settings
+	ScanRules      []scan.Rule // custom scan rules, see config.ScanConfig.LoadRules
+	ScanBaseline   string      // baseline file of known findings, re-read every cycle; "" = none
+	ScanFix        bool        // apply and commit deterministic fixes instead of creating tasks for them
+	ScanWorkers    int         // repos scanned concurrently; 0 = one per CPU
+	ScanCache      string      // scan result cache file; "" = no cache
+	ScanAdvisories string      // OSV advisory dir for sec-vulnerable-dependency; "" = check off
+	ScanHistory    int         // commits searched for committed secrets; 0 = default, negative = off
+	Version        string      // tokencontrol version, part of the scan cache key
+	RunFn          RunFunc     // injected execution function
 }
 
 // Loop is the continuous sentinel daemon: scan → dedup → run → cooldown → repeat.
@@ -194,10 +202,28 @@ func (l *Loop) discover(ctx context.Context) ([]task.Task, string, error) {
 	// scan-based discovery
 	if !l.cfg.GenerateOnly {
 		l.state.SetPhase(PhaseScanning, "scanning repos")
+		var baseline *scan.Baseline
+		if l.cfg.ScanBaseline != "" {
+			b, err := scan.LoadBaseline(l.cfg.ScanBaseline)
+			if err != nil {
+				slog.Warn("sentinel: ignoring scan baseline", "error", err)
+			}
+			baseline = b
+		}
 		scanResult, err := scan.Scan(scan.ScanOptions{
-			ReposDir:    l.cfg.ReposDir,
-			MinSeverity: scan.SeverityWarning, // skip info-level
+			ReposDir:     l.cfg.ReposDir,
+			MinSeverity:  scan.SeverityWarning, // skip info-level
+			Rules:        l.cfg.ScanRules,
+			Baseline:     baseline,
+			Workers:      l.cfg.ScanWorkers,
+			CachePath:    l.cfg.ScanCache,
+			Version:      l.cfg.Version,
+			AdvisoryDir:  l.cfg.ScanAdvisories,
+			HistoryDepth: l.cfg.ScanHistory,
 		})
+		if err == nil && l.cfg.ScanFix {
+			scanResult = l.applyFixes(scanResult)
+		}
 		if err != nil {
 			slog.Warn("sentinel: scan error", "error", err)
 		} else if len(scanResult.Findings) > 0 {
@@ -230,6 +256,25 @@ func (l *Loop) discover(ctx context.Context) ([]task.Task, string, error) {
 	return allTasks, source, nil
 }
 
+// applyFixes commits the deterministic fixes for the scan's findings and
+// returns the findings left for agents.
+func (l *Loop) applyFixes(result *scan.ScanResult) *scan.ScanResult {
+	l.state.SetPhase(PhaseScanning, "applying deterministic fixes")
+	fixes, err := scan.ApplyFixes(result, scan.FixOptions{ReposDir: l.cfg.ReposDir, Commit: true})
+	if err != nil {
+		slog.Warn("sentinel: fix error", "error", err)
+		return result
+	}
+	for _, fr := range fixes {
+		if fr.Err != nil {
+			slog.Warn("sentinel: fix failed", "repo", fr.Repo, "error", fr.Err)
+			continue
+		}
+		slog.Info("sentinel: fixed", "repo", fr.Repo, "files", fr.Files)
+	}
+	return result.WithoutFixed(fixes)
+}
+
 // findingsToTasks converts scan findings to tasks using the TaskFormatter.
 func findingsToTasks(result *scan.ScanResult, owner string) []task.Task {
 	var buf bytes.Buffer
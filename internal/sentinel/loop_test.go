@@ -2,7 +2,10 @@ package sentinel
 
 import (
 	"context"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -103,6 +106,53 @@ func TestLoop_CycleWithNoFindings(t *testing.T) {
 	}
 }
 
+func TestLoop_DiscoverAppliesFixes(t *testing.T) {
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@test.com")
+	}
+	base := t.TempDir()
+	repo := filepath.Join(base, "app")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module m\n\ngo 1.24\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	l, err := NewLoop(LoopConfig{
+		ReposDir: base,
+		RunFn:    fakeRunFn(0, 0),
+		StateDir: filepath.Join(t.TempDir(), "completed.json"),
+		ScanOnly: true,
+		ScanFix:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks, _, err := l.discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tk := range tasks {
+		if tk.ID == "app-scan-missing-license" || tk.ID == "app-scan-missing-gitignore" {
+			t.Errorf("task %s generated for a fixed finding", tk.ID)
+		}
+	}
+	out, err := exec.Command("git", "-C", repo, "log", "--format=%s").Output()
+	if err != nil {
+		t.Fatalf("no fix commit: %v", err)
+	}
+	if !strings.Contains(string(out), "LICENSE") {
+		t.Errorf("fix commit = %q", out)
+	}
+}
+
 func TestLoop_RunCancellation(t *testing.T) {
 	l, err := NewLoop(LoopConfig{
 		ReposDir: t.TempDir(),
@@ -0,0 +1,275 @@
+package task
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Risk rule actions.
+const (
+	RiskReview = "review" // task must be reviewed before its changes are trusted
+	RiskBlock  = "block"  // worktree branch is not merged back
+)
+
+// Risk rule categories for RiskRule.When.
+const (
+	RiskWhenCI       = "ci"
+	RiskWhenLockfile = "lockfile"
+	RiskWhenSecret   = "secret"
+	RiskWhenDenied   = "denied"
+)
+
+// ChangeSummary describes what a completed task changed in its repo.
+type ChangeSummary struct {
+	Base      string       `json:"base,omitempty"`
+	Head      string       `json:"head,omitempty"`
+	Files     []FileChange `json:"files,omitempty"`
+	Added     int          `json:"added"`
+	Deleted   int          `json:"deleted"`
+	TestFiles int          `json:"test_files"`
+	TestRatio float64      `json:"test_ratio"` // test files / changed files
+	CI        []string     `json:"ci,omitempty"`
+	Lockfiles []string     `json:"lockfiles,omitempty"`
+	Secrets   []string     `json:"secrets,omitempty"`
+	Denied    []string     `json:"denied,omitempty"`
+	Risks     []RiskFlag   `json:"risks,omitempty"`
+}
+
+// RiskFlag records a risk rule that matched a task's changes.
+type RiskFlag struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// RiskRule flags a task when its changes match. All conditions that are set
+// are checked and any match triggers the rule.
+type RiskRule struct {
+	Name         string   `yaml:"name" json:"name"`
+	When         string   `yaml:"when,omitempty" json:"when,omitempty"`   // ci, lockfile, secret or denied
+	Paths        []string `yaml:"paths,omitempty" json:"paths,omitempty"` // globs; ** matches any depth
+	MaxLines     int      `yaml:"max_lines,omitempty" json:"max_lines,omitempty"`
+	MinTestRatio float64  `yaml:"min_test_ratio,omitempty" json:"min_test_ratio,omitempty"`
+	Action       string   `yaml:"action,omitempty" json:"action,omitempty"` // review (default) or block
+}
+
+// DefaultRiskRules are used when no rules are configured. They only flag
+// tasks for review; blocking merge-back is opt-in via a rule's action.
+func DefaultRiskRules() []RiskRule {
+	return []RiskRule{
+		{Name: "ci-change", When: RiskWhenCI, Action: RiskReview},
+		{Name: "lockfile-change", When: RiskWhenLockfile, Action: RiskReview},
+		{Name: "secret-file", When: RiskWhenSecret, Action: RiskReview},
+		{Name: "denied-path", When: RiskWhenDenied, Action: RiskReview},
+	}
+}
+
+// NeedsReview reports whether any matched rule requires review or blocks.
+func (c *ChangeSummary) NeedsReview() bool {
+	return c != nil && len(c.Risks) > 0
+}
+
+// Blocked reports whether a matched rule blocks merge-back.
+func (c *ChangeSummary) Blocked() bool {
+	if c == nil {
+		return false
+	}
+	for _, r := range c.Risks {
+		if r.Action == RiskBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleNames lists the names of the matched risk rules.
+func (c *ChangeSummary) RuleNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, len(c.Risks))
+	for i, r := range c.Risks {
+		names[i] = r.Rule
+	}
+	return names
+}
+
+// AnalyzeChanges classifies changed files and evaluates risk rules. Paths
+// matching denyPaths are reported as denied; nil rules means DefaultRiskRules.
+func AnalyzeChanges(files []FileChange, denyPaths []string, rules []RiskRule) *ChangeSummary {
+	s := &ChangeSummary{Files: files}
+	for _, f := range files {
+		s.Added += f.Added
+		s.Deleted += f.Deleted
+		if IsTestFile(f.Path) {
+			s.TestFiles++
+		}
+		if IsCIFile(f.Path) {
+			s.CI = append(s.CI, f.Path)
+		}
+		if IsLockfile(f.Path) {
+			s.Lockfiles = append(s.Lockfiles, f.Path)
+		}
+		if IsSecretFile(f.Path) {
+			s.Secrets = append(s.Secrets, f.Path)
+		}
+		if MatchAnyPath(denyPaths, f.Path) {
+			s.Denied = append(s.Denied, f.Path)
+		}
+	}
+	if len(files) > 0 {
+		s.TestRatio = float64(s.TestFiles) / float64(len(files))
+	}
+
+	if rules == nil {
+		rules = DefaultRiskRules()
+	}
+	for _, rule := range rules {
+		if reason := s.match(rule); reason != "" {
+			action := rule.Action
+			if action == "" {
+				action = RiskReview
+			}
+			s.Risks = append(s.Risks, RiskFlag{Rule: rule.Name, Action: action, Reason: reason})
+		}
+	}
+	return s
+}
+
+// match returns why rule matched, or "" when it didn't.
+func (s *ChangeSummary) match(rule RiskRule) string {
+	var category []string
+	switch rule.When {
+	case RiskWhenCI:
+		category = s.CI
+	case RiskWhenLockfile:
+		category = s.Lockfiles
+	case RiskWhenSecret:
+		category = s.Secrets
+	case RiskWhenDenied:
+		category = s.Denied
+	}
+	if len(category) > 0 {
+		return fmt.Sprintf("%s files changed: %s", rule.When, strings.Join(category, ", "))
+	}
+	for _, f := range s.Files {
+		if MatchAnyPath(rule.Paths, f.Path) {
+			return "path matched: " + f.Path
+		}
+	}
+	if lines := s.Added + s.Deleted; rule.MaxLines > 0 && lines > rule.MaxLines {
+		return fmt.Sprintf("%d lines changed (max %d)", lines, rule.MaxLines)
+	}
+	if rule.MinTestRatio > 0 && len(s.Files) > 0 && s.TestRatio < rule.MinTestRatio {
+		return fmt.Sprintf("test file ratio %.2f below %.2f", s.TestRatio, rule.MinTestRatio)
+	}
+	return ""
+}
+
+// MatchAnyPath reports whether p matches any of the patterns.
+func MatchAnyPath(patterns []string, p string) bool {
+	for _, pat := range patterns {
+		if MatchPath(pat, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath matches a slash-separated repo path against a glob pattern.
+// "**" matches any number of directories, a pattern without "/" matches the
+// base name at any depth, and a trailing "/" matches everything below a
+// directory.
+func MatchPath(pattern, p string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+func matchSegments(pat, parts []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pat[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], parts[0]); !ok {
+			return false
+		}
+		pat, parts = pat[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// IsTestFile reports whether a path looks like a test file.
+func IsTestFile(p string) bool {
+	base := path.Base(p)
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"),
+		strings.HasSuffix(base, "_test.rs"),
+		strings.Contains(base, ".test."),
+		strings.Contains(base, ".spec."):
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" || dir == "testdata" {
+			return true
+		}
+	}
+	return false
+}
+
+var ciPatterns = []string{
+	".github/workflows/**", ".gitea/workflows/**", ".circleci/**", ".buildkite/**",
+	".gitlab-ci.yml", ".travis.yml", "Jenkinsfile", "azure-pipelines.yml", ".woodpecker.yml",
+}
+
+// IsCIFile reports whether a path is CI/CD configuration.
+func IsCIFile(p string) bool {
+	for _, pat := range ciPatterns {
+		if strings.Contains(pat, "/") && MatchPath(pat, p) || p == pat {
+			return true
+		}
+	}
+	return false
+}
+
+var lockfiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Cargo.lock": true, "poetry.lock": true, "Pipfile.lock": true, "uv.lock": true,
+	"Gemfile.lock": true, "composer.lock": true, "bun.lockb": true,
+}
+
+// IsLockfile reports whether a path is a dependency lockfile.
+func IsLockfile(p string) bool {
+	return lockfiles[path.Base(p)]
+}
+
+var secretPatterns = []string{
+	".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx", "*.keystore",
+	"id_rsa*", "id_ed25519*", "credentials*.json", ".netrc", ".npmrc", ".pypirc",
+}
+
+// IsSecretFile reports whether a path is likely to hold credentials.
+func IsSecretFile(p string) bool {
+	base := path.Base(p)
+	if base == ".env.example" || base == ".env.sample" || strings.HasSuffix(base, ".pub") {
+		return false
+	}
+	return MatchAnyPath(secretPatterns, p)
+}
@@ -0,0 +1,133 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"go.sum", "go.sum", true},
+		{"go.sum", "sub/go.sum", true},
+		{"LICENSE", "docs/LICENSE.md", false},
+		{".github/workflows/**", ".github/workflows/ci.yml", true},
+		{".github/workflows/**", ".github/dependabot.yml", false},
+		{"deploy/", "deploy/k8s/app.yaml", true},
+		{"/cmd/*/main.go", "cmd/tool/main.go", true},
+		{"internal/**/secret.go", "internal/secret.go", true},
+		{"internal/**/secret.go", "internal/a/b/secret.go", true},
+		{"internal/*.go", "internal/a/b.go", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFileClassifiers(t *testing.T) {
+	for _, p := range []string{"a/b_test.go", "tests/helpers.py", "src/app.spec.ts", "test_util.py"} {
+		if !IsTestFile(p) {
+			t.Errorf("IsTestFile(%q) = false", p)
+		}
+	}
+	if IsTestFile("internal/latest.go") {
+		t.Error("latest.go is not a test file")
+	}
+	if !IsCIFile(".github/workflows/release.yml") || !IsCIFile(".gitlab-ci.yml") || IsCIFile("docs/.gitlab-ci.yml") {
+		t.Error("IsCIFile mismatch")
+	}
+	if !IsLockfile("web/package-lock.json") || IsLockfile("go.mod") {
+		t.Error("IsLockfile mismatch")
+	}
+	if !IsSecretFile(".env.production") || !IsSecretFile("certs/server.key") || IsSecretFile(".env.example") || IsSecretFile("id_rsa.pub") {
+		t.Error("IsSecretFile mismatch")
+	}
+}
+
+func TestAnalyzeChanges_DefaultRules(t *testing.T) {
+	files := []FileChange{
+		{Path: "main.go", Status: "M", Added: 10, Deleted: 2},
+		{Path: "main_test.go", Status: "A", Added: 20},
+		{Path: "go.sum", Status: "M", Added: 4, Deleted: 4},
+		{Path: ".github/workflows/ci.yml", Status: "M", Added: 1, Deleted: 1},
+	}
+	s := AnalyzeChanges(files, nil, nil)
+
+	if s.Added != 35 || s.Deleted != 7 || s.TestFiles != 1 || s.TestRatio != 0.25 {
+		t.Errorf("stats = %+v", s)
+	}
+	if len(s.CI) != 1 || len(s.Lockfiles) != 1 || len(s.Secrets) != 0 {
+		t.Errorf("categories = ci %v, lock %v, secrets %v", s.CI, s.Lockfiles, s.Secrets)
+	}
+	if len(s.Risks) != 2 || s.Risks[0].Rule != "ci-change" || s.Risks[1].Rule != "lockfile-change" {
+		t.Fatalf("risks = %+v", s.Risks)
+	}
+	if !s.NeedsReview() || s.Blocked() {
+		t.Errorf("review = %v, blocked = %v", s.NeedsReview(), s.Blocked())
+	}
+}
+
+func TestAnalyzeChanges_DefaultsOnlyReview(t *testing.T) {
+	// secret files and denied paths are flagged, but blocking is opt-in
+	s := AnalyzeChanges([]FileChange{{Path: "LICENSE", Status: "M"}, {Path: ".npmrc", Status: "A"}}, []string{"LICENSE"}, nil)
+	if len(s.Denied) != 1 || len(s.Secrets) != 1 || len(s.Risks) != 2 {
+		t.Fatalf("summary = %+v", s)
+	}
+	if !s.NeedsReview() || s.Blocked() {
+		t.Errorf("review = %v, blocked = %v, want review without block", s.NeedsReview(), s.Blocked())
+	}
+	if !strings.Contains(s.Risks[1].Reason, "denied files changed: LICENSE") {
+		t.Errorf("reason = %q", s.Risks[1].Reason)
+	}
+
+	blocking := []RiskRule{{Name: "denied-path", When: RiskWhenDenied, Action: RiskBlock}}
+	if s := AnalyzeChanges([]FileChange{{Path: "LICENSE", Status: "M"}}, []string{"LICENSE"}, blocking); !s.Blocked() {
+		t.Errorf("an explicit block rule should block: %+v", s.Risks)
+	}
+}
+
+func TestAnalyzeChanges_CustomRules(t *testing.T) {
+	files := []FileChange{
+		{Path: "api/handler.go", Added: 300, Deleted: 250},
+		{Path: "api/routes.go", Added: 5},
+	}
+	rules := []RiskRule{
+		{Name: "big", MaxLines: 500},
+		{Name: "untested", MinTestRatio: 0.2, Action: RiskBlock},
+		{Name: "api", Paths: []string{"api/**"}},
+		{Name: "ci", When: RiskWhenCI, Action: RiskBlock},
+	}
+	s := AnalyzeChanges(files, nil, rules)
+
+	got := make(map[string]RiskFlag)
+	for _, r := range s.Risks {
+		got[r.Rule] = r
+	}
+	if len(got) != 3 {
+		t.Fatalf("risks = %+v", s.Risks)
+	}
+	if got["big"].Action != RiskReview || got["big"].Reason != "555 lines changed (max 500)" {
+		t.Errorf("big = %+v", got["big"])
+	}
+	if got["untested"].Action != RiskBlock || !s.Blocked() {
+		t.Errorf("untested = %+v", got["untested"])
+	}
+	if got["api"].Reason != "path matched: api/handler.go" {
+		t.Errorf("api = %+v", got["api"])
+	}
+}
+
+func TestAnalyzeChanges_EmptyRules(t *testing.T) {
+	s := AnalyzeChanges([]FileChange{{Path: ".github/workflows/ci.yml"}}, nil, []RiskRule{})
+	if s.NeedsReview() {
+		t.Errorf("explicit empty rules should flag nothing: %+v", s.Risks)
+	}
+	var nilSummary *ChangeSummary
+	if nilSummary.NeedsReview() || nilSummary.Blocked() {
+		t.Error("nil summary must not flag")
+	}
+}
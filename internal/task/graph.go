@@ -1,9 +1,16 @@
 package task
 
-import "fmt"
-
-// Graph represents a directed acyclic graph of tasks.
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Graph represents a directed acyclic graph of tasks. Tasks can be added or
+// replaced while a run is in progress; accessors return copies so callers
+// never observe a half-applied change.
 type Graph struct {
+	mu       sync.RWMutex
 	tasks    map[string]*Task
 	deps     map[string][]string // child → parents (depends_on)
 	children map[string][]string // parent → children
@@ -40,11 +47,15 @@ func BuildGraph(tasks []Task) (*Graph, error) {
 // Order returns tasks in topological order (dependencies first).
 // Within the same dependency level, sorted by priority ascending then ID.
 func (g *Graph) Order() []string {
-	return g.order
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.Clone(g.order)
 }
 
 // Roots returns task IDs with no dependencies.
 func (g *Graph) Roots() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	var roots []string
 	for id := range g.tasks {
 		if len(g.deps[id]) == 0 {
@@ -57,26 +68,113 @@ func (g *Graph) Roots() []string {
 
 // Children returns IDs that depend on the given task.
 func (g *Graph) Children(id string) []string {
-	return g.children[id]
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.Clone(g.children[id])
 }
 
 // Deps returns the parent task IDs that the given task depends on.
 func (g *Graph) Deps(id string) []string {
-	return g.deps[id]
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.Clone(g.deps[id])
 }
 
-// Task returns the task for an ID.
+// Task returns the task for an ID. The task must be treated as read-only;
+// use UpdateTask to change it.
 func (g *Graph) Task(id string) *Task {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	return g.tasks[id]
 }
 
 // Tasks returns all tasks in the graph.
 func (g *Graph) Tasks() map[string]*Task {
-	return g.tasks
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	cp := make(map[string]*Task, len(g.tasks))
+	for id, t := range g.tasks {
+		cp[id] = t
+	}
+	return cp
+}
+
+// AddTask inserts a new task. Its ID must be unused and its dependencies
+// must already be in the graph, so the insertion cannot create a cycle.
+func (g *Graph) AddTask(t Task) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if t.ID == "" {
+		return fmt.Errorf("task has no id")
+	}
+	if _, ok := g.tasks[t.ID]; ok {
+		return fmt.Errorf("task %q already exists", t.ID)
+	}
+	for _, dep := range t.DependsOn {
+		if _, ok := g.tasks[dep]; !ok {
+			return fmt.Errorf("task %q: unknown dependency %q", t.ID, dep)
+		}
+	}
+	g.tasks[t.ID] = &t
+	for _, dep := range t.DependsOn {
+		g.deps[t.ID] = append(g.deps[t.ID], dep)
+		g.children[dep] = append(g.children[dep], t.ID)
+	}
+	order, err := g.topoSort()
+	if err != nil {
+		return err
+	}
+	g.order = order
+	return nil
+}
+
+// UpdateTask replaces an existing task with the same ID. The previous *Task
+// is left untouched, so an execution already holding it is not affected.
+// Dependency changes are validated; on a cycle the graph is left as it was.
+func (g *Graph) UpdateTask(t Task) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	prev, ok := g.tasks[t.ID]
+	if !ok {
+		return fmt.Errorf("task %q not found", t.ID)
+	}
+	for _, dep := range t.DependsOn {
+		if dep == t.ID {
+			return fmt.Errorf("task %q depends on itself", t.ID)
+		}
+		if _, ok := g.tasks[dep]; !ok {
+			return fmt.Errorf("task %q: unknown dependency %q", t.ID, dep)
+		}
+	}
+
+	g.tasks[t.ID] = &t
+	g.setDeps(t.ID, t.DependsOn)
+	order, err := g.topoSort()
+	if err != nil {
+		g.tasks[t.ID] = prev
+		g.setDeps(t.ID, prev.DependsOn)
+		return err
+	}
+	g.order = order
+	return nil
+}
+
+// setDeps replaces the dependency edges of id.
+func (g *Graph) setDeps(id string, deps []string) {
+	for _, dep := range g.deps[id] {
+		g.children[dep] = slices.DeleteFunc(g.children[dep], func(c string) bool { return c == id })
+	}
+	delete(g.deps, id)
+	for _, dep := range deps {
+		g.deps[id] = append(g.deps[id], dep)
+		g.children[dep] = append(g.children[dep], id)
+	}
 }
 
 // Dependents returns all transitive dependents of a task.
 func (g *Graph) Dependents(id string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	var result []string
 	visited := make(map[string]struct{})
 	g.collectDependents(id, visited, &result)
@@ -281,3 +281,74 @@ func indexOf(s []string, v string) int {
 	}
 	return -1
 }
+
+func TestGraph_AddTask(t *testing.T) {
+	g, err := BuildGraph([]Task{
+		{ID: "a", Repo: "org/r", Priority: 1},
+		{ID: "b", Repo: "org/r", Priority: 1, DependsOn: []string{"a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.AddTask(Task{ID: "c", Repo: "org/r", Priority: 0, DependsOn: []string{"a"}}); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	if got := g.Order(); len(got) != 3 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("order = %v, want a first then c (priority 0)", got)
+	}
+	if children := g.Children("a"); len(children) != 2 {
+		t.Errorf("children of a = %v", children)
+	}
+
+	if err := g.AddTask(Task{ID: "a"}); err == nil {
+		t.Error("expected error for duplicate id")
+	}
+	if err := g.AddTask(Task{ID: "d", DependsOn: []string{"missing"}}); err == nil {
+		t.Error("expected error for unknown dependency")
+	}
+	if g.Task("d") != nil {
+		t.Error("rejected task should not be in the graph")
+	}
+}
+
+func TestGraph_UpdateTask(t *testing.T) {
+	g, err := BuildGraph([]Task{
+		{ID: "a", Repo: "org/r", Priority: 1, Prompt: "old"},
+		{ID: "b", Repo: "org/r", Priority: 1, DependsOn: []string{"a"}},
+		{ID: "c", Repo: "org/r", Priority: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := g.Task("a")
+
+	if err := g.UpdateTask(Task{ID: "a", Repo: "org/r", Priority: 1, Prompt: "new"}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if g.Task("a").Prompt != "new" || prev.Prompt != "old" {
+		t.Error("update should replace the task without mutating the previous one")
+	}
+
+	// move b from a to c
+	if err := g.UpdateTask(Task{ID: "b", Repo: "org/r", Priority: 1, DependsOn: []string{"c"}}); err != nil {
+		t.Fatalf("UpdateTask deps: %v", err)
+	}
+	if len(g.Children("a")) != 0 || len(g.Children("c")) != 1 {
+		t.Errorf("children a=%v c=%v", g.Children("a"), g.Children("c"))
+	}
+
+	// a cycle is rejected and the graph stays as it was
+	if err := g.UpdateTask(Task{ID: "c", Repo: "org/r", Priority: 1, DependsOn: []string{"b"}}); err == nil {
+		t.Fatal("expected cycle error")
+	}
+	if deps := g.Deps("c"); len(deps) != 0 {
+		t.Errorf("deps of c after rejected update = %v", deps)
+	}
+	if len(g.Order()) != 3 {
+		t.Errorf("order = %v", g.Order())
+	}
+	if err := g.UpdateTask(Task{ID: "nope"}); err == nil {
+		t.Error("expected error for unknown task")
+	}
+}
@@ -55,8 +55,22 @@ type Task struct {
 	Difficulty string   `json:"difficulty,omitempty"`  // "simple", "medium", "complex"
 	Score      int      `json:"score,omitempty"`       // numeric difficulty score
 	SourceFile string   `json:"source_file,omitempty"` // populated during multi-file load
+
+	AllowPaths []string `json:"allow_paths,omitempty"` // if set, agents may only change matching paths
+	DenyPaths  []string `json:"deny_paths,omitempty"`  // paths agents must not change
+	PathPolicy string   `json:"path_policy,omitempty"` // on violation: "fail" (default) or "revert"
+
+	Handoff *HandoffConfig `json:"handoff,omitempty"` // pass a failed attempt's summary to the next one
+
+	SpawnedBy string `json:"spawned_by,omitempty"` // task whose agent proposed this one as a follow-up
 }
 
+// Path policies applied when an attempt changes paths outside its rules.
+const (
+	PathPolicyFail   = "fail"   // fail the attempt so the cascade moves on
+	PathPolicyRevert = "revert" // restore the offending files and keep the rest
+)
+
 // UnmarshalJSON supports both string and array formats for depends_on.
 // String: "depends_on": "task-a" → []string{"task-a"}
 // Array:  "depends_on": ["task-a", "task-b"] → []string{"task-a", "task-b"}
@@ -109,10 +123,20 @@ type RunnerProfileConfig struct {
 	FallbackOnly   bool              `json:"fallback_only,omitempty"`   // true = never assign as primary via striping
 }
 
+// TaskDefaults are per-file values applied to tasks that leave them unset.
+type TaskDefaults struct {
+	Runner     string   `json:"runner,omitempty"`
+	Fallbacks  []string `json:"fallbacks,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+}
+
 // TaskFile is the top-level structure of the tasks JSON file.
 type TaskFile struct {
 	Description      string                          `json:"description,omitempty"`
 	Generated        string                          `json:"generated,omitempty"`
+	Extends          string                          `json:"extends,omitempty"`  // base file whose settings this file inherits
+	Include          []string                        `json:"include,omitempty"`  // files whose tasks and settings are merged in
+	Defaults         *TaskDefaults                   `json:"defaults,omitempty"` // applied to this file's tasks
 	AllowedRepos     []string                        `json:"allowed_repos,omitempty"`
 	DefaultRunner    string                          `json:"default_runner,omitempty"`    // default: "codex"
 	DefaultFallbacks []string                        `json:"default_fallbacks,omitempty"` // applied when task has no fallbacks
@@ -120,6 +144,10 @@ type TaskFile struct {
 	Review           *ReviewConfig                   `json:"review,omitempty"`            // auto-review config
 	ParallelRepo     bool                            `json:"parallel_repo,omitempty"`     // enable worktree isolation for same-repo tasks
 	MergeBack        *bool                           `json:"merge_back,omitempty"`        // auto-merge worktree branch; nil=true
+	AllowPaths       []string                        `json:"allow_paths,omitempty"`       // applied to tasks without their own allow_paths
+	DenyPaths        []string                        `json:"deny_paths,omitempty"`        // added to every task's deny_paths
+	PathPolicy       string                          `json:"path_policy,omitempty"`       // applied to tasks without their own policy
+	Handoff          *HandoffConfig                  `json:"handoff,omitempty"`           // applied to tasks without their own handoff
 	Tasks            []Task                          `json:"tasks"`
 }
 
@@ -130,6 +158,24 @@ type ReviewConfig struct {
 	FallbackOnly bool   `json:"fallback_only,omitempty"` // only review tasks that used a fallback
 }
 
+// HandoffConfig controls passing a summary of a failed attempt — its last
+// message, error output and changed files — to the next attempt's prompt.
+type HandoffConfig struct {
+	Enabled  bool `json:"enabled"`
+	MaxBytes int  `json:"max_bytes,omitempty"` // cap on the summary; default DefaultHandoffBytes
+}
+
+// DefaultHandoffBytes caps a handoff summary when max_bytes is unset.
+const DefaultHandoffBytes = 4000
+
+// Limit returns the effective size cap.
+func (h *HandoffConfig) Limit() int {
+	if h == nil || h.MaxBytes <= 0 {
+		return DefaultHandoffBytes
+	}
+	return h.MaxBytes
+}
+
 // AttemptInfo records a single runner attempt within a fallback cascade.
 type AttemptInfo struct {
 	Runner            string        `json:"runner"`
@@ -139,6 +185,9 @@ type AttemptInfo struct {
 	Error             string        `json:"error,omitempty"`
 	OutputDir         string        `json:"output_dir,omitempty"`
 	ConnectivityError string        `json:"connectivity_error,omitempty"`
+	PathViolations    []string      `json:"path_violations,omitempty"` // changed paths outside allow/deny rules
+	RolledBack        bool          `json:"rolled_back,omitempty"`     // repo restored to its pre-attempt state
+	FailedPatch       string        `json:"failed_patch,omitempty"`    // discarded changes of a rolled-back attempt
 }
 
 // TokenUsage tracks token consumption for a task or aggregate report.
@@ -148,6 +197,24 @@ type TokenUsage struct {
 	TotalTokens  int `json:"total_tokens"`
 }
 
+// FileChange is one file's entry in a diff summary.
+type FileChange struct {
+	Path    string `json:"path"`
+	Status  string `json:"status"` // git name-status letter: A, M, D, R, ...
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// PatchInfo describes the diff exported by a --patch-only run.
+type PatchInfo struct {
+	Path    string       `json:"path"` // changes.patch in the task output dir
+	Base    string       `json:"base"` // commit the patch applies to
+	Files   []FileChange `json:"files"`
+	Added   int          `json:"added"`
+	Deleted int          `json:"deleted"`
+}
+
 // TaskResult captures the outcome of executing a single task.
 type TaskResult struct {
 	TaskID    string        `json:"task_id"`
@@ -166,8 +233,12 @@ type TaskResult struct {
 	AutoCommitted bool `json:"auto_committed,omitempty"` // tokencontrol committed changes the agent left unstaged
 
 	WorktreeBranch string `json:"worktree_branch,omitempty"` // branch name when worktree isolation used
+	BaseBranch     string `json:"base_branch,omitempty"`     // parent task branch the worktree was stacked on
 	MergeConflict  bool   `json:"merge_conflict,omitempty"`  // FF merge back to main failed
 
+	Patch   *PatchInfo     `json:"patch,omitempty"`   // exported diff in --patch-only mode
+	Changes *ChangeSummary `json:"changes,omitempty"` // diff stats and risk flags of the task's changes
+
 	Remediated   bool   `json:"remediated,omitempty"`    // strong runner fixed quality issues after completion
 	RemediatedBy string `json:"remediated_by,omitempty"` // runner that performed the remediation
 	BuildError   string `json:"build_error,omitempty"`   // build verification failure message
@@ -176,6 +247,25 @@ type TaskResult struct {
 	Attempts   []AttemptInfo `json:"attempts,omitempty"`    // all cascade attempts
 	Review     *ReviewResult `json:"review,omitempty"`      // auto-review result
 	TokensUsed *TokenUsage   `json:"tokens_used,omitempty"` // nil = no data available
+
+	Followups []FollowupInfo `json:"followups,omitempty"` // follow-up tasks the agent proposed
+}
+
+// Follow-up statuses.
+const (
+	FollowupAdded    = "added"    // inserted into the run
+	FollowupPending  = "pending"  // waiting for approval in the TUI
+	FollowupRejected = "rejected" // declined in the TUI
+	FollowupLogged   = "logged"   // recorded only (log mode)
+	FollowupDropped  = "dropped"  // invalid, over a limit, or could not be inserted
+)
+
+// FollowupInfo records a follow-up task proposed by a task's agent.
+type FollowupInfo struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // ReviewResult captures the outcome of an automatic code review.
@@ -205,11 +295,26 @@ type RunReport struct {
 	FalsePositives int                    `json:"false_positives,omitempty"`
 	AutoCommits    int                    `json:"auto_commits,omitempty"`
 	MergeConflicts int                    `json:"merge_conflicts,omitempty"`
+	RiskReviews    int                    `json:"risk_reviews,omitempty"` // completed tasks flagged by risk rules
+	RiskBlocked    int                    `json:"risk_blocked,omitempty"` // of those, tasks whose merge-back was blocked
 	Remediations   int                    `json:"remediations,omitempty"`
 	Retries        int                    `json:"retries,omitempty"`
 	TotalDuration  time.Duration          `json:"total_duration"`
 	ResetsAt       time.Time              `json:"resets_at,omitempty"`
 	TotalTokens    *TokenUsage            `json:"total_tokens,omitempty"`
+	Pauses         []RunPause             `json:"pauses,omitempty"`
+	Overlay        string                 `json:"overlay,omitempty"` // tasks edited or added from the TUI
+}
+
+// RunPause records a period where dispatch was suspended waiting for a
+// rate-limit reset (--wait-for-reset).
+type RunPause struct {
+	TaskID    string    `json:"task_id"`             // task whose rate limit triggered the pause
+	StartedAt time.Time `json:"started_at"`          // when dispatch stopped
+	ResetsAt  time.Time `json:"resets_at,omitempty"` // provider-reported reset, if known
+	ResumeAt  time.Time `json:"resume_at"`           // planned resume (reset or max wait, whichever is first)
+	EndedAt   time.Time `json:"ended_at,omitempty"`  // when the pause ended
+	Resumed   bool      `json:"resumed"`             // false if the run was cancelled or stopped during the pause
 }
 
 // UnmarshalJSON supports both old ("tasks_file": "x.json") and new
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,6 +22,17 @@ type SchedulerConfig struct {
 	ExecFn   ExecFn
 	OnUpdate func(id string, result *TaskResult) // called on state changes
 	FailFast bool                                // stop spawning on first failure
+
+	// WaitForReset keeps the run alive after a rate limit and resumes
+	// dispatch once the reset time passes instead of ending the run.
+	WaitForReset bool
+	// MaxResetWait caps how long a single pause may last. When the reset
+	// time is unknown, the pause lasts exactly MaxResetWait; zero means
+	// such rate limits end the run as usual.
+	MaxResetWait time.Duration
+	// OnPause is called when dispatch pauses and again when the pause ends
+	// (EndedAt set).
+	OnPause func(p RunPause)
 }
 
 // Scheduler manages dependency-aware parallel task execution.
@@ -31,6 +43,7 @@ type Scheduler struct {
 	mu          sync.Mutex
 	stopping    atomic.Bool  // set when fail-fast triggered
 	rateLimited atomic.Bool  // set when rate limit detected
+	failedFast  atomic.Bool  // set when fail-fast triggered; blocks resume
 	inflight    atomic.Int64 // tracks tasks enqueued or executing
 	doneCh      chan struct{}
 	doneOnce    sync.Once
@@ -39,6 +52,11 @@ type Scheduler struct {
 	work       chan string
 	taskCancel map[string]context.CancelFunc
 	finished   atomic.Bool // true after Run() exits
+
+	// Rate-limit pause state (WaitForReset), guarded by mu.
+	paused   bool
+	resumeAt time.Time
+	pauses   []RunPause
 }
 
 // NewScheduler creates a scheduler for the given task graph.
@@ -90,7 +108,12 @@ func (s *Scheduler) Run(ctx context.Context) map[string]*TaskResult {
 					s.decInflight()
 					continue
 				}
-				s.execute(ctx, id, work)
+				if !s.claim(id) {
+					// stale queue entry: task was requeued or reset meanwhile
+					s.decInflight()
+					continue
+				}
+				s.execute(ctx, id)
 				s.decInflight()
 			}
 		}()
@@ -118,6 +141,20 @@ func (s *Scheduler) Run(ctx context.Context) map[string]*TaskResult {
 	return s.results
 }
 
+// claim moves a dequeued task from Ready to Waiting. It returns false when
+// the task is no longer Ready, which guards against executing a task twice
+// when a resume re-enqueues an id that was still sitting in the queue.
+func (s *Scheduler) claim(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.results[id]
+	if !ok || r.State != StateReady {
+		return false
+	}
+	r.State = StateWaiting
+	return true
+}
+
 // decInflight decrements the inflight counter and signals done when it reaches zero.
 func (s *Scheduler) decInflight() {
 	if s.inflight.Add(-1) <= 0 {
@@ -204,24 +241,160 @@ func (s *Scheduler) RequeueTask(id, runner string) {
 	r.EndedAt = time.Time{}
 	r.Duration = 0
 	r.Attempts = nil
+	r.TokensUsed = nil // only a rate-limit resume carries usage over
 	r.FalsePositive = false
 	r.RunnerUsed = ""
 
 	// Apply runner override to the graph task.
 	if runner != "" {
 		if t := s.graph.Task(id); t != nil {
-			t.Runner = runner
+			cp := *t
+			cp.Runner = runner
+			_ = s.graph.UpdateTask(cp)
 		}
 	}
 	s.mu.Unlock()
 	s.notify(id)
 
-	// Re-enqueue via goroutine to avoid blocking TUI if channel is full.
+	// Re-enqueue without blocking the TUI if the channel is full.
+	s.enqueue(id)
+}
+
+// AddTask inserts a new task into a running graph. It is queued as soon as
+// its dependencies have completed and skipped if one of them has failed.
+// Returns an error if the run has finished or the task cannot be added.
+func (s *Scheduler) AddTask(t Task) error {
+	if !s.hold() {
+		return fmt.Errorf("run has finished")
+	}
+	defer s.decInflight()
+
+	s.mu.Lock()
+	if err := s.graph.AddTask(t); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	r := &TaskResult{TaskID: t.ID, State: StatePending}
+	s.results[t.ID] = r
+	ready := s.reevaluate(t.ID)
+	s.mu.Unlock()
+
+	s.notify(t.ID)
+	if ready {
+		s.enqueue(t.ID)
+	}
+	return nil
+}
+
+// UpdateTask replaces the definition of a task that is not running or
+// completed. A queued task whose dependencies changed is re-evaluated; a
+// failed, skipped or rate-limited task keeps its state until requeued.
+func (s *Scheduler) UpdateTask(t Task) error {
+	if !s.hold() {
+		return fmt.Errorf("run has finished")
+	}
+	defer s.decInflight()
+
+	s.mu.Lock()
+	r, ok := s.results[t.ID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("task %q not found", t.ID)
+	}
+	switch r.State {
+	case StatePending, StateReady, StateFailed, StateSkipped, StateRateLimited:
+	default:
+		s.mu.Unlock()
+		return fmt.Errorf("task %q is %s", t.ID, r.State)
+	}
+	if err := s.graph.UpdateTask(t); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	ready := false
+	if r.State == StatePending || r.State == StateReady {
+		wasReady := r.State == StateReady
+		// a queued entry left behind is dropped by claim
+		ready = s.reevaluate(t.ID) && !wasReady
+	}
+	s.mu.Unlock()
+
+	s.notify(t.ID)
+	if ready {
+		s.enqueue(t.ID)
+	}
+	return nil
+}
+
+// Hold keeps the run from ending until release is called, for example while
+// a proposed follow-up task waits for approval. Returns false if the run has
+// already ended.
+func (s *Scheduler) Hold() (release func(), ok bool) {
+	if !s.hold() {
+		return nil, false
+	}
+	var once sync.Once
+	return func() { once.Do(s.decInflight) }, true
+}
+
+// hold takes an inflight slot so the run cannot end while a task is being
+// inserted or changed. Returns false if the run has already ended.
+func (s *Scheduler) hold() bool {
+	if s.doneCh == nil || s.finished.Load() {
+		return false
+	}
+	s.inflight.Add(1)
+	select {
+	case <-s.doneCh:
+		s.inflight.Add(-1)
+		return false
+	default:
+		return true
+	}
+}
+
+// reevaluate sets the state of a pending or queued task from its
+// dependencies and reports whether it is Ready. Caller must hold s.mu.
+func (s *Scheduler) reevaluate(id string) bool {
+	r := s.results[id]
+	allDone := true
+	for _, parentID := range s.graph.Deps(id) {
+		switch s.results[parentID].State {
+		case StateCompleted:
+			continue
+		case StateFailed, StateSkipped:
+			r.State = StateSkipped
+			r.Error = fmt.Sprintf("dependency %q failed", parentID)
+			return false
+		}
+		allDone = false
+	}
+	switch {
+	case !allDone:
+		r.State = StatePending
+	case s.stopping.Load() && s.rateLimited.Load():
+		r.State = StateRateLimited
+		r.Error = "rate limit reached"
+	case s.stopping.Load():
+		r.State = StateSkipped
+		r.Error = "fail-fast: stopped after failure"
+	default:
+		r.State = StateReady
+	}
+	return r.State == StateReady
+}
+
+// enqueue queues a Ready task for the workers without blocking the caller.
+func (s *Scheduler) enqueue(id string) {
 	s.inflight.Add(1)
-	go func() { s.work <- id }()
+	select {
+	case s.work <- id:
+	default:
+		go func() { s.work <- id }()
+	}
 }
 
-func (s *Scheduler) execute(ctx context.Context, id string, work chan<- string) {
+func (s *Scheduler) execute(ctx context.Context, id string) {
 	task := s.graph.Task(id)
 	if task == nil {
 		s.setFailed(id, "task not found in graph")
@@ -254,6 +427,7 @@ func (s *Scheduler) execute(ctx context.Context, id string, work chan<- string)
 
 	s.mu.Lock()
 	result.TaskID = id
+	carryOver(result, s.results[id])
 	s.results[id] = result
 	delete(s.taskCancel, id)
 	s.mu.Unlock()
@@ -262,20 +436,25 @@ func (s *Scheduler) execute(ctx context.Context, id string, work chan<- string)
 	// handle dependents
 	switch result.State {
 	case StateCompleted:
-		s.unlockChildren(id, work)
+		s.unlockChildren(id)
 	case StateRateLimited:
 		s.rateLimited.Store(true)
 		s.stopping.Store(true)
 		s.rateLimitRemaining(result.ResetsAt)
+		s.pauseForReset(ctx, id, result.ResetsAt)
 	default:
 		if s.cfg.FailFast {
+			s.failedFast.Store(true)
 			s.stopping.Store(true)
 		}
 		s.skipDependents(id)
 	}
 }
 
-func (s *Scheduler) unlockChildren(id string, work chan<- string) {
+// unlockChildren queues the children of a completed task whose
+// dependencies are now all complete. Tasks added during the run can
+// outnumber the queue's buffer, so it never blocks the calling worker.
+func (s *Scheduler) unlockChildren(id string) {
 	children := s.graph.Children(id)
 	for _, childID := range children {
 		// fail-fast or rate-limit: skip new tasks when stopping
@@ -316,8 +495,7 @@ func (s *Scheduler) unlockChildren(id string, work chan<- string) {
 
 		if shouldStart {
 			s.notify(childID)
-			s.inflight.Add(1)
-			work <- childID
+			s.enqueue(childID)
 		}
 	}
 }
@@ -335,6 +513,181 @@ func (s *Scheduler) rateLimitRemaining(resetsAt time.Time) {
 	s.mu.Unlock()
 }
 
+// Pauses returns a copy of all rate-limit pauses recorded so far.
+func (s *Scheduler) Pauses() []RunPause {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pauses) == 0 {
+		return nil
+	}
+	out := make([]RunPause, len(s.pauses))
+	copy(out, s.pauses)
+	return out
+}
+
+// ActivePause returns the pause in progress, or nil if dispatch is running.
+func (s *Scheduler) ActivePause() *RunPause {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused || len(s.pauses) == 0 {
+		return nil
+	}
+	p := s.pauses[len(s.pauses)-1]
+	p.ResumeAt = s.resumeAt
+	return &p
+}
+
+// resumeDeadline computes when a paused run should resume.
+// Returns false when the run should end instead of waiting.
+func (s *Scheduler) resumeDeadline(resetsAt, now time.Time) (time.Time, bool) {
+	maxWait := s.cfg.MaxResetWait
+	if resetsAt.IsZero() {
+		if maxWait <= 0 {
+			return time.Time{}, false
+		}
+		return now.Add(maxWait), true
+	}
+	if maxWait > 0 && resetsAt.After(now.Add(maxWait)) {
+		return now.Add(maxWait), true
+	}
+	return resetsAt, true
+}
+
+// pauseForReset suspends dispatch until the rate limit resets. The pause
+// holds an inflight slot so Run does not return while waiting. A second
+// rate limit during the pause extends it to the later deadline.
+func (s *Scheduler) pauseForReset(ctx context.Context, id string, resetsAt time.Time) {
+	if !s.cfg.WaitForReset || s.failedFast.Load() {
+		return
+	}
+	now := time.Now()
+	until, ok := s.resumeDeadline(resetsAt, now)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	if s.paused {
+		if until.After(s.resumeAt) {
+			s.resumeAt = until
+			s.pauses[len(s.pauses)-1].ResumeAt = until
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.paused = true
+	s.resumeAt = until
+	pause := RunPause{TaskID: id, StartedAt: now, ResetsAt: resetsAt, ResumeAt: until}
+	s.pauses = append(s.pauses, pause)
+	s.mu.Unlock()
+
+	s.inflight.Add(1)
+	if s.cfg.OnPause != nil {
+		s.cfg.OnPause(pause)
+	}
+	go s.waitAndResume(ctx)
+}
+
+// waitAndResume sleeps until the current resume deadline (which may move
+// later while sleeping) and then resumes dispatch.
+func (s *Scheduler) waitAndResume(ctx context.Context) {
+	defer s.decInflight()
+	for {
+		s.mu.Lock()
+		wait := time.Until(s.resumeAt)
+		s.mu.Unlock()
+		if wait <= 0 {
+			break
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.endPause(false)
+			return
+		case <-timer.C:
+		}
+	}
+	if s.failedFast.Load() {
+		s.endPause(false)
+		return
+	}
+	s.resume()
+}
+
+// endPause closes the active pause record and notifies OnPause.
+func (s *Scheduler) endPause(resumed bool) {
+	s.mu.Lock()
+	s.paused = false
+	p := &s.pauses[len(s.pauses)-1]
+	p.EndedAt = time.Now()
+	p.Resumed = resumed
+	pause := *p
+	s.mu.Unlock()
+	if s.cfg.OnPause != nil {
+		s.cfg.OnPause(pause)
+	}
+}
+
+// carryOver prepends the attempts of an earlier dispatch, kept by resume,
+// to a task's new result and adds up their token usage.
+func carryOver(result, prior *TaskResult) {
+	if prior == nil || len(prior.Attempts) == 0 && prior.TokensUsed == nil {
+		return
+	}
+	result.Attempts = append(slices.Clone(prior.Attempts), result.Attempts...)
+	if prior.TokensUsed != nil {
+		total := *prior.TokensUsed
+		if result.TokensUsed != nil {
+			total.InputTokens += result.TokensUsed.InputTokens
+			total.OutputTokens += result.TokensUsed.OutputTokens
+			total.TotalTokens += result.TokensUsed.TotalTokens
+		}
+		result.TokensUsed = &total
+	}
+}
+
+// resume clears the rate-limit stop, resets rate-limited tasks and
+// re-enqueues those whose dependencies are complete.
+func (s *Scheduler) resume() {
+	s.endPause(true)
+	s.stopping.Store(false)
+	s.rateLimited.Store(false)
+
+	s.mu.Lock()
+	var reset []string
+	for id, r := range s.results {
+		if r.State != StateRateLimited {
+			continue
+		}
+		// attempts and tokens so far carry over into the retried result
+		s.results[id] = &TaskResult{TaskID: id, State: StatePending, Attempts: r.Attempts, TokensUsed: r.TokensUsed}
+		reset = append(reset, id)
+	}
+	var ready []string
+	for _, id := range reset {
+		allDone := true
+		for _, parentID := range s.graph.Deps(id) {
+			if s.results[parentID].State != StateCompleted {
+				allDone = false
+				break
+			}
+		}
+		if allDone {
+			s.results[id].State = StateReady
+			ready = append(ready, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range reset {
+		s.notify(id)
+	}
+	for _, id := range ready {
+		s.enqueue(id)
+	}
+}
+
 func (s *Scheduler) skipDependents(id string) {
 	dependents := s.graph.Dependents(id)
 	for _, depID := range dependents {
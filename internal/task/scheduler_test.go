@@ -696,6 +696,185 @@ func TestScheduler_WorkerLimitWithDeps(t *testing.T) {
 	}
 }
 
+func TestScheduler_RateLimitEndsRunByDefault(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a"},
+		{ID: "b", Repo: "org/r", Priority: 1, DependsOn: []string{"a"}, Title: "B", Prompt: "b"},
+	}
+	g, err := BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		return &TaskResult{TaskID: task.ID, State: StateRateLimited, ResetsAt: time.Now().Add(time.Hour)}
+	}
+	sched := NewScheduler(g, SchedulerConfig{Workers: 1, ReposDir: "/tmp", RunDir: "/tmp/run", ExecFn: execFn})
+	results := sched.Run(context.Background())
+
+	if results["b"].State != StateRateLimited {
+		t.Errorf("b: expected RATE_LIMITED, got %s", results["b"].State)
+	}
+	if len(sched.Pauses()) != 0 {
+		t.Error("no pause expected without WaitForReset")
+	}
+}
+
+func TestScheduler_WaitForResetResumes(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a"},
+		{ID: "b", Repo: "org/r", Priority: 1, DependsOn: []string{"a"}, Title: "B", Prompt: "b"},
+		{ID: "c", Repo: "org/r", Priority: 2, Title: "C", Prompt: "c"},
+	}
+	g, err := BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		// the first execution of "a" hits a rate limit that resets shortly
+		if task.ID == "a" && calls.Add(1) == 1 {
+			return &TaskResult{TaskID: task.ID, State: StateRateLimited, ResetsAt: time.Now().Add(100 * time.Millisecond),
+				Attempts:   []AttemptInfo{{Runner: "claude", State: StateRateLimited}},
+				TokensUsed: &TokenUsage{InputTokens: 100, OutputTokens: 10, TotalTokens: 110}}
+		}
+		return &TaskResult{TaskID: task.ID, State: StateCompleted, EndedAt: time.Now(),
+			Attempts:   []AttemptInfo{{Runner: "codex", State: StateCompleted}},
+			TokensUsed: &TokenUsage{InputTokens: 50, OutputTokens: 5, TotalTokens: 55}}
+	}
+
+	var pauseEvents atomic.Int32
+	sched := NewScheduler(g, SchedulerConfig{
+		Workers:      1,
+		ReposDir:     "/tmp",
+		RunDir:       "/tmp/run",
+		ExecFn:       execFn,
+		WaitForReset: true,
+		OnPause:      func(RunPause) { pauseEvents.Add(1) },
+	})
+	results := sched.Run(context.Background())
+
+	for id, r := range results {
+		if r.State != StateCompleted {
+			t.Errorf("task %s: expected COMPLETED after resume, got %s", id, r.State)
+		}
+	}
+	// the rate-limited attempt and its tokens stay in the report
+	a := results["a"]
+	if len(a.Attempts) != 2 || a.Attempts[0].Runner != "claude" || a.Attempts[1].Runner != "codex" {
+		t.Errorf("a attempts = %+v, want the claude attempt kept before codex", a.Attempts)
+	}
+	if a.TokensUsed == nil || a.TokensUsed.TotalTokens != 165 || a.TokensUsed.InputTokens != 150 {
+		t.Errorf("a tokens = %+v, want both dispatches summed", a.TokensUsed)
+	}
+	if len(results["c"].Attempts) != 1 {
+		t.Errorf("c attempts = %+v, want only its own", results["c"].Attempts)
+	}
+
+	pauses := sched.Pauses()
+	if len(pauses) != 1 {
+		t.Fatalf("expected 1 pause, got %d", len(pauses))
+	}
+	p := pauses[0]
+	if p.TaskID != "a" || !p.Resumed || p.EndedAt.Before(p.ResumeAt) {
+		t.Errorf("unexpected pause record: %+v", p)
+	}
+	if pauseEvents.Load() != 2 {
+		t.Errorf("expected pause start+end events, got %d", pauseEvents.Load())
+	}
+	if sched.ActivePause() != nil {
+		t.Error("no pause should be active after the run")
+	}
+}
+
+func TestScheduler_WaitForResetMaxWait(t *testing.T) {
+	tasks := []Task{{ID: "a", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a"}}
+	g, err := BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		if calls.Add(1) == 1 {
+			// reset far in the future — max wait must cut the pause short
+			return &TaskResult{TaskID: task.ID, State: StateRateLimited, ResetsAt: time.Now().Add(time.Hour)}
+		}
+		return &TaskResult{TaskID: task.ID, State: StateCompleted}
+	}
+	sched := NewScheduler(g, SchedulerConfig{
+		Workers:      1,
+		ReposDir:     "/tmp",
+		RunDir:       "/tmp/run",
+		ExecFn:       execFn,
+		WaitForReset: true,
+		MaxResetWait: 50 * time.Millisecond,
+	})
+
+	done := make(chan map[string]*TaskResult)
+	go func() { done <- sched.Run(context.Background()) }()
+	select {
+	case results := <-done:
+		if results["a"].State != StateCompleted {
+			t.Errorf("a: expected COMPLETED, got %s", results["a"].State)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not resume after max wait")
+	}
+}
+
+func TestScheduler_WaitForResetUnknownResetEndsRun(t *testing.T) {
+	tasks := []Task{{ID: "a", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a"}}
+	g, err := BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		return &TaskResult{TaskID: task.ID, State: StateRateLimited}
+	}
+	sched := NewScheduler(g, SchedulerConfig{Workers: 1, ReposDir: "/tmp", RunDir: "/tmp/run", ExecFn: execFn, WaitForReset: true})
+	results := sched.Run(context.Background())
+	if results["a"].State != StateRateLimited {
+		t.Errorf("a: expected RATE_LIMITED, got %s", results["a"].State)
+	}
+	if len(sched.Pauses()) != 0 {
+		t.Error("unknown reset without max wait should not pause")
+	}
+}
+
+func TestScheduler_WaitForResetCancelled(t *testing.T) {
+	tasks := []Task{{ID: "a", Repo: "org/r", Priority: 1, Title: "A", Prompt: "a"}}
+	g, err := BuildGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		return &TaskResult{TaskID: task.ID, State: StateRateLimited, ResetsAt: time.Now().Add(time.Hour)}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sched := NewScheduler(g, SchedulerConfig{
+		Workers:      1,
+		ReposDir:     "/tmp",
+		RunDir:       "/tmp/run",
+		ExecFn:       execFn,
+		WaitForReset: true,
+		OnPause: func(p RunPause) {
+			if p.EndedAt.IsZero() {
+				cancel()
+			}
+		},
+	})
+	results := sched.Run(ctx)
+	if results["a"].State != StateRateLimited {
+		t.Errorf("a: expected RATE_LIMITED, got %s", results["a"].State)
+	}
+	pauses := sched.Pauses()
+	if len(pauses) != 1 || pauses[0].Resumed {
+		t.Errorf("expected one unresumed pause, got %+v", pauses)
+	}
+}
+
 func TestRepoName(t *testing.T) {
 	tests := []struct {
 		input string
@@ -713,3 +892,232 @@ func TestRepoName(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduler_AddTaskDuringRun(t *testing.T) {
+	g, err := BuildGraph([]Task{
+		{ID: "a", Repo: "org/r", Priority: 1, Prompt: "a"},
+		{ID: "bad", Repo: "org/r", Priority: 1, Prompt: "bad"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	var sched *Scheduler
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		switch task.ID {
+		case "a":
+			<-release
+		case "bad":
+			return &TaskResult{TaskID: task.ID, State: StateFailed}
+		}
+		return &TaskResult{TaskID: task.ID, State: StateCompleted}
+	}
+	sched = NewScheduler(g, SchedulerConfig{
+		Workers:  2,
+		ReposDir: "/tmp",
+		RunDir:   "/tmp/run",
+		ExecFn:   execFn,
+		OnUpdate: func(id string, r *TaskResult) {
+			if id != "bad" || r.State != StateFailed {
+				return
+			}
+			// a is still running, so the run is alive
+			if err := sched.AddTask(Task{ID: "child", Repo: "org/r", DependsOn: []string{"a"}}); err != nil {
+				t.Errorf("AddTask child: %v", err)
+			}
+			if err := sched.AddTask(Task{ID: "orphan", Repo: "org/r", DependsOn: []string{"bad"}}); err != nil {
+				t.Errorf("AddTask orphan: %v", err)
+			}
+			if err := sched.AddTask(Task{ID: "adhoc", Repo: "org/r"}); err != nil {
+				t.Errorf("AddTask adhoc: %v", err)
+			}
+			if err := sched.AddTask(Task{ID: "adhoc", Repo: "org/r"}); err == nil {
+				t.Error("expected duplicate id error")
+			}
+			close(release)
+		},
+	})
+
+	results := sched.Run(context.Background())
+
+	for id, want := range map[string]TaskState{
+		"a": StateCompleted, "child": StateCompleted, "adhoc": StateCompleted, "orphan": StateSkipped,
+	} {
+		if r := results[id]; r == nil || r.State != want {
+			t.Errorf("%s = %v, want %s", id, r, want)
+		}
+	}
+	if err := sched.AddTask(Task{ID: "late", Repo: "org/r"}); err == nil {
+		t.Error("expected error after run finished")
+	}
+}
+
+func TestScheduler_RequeueDropsFailedRunTokens(t *testing.T) {
+	g, err := BuildGraph([]Task{{ID: "a", Repo: "org/r", Priority: 1, Prompt: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		if calls.Add(1) == 1 {
+			return &TaskResult{TaskID: task.ID, State: StateFailed, TokensUsed: &TokenUsage{TotalTokens: 100},
+				Attempts: []AttemptInfo{{Runner: "codex", State: StateFailed}}}
+		}
+		return &TaskResult{TaskID: task.ID, State: StateCompleted, TokensUsed: &TokenUsage{TotalTokens: 40},
+			Attempts: []AttemptInfo{{Runner: "claude", State: StateCompleted}}}
+	}
+	var sched *Scheduler
+	var once sync.Once
+	sched = NewScheduler(g, SchedulerConfig{
+		Workers:  1,
+		ReposDir: "/tmp",
+		RunDir:   "/tmp/run",
+		ExecFn:   execFn,
+		OnUpdate: func(id string, r *TaskResult) {
+			if r.State == StateFailed {
+				once.Do(func() { sched.RequeueTask(id, "claude") })
+			}
+		},
+	})
+
+	r := sched.Run(context.Background())["a"]
+	if r.State != StateCompleted {
+		t.Fatalf("a = %s, want completed after requeue", r.State)
+	}
+	if r.TokensUsed == nil || r.TokensUsed.TotalTokens != 40 {
+		t.Errorf("tokens = %+v, want only the requeued run's 40", r.TokensUsed)
+	}
+	if len(r.Attempts) != 1 {
+		t.Errorf("attempts = %+v, want only the requeued run's", r.Attempts)
+	}
+}
+
+func TestScheduler_AddedDependentsOutnumberQueue(t *testing.T) {
+	g, err := BuildGraph([]Task{{ID: "a", Repo: "org/r", Priority: 1, Prompt: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sched *Scheduler
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		if task.ID == "a" {
+			// more dependents than the queue was sized for, with no idle worker
+			for _, id := range []string{"b", "c", "d"} {
+				if err := sched.AddTask(Task{ID: id, Repo: "org/r", DependsOn: []string{"a"}}); err != nil {
+					t.Errorf("AddTask %s: %v", id, err)
+				}
+			}
+		}
+		return &TaskResult{TaskID: task.ID, State: StateCompleted}
+	}
+	sched = NewScheduler(g, SchedulerConfig{Workers: 1, ReposDir: "/tmp", RunDir: "/tmp/run", ExecFn: execFn})
+
+	done := make(chan map[string]*TaskResult)
+	go func() { done <- sched.Run(context.Background()) }()
+	select {
+	case results := <-done:
+		for _, id := range []string{"a", "b", "c", "d"} {
+			if r := results[id]; r == nil || r.State != StateCompleted {
+				t.Errorf("%s = %v, want completed", id, r)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return: worker blocked queueing added dependents")
+	}
+}
+
+func TestScheduler_UpdateTask(t *testing.T) {
+	g, err := BuildGraph([]Task{
+		{ID: "a", Repo: "org/r", Priority: 1, Prompt: "a"},
+		{ID: "b", Repo: "org/r", Priority: 1, Prompt: "old", DependsOn: []string{"a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	prompts := map[string]string{}
+	release := make(chan struct{})
+	var sched *Scheduler
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		if task.ID == "a" {
+			if err := sched.UpdateTask(Task{ID: "a", Repo: "org/r"}); err == nil {
+				t.Error("running task should not be editable")
+			}
+			if err := sched.UpdateTask(Task{ID: "b", Repo: "org/r", Prompt: "new", Runner: "claude", DependsOn: []string{"a"}}); err != nil {
+				t.Errorf("UpdateTask b: %v", err)
+			}
+			close(release)
+		}
+		mu.Lock()
+		prompts[task.ID] = task.Prompt + "/" + task.Runner
+		mu.Unlock()
+		return &TaskResult{TaskID: task.ID, State: StateCompleted}
+	}
+	sched = NewScheduler(g, SchedulerConfig{Workers: 1, ReposDir: "/tmp", RunDir: "/tmp/run", ExecFn: execFn})
+
+	results := sched.Run(context.Background())
+	<-release
+
+	if results["b"].State != StateCompleted {
+		t.Fatalf("b = %s", results["b"].State)
+	}
+	if prompts["b"] != "new/claude" {
+		t.Errorf("b ran with %q, want edited definition", prompts["b"])
+	}
+	if err := sched.UpdateTask(Task{ID: "b"}); err == nil {
+		t.Error("expected error after run finished")
+	}
+}
+
+func TestScheduler_UpdateTaskDropsDependency(t *testing.T) {
+	g, err := BuildGraph([]Task{
+		{ID: "slow", Repo: "org/r", Priority: 1},
+		{ID: "fast", Repo: "org/r", Priority: 1},
+		{ID: "b", Repo: "org/r", Priority: 1, DependsOn: []string{"slow"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	var sched *Scheduler
+	var order []string
+	var mu sync.Mutex
+	execFn := func(_ context.Context, task *Task, _, _ string) *TaskResult {
+		switch task.ID {
+		case "slow":
+			<-release
+		case "fast":
+			// b no longer waits for slow and starts right away
+			if err := sched.UpdateTask(Task{ID: "b", Repo: "org/r", Priority: 1}); err != nil {
+				t.Errorf("UpdateTask: %v", err)
+			}
+		case "b":
+			close(release)
+		}
+		mu.Lock()
+		order = append(order, task.ID)
+		mu.Unlock()
+		return &TaskResult{TaskID: task.ID, State: StateCompleted}
+	}
+	sched = NewScheduler(g, SchedulerConfig{Workers: 2, ReposDir: "/tmp", RunDir: "/tmp/run", ExecFn: execFn})
+
+	done := make(chan map[string]*TaskResult)
+	go func() { done <- sched.Run(context.Background()) }()
+	select {
+	case results := <-done:
+		for id, r := range results {
+			if r.State != StateCompleted {
+				t.Errorf("%s = %s", id, r.State)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not finish; b should have been started by the update")
+	}
+	if order[len(order)-1] != "slow" {
+		t.Errorf("order = %v, want slow last", order)
+	}
+}
@@ -0,0 +1,170 @@
+package telemetry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Budget windows. Windows are calendar-aligned in UTC so every process
+// agrees on when a budget resets.
+const (
+	WindowDaily   = "daily"
+	WindowWeekly  = "weekly"
+	WindowMonthly = "monthly"
+)
+
+// Budget is a spend limit for one provider or runner profile over a window.
+// A zero MaxUSD or MaxTokens means that dimension is unlimited.
+type Budget struct {
+	Key       string  `json:"key"` // provider ("anthropic") or runner profile name ("codex")
+	Window    string  `json:"window"`
+	MaxUSD    float64 `json:"max_usd,omitempty"`
+	MaxTokens int     `json:"max_tokens,omitempty"`
+}
+
+// BudgetStatus reports consumption against a budget in its current window.
+type BudgetStatus struct {
+	Budget
+	SpentUSD    float64   `json:"spent_usd"`
+	SpentTokens int       `json:"spent_tokens"`
+	ResetsAt    time.Time `json:"resets_at"`
+}
+
+// Exhausted returns true when either the USD or token limit has been reached.
+func (s BudgetStatus) Exhausted() bool {
+	if s.MaxUSD > 0 && s.SpentUSD >= s.MaxUSD {
+		return true
+	}
+	return s.MaxTokens > 0 && s.SpentTokens >= s.MaxTokens
+}
+
+// RemainingUSD returns the USD headroom, floored at zero.
+func (s BudgetStatus) RemainingUSD() float64 {
+	if s.SpentUSD >= s.MaxUSD {
+		return 0
+	}
+	return s.MaxUSD - s.SpentUSD
+}
+
+// RemainingTokens returns the token headroom, floored at zero.
+func (s BudgetStatus) RemainingTokens() int {
+	if s.SpentTokens >= s.MaxTokens {
+		return 0
+	}
+	return s.MaxTokens - s.SpentTokens
+}
+
+// Matches returns true if spend by the given runner/provider counts against this budget.
+func (s BudgetStatus) Matches(runner, provider string) bool {
+	return s.Key != "" && (s.Key == runner || s.Key == provider)
+}
+
+// WindowBounds returns the start and end of the window containing now.
+func WindowBounds(window string, now time.Time) (start, end time.Time, err error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch window {
+	case WindowDaily:
+		return day, day.AddDate(0, 0, 1), nil
+	case WindowWeekly:
+		// weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		start = day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7), nil
+	case WindowMonthly:
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown budget window %q", window)
+	}
+}
+
+// QueryBudgets returns current-window consumption for each budget.
+// Spend matches task executions whose provider or runner equals the budget key.
+func QueryBudgets(db *DB, budgets []Budget, now time.Time) ([]BudgetStatus, error) {
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for _, b := range budgets {
+		start, end, err := WindowBounds(b.Window, now)
+		if err != nil {
+			return nil, err
+		}
+		st := BudgetStatus{Budget: b, ResetsAt: end}
+		err = db.conn.QueryRow(`SELECT COALESCE(SUM(cost_usd), 0), COALESCE(SUM(total_tokens), 0)
+			FROM task_executions
+			WHERE (provider = ? OR runner = ?) AND created_at >= ?`,
+			b.Key, b.Key, start.Format(time.RFC3339)).Scan(&st.SpentUSD, &st.SpentTokens)
+		if err != nil {
+			return nil, fmt.Errorf("query budget %s/%s: %w", b.Key, b.Window, err)
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// BudgetLedger tracks budget consumption during a run. It is seeded from the
+// telemetry DB and charged after each runner attempt, because telemetry rows
+// are only written once the run ends. It is safe for concurrent use.
+type BudgetLedger struct {
+	mu       sync.Mutex
+	statuses []BudgetStatus
+	now      func() time.Time
+}
+
+// NewBudgetLedger creates a ledger from current-window statuses.
+func NewBudgetLedger(statuses []BudgetStatus) *BudgetLedger {
+	cp := make([]BudgetStatus, len(statuses))
+	copy(cp, statuses)
+	return &BudgetLedger{statuses: cp, now: time.Now}
+}
+
+// Charge adds spend by a runner and returns the budgets that this charge
+// exhausted. Budgets that were already exhausted are not returned again.
+func (l *BudgetLedger) Charge(runner, provider string, costUSD float64, tokens int) []BudgetStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var exhausted []BudgetStatus
+	for i := range l.statuses {
+		st := &l.statuses[i]
+		if !st.Matches(runner, provider) {
+			continue
+		}
+		// window rolled over during a long run — start counting afresh
+		if !now.Before(st.ResetsAt) {
+			if _, end, err := WindowBounds(st.Window, now); err == nil {
+				st.SpentUSD, st.SpentTokens, st.ResetsAt = 0, 0, end
+			}
+		}
+		wasExhausted := st.Exhausted()
+		st.SpentUSD += costUSD
+		st.SpentTokens += tokens
+		if !wasExhausted && st.Exhausted() {
+			exhausted = append(exhausted, *st)
+		}
+	}
+	return exhausted
+}
+
+// Exhausted returns all budgets that are currently over their limit.
+func (l *BudgetLedger) Exhausted() []BudgetStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []BudgetStatus
+	for _, st := range l.statuses {
+		if st.Exhausted() && l.now().Before(st.ResetsAt) {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// Statuses returns a copy of all tracked budget statuses.
+func (l *BudgetLedger) Statuses() []BudgetStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]BudgetStatus, len(l.statuses))
+	copy(out, l.statuses)
+	return out
+}
@@ -0,0 +1,177 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func TestWindowBounds(t *testing.T) {
+	// Wednesday 2026-03-11 15:30 UTC
+	now := time.Date(2026, 3, 11, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		window     string
+		start, end time.Time
+	}{
+		{WindowDaily, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{WindowWeekly, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{WindowMonthly, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		start, end, err := WindowBounds(tt.window, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.window, err)
+		}
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%s: got [%s, %s), want [%s, %s)", tt.window, start, end, tt.start, tt.end)
+		}
+	}
+
+	if _, _, err := WindowBounds("hourly", now); err == nil {
+		t.Error("expected error for unknown window")
+	}
+}
+
+func TestWindowBounds_WeeklyOnSunday(t *testing.T) {
+	sunday := time.Date(2026, 3, 15, 23, 0, 0, 0, time.UTC)
+	start, _, err := WindowBounds(WindowWeekly, sunday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("got %s, want %s", start, want)
+	}
+}
+
+func TestQueryBudgets_MatchesProviderAndRunner(t *testing.T) {
+	db := tempDB(t)
+
+	now := time.Now()
+	tasks := []task.Task{
+		{ID: "t1", Repo: "org/repo"},
+		{ID: "t2", Repo: "org/repo"},
+	}
+	report := &task.RunReport{
+		RunID: "budget1",
+		Results: map[string]*task.TaskResult{
+			"t1": {
+				TaskID: "t1", State: task.StateCompleted, RunnerUsed: "codex",
+				TokensUsed: &task.TokenUsage{InputTokens: 600_000, OutputTokens: 400_000},
+			},
+			"t2": {
+				TaskID: "t2", State: task.StateCompleted, RunnerUsed: "sonnet",
+				TokensUsed: &task.TokenUsage{InputTokens: 1_000_000, OutputTokens: 100_000},
+			},
+		},
+	}
+	profiles := map[string]*task.RunnerProfileConfig{
+		"sonnet": {Type: "claude", Model: "claude-sonnet-4-6"},
+	}
+	if err := Record(db, report, tasks, profiles); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := QueryBudgets(db, []Budget{
+		{Key: "anthropic", Window: WindowDaily, MaxUSD: 4},
+		{Key: "codex", Window: WindowWeekly, MaxTokens: 5_000_000},
+		{Key: "openai", Window: WindowMonthly, MaxTokens: 500_000},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+
+	anthropic := statuses[0]
+	wantCost := EstimateCost("claude-sonnet-4-6", 1_000_000, 100_000)
+	if anthropic.SpentUSD != wantCost {
+		t.Errorf("anthropic spent: got %.4f, want %.4f", anthropic.SpentUSD, wantCost)
+	}
+	if !anthropic.Exhausted() {
+		t.Error("anthropic budget should be exhausted")
+	}
+
+	codex := statuses[1]
+	if codex.SpentTokens != 1_000_000 {
+		t.Errorf("codex tokens: got %d, want 1000000", codex.SpentTokens)
+	}
+	if codex.Exhausted() {
+		t.Error("codex budget should have headroom")
+	}
+	if codex.RemainingTokens() != 4_000_000 {
+		t.Errorf("codex remaining: got %d, want 4000000", codex.RemainingTokens())
+	}
+
+	// codex runner resolves to the openai provider
+	if !statuses[2].Exhausted() {
+		t.Error("openai budget should be exhausted by codex spend")
+	}
+}
+
+func TestQueryBudgets_IgnoresPreviousWindow(t *testing.T) {
+	db := tempDB(t)
+	yesterday := time.Now().UTC().AddDate(0, 0, -2).Format(time.RFC3339)
+	_, err := db.conn.Exec(`INSERT INTO task_executions
+		(id, run_id, task_id, runner, state, total_tokens, cost_usd, provider, created_at)
+		VALUES ('old/t1', 'old', 't1', 'codex', 'COMPLETED', 9000000, 50, 'openai', ?)`, yesterday)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := QueryBudgets(db, []Budget{{Key: "openai", Window: WindowDaily, MaxUSD: 10}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].SpentUSD != 0 || statuses[0].Exhausted() {
+		t.Errorf("expected no spend in current window, got $%.2f", statuses[0].SpentUSD)
+	}
+}
+
+func TestBudgetLedger_Charge(t *testing.T) {
+	ledger := NewBudgetLedger([]BudgetStatus{
+		{Budget: Budget{Key: "anthropic", Window: WindowDaily, MaxUSD: 20}, SpentUSD: 15, ResetsAt: time.Now().Add(time.Hour)},
+		{Budget: Budget{Key: "codex", Window: WindowWeekly, MaxTokens: 1000}, ResetsAt: time.Now().Add(time.Hour)},
+	})
+
+	if got := ledger.Charge("sonnet", "anthropic", 3, 100); len(got) != 0 {
+		t.Fatalf("expected no exhaustion yet, got %v", got)
+	}
+	got := ledger.Charge("sonnet", "anthropic", 3, 100)
+	if len(got) != 1 || got[0].Key != "anthropic" {
+		t.Fatalf("expected anthropic exhausted, got %v", got)
+	}
+	// already exhausted — not reported twice
+	if got := ledger.Charge("sonnet", "anthropic", 1, 0); len(got) != 0 {
+		t.Fatalf("expected no repeat exhaustion, got %v", got)
+	}
+	// runner-keyed budget only matches that runner
+	if got := ledger.Charge("codex-mini", "openai", 0, 5000); len(got) != 0 {
+		t.Fatalf("codex budget should not match codex-mini, got %v", got)
+	}
+	if got := ledger.Charge("codex", "openai", 0, 5000); len(got) != 1 {
+		t.Fatalf("expected codex exhausted, got %v", got)
+	}
+
+	if n := len(ledger.Exhausted()); n != 2 {
+		t.Errorf("expected 2 exhausted budgets, got %d", n)
+	}
+}
+
+func TestBudgetLedger_WindowRollover(t *testing.T) {
+	ledger := NewBudgetLedger([]BudgetStatus{
+		{Budget: Budget{Key: "anthropic", Window: WindowDaily, MaxUSD: 10}, SpentUSD: 12, ResetsAt: time.Now().Add(-time.Minute)},
+	})
+	if n := len(ledger.Exhausted()); n != 0 {
+		t.Fatalf("expired window should not count as exhausted, got %d", n)
+	}
+	ledger.Charge("claude", "anthropic", 1, 0)
+	st := ledger.Statuses()[0]
+	if st.SpentUSD != 1 {
+		t.Errorf("expected spend reset on rollover, got $%.2f", st.SpentUSD)
+	}
+	if !st.ResetsAt.After(time.Now()) {
+		t.Error("expected resets_at to advance to next window")
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 const (
 	dbDriver        = "sqlite"
-	dbSchemaVersion = 2
+	dbSchemaVersion = 4
 )
 
 // DB wraps a SQLite connection for telemetry storage.
@@ -71,6 +71,16 @@ func (db *DB) migrate() error {
 			return fmt.Errorf("migrate v2: %w", err)
 		}
 	}
+	if version < 3 {
+		if err := db.migrateV3(); err != nil {
+			return fmt.Errorf("migrate v3: %w", err)
+		}
+	}
+	if version < 4 {
+		if err := db.migrateV4(); err != nil {
+			return fmt.Errorf("migrate v4: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -182,3 +192,76 @@ func (db *DB) migrateV2() error {
 
 	return tx.Commit()
 }
+
+func (db *DB) migrateV3() error {
+	stmts := []string{
+		// Provider column so budgets can aggregate spend across runner profiles
+		`ALTER TABLE task_executions ADD COLUMN provider TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_te_provider ON task_executions(provider)`,
+
+		`INSERT INTO schema_version (version) VALUES (3)`,
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("exec %q: %w", stmt[:40], err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (db *DB) migrateV4() error {
+	stmts := []string{
+		// Scan history for `scan trend`
+		`CREATE TABLE IF NOT EXISTS scan_runs (
+			scan_id       TEXT PRIMARY KEY,
+			repos_dir     TEXT NOT NULL DEFAULT '',
+			repos_scanned INTEGER NOT NULL DEFAULT 0,
+			findings      INTEGER NOT NULL DEFAULT 0,
+			created_at    TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS scan_repos (
+			scan_id TEXT NOT NULL,
+			repo    TEXT NOT NULL,
+			PRIMARY KEY (scan_id, repo)
+		)`,
+		`CREATE TABLE IF NOT EXISTS scan_findings (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			scan_id     TEXT NOT NULL,
+			fingerprint TEXT NOT NULL,
+			repo        TEXT NOT NULL,
+			check_id    TEXT NOT NULL,
+			category    TEXT NOT NULL DEFAULT '',
+			severity    TEXT NOT NULL DEFAULT '',
+			message     TEXT NOT NULL DEFAULT '',
+			status      TEXT NOT NULL DEFAULT '',
+			created_at  TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_sf_scan_id ON scan_findings(scan_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_sf_fingerprint ON scan_findings(fingerprint)`,
+		`CREATE INDEX IF NOT EXISTS idx_sr_created_at ON scan_runs(created_at)`,
+
+		`INSERT INTO schema_version (version) VALUES (4)`,
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("exec %q: %w", stmt[:40], err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"database/sql"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/scan"
+)
+
+// RepoHealth is one repo's row in the portfolio: its health score at the
+// latest scan that covered it, and what has been run and spent on it.
+type RepoHealth struct {
+	Repo       string         `json:"repo"`
+	Score      int            `json:"score"`
+	Trend      *int           `json:"trend,omitempty"` // score change since the repo's previous scan; nil if scanned once
+	Findings   int            `json:"findings"`        // not counting suppressed findings
+	BySeverity map[string]int `json:"by_severity"`
+	ScannedAt  time.Time      `json:"scanned_at"`
+	OpenTasks  int            `json:"open_tasks"` // filled in by the caller from the state tracker
+	LastRun    time.Time      `json:"last_run,omitzero"`
+	CostUSD    float64        `json:"cost_usd"`
+}
+
+// QueryPortfolio scores every repo that has been scanned, using each repo's
+// latest two scans for the score and trend. Task runs are matched to repos
+// by the last element of the task's repo, so "owner/app" counts for "app".
+func QueryPortfolio(db *DB, weights scan.ScoreWeights) ([]RepoHealth, error) {
+	scans, err := loadScans(db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byRepo := make(map[string]*RepoHealth)
+	var order []string
+	for _, s := range scans {
+		for _, r := range s.repos {
+			var findings []scan.Finding
+			bySeverity := make(map[string]int)
+			for _, f := range s.findings[r] {
+				sf := scan.Finding{Repo: r, Category: f.Category, Severity: scan.ParseSeverity(f.Severity)}
+				if f.suppressed {
+					sf.Status = scan.StatusSuppressed
+				} else {
+					bySeverity[f.Severity]++
+				}
+				findings = append(findings, sf)
+			}
+			score := scan.HealthScore(findings, weights)
+
+			h := byRepo[r]
+			if h == nil {
+				h = &RepoHealth{Repo: r}
+				byRepo[r] = h
+				order = append(order, r)
+			} else {
+				trend := score - h.Score
+				h.Trend = &trend
+			}
+			h.Score, h.ScannedAt, h.BySeverity = score, s.at, bySeverity
+			h.Findings = 0
+			for _, n := range bySeverity {
+				h.Findings += n
+			}
+		}
+	}
+
+	if err := addRunStats(db, byRepo); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(order)
+	out := make([]RepoHealth, 0, len(order))
+	for _, r := range order {
+		out = append(out, *byRepo[r])
+	}
+	return out, nil
+}
+
+// addRunStats fills in the last successful run and total cost per repo.
+func addRunStats(db *DB, byRepo map[string]*RepoHealth) error {
+	rows, err := db.conn.Query(`SELECT repo,
+		MAX(CASE WHEN state = 'COMPLETED' THEN created_at END),
+		COALESCE(SUM(cost_usd), 0)
+		FROM task_executions WHERE repo != '' GROUP BY repo`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var repo string
+		var lastRun sql.NullString
+		var cost float64
+		if err := rows.Scan(&repo, &lastRun, &cost); err != nil {
+			return err
+		}
+		h := byRepo[path.Base(repo)]
+		if h == nil {
+			continue
+		}
+		h.CostUSD += cost
+		if lastRun.Valid {
+			if t, err := time.Parse(time.RFC3339, lastRun.String); err == nil && t.After(h.LastRun) {
+				h.LastRun = t
+			}
+		}
+	}
+	return rows.Err()
+}
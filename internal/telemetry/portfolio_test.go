@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/scan"
+)
+
+func TestQueryPortfolio(t *testing.T) {
+	db := tempDB(t)
+	day := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	critical := makeFinding("app", "sec-env-committed", "security")
+	critical.Severity = scan.SeverityCritical
+	suppressed := makeFinding("lib", "missing-license", "structure")
+	suppressed.Status = scan.StatusSuppressed
+	scans := []*scan.ScanResult{
+		{ReposScanned: []string{"app", "lib"}, Findings: []scan.Finding{
+			critical,
+			makeFinding("app", "missing-ci", "structure"),
+		}},
+		// app fixed its secret, lib gained a warning; docs was scanned alone
+		{ReposScanned: []string{"app", "lib"}, Findings: []scan.Finding{
+			makeFinding("app", "missing-ci", "structure"),
+			makeFinding("lib", "go-no-tests", "go"),
+			suppressed,
+		}},
+		{ReposScanned: []string{"docs"}},
+	}
+	for i, r := range scans {
+		if err := RecordScan(db, string(rune('a'+i)), "/repos", day.AddDate(0, 0, i), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, e := range []struct {
+		id, repo, state string
+		cost            float64
+		at              time.Time
+	}{
+		{"r1/app-scan-a", "ppiankov/app", "COMPLETED", 0.5, day},
+		{"r2/app-scan-b", "ppiankov/app", "FAILED", 0.25, day.AddDate(0, 0, 3)},
+		{"r2/lib-scan-c", "lib", "FAILED", 1, day},
+		{"r2/other-x", "ppiankov/other", "COMPLETED", 9, day},
+	} {
+		_, err := db.conn.Exec(`INSERT INTO task_executions (id, run_id, task_id, runner, state, repo, cost_usd, created_at)
+			VALUES (?, 'r', ?, 'codex', ?, ?, ?, ?)`, e.id, e.id, e.state, e.repo, e.cost, e.at.Format(time.RFC3339))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos, err := QueryPortfolio(db, scan.DefaultScoreWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("repos = %+v, want app, docs and lib", repos)
+	}
+
+	app, docs, lib := repos[0], repos[1], repos[2]
+	if app.Repo != "app" || app.Score != 97 || app.Trend == nil || *app.Trend != 20 || app.Findings != 1 {
+		t.Errorf("app = %+v, want score 97 up 20", app)
+	}
+	if !app.LastRun.Equal(day) || app.CostUSD != 0.75 {
+		t.Errorf("app last run %v cost %v, want %v and 0.75", app.LastRun, app.CostUSD, day)
+	}
+	if !app.ScannedAt.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("app scanned at %v", app.ScannedAt)
+	}
+
+	if docs.Score != 100 || docs.Trend != nil || docs.Findings != 0 {
+		t.Errorf("docs = %+v, want score 100 without a trend", docs)
+	}
+
+	if lib.Score != 97 || lib.Trend == nil || *lib.Trend != -3 || lib.BySeverity["warning"] != 1 || lib.Findings != 1 {
+		t.Errorf("lib = %+v, want score 97 down 3 with the suppressed finding ignored", lib)
+	}
+	if !lib.LastRun.IsZero() || lib.CostUSD != 1 {
+		t.Errorf("lib last run %v cost %v, want none and 1", lib.LastRun, lib.CostUSD)
+	}
+}
@@ -0,0 +1,222 @@
+package telemetry
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// RunnerRate holds per-runner throughput learned from past runs.
+// LimitedRequestsPerMin and LimitedTokensPerMin are the rates the runner
+// sustained in the minutes before it was rate limited, taken at a high
+// percentile across runs — the best available estimate of the provider's
+// real ceiling. They are zero when the runner has never been rate limited.
+type RunnerRate struct {
+	Runner                string
+	Tasks                 int
+	AvgTokensPerTask      int
+	LimitedRuns           int
+	LimitedRequestsPerMin float64
+	LimitedTokensPerMin   float64
+}
+
+const (
+	// rateWindow is how far before a runner's first rate limit in a run its
+	// requests and tokens are counted.
+	rateWindow = 5 * time.Minute
+
+	// minRateWindow is the shortest span used to derive a per-minute rate.
+	// Shorter spans are bursts that say little about sustained limits.
+	minRateWindow = time.Minute
+
+	// limitedRatePercentile picks the learned rate across limited runs: a
+	// high percentile, so one run limited at a low rate (a transient
+	// provider error, a quota shared with another client) doesn't throttle
+	// every later run.
+	limitedRatePercentile = 0.9
+
+	// Floors under learned limits, so pacing never stalls on a bad sample.
+	minLearnedRequestsPerMin = 1.0
+	minLearnedTokensPerMin   = 10_000.0
+)
+
+// QueryRunnerRates returns learned request and token rates per runner.
+func QueryRunnerRates(db *DB, since string) ([]RunnerRate, error) {
+	query := `SELECT runner, COUNT(*) as tasks,
+		COALESCE(AVG(CASE WHEN tokens_reported = 1 THEN total_tokens END), 0) as avg_tokens
+		FROM task_executions WHERE runner != ''`
+	var args []any
+	if since != "" {
+		query += ` AND created_at >= ?`
+		args = append(args, since)
+	}
+	query += ` GROUP BY runner ORDER BY runner`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	byRunner := make(map[string]*RunnerRate)
+	var results []*RunnerRate
+	for rows.Next() {
+		var r RunnerRate
+		var avg float64
+		if err := rows.Scan(&r.Runner, &r.Tasks, &avg); err != nil {
+			return nil, err
+		}
+		r.AvgTokensPerTask = int(avg)
+		byRunner[r.Runner] = &r
+		results = append(results, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	limited, err := queryLimitedRates(db, since)
+	if err != nil {
+		return nil, err
+	}
+	for name, samples := range limited {
+		r, ok := byRunner[name]
+		if !ok {
+			r = &RunnerRate{Runner: name}
+			byRunner[name] = r
+			results = append(results, r)
+		}
+		var rpms, tpms []float64
+		for _, s := range samples {
+			rpms = append(rpms, s.rpm)
+			if s.tpm > 0 {
+				tpms = append(tpms, s.tpm)
+			}
+		}
+		r.LimitedRuns = len(samples)
+		r.LimitedRequestsPerMin = max(percentile(rpms, limitedRatePercentile), minLearnedRequestsPerMin)
+		if len(tpms) > 0 {
+			r.LimitedTokensPerMin = max(percentile(tpms, limitedRatePercentile), minLearnedTokensPerMin)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Runner < results[j].Runner })
+
+	out := make([]RunnerRate, len(results))
+	for i, r := range results {
+		out[i] = *r
+	}
+	return out, nil
+}
+
+// limitedSample is the throughput a runner sustained in the window leading
+// up to its first rate limit in one run.
+type limitedSample struct {
+	rpm, tpm float64
+}
+
+type attemptRow struct {
+	runner, state string
+	start         time.Time
+	tokens        int64 // the task's tokens, on the attempt that produced them
+}
+
+// queryLimitedRates measures, for every (run, runner) pair that hit a rate
+// limit, the requests and tokens the runner sent in the rateWindow before
+// its first RATE_LIMITED attempt. Attempt start times are reconstructed
+// from the task's start and the durations of its earlier attempts; runs
+// recorded without task start times are skipped.
+func queryLimitedRates(db *DB, since string) (map[string][]limitedSample, error) {
+	query := `SELECT a.run_id, a.task_id, a.attempt_num, a.runner, a.state, a.duration_ms,
+		te.started_at, te.runner, te.total_tokens,
+		(SELECT MAX(attempt_num) FROM attempts l WHERE l.run_id = a.run_id AND l.task_id = a.task_id)
+		FROM attempts a JOIN task_executions te ON te.run_id = a.run_id AND te.task_id = a.task_id
+		WHERE a.run_id IN (SELECT run_id FROM attempts WHERE state = 'RATE_LIMITED'`
+	var args []any
+	if since != "" {
+		query += ` AND created_at >= ?`
+		args = append(args, since)
+	}
+	query += `) ORDER BY a.run_id, a.task_id, a.attempt_num`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	runs := make(map[string][]attemptRow)
+	var runOrder []string
+	var lastTask string
+	var next time.Time // start of the task's next attempt
+	for rows.Next() {
+		var runID, taskID, runner, state, startedAt, usedRunner string
+		var num, durationMs, tokens, lastNum int64
+		if err := rows.Scan(&runID, &taskID, &num, &runner, &state, &durationMs,
+			&startedAt, &usedRunner, &tokens, &lastNum); err != nil {
+			return nil, err
+		}
+		if key := runID + "/" + taskID; key != lastTask {
+			lastTask = key
+			next, _ = time.Parse(time.RFC3339, startedAt)
+		}
+		if next.IsZero() {
+			continue
+		}
+		if _, ok := runs[runID]; !ok {
+			runOrder = append(runOrder, runID)
+		}
+		a := attemptRow{runner: runner, state: state, start: next}
+		if num == lastNum && runner == usedRunner {
+			a.tokens = tokens
+		}
+		runs[runID] = append(runs[runID], a)
+		next = next.Add(time.Duration(durationMs) * time.Millisecond)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	samples := make(map[string][]limitedSample)
+	for _, runID := range runOrder {
+		attempts := runs[runID]
+		firstLimit := make(map[string]time.Time)
+		for _, a := range attempts {
+			if t, ok := firstLimit[a.runner]; a.state == "RATE_LIMITED" && (!ok || a.start.Before(t)) {
+				firstLimit[a.runner] = a.start
+			}
+		}
+		for name, limitAt := range firstLimit {
+			from := limitAt.Add(-rateWindow)
+			var requests int
+			var tokens int64
+			earliest := limitAt
+			for _, a := range attempts {
+				if a.runner != name || a.start.Before(from) || a.start.After(limitAt) {
+					continue
+				}
+				requests++
+				tokens += a.tokens
+				if a.start.Before(earliest) {
+					earliest = a.start
+				}
+			}
+			minutes := max(limitAt.Sub(earliest), minRateWindow).Minutes()
+			samples[name] = append(samples[name], limitedSample{
+				rpm: float64(requests) / minutes,
+				tpm: float64(tokens) / minutes,
+			})
+		}
+	}
+	return samples, nil
+}
+
+// percentile returns the nearest-rank p-th percentile of values.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(idx, 0)]
+}
@@ -0,0 +1,168 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/task"
+)
+
+func TestQueryRunnerRates(t *testing.T) {
+	db := tempDB(t)
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	tasks := []task.Task{
+		{ID: "t1", Repo: "org/repo"},
+		{ID: "t2", Repo: "org/repo"},
+		{ID: "t3", Repo: "org/repo"},
+	}
+	report := &task.RunReport{
+		RunID:         "rates1",
+		TotalDuration: 2 * time.Minute,
+		Results: map[string]*task.TaskResult{
+			"t1": {
+				TaskID: "t1", State: task.StateCompleted, RunnerUsed: "claude", StartedAt: start,
+				TokensUsed: &task.TokenUsage{InputTokens: 90_000, OutputTokens: 10_000},
+				Attempts:   []task.AttemptInfo{{Runner: "claude", State: task.StateCompleted, Duration: time.Minute}},
+			},
+			"t2": {
+				TaskID: "t2", State: task.StateCompleted, RunnerUsed: "codex", StartedAt: start.Add(2 * time.Minute),
+				TokensUsed: &task.TokenUsage{InputTokens: 30_000, OutputTokens: 10_000},
+				Attempts: []task.AttemptInfo{
+					{Runner: "claude", State: task.StateRateLimited},
+					{Runner: "codex", State: task.StateCompleted},
+				},
+			},
+			"t3": {
+				TaskID: "t3", State: task.StateCompleted, RunnerUsed: "claude", StartedAt: start.Add(time.Minute),
+				TokensUsed: &task.TokenUsage{InputTokens: 50_000, OutputTokens: 10_000},
+				Attempts:   []task.AttemptInfo{{Runner: "claude", State: task.StateCompleted}},
+			},
+		},
+	}
+	if err := Record(db, report, tasks, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := QueryRunnerRates(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byRunner := make(map[string]RunnerRate)
+	for _, r := range rates {
+		byRunner[r.Runner] = r
+	}
+
+	claude := byRunner["claude"]
+	if claude.Tasks != 2 || claude.AvgTokensPerTask != 80_000 {
+		t.Errorf("claude history: got %d tasks, %d avg tokens", claude.Tasks, claude.AvgTokensPerTask)
+	}
+	if claude.LimitedRuns != 1 {
+		t.Fatalf("claude limited runs: got %d, want 1", claude.LimitedRuns)
+	}
+	// 3 requests and 160k tokens in the 2 minutes up to the rate limit
+	if claude.LimitedRequestsPerMin != 1.5 {
+		t.Errorf("claude rpm: got %.2f, want 1.5", claude.LimitedRequestsPerMin)
+	}
+	if claude.LimitedTokensPerMin != 80_000 {
+		t.Errorf("claude tpm: got %.0f, want 80000", claude.LimitedTokensPerMin)
+	}
+
+	codex := byRunner["codex"]
+	if codex.LimitedRuns != 0 || codex.LimitedRequestsPerMin != 0 {
+		t.Errorf("codex was never rate limited: %+v", codex)
+	}
+}
+
+func TestQueryRunnerRates_ShortRunUsesMinimumWindow(t *testing.T) {
+	db := tempDB(t)
+	report := &task.RunReport{
+		RunID:         "burst",
+		TotalDuration: 5 * time.Second,
+		Results: map[string]*task.TaskResult{
+			"t1": {
+				TaskID: "t1", State: task.StateRateLimited, RunnerUsed: "gemini", StartedAt: time.Now(),
+				Attempts: []task.AttemptInfo{{Runner: "gemini", State: task.StateRateLimited}},
+			},
+		},
+	}
+	if err := Record(db, report, []task.Task{{ID: "t1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	rates, err := QueryRunnerRates(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0].LimitedRequestsPerMin != 1 {
+		t.Errorf("expected 1 rpm over the minimum window, got %+v", rates)
+	}
+}
+
+// limitedRun records a run in which runner sent one attempt at each offset
+// from start, the last of them rate limited.
+func limitedRun(t *testing.T, db *DB, runID, runner string, start time.Time, offsets ...time.Duration) {
+	t.Helper()
+	report := &task.RunReport{RunID: runID, Results: make(map[string]*task.TaskResult)}
+	var tasks []task.Task
+	for i, off := range offsets {
+		id := fmt.Sprintf("t%d", i)
+		state := task.StateCompleted
+		if i == len(offsets)-1 {
+			state = task.StateRateLimited
+		}
+		tasks = append(tasks, task.Task{ID: id})
+		report.Results[id] = &task.TaskResult{
+			TaskID: id, State: state, RunnerUsed: runner, StartedAt: start.Add(off),
+			Attempts: []task.AttemptInfo{{Runner: runner, State: state, Duration: 10 * time.Second}},
+		}
+	}
+	report.TotalDuration = offsets[len(offsets)-1]
+	if err := Record(db, report, tasks, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryRunnerRates_SingleLimitInLongRun(t *testing.T) {
+	db := tempDB(t)
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	// three calls over three hours, the last one rate limited: the whole-run
+	// rate would be 0.017 rpm
+	limitedRun(t, db, "long", "claude", start, 0, 90*time.Minute, 180*time.Minute)
+	rates, err := QueryRunnerRates(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0].LimitedRequestsPerMin != minLearnedRequestsPerMin {
+		t.Errorf("single limit in a long run: got %+v, want the %v rpm floor", rates, minLearnedRequestsPerMin)
+	}
+
+	// a run that really hit the ceiling: 12 calls in the 2 minutes before
+	// the limit; the earlier slow run doesn't drag it down
+	var burst []time.Duration
+	for i := range 13 {
+		burst = append(burst, time.Duration(i)*10*time.Second)
+	}
+	limitedRun(t, db, "burst", "claude", start.Add(24*time.Hour), burst...)
+	rates, err = QueryRunnerRates(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0].LimitedRuns != 2 || rates[0].LimitedRequestsPerMin != 6.5 {
+		t.Errorf("got %+v, want 2 limited runs at 6.5 rpm", rates)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+	if got := percentile(values, 0.9); got != 9 {
+		t.Errorf("p90 = %v, want 9", got)
+	}
+	if got := percentile(values[:3], 0.9); got != 5 {
+		t.Errorf("p90 of 3 = %v, want the max 5", got)
+	}
+	if got := percentile(nil, 0.9); got != 0 {
+		t.Errorf("p90 of none = %v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/ppiankov/tokencontrol/internal/runner"
 	"github.com/ppiankov/tokencontrol/internal/task"
 )
 
@@ -74,8 +75,8 @@ func Record(db *DB, report *task.RunReport, tasks []task.Task, profiles map[stri
 			 duration_ms, started_at, ended_at,
 			 false_positive, auto_committed, attempts,
 			 repo, task_title, tasks_file,
-			 error, tokens_reported, merge_conflict, created_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			 error, tokens_reported, merge_conflict, provider, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			report.RunID+"/"+t.ID, report.RunID, t.ID,
 			res.RunnerUsed, model, res.State.String(), t.Difficulty, cascadeStep,
 			inputTokens, outputTokens, totalTokens, cost,
@@ -83,7 +84,8 @@ func Record(db *DB, report *task.RunReport, tasks []task.Task, profiles map[stri
 			formatTime(res.StartedAt), formatTime(res.EndedAt),
 			boolToInt(res.FalsePositive), boolToInt(res.AutoCommitted),
 			len(res.Attempts), t.Repo, t.Title, "",
-			errMsg, tokensReported, boolToInt(res.MergeConflict), now)
+			errMsg, tokensReported, boolToInt(res.MergeConflict),
+			runner.ProviderForRunner(res.RunnerUsed, profiles), now)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,256 @@
+package telemetry
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/scan"
+)
+
+// RecordScan persists a scan result. Findings of every status are stored so
+// baselined and suppressed findings keep their history.
+func RecordScan(db *DB, scanID, reposDir string, at time.Time, result *scan.ScanResult) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	created := at.UTC().Format(time.RFC3339)
+	_, err = tx.Exec(`INSERT OR REPLACE INTO scan_runs
+		(scan_id, repos_dir, repos_scanned, findings, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		scanID, reposDir, len(result.ReposScanned), len(result.Findings), created)
+	if err != nil {
+		return err
+	}
+	for _, repo := range result.ReposScanned {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO scan_repos (scan_id, repo) VALUES (?, ?)`, scanID, repo); err != nil {
+			return err
+		}
+	}
+	for _, f := range result.Findings {
+		_, err = tx.Exec(`INSERT INTO scan_findings
+			(scan_id, fingerprint, repo, check_id, category, severity, message, status, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			scanID, f.Fingerprint, f.Repo, f.Check, f.Category, f.Severity.String(), f.Message, f.Status, created)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ScanPoint summarizes one recorded scan. Suppressed findings are not
+// counted.
+type ScanPoint struct {
+	ScanID     string         `json:"scan_id"`
+	At         time.Time      `json:"at"`
+	Repos      int            `json:"repos"`
+	Findings   int            `json:"findings"`
+	Introduced int            `json:"introduced"` // not present in the repo's previous scan
+	Resolved   int            `json:"resolved"`   // present in the repo's previous scan, gone now
+	ByRepo     map[string]int `json:"by_repo"`
+	ByCategory map[string]int `json:"by_category"`
+}
+
+// ScanChange is a finding introduced or resolved between scans. For
+// resolved findings RunID and TaskID name the completed `<repo>-scan-<check>`
+// task that ran between the last scan that saw it and the one that didn't.
+type ScanChange struct {
+	Fingerprint  string    `json:"fingerprint"`
+	Repo         string    `json:"repo"`
+	Check        string    `json:"check"`
+	Category     string    `json:"category"`
+	Severity     string    `json:"severity"`
+	Message      string    `json:"message"`
+	IntroducedAt time.Time `json:"introduced_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	ResolvedAt   time.Time `json:"resolved_at,omitzero"`
+	RunID        string    `json:"run_id,omitempty"`
+	TaskID       string    `json:"task_id,omitempty"`
+}
+
+// ScanTrend is the scan history since a point in time.
+type ScanTrend struct {
+	Points        []ScanPoint   `json:"points"`
+	Introduced    []ScanChange  `json:"introduced"`
+	Resolved      []ScanChange  `json:"resolved"`
+	MeanTimeToFix time.Duration `json:"mean_time_to_fix"` // introduced to first scan without it; bounded by scan frequency
+	Open          int           `json:"open"`             // findings open at the latest scan
+}
+
+type scanRow struct {
+	id       string
+	at       time.Time
+	findings map[string][]scanFinding // by repo
+	// repos scanned; a repo absent from a scan keeps its findings open
+	repos []string
+}
+
+type scanFinding struct {
+	ScanChange
+	suppressed bool // kept open but not counted
+}
+
+// QueryScanTrend replays the recorded scans to find when each finding was
+// introduced and resolved. Only scans at or after since are reported, but
+// earlier scans establish which findings already existed. repo limits the
+// trend to one repo when set.
+func QueryScanTrend(db *DB, since time.Time, repo string) (*ScanTrend, error) {
+	scans, err := loadScans(db, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &ScanTrend{}
+	open := make(map[string]*ScanChange) // by fingerprint
+	seenRepo := make(map[string]bool)
+	var totalFix time.Duration
+
+	for _, s := range scans {
+		inWindow := !s.at.Before(since)
+		p := ScanPoint{
+			ScanID: s.id, At: s.at, Repos: len(s.repos),
+			ByRepo: make(map[string]int), ByCategory: make(map[string]int),
+		}
+		for _, r := range s.repos {
+			current := make(map[string]bool)
+			for _, f := range s.findings[r] {
+				current[f.Fingerprint] = true
+				if !f.suppressed {
+					p.Findings++
+					p.ByRepo[r]++
+					p.ByCategory[f.Category]++
+				}
+				if c, ok := open[f.Fingerprint]; ok {
+					c.LastSeenAt = s.at
+					continue
+				}
+				c := f.ScanChange
+				c.IntroducedAt, c.LastSeenAt = s.at, s.at
+				open[f.Fingerprint] = &c
+				if seenRepo[r] && inWindow && !f.suppressed {
+					p.Introduced++
+					trend.Introduced = append(trend.Introduced, c)
+				}
+			}
+			for fp, c := range open {
+				if c.Repo != r || current[fp] {
+					continue
+				}
+				delete(open, fp)
+				c.ResolvedAt = s.at
+				if !inWindow {
+					continue
+				}
+				p.Resolved++
+				if err := linkResolution(db, c); err != nil {
+					return nil, err
+				}
+				trend.Resolved = append(trend.Resolved, *c)
+				totalFix += c.ResolvedAt.Sub(c.IntroducedAt)
+			}
+			seenRepo[r] = true
+		}
+		if inWindow {
+			trend.Points = append(trend.Points, p)
+		}
+	}
+
+	trend.Open = len(open)
+	if n := len(trend.Resolved); n > 0 {
+		trend.MeanTimeToFix = totalFix / time.Duration(n)
+	}
+	sortChanges(trend.Introduced)
+	sortChanges(trend.Resolved)
+	return trend, nil
+}
+
+// loadScans reads all recorded scans in order with their findings.
+func loadScans(db *DB, repo string) ([]*scanRow, error) {
+	rows, err := db.conn.Query(`SELECT scan_id, created_at FROM scan_runs ORDER BY created_at, rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var scans []*scanRow
+	byID := make(map[string]*scanRow)
+	for rows.Next() {
+		var id, created string
+		if err := rows.Scan(&id, &created); err != nil {
+			return nil, err
+		}
+		at, _ := time.Parse(time.RFC3339, created)
+		s := &scanRow{id: id, at: at, findings: make(map[string][]scanFinding)}
+		scans = append(scans, s)
+		byID[id] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	repoRows, err := db.conn.Query(`SELECT scan_id, repo FROM scan_repos ORDER BY scan_id, repo`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = repoRows.Close() }()
+	for repoRows.Next() {
+		var id, r string
+		if err := repoRows.Scan(&id, &r); err != nil {
+			return nil, err
+		}
+		if s := byID[id]; s != nil && (repo == "" || r == repo) {
+			s.repos = append(s.repos, r)
+		}
+	}
+	if err := repoRows.Err(); err != nil {
+		return nil, err
+	}
+
+	findingRows, err := db.conn.Query(`SELECT scan_id, fingerprint, repo, check_id, category, severity, message, status
+		FROM scan_findings ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = findingRows.Close() }()
+	for findingRows.Next() {
+		var id, status string
+		var f scanFinding
+		if err := findingRows.Scan(&id, &f.Fingerprint, &f.Repo, &f.Check, &f.Category, &f.Severity, &f.Message, &status); err != nil {
+			return nil, err
+		}
+		s := byID[id]
+		if s == nil || (repo != "" && f.Repo != repo) {
+			continue
+		}
+		f.suppressed = status == scan.StatusSuppressed
+		s.findings[f.Repo] = append(s.findings[f.Repo], f)
+	}
+	return scans, findingRows.Err()
+}
+
+// linkResolution finds the completed scan task for a resolved finding.
+func linkResolution(db *DB, c *ScanChange) error {
+	row := db.conn.QueryRow(`SELECT run_id, task_id FROM task_executions
+		WHERE task_id = ? AND state = 'COMPLETED' AND created_at >= ? AND created_at <= ?
+		ORDER BY created_at DESC LIMIT 1`,
+		c.Repo+"-scan-"+c.Check, c.LastSeenAt.UTC().Format(time.RFC3339), c.ResolvedAt.UTC().Format(time.RFC3339))
+	err := row.Scan(&c.RunID, &c.TaskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func sortChanges(changes []ScanChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Repo != changes[j].Repo {
+			return changes[i].Repo < changes[j].Repo
+		}
+		return changes[i].Check < changes[j].Check
+	})
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ppiankov/tokencontrol/internal/scan"
+)
+
+func makeFinding(repo, check, category string) scan.Finding {
+	return scan.Finding{Repo: repo, Check: check, Category: category, Severity: scan.SeverityWarning,
+		Message: check + " in " + repo, Fingerprint: repo + "/" + check}
+}
+
+func TestQueryScanTrend(t *testing.T) {
+	db := tempDB(t)
+	day := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	scans := []*scan.ScanResult{
+		{ReposScanned: []string{"app", "lib"}, Findings: []scan.Finding{
+			makeFinding("app", "missing-readme", "structure"),
+			makeFinding("app", "missing-ci", "structure"),
+			makeFinding("lib", "go-no-tests", "go"),
+		}},
+		// app fixed its README, lib was not scanned
+		{ReposScanned: []string{"app"}, Findings: []scan.Finding{
+			makeFinding("app", "missing-ci", "structure"),
+		}},
+		// a new finding in lib, which also has a suppressed one
+		{ReposScanned: []string{"app", "lib"}, Findings: []scan.Finding{
+			makeFinding("app", "missing-ci", "structure"),
+			makeFinding("lib", "go-no-tests", "go"),
+			makeFinding("lib", "sec-env-committed", "security"),
+			func() scan.Finding {
+				f := makeFinding("lib", "missing-license", "structure")
+				f.Status = scan.StatusSuppressed
+				return f
+			}(),
+		}},
+	}
+	for i, r := range scans {
+		if err := RecordScan(db, string(rune('a'+i)), "/repos", day.AddDate(0, 0, 2*i), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the scan task that fixed the README ran between scan 1 and 2
+	_, err := db.conn.Exec(`INSERT INTO task_executions (id, run_id, task_id, runner, state, created_at)
+		VALUES ('r1/app-scan-missing-readme', 'r1', 'app-scan-missing-readme', 'codex', 'COMPLETED', ?)`,
+		day.AddDate(0, 0, 1).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trend, err := QueryScanTrend(db, time.Time{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trend.Points) != 3 {
+		t.Fatalf("points = %d, want 3", len(trend.Points))
+	}
+	if p := trend.Points[0]; p.Findings != 3 || p.Introduced != 0 || p.ByCategory["structure"] != 2 {
+		t.Errorf("first point = %+v", p)
+	}
+	if p := trend.Points[1]; p.Resolved != 1 || p.ByRepo["app"] != 1 || p.ByRepo["lib"] != 0 {
+		t.Errorf("second point = %+v", p)
+	}
+	if p := trend.Points[2]; p.Findings != 3 || p.Introduced != 1 || p.Resolved != 0 {
+		t.Errorf("third point = %+v", p)
+	}
+
+	if len(trend.Resolved) != 1 {
+		t.Fatalf("resolved = %+v", trend.Resolved)
+	}
+	r := trend.Resolved[0]
+	if r.Check != "missing-readme" || r.TaskID != "app-scan-missing-readme" || r.RunID != "r1" {
+		t.Errorf("resolved = %+v", r)
+	}
+	if trend.MeanTimeToFix != 48*time.Hour {
+		t.Errorf("MeanTimeToFix = %v, want 48h", trend.MeanTimeToFix)
+	}
+	if len(trend.Introduced) != 1 || trend.Introduced[0].Check != "sec-env-committed" {
+		t.Errorf("introduced = %+v", trend.Introduced)
+	}
+	if trend.Open != 4 {
+		t.Errorf("Open = %d, want 4", trend.Open)
+	}
+
+	// since and repo filters
+	trend, err = QueryScanTrend(db, day.AddDate(0, 0, 3), "lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trend.Points) != 1 || len(trend.Resolved) != 0 || len(trend.Introduced) != 1 || trend.Points[0].ByRepo["app"] != 0 {
+		t.Errorf("filtered trend = %+v", trend)
+	}
+}